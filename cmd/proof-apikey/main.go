@@ -0,0 +1,519 @@
+// Copyright 2025 Certen Protocol
+//
+// Certen API Key Administration
+// Creates, rotates, deactivates and edits API keys, and registers and revokes
+// validator attestation keys, in the configured database
+//
+// Usage:
+//   proof-apikey create -name NAME -type TYPE [-read] [-request] [-bulk] [-submit -validator ID] [-admin] [-expires TIME]
+//   proof-apikey list [-all]
+//   proof-apikey describe KEY_ID
+//   proof-apikey rotate [-overlap 24h] KEY_ID
+//   proof-apikey deactivate KEY_ID
+//   proof-apikey expire (-at TIME | -never) KEY_ID
+//   proof-apikey permissions [-read=BOOL] [-request=BOOL] [-bulk=BOOL] KEY_ID
+//   proof-apikey validator-register -validator ID -pubkey HEX [-description TEXT]
+//   proof-apikey validator-keys [-validator ID] [-all]
+//   proof-apikey validator-revoke VALIDATOR_KEY_ID
+//
+// The database is configured from the same environment variables as the
+// service (DATABASE_URL). Actions are recorded in the audit log as the local
+// user. Use this to create the first key with -admin; after that keys can
+// also be managed through /api/v1/admin/api-keys and
+// /api/v1/admin/validator-keys.
+
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/config"
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+const usage = `Usage: %s <command> [flags] [KEY_ID]
+
+Commands:
+  create        Create a key and print its plaintext once
+  list          List active keys (-all includes inactive and expired keys)
+  describe      Show a key and its audit log
+  rotate        Replace a key, keeping the old one for -overlap
+  deactivate    Deactivate a key immediately
+  expire        Set (-at) or clear (-never) a key's expiry
+  permissions   Edit read, request and bulk download permissions
+
+Validator key commands:
+  validator-register  Register a validator's Ed25519 attestation key
+  validator-keys      List active validator keys (-all includes revoked keys)
+  validator-revoke    Revoke a validator key
+`
+
+var logger = log.New(os.Stderr, "[APIKey] ", log.LstdFlags)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
+		os.Exit(2)
+	}
+
+	commands := map[string]func(ctx context.Context, keys *database.APIKeyRepository, args []string) error{
+		"create":      runCreate,
+		"list":        runList,
+		"describe":    runDescribe,
+		"rotate":      runRotate,
+		"deactivate":  runDeactivate,
+		"expire":      runExpire,
+		"permissions": runPermissions,
+	}
+	validatorCommands := map[string]func(ctx context.Context, keys *database.IngestionRepository, args []string) error{
+		"validator-register": runValidatorRegister,
+		"validator-keys":     runValidatorKeys,
+		"validator-revoke":   runValidatorRevoke,
+	}
+	run, ok := commands[os.Args[1]]
+	runValidator, validatorOK := validatorCommands[os.Args[1]]
+	if !ok && !validatorOK {
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("Failed to load configuration: %v", err)
+	}
+	dbClient, err := database.NewClient(cfg)
+	if err != nil {
+		logger.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbClient.Close()
+	repos := database.NewRepositories(dbClient)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if validatorOK {
+		err = runValidator(ctx, repos.Ingestion, os.Args[2:])
+	} else {
+		err = run(ctx, repos.APIKeys, os.Args[2:])
+	}
+	if err != nil {
+		logger.Printf("%s failed: %v", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// =============================================================================
+// COMMANDS
+// =============================================================================
+
+func runCreate(ctx context.Context, keys *database.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	name := fs.String("name", "", "client name (required)")
+	clientType := fs.String("type", "service", "client type: auditor, service, institution, developer, internal, validator")
+	read := fs.Bool("read", false, "allow reading proofs")
+	request := fs.Bool("request", false, "allow requesting proofs")
+	bulk := fs.Bool("bulk", false, "allow bulk export")
+	submit := fs.Bool("submit", false, "allow submitting proofs (requires -validator)")
+	admin := fs.Bool("admin", false, "allow API key administration")
+	validator := fs.String("validator", "", "validator the key is issued to")
+	rate := fs.Int("rate", 100, "requests per minute")
+	expires := fs.String("expires", "", "expiry as RFC 3339 time or duration from now (e.g. 720h)")
+	description := fs.String("description", "", "description")
+	email := fs.String("email", "", "contact email")
+	fs.Parse(args)
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+	if *submit && *validator == "" {
+		return fmt.Errorf("-submit requires -validator")
+	}
+	input := &database.NewAPIKey{
+		ClientName:       *name,
+		ClientType:       *clientType,
+		CanReadProofs:    *read,
+		CanRequestProofs: *request,
+		CanBulkDownload:  *bulk,
+		CanSubmitProofs:  *submit,
+		CanAdmin:         *admin,
+		ValidatorID:      optionalString(*validator),
+		RateLimitPerMin:  *rate,
+		Description:      optionalString(*description),
+		ContactEmail:     optionalString(*email),
+	}
+	if *expires != "" {
+		t, err := parseTime(*expires)
+		if err != nil {
+			return err
+		}
+		input.ExpiresAt = &t
+	}
+
+	created, err := keys.CreateKey(ctx, input, cliActor())
+	if err != nil {
+		return err
+	}
+	printKey(created.Key)
+	fmt.Printf("\nAPI key (shown once, store it now):\n%s\n", created.Plaintext)
+	return nil
+}
+
+func runList(ctx context.Context, keys *database.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	all := fs.Bool("all", false, "include inactive and expired keys")
+	fs.Parse(args)
+
+	list, err := keys.ListKeys(ctx, *all)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY_ID\tPREFIX\tCLIENT\tTYPE\tPERMISSIONS\tACTIVE\tEXPIRES")
+	for _, key := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n",
+			key.KeyID, derefString(key.KeyPrefix), key.ClientName, key.ClientType,
+			permissionList(key), key.IsActive, formatTime(key.ExpiresAt))
+	}
+	return tw.Flush()
+}
+
+func runDescribe(ctx context.Context, keys *database.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("describe", flag.ExitOnError)
+	fs.Parse(args)
+	keyID, err := keyIDArg(fs)
+	if err != nil {
+		return err
+	}
+
+	key, err := keys.GetKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return database.ErrAPIKeyNotFound
+	}
+	events, err := keys.ListAuditEvents(ctx, keyID, 100)
+	if err != nil {
+		return err
+	}
+
+	printKey(key)
+	fmt.Println("\nAudit log:")
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, e := range events {
+		fmt.Fprintf(tw, "  %s\t%s\t%s:%s\t%s\n",
+			e.CreatedAt.Format(time.RFC3339), e.Action, e.ActorType, e.ActorName, string(e.Details))
+	}
+	return tw.Flush()
+}
+
+func runRotate(ctx context.Context, keys *database.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("rotate", flag.ExitOnError)
+	overlap := fs.Duration("overlap", 24*time.Hour, "how long the old key keeps working")
+	fs.Parse(args)
+	keyID, err := keyIDArg(fs)
+	if err != nil {
+		return err
+	}
+	if *overlap < 0 {
+		return fmt.Errorf("-overlap must not be negative")
+	}
+
+	created, previous, err := keys.RotateKey(ctx, keyID, *overlap, cliActor())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rotated %s; it expires %s\n\n", previous.KeyID, formatTime(previous.ExpiresAt))
+	printKey(created.Key)
+	fmt.Printf("\nAPI key (shown once, store it now):\n%s\n", created.Plaintext)
+	return nil
+}
+
+func runDeactivate(ctx context.Context, keys *database.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("deactivate", flag.ExitOnError)
+	fs.Parse(args)
+	keyID, err := keyIDArg(fs)
+	if err != nil {
+		return err
+	}
+
+	key, err := keys.DeactivateKey(ctx, keyID, cliActor())
+	if err != nil {
+		return err
+	}
+	printKey(key)
+	return nil
+}
+
+func runExpire(ctx context.Context, keys *database.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("expire", flag.ExitOnError)
+	at := fs.String("at", "", "expiry as RFC 3339 time or duration from now (e.g. 720h)")
+	never := fs.Bool("never", false, "remove the expiry")
+	fs.Parse(args)
+	keyID, err := keyIDArg(fs)
+	if err != nil {
+		return err
+	}
+	if (*at == "") == !*never {
+		return fmt.Errorf("exactly one of -at or -never is required")
+	}
+
+	var expiresAt *time.Time
+	if *at != "" {
+		t, err := parseTime(*at)
+		if err != nil {
+			return err
+		}
+		expiresAt = &t
+	}
+
+	key, err := keys.SetKeyExpiry(ctx, keyID, expiresAt, cliActor())
+	if err != nil {
+		return err
+	}
+	printKey(key)
+	return nil
+}
+
+func runPermissions(ctx context.Context, keys *database.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("permissions", flag.ExitOnError)
+	var perms database.APIKeyPermissions
+	fs.Var(&optionalBool{&perms.CanReadProofs}, "read", "allow reading proofs")
+	fs.Var(&optionalBool{&perms.CanRequestProofs}, "request", "allow requesting proofs")
+	fs.Var(&optionalBool{&perms.CanBulkDownload}, "bulk", "allow bulk export")
+	fs.Parse(args)
+	keyID, err := keyIDArg(fs)
+	if err != nil {
+		return err
+	}
+	if perms.CanReadProofs == nil && perms.CanRequestProofs == nil && perms.CanBulkDownload == nil {
+		return fmt.Errorf("set at least one of -read, -request, -bulk")
+	}
+
+	key, err := keys.UpdateKeyPermissions(ctx, keyID, &perms, cliActor())
+	if err != nil {
+		return err
+	}
+	printKey(key)
+	return nil
+}
+
+// =============================================================================
+// VALIDATOR KEY COMMANDS
+// =============================================================================
+
+func runValidatorRegister(ctx context.Context, keys *database.IngestionRepository, args []string) error {
+	fs := flag.NewFlagSet("validator-register", flag.ExitOnError)
+	validator := fs.String("validator", "", "validator the key belongs to (required)")
+	pubkey := fs.String("pubkey", "", "hex Ed25519 public key (required)")
+	description := fs.String("description", "", "description")
+	fs.Parse(args)
+
+	if *validator == "" {
+		return fmt.Errorf("-validator is required")
+	}
+	publicKey, err := hex.DecodeString(*pubkey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("-pubkey must be a hex Ed25519 public key")
+	}
+
+	key, err := keys.RegisterValidatorKey(ctx, *validator, publicKey, optionalString(*description), cliActor())
+	if err != nil {
+		return err
+	}
+	printValidatorKey(key)
+	return nil
+}
+
+func runValidatorKeys(ctx context.Context, keys *database.IngestionRepository, args []string) error {
+	fs := flag.NewFlagSet("validator-keys", flag.ExitOnError)
+	validator := fs.String("validator", "", "only keys of this validator")
+	all := fs.Bool("all", false, "include revoked keys")
+	fs.Parse(args)
+
+	list, err := keys.ListValidatorKeys(ctx, *validator, *all)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY_ID\tVALIDATOR\tPUBLIC_KEY\tACTIVE\tREGISTERED\tREVOKED")
+	for _, key := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n",
+			key.KeyID, key.ValidatorID, hex.EncodeToString(key.PublicKey), key.IsActive,
+			key.RegisteredAt.Format(time.RFC3339), formatTime(key.RevokedAt))
+	}
+	return tw.Flush()
+}
+
+func runValidatorRevoke(ctx context.Context, keys *database.IngestionRepository, args []string) error {
+	fs := flag.NewFlagSet("validator-revoke", flag.ExitOnError)
+	fs.Parse(args)
+	keyID, err := keyIDArg(fs)
+	if err != nil {
+		return err
+	}
+
+	key, err := keys.RevokeValidatorKey(ctx, keyID, cliActor())
+	if err != nil {
+		return err
+	}
+	printValidatorKey(key)
+	return nil
+}
+
+// =============================================================================
+// HELPERS
+// =============================================================================
+
+// optionalBool is a boolean flag that stays nil unless given
+type optionalBool struct {
+	value **bool
+}
+
+func (b *optionalBool) String() string {
+	if b.value == nil || *b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(**b.value)
+}
+
+func (b *optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.value = &v
+	return nil
+}
+
+func (b *optionalBool) IsBoolFlag() bool { return true }
+
+func keyIDArg(fs *flag.FlagSet) (uuid.UUID, error) {
+	if fs.NArg() != 1 {
+		return uuid.Nil, errors.New("exactly one KEY_ID is required")
+	}
+	keyID, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid key ID: %w", err)
+	}
+	return keyID, nil
+}
+
+// parseTime accepts an RFC 3339 time or a duration from now
+func parseTime(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().UTC().Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 or a duration", s)
+	}
+	return t, nil
+}
+
+func cliActor() *database.APIKeyActor {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return &database.APIKeyActor{Type: "cli", Name: name}
+}
+
+func printKey(key *database.APIKey) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Key ID:\t%s\n", key.KeyID)
+	fmt.Fprintf(tw, "Prefix:\t%s\n", derefString(key.KeyPrefix))
+	fmt.Fprintf(tw, "Client:\t%s (%s)\n", key.ClientName, key.ClientType)
+	fmt.Fprintf(tw, "Permissions:\t%s\n", permissionList(key))
+	if key.ValidatorID != nil {
+		fmt.Fprintf(tw, "Validator:\t%s\n", *key.ValidatorID)
+	}
+	fmt.Fprintf(tw, "Rate limit:\t%d/min\n", key.RateLimitPerMin)
+	fmt.Fprintf(tw, "Active:\t%t\n", key.IsActive)
+	fmt.Fprintf(tw, "Expires:\t%s\n", formatTime(key.ExpiresAt))
+	if key.RotatedFrom != nil {
+		fmt.Fprintf(tw, "Rotated from:\t%s\n", *key.RotatedFrom)
+	}
+	if key.DeactivatedAt != nil {
+		fmt.Fprintf(tw, "Deactivated:\t%s\n", formatTime(key.DeactivatedAt))
+	}
+	fmt.Fprintf(tw, "Created:\t%s\n", key.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Last used:\t%s\n", formatTime(key.LastUsedAt))
+	tw.Flush()
+}
+
+func printValidatorKey(key *database.ValidatorKey) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Key ID:\t%s\n", key.KeyID)
+	fmt.Fprintf(tw, "Validator:\t%s\n", key.ValidatorID)
+	fmt.Fprintf(tw, "Public key:\t%s\n", hex.EncodeToString(key.PublicKey))
+	fmt.Fprintf(tw, "Active:\t%t\n", key.IsActive)
+	fmt.Fprintf(tw, "Description:\t%s\n", derefString(key.Description))
+	fmt.Fprintf(tw, "Registered:\t%s\n", key.RegisteredAt.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Revoked:\t%s\n", formatTime(key.RevokedAt))
+	tw.Flush()
+}
+
+func permissionList(key *database.APIKey) string {
+	var perms []string
+	for _, p := range []struct {
+		name string
+		set  bool
+	}{
+		{"read", key.CanReadProofs},
+		{"request", key.CanRequestProofs},
+		{"bulk", key.CanBulkDownload},
+		{"submit", key.CanSubmitProofs},
+		{"admin", key.CanAdmin},
+	} {
+		if p.set {
+			perms = append(perms, p.name)
+		}
+	}
+	if len(perms) == 0 {
+		return "-"
+	}
+	return strings.Join(perms, ",")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Certen Protocol
+//
+// Certen Proof Import
+// Restores proofs from a bulk JSON lines export into the configured database
+//
+// Usage:
+//   proof-import [-json] [export.jsonl[.gz]]
+//
+// Reads standard input when no file is given. The database is configured
+// from the same environment variables as the service (DATABASE_URL). Exits
+// non-zero if the import stopped early or any record was rejected.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/certen/proofs-service/pkg/config"
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/proofarchive"
+)
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "print the import report as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-json] [export.jsonl[.gz]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "[ProofImport] ", log.LstdFlags)
+
+	var input io.Reader = os.Stdin
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			logger.Fatalf("Failed to open export: %v", err)
+		}
+		defer f.Close()
+		input = f
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("Failed to load configuration: %v", err)
+	}
+	dbClient, err := database.NewClient(cfg)
+	if err != nil {
+		logger.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbClient.Close()
+	repos := database.NewRepositories(dbClient)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, importErr := proofarchive.NewImporter(repos.Ingestion).ImportJSONLines(ctx, input)
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		for _, e := range report.Errors {
+			fmt.Printf("line %d: rejected %s: %s\n", e.Line, e.ProofID, e.Error)
+		}
+		if dropped := report.Rejected - len(report.Errors); dropped > 0 {
+			fmt.Printf("... %d more rejected records not shown\n", dropped)
+		}
+		fmt.Printf("imported: %d\nskipped: %d\nrejected: %d\n", report.Imported, report.Skipped, report.Rejected)
+	}
+
+	if importErr != nil {
+		logger.Printf("Import stopped: %v", importErr)
+		os.Exit(1)
+	}
+	if report.Rejected > 0 {
+		os.Exit(1)
+	}
+}
@@ -21,8 +21,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/certen/proofs-service/pkg/auth"
 	"github.com/certen/proofs-service/pkg/config"
 	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/pipeline"
+	"github.com/certen/proofs-service/pkg/proofbundle"
 	"github.com/certen/proofs-service/pkg/server"
 )
 
@@ -66,19 +69,170 @@ func main() {
 		repos = database.NewRepositories(dbClient)
 	}
 
+	// Start batcher
+	var txBatcher pipeline.TransactionBatcher
+	if repos != nil && cfg.BatcherEnabled {
+		batcher := pipeline.NewBatcher(repos, &pipeline.BatcherConfig{
+			ValidatorID:       cfg.ValidatorID,
+			MaxBatchSize:      cfg.BatchMaxSize,
+			OnCadenceInterval: time.Duration(cfg.BatchOnCadenceInterval) * time.Second,
+			OnDemandInterval:  time.Duration(cfg.BatchOnDemandInterval) * time.Second,
+		}, logger)
+		batcher.Start()
+		defer batcher.Stop()
+		txBatcher = batcher
+		logger.Printf("Batcher started (max_size=%d, on_cadence=%ds)", cfg.BatchMaxSize, cfg.BatchOnCadenceInterval)
+	}
+
+	// Start anchoring pipeline
+	if repos != nil && cfg.AnchorBackend != "" {
+		var backend pipeline.AnchorBackend
+		switch cfg.AnchorBackend {
+		case "simulated":
+			backend = pipeline.NewSimulatedChain(&pipeline.SimulatedChainConfig{
+				BlockTime: time.Duration(cfg.AnchorSimBlockTime) * time.Second,
+			})
+		default:
+			logger.Fatalf("Unsupported anchor backend: %s", cfg.AnchorBackend)
+		}
+		anchorer := pipeline.NewAnchorer(repos, backend, &pipeline.AnchorerConfig{
+			ValidatorID:  cfg.ValidatorID,
+			PollInterval: time.Duration(cfg.AnchorPollInterval) * time.Second,
+		}, logger)
+		anchorer.Start()
+		defer anchorer.Stop()
+		logger.Printf("Anchoring pipeline started (backend=%s, chain=%s)", cfg.AnchorBackend, backend.TargetChain())
+	}
+
+	// Start Merkle consistency audit
+	if repos != nil && cfg.MerkleAuditEnabled {
+		auditor := pipeline.NewMerkleAuditor(repos, &pipeline.MerkleAuditorConfig{
+			Interval: time.Duration(cfg.MerkleAuditInterval) * time.Second,
+		}, logger)
+		auditor.Start()
+		defer auditor.Stop()
+		logger.Printf("Merkle auditor started (interval=%ds)", cfg.MerkleAuditInterval)
+	}
+
+	// Start BLS consensus timeout sweep
+	if repos != nil {
+		sweeper := pipeline.NewConsensusSweeper(repos, &pipeline.ConsensusSweeperConfig{
+			Interval: time.Duration(cfg.ConsensusSweepInterval) * time.Second,
+		}, logger)
+		sweeper.Start()
+		defer sweeper.Stop()
+		logger.Printf("Consensus sweeper started (interval=%ds, timeout=%ds)", cfg.ConsensusSweepInterval, cfg.ConsensusTimeout)
+	}
+
+	// Start expired idempotency key purge
+	if repos != nil {
+		purger := pipeline.NewIdempotencyPurger(repos, &pipeline.IdempotencyPurgerConfig{
+			Interval: time.Duration(cfg.IdempotencyPurgeInterval) * time.Second,
+		}, logger)
+		purger.Start()
+		defer purger.Stop()
+		logger.Printf("Idempotency purger started (interval=%ds, ttl=%ds)", cfg.IdempotencyPurgeInterval, cfg.IdempotencyTTL)
+	}
+
+	// Start access log retention purge
+	if repos != nil && cfg.AccessLogEnabled {
+		purger := pipeline.NewAccessLogPurger(repos, &pipeline.AccessLogPurgerConfig{
+			Retention: time.Duration(cfg.AccessLogRetentionDays) * 24 * time.Hour,
+			Interval:  time.Duration(cfg.AccessLogPurgeInterval) * time.Second,
+		}, logger)
+		purger.Start()
+		defer purger.Stop()
+		logger.Printf("Access log purger started (interval=%ds, retention=%dd)", cfg.AccessLogPurgeInterval, cfg.AccessLogRetentionDays)
+	}
+
+	// Start proof request processor
+	var requestProcessor *pipeline.RequestProcessor
+	if repos != nil && cfg.RequestWorkerEnabled {
+		requestProcessor = pipeline.NewRequestProcessor(repos, &pipeline.RequestProcessorConfig{
+			PollInterval:   time.Duration(cfg.RequestPollInterval) * time.Second,
+			BatchSize:      cfg.RequestBatchSize,
+			RequestTimeout: time.Duration(cfg.RequestTimeout) * time.Second,
+			Batcher:        txBatcher,
+		}, logger)
+		requestProcessor.Start()
+		defer requestProcessor.Stop()
+		logger.Printf("Proof request processor started (poll=%ds, batch=%d)", cfg.RequestPollInterval, cfg.RequestBatchSize)
+	}
+
+	// Load bundle signing keys
+	var bundleSigner *proofbundle.Signer
+	if cfg.BundleSigningKey != "" {
+		bundleSigner, err = proofbundle.ParseSigningKey(cfg.BundleSigningKey)
+		if err != nil {
+			logger.Fatalf("Invalid BUNDLE_SIGNING_KEY: %v", err)
+		}
+		logger.Printf("Bundle signing enabled (format=%s, key_id=%s)", proofbundle.SignedBundleFormat, bundleSigner.KeyID())
+	}
+	bundleKeys := proofbundle.NewKeySet(bundleSigner)
+	for _, key := range cfg.BundleRetiredKeys {
+		if err := bundleKeys.AddRetired(key); err != nil {
+			logger.Fatalf("Invalid BUNDLE_RETIRED_KEYS entry: %v", err)
+		}
+	}
+
+	// Load bearer token verification keys
+	tokenVerifier, err := auth.NewVerifier(&auth.VerifierConfig{
+		HMACSecret: []byte(cfg.JWTSecret),
+		JWKSFile:   cfg.JWTJWKSFile,
+		Issuer:     cfg.JWTIssuer,
+		Audience:   cfg.JWTAudience,
+		Leeway:     time.Duration(cfg.JWTLeeway) * time.Second,
+	})
+	if err != nil {
+		logger.Fatalf("Invalid JWT configuration: %v", err)
+	}
+	if tokenVerifier != nil {
+		logger.Printf("Bearer token authentication enabled (algorithms=%s)", strings.Join(tokenVerifier.Algorithms(), ","))
+	}
+
 	// Create HTTP handlers
 	proofHandlers := server.NewProofHandlers(repos, cfg.ValidatorID, logger)
-	bundleHandlers := server.NewBundleHandlers(repos, &server.BundleHandlersConfig{
-		ValidatorID:        cfg.ValidatorID,
-		RateLimitPerMinute: cfg.RateLimitRequests,
-	}, logger)
+	bundleConfig := &server.BundleHandlersConfig{
+		ValidatorID: cfg.ValidatorID,
+		BundleTTL:   time.Duration(cfg.BundleTTL) * time.Second,
+		Signer:      bundleSigner,
+		KeySet:      bundleKeys,
+	}
+	if requestProcessor != nil {
+		bundleConfig.QueueEstimator = requestProcessor
+	}
+	bundleHandlers := server.NewBundleHandlers(repos, bundleConfig, logger)
 	bulkHandlers := server.NewBulkHandlers(repos, &server.BulkHandlersConfig{
-		ValidatorID:        cfg.ValidatorID,
-		RateLimitPerMinute: cfg.RateLimitRequests,
-		MaxExportSize:      10000,
+		ValidatorID:    cfg.ValidatorID,
+		MaxExportSize:  10000,
+		MaxImportBytes: int64(cfg.BulkImportMaxMB) << 20,
 	}, logger)
 	txCenterHandlers := server.NewTransactionCenterHandlers(repos, cfg.ValidatorID, logger)
 	lifecycleHandlers := server.NewIntentLifecycleHandlers(repos, logger)
+	merkleHandlers := server.NewMerkleHandlers(repos, cfg.ValidatorID, logger)
+	accessLogHandlers := server.NewAccessLogHandlers(repos, logger)
+	downloadURLSigner := server.NewSignedURLSigner([]byte(cfg.DownloadURLSecret), logger)
+	signedURLHandlers := server.NewSignedURLHandlers(repos, &server.SignedURLHandlersConfig{
+		Signer:  downloadURLSigner,
+		Exports: bulkHandlers,
+		MaxTTL:  time.Duration(cfg.DownloadURLMaxTTL) * time.Second,
+	}, logger)
+	ingestionHandlers := server.NewIngestionHandlers(repos, &server.IngestionHandlersConfig{
+		ConsensusTimeout: time.Duration(cfg.ConsensusTimeout) * time.Second,
+	}, logger)
+	apiKeyValidator := server.NewAPIKeyValidator(repos)
+	adminHandlers := server.NewAdminHandlers(repos, &server.AdminHandlersConfig{
+		RotationOverlap: time.Duration(cfg.APIKeyRotationOverlap) * time.Second,
+		APIKeyValidator: apiKeyValidator,
+	}, logger)
+
+	// Resolve the caller of each request; routes declare the permission they need
+	authn := server.NewAuthMiddleware(repos, &server.AuthConfig{
+		APIKeyRequired:  cfg.APIKeyRequired,
+		TokenVerifier:   tokenVerifier,
+		APIKeyValidator: apiKeyValidator,
+	}, logger)
+	read := func(h http.HandlerFunc) http.Handler { return authn.Require(server.PermissionReadProofs, h) }
 
 	// Set up HTTP router
 	mux := http.NewServeMux()
@@ -94,44 +248,86 @@ func main() {
 		fmt.Fprintf(w, `{"status":"%s","service":"proof-service","version":"1.0.0"}`, status)
 	})
 
+	// Bundle signing keys
+	mux.HandleFunc("/.well-known/certen-bundle-keys", bundleHandlers.HandleGetBundleKeys)
+
+	// Verification of bundles held outside the service
+	mux.HandleFunc("/api/v1/bundles/verify", bundleHandlers.HandleVerifyUploadedBundle)
+
 	// API v1 Proof Discovery endpoints
-	mux.HandleFunc("/api/v1/proofs/tx/", proofHandlers.HandleGetProofByTxHash)
-	mux.HandleFunc("/api/v1/proofs/account/", proofHandlers.HandleGetProofsByAccount)
-	mux.HandleFunc("/api/v1/proofs/batch/", proofHandlers.HandleGetProofsByBatch)
-	mux.HandleFunc("/api/v1/proofs/anchor/", proofHandlers.HandleGetProofsByAnchor)
-	mux.HandleFunc("/api/v1/proofs/query", proofHandlers.HandleQueryProofs)
-	mux.HandleFunc("/api/v1/proofs/chain-tx/", proofHandlers.HandleGetRelatedProofsByChainTx)
+	mux.Handle("/api/v1/proofs/tx/", read(proofHandlers.HandleGetProofByTxHash))
+	mux.Handle("/api/v1/proofs/account/", read(proofHandlers.HandleGetProofsByAccount))
+	mux.Handle("/api/v1/proofs/batch/", read(proofHandlers.HandleGetProofsByBatch))
+	mux.Handle("/api/v1/proofs/anchor/", read(proofHandlers.HandleGetProofsByAnchor))
+	mux.Handle("/api/v1/proofs/query", read(proofHandlers.HandleQueryProofs))
+	mux.Handle("/api/v1/proofs/chain-tx/", read(proofHandlers.HandleGetRelatedProofsByChainTx))
 
 	// API v1 Proof Request endpoints
-	mux.HandleFunc("/api/v1/proofs/request", bundleHandlers.HandleRequestProof)
-	mux.HandleFunc("/api/v1/proofs/request/", bundleHandlers.HandleGetRequestStatus)
+	mux.Handle("/api/v1/proofs/request", authn.Require(server.PermissionRequestProofs, bundleHandlers.HandleRequestProof))
+	mux.Handle("/api/v1/proofs/request/", read(bundleHandlers.HandleGetRequestStatus))
+
+	// API v1 Proof Ingestion endpoints (validators), also served on the
+	// validator listener
+	validatorRoutes := func(mux *http.ServeMux) {
+		mux.Handle("/api/v1/proofs/ingest", authn.Require(server.PermissionSubmitProofs, ingestionHandlers.HandleIngestProof))
+		mux.Handle("/api/v1/attestations", authn.Require(server.PermissionSubmitProofs, ingestionHandlers.HandleSubmitAttestation))
+		mux.Handle("/api/v1/attestations/bls", authn.Require(server.PermissionSubmitProofs, ingestionHandlers.HandleSubmitBLSAttestation))
+		mux.Handle("/api/v1/attestations/bls/", authn.Require(server.PermissionAuthenticated, ingestionHandlers.HandleGetResultConsensus))
+	}
+	validatorRoutes(mux)
 
 	// API v1 Verification endpoints
 	mux.HandleFunc("/api/v1/proofs/verify/merkle", bundleHandlers.HandleVerifyMerkle)
 	mux.HandleFunc("/api/v1/proofs/verify/governance", bundleHandlers.HandleVerifyGovernance)
 
 	// API v1 Statistics and Health endpoints
-	mux.HandleFunc("/api/v1/proofs/stats", bulkHandlers.HandleGetProofStats)
+	mux.Handle("/api/v1/proofs/stats", read(bulkHandlers.HandleGetProofStats))
 	mux.HandleFunc("/api/v1/system/health", bulkHandlers.HandleGetSystemHealth)
 
 	// API v1 Bulk Export endpoints
-	mux.HandleFunc("/api/v1/proofs/bulk/export", bulkHandlers.HandleBulkExport)
-	mux.HandleFunc("/api/v1/proofs/bulk/export/", bulkHandlers.HandleGetExportStatus)
+	mux.Handle("/api/v1/proofs/bulk/export", authn.Require(server.PermissionBulkDownload, bulkHandlers.HandleBulkExport))
+	mux.Handle("/api/v1/proofs/bulk/export/", authn.Require(server.PermissionBulkDownload, bulkHandlers.HandleGetExportStatus))
+	mux.Handle("/api/v1/proofs/bulk/download/", downloadURLSigner.Allow(
+		http.HandlerFunc(bulkHandlers.HandleDownloadExport),
+		authn.Require(server.PermissionBulkDownload, bulkHandlers.HandleDownloadExport),
+	))
+	mux.Handle("/api/v1/proofs/bulk/import", authn.Require(server.PermissionBulkDownload, bulkHandlers.HandleBulkImport))
+
+	// API v1 Signed download URLs (bundles and exports shared without an API key)
+	mux.Handle("/api/v1/downloads/signed-urls", authn.Require(server.PermissionAuthenticated, signedURLHandlers.HandleCreateSignedURL))
+
+	// API v1 Admin endpoints (require an API key with can_admin)
+	mux.Handle("/api/v1/admin/api-keys", authn.Require(server.PermissionAdmin, adminHandlers.HandleAPIKeys))
+	mux.Handle("/api/v1/admin/api-keys/", authn.Require(server.PermissionAdmin, adminHandlers.HandleAPIKey))
+	mux.Handle("/api/v1/admin/validator-keys", authn.Require(server.PermissionAdmin, adminHandlers.HandleValidatorKeys))
+	mux.Handle("/api/v1/admin/validator-keys/", authn.Require(server.PermissionAdmin, adminHandlers.HandleValidatorKey))
 
 	// API v1 Intent Lifecycle endpoints (PostgreSQL source of truth)
-	mux.HandleFunc("/api/v1/intent/recent", lifecycleHandlers.HandleListRecent)
-	mux.HandleFunc("/api/v1/intent/status/", lifecycleHandlers.HandleListByStatus)
-	mux.HandleFunc("/api/v1/intent/user/", lifecycleHandlers.HandleListByUser)
-	mux.HandleFunc("/api/v1/intent/tx/", lifecycleHandlers.HandleGetByTxHash)
-	mux.HandleFunc("/api/v1/intent/", lifecycleHandlers.HandleGetByIntentID)
+	mux.Handle("/api/v1/intent/recent", read(lifecycleHandlers.HandleListRecent))
+	mux.Handle("/api/v1/intent/status/", read(lifecycleHandlers.HandleListByStatus))
+	mux.Handle("/api/v1/intent/user/", read(lifecycleHandlers.HandleListByUser))
+	mux.Handle("/api/v1/intent/tx/", read(lifecycleHandlers.HandleGetByTxHash))
+	mux.Handle("/api/v1/intent/", read(lifecycleHandlers.HandleGetByIntentID))
 
 	// API v1 Transaction Center endpoints (for web app integration)
-	mux.HandleFunc("/api/v1/intents/", txCenterHandlers.HandleIntentRouting)
-	mux.HandleFunc("/api/v1/user/", txCenterHandlers.HandleGetUserIntents)
-	mux.HandleFunc("/api/v1/audit/intents", txCenterHandlers.HandleSearchAuditTrail)
+	mux.Handle("/api/v1/intents/", read(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/bundle"):
+			bundleHandlers.HandleDownloadIntentBundle(w, r)
+		default:
+			txCenterHandlers.HandleIntentRouting(w, r)
+		}
+	}))
+	mux.Handle("/api/v1/user/", read(txCenterHandlers.HandleGetUserIntents))
+	mux.Handle("/api/v1/audit/intents", read(txCenterHandlers.HandleSearchAuditTrail))
+	mux.Handle("/api/v1/audit/access", read(accessLogHandlers.HandleListAccessLog))
+
+	// Merkle consistency audit
+	mux.Handle("/api/v1/audit/merkle/findings", read(merkleHandlers.HandleListAuditFindings))
 
 	// API v1 Proof Detail endpoints (with sub-paths)
-	mux.HandleFunc("/api/v1/proofs/", func(w http.ResponseWriter, r *http.Request) {
+	// A signed URL reaches only the bundle download it names
+	proofRoutes := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 		switch {
 		case strings.HasSuffix(path, "/bundle/verify"):
@@ -140,13 +336,80 @@ func main() {
 			bundleHandlers.HandleDownloadBundle(w, r)
 		case strings.HasSuffix(path, "/custody"):
 			bundleHandlers.HandleGetCustodyChain(w, r)
+		case strings.HasSuffix(path, "/merkle"):
+			merkleHandlers.HandleGetProofMerkle(w, r)
 		default:
 			proofHandlers.HandleGetProofByID(w, r)
 		}
 	})
+	mux.Handle("/api/v1/proofs/", downloadURLSigner.Allow(proofRoutes, read(proofRoutes)))
+
+	// API v1 Batch endpoints (with sub-paths)
+	mux.Handle("/api/v1/batches/", read(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/tree"):
+			merkleHandlers.HandleGetBatchTree(w, r)
+		case strings.HasSuffix(path, "/bundle/verify"):
+			bundleHandlers.HandleVerifyBatchBundle(w, r)
+		case strings.HasSuffix(path, "/bundle"):
+			bundleHandlers.HandleDownloadBatchBundle(w, r)
+		case strings.HasSuffix(path, "/stats"):
+			proofHandlers.HandleGetBatchStats(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	// Replay responses for retried mutating requests
+	idempotency := server.NewIdempotencyMiddleware(repos, &server.IdempotencyConfig{
+		TTL:   time.Duration(cfg.IdempotencyTTL) * time.Second,
+		Lease: time.Duration(cfg.IdempotencyLease) * time.Second,
+	}, logger)
+
+	// Limit requests per API key, token subject or anonymous client IP
+	rateLimit := server.NewRateLimitMiddleware(repos, &server.RateLimitConfig{
+		Window:           time.Duration(cfg.RateLimitWindow) * time.Second,
+		AnonymousLimit:   cfg.RateLimitRequests,
+		CertificateLimit: cfg.RateLimitValidatorRequests,
+		RouteCosts: map[string]int{
+			"/health":                     0,
+			"/api/v1/proofs/bulk/":        cfg.RateLimitBulkCost,
+			"/api/v1/proofs/bulk/export/": 1, // Job status polling
+		},
+		Shared: cfg.RateLimitShared,
+	}, logger)
+	logger.Printf("Rate limiting enabled (window=%ds, anonymous=%d, shared=%t)", cfg.RateLimitWindow, cfg.RateLimitRequests, cfg.RateLimitShared)
+
+	// Resolve client IPs, believing forwarding headers only from trusted proxies
+	trustedProxies, err := server.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		logger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	clientIP := server.NewClientIPMiddleware(&server.ClientIPConfig{
+		TrustedProxies: trustedProxies,
+		Header:         cfg.TrustedProxyHeader,
+	})
+	logger.Printf("Client IP resolution trusts %d proxy range(s) (header=%s)", len(trustedProxies), cfg.TrustedProxyHeader)
+
+	// Record who called which route for which proof, intent, account or user
+	accessLog := func(router *http.ServeMux) *server.AccessLogMiddleware {
+		if !cfg.AccessLogEnabled {
+			return server.NewAccessLogMiddleware(nil, nil, logger)
+		}
+		m := server.NewAccessLogMiddleware(repos, &server.AccessLogConfig{
+			Router:    router,
+			SkipPaths: []string{"/health"},
+			QueueSize: 1000,
+		}, logger)
+		m.Start()
+		return m
+	}
+	apiAccessLog := accessLog(mux)
+	defer apiAccessLog.Stop()
 
 	// Wrap with CORS middleware
-	handler := corsMiddleware(cfg.CORSOrigins)(mux)
+	handler := clientIP.Wrap(corsMiddleware(cfg.CORSOrigins)(rateLimit.WrapClientIP(apiAccessLog.Wrap(authn.Wrap(rateLimit.Wrap(idempotency.Wrap(mux)))))))
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -157,10 +420,63 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Load the TLS certificate and reload it when renewed
+	var certReloader *server.CertReloader
+	if cfg.TLSEnabled {
+		certReloader, err = server.NewCertReloader(&server.CertReloaderConfig{
+			CertFile: cfg.TLSCertFile,
+			KeyFile:  cfg.TLSKeyFile,
+			Interval: time.Duration(cfg.TLSReloadInterval) * time.Second,
+		}, logger)
+		if err != nil {
+			logger.Fatalf("Invalid TLS configuration: %v", err)
+		}
+		certReloader.Start()
+		defer certReloader.Stop()
+		srv.TLSConfig = server.NewServerTLSConfig(certReloader)
+	}
+
+	// Mutual TLS listener where validators authenticate with client certificates
+	var validatorSrv *http.Server
+	if cfg.ValidatorListenAddr != "" {
+		if certReloader == nil || cfg.ValidatorClientCAFile == "" {
+			logger.Fatalf("VALIDATOR_PORT requires TLS_ENABLED and VALIDATOR_CLIENT_CA_FILE")
+		}
+		validatorTLS, err := server.NewValidatorTLSConfig(certReloader, cfg.ValidatorClientCAFile)
+		if err != nil {
+			logger.Fatalf("Invalid validator TLS configuration: %v", err)
+		}
+		validatorMux := http.NewServeMux()
+		validatorRoutes(validatorMux)
+		validatorAccessLog := accessLog(validatorMux)
+		defer validatorAccessLog.Stop()
+		validatorSrv = &http.Server{
+			Addr:         cfg.ValidatorListenAddr,
+			Handler:      clientIP.Wrap(rateLimit.WrapClientIP(validatorAccessLog.Wrap(authn.Wrap(rateLimit.Wrap(idempotency.Wrap(validatorMux)))))),
+			TLSConfig:    validatorTLS,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+		go func() {
+			logger.Printf("Validator mTLS listener on %s", cfg.ValidatorListenAddr)
+			if err := validatorSrv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+				logger.Fatalf("Validator server error: %v", err)
+			}
+		}()
+	}
+
 	// Start server in goroutine
 	go func() {
-		logger.Printf("API server listening on %s", cfg.ListenAddr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if srv.TLSConfig != nil {
+			logger.Printf("API server listening on %s (TLS)", cfg.ListenAddr)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			logger.Printf("API server listening on %s", cfg.ListenAddr)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Fatalf("Server error: %v", err)
 		}
 	}()
@@ -176,6 +492,11 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if validatorSrv != nil {
+		if err := validatorSrv.Shutdown(ctx); err != nil {
+			logger.Printf("Validator server forced to shutdown: %v", err)
+		}
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatalf("Server forced to shutdown: %v", err)
 	}
@@ -207,8 +528,9 @@ func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
 			if allowed {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Idempotency-Key")
 				w.Header().Set("Access-Control-Max-Age", "86400")
+				w.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 			}
 
 			// Handle preflight
@@ -3,6 +3,15 @@ module github.com/certen/proofs-service
 go 1.21
 
 require (
+	github.com/consensys/gnark-crypto v0.13.0
 	github.com/google/uuid v1.6.0
 	github.com/lib/pq v1.10.9
 )
+
+require (
+	github.com/bits-and-blooms/bitset v1.7.0 // indirect
+	github.com/consensys/bavard v0.1.13 // indirect
+	github.com/mmcloughlin/addchain v0.4.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	rsc.io/tmplfunc v0.0.3 // indirect
+)
@@ -0,0 +1,283 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for JWT verification and JWKS loading
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	testSecret = []byte("test-secret-with-at-least-32-bytes!")
+	testNow    = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signToken builds a compact JWS; sign receives the signing input
+func signToken(t *testing.T, header, claims map[string]interface{}, sign func([]byte) []byte) string {
+	t.Helper()
+
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func hs256(secret []byte) func([]byte) []byte {
+	return func(input []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil)
+	}
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":     "user-123",
+		"iss":     "https://app.certen.io",
+		"aud":     "proofs-api",
+		"exp":     testNow.Add(time.Hour).Unix(),
+		"iat":     testNow.Unix(),
+		"scope":   "proofs:read bulk:export",
+		"org_adi": "acc://acme.acme",
+	}
+}
+
+func testVerifier(t *testing.T, config *VerifierConfig) *Verifier {
+	t.Helper()
+
+	v, err := NewVerifier(config)
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]interface{}) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	data, _ := json.Marshal(map[string]interface{}{"keys": keys})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey) map[string]interface{} {
+	return map[string]interface{}{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ed25519JWK(kid string, pub ed25519.PublicKey) map[string]interface{} {
+	return map[string]interface{}{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"kid": kid,
+		"x":   base64.RawURLEncoding.EncodeToString(pub),
+	}
+}
+
+// ============================================================================
+// HS256 Tests
+// ============================================================================
+
+func TestVerify_HS256(t *testing.T) {
+	v := testVerifier(t, &VerifierConfig{HMACSecret: testSecret, Issuer: "https://app.certen.io", Audience: "proofs-api"})
+
+	token := signToken(t, map[string]interface{}{"alg": "HS256", "typ": "JWT"}, validClaims(), hs256(testSecret))
+	claims, err := v.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if claims.Subject != "user-123" || !claims.HasScope(ScopeProofsRead) || !claims.HasScope(ScopeBulkExport) || claims.HasScope(ScopeAdmin) {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+	if claims.OrgADI != "acc://acme.acme" {
+		t.Errorf("Expected org_adi acc://acme.acme, got %q", claims.OrgADI)
+	}
+	if !claims.ExpiresAt.Equal(testNow.Add(time.Hour)) {
+		t.Errorf("Expected exp %v, got %v", testNow.Add(time.Hour), claims.ExpiresAt)
+	}
+}
+
+func TestVerify_Rejections(t *testing.T) {
+	v := testVerifier(t, &VerifierConfig{HMACSecret: testSecret, Issuer: "https://app.certen.io", Audience: "proofs-api", Leeway: time.Minute})
+	header := map[string]interface{}{"alg": "HS256"}
+
+	tests := []struct {
+		name   string
+		header map[string]interface{}
+		modify func(c map[string]interface{})
+		sign   func([]byte) []byte
+		want   error
+	}{
+		{"wrong secret", header, nil, hs256([]byte("other")), ErrInvalidToken},
+		{"none", map[string]interface{}{"alg": "none"}, nil, func([]byte) []byte { return nil }, ErrUnsupportedAlgorithm},
+		{"RS256 without JWKS", map[string]interface{}{"alg": "RS256"}, nil, hs256(testSecret), ErrUnsupportedAlgorithm},
+		{"expired", header, func(c map[string]interface{}) { c["exp"] = testNow.Add(-2 * time.Minute).Unix() }, hs256(testSecret), ErrTokenExpired},
+		{"not yet valid", header, func(c map[string]interface{}) { c["nbf"] = testNow.Add(2 * time.Minute).Unix() }, hs256(testSecret), ErrTokenExpired},
+		{"missing exp", header, func(c map[string]interface{}) { delete(c, "exp") }, hs256(testSecret), ErrInvalidToken},
+		{"missing sub", header, func(c map[string]interface{}) { delete(c, "sub") }, hs256(testSecret), ErrInvalidToken},
+		{"wrong issuer", header, func(c map[string]interface{}) { c["iss"] = "https://evil.example" }, hs256(testSecret), ErrInvalidToken},
+		{"wrong audience", header, func(c map[string]interface{}) { c["aud"] = []string{"other-api"} }, hs256(testSecret), ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			_, err := v.Verify(signToken(t, tt.header, claims, tt.sign))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Within the leeway an expired token is still accepted
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-30 * time.Second).Unix()
+	if _, err := v.Verify(signToken(t, header, claims, hs256(testSecret))); err != nil {
+		t.Errorf("Expected token within leeway to verify, got %v", err)
+	}
+
+	if _, err := v.Verify("not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for malformed token, got %v", err)
+	}
+}
+
+func TestVerify_AudienceArrayAndScp(t *testing.T) {
+	v := testVerifier(t, &VerifierConfig{HMACSecret: testSecret, Audience: "proofs-api"})
+
+	claims := validClaims()
+	claims["aud"] = []string{"web-app", "proofs-api"}
+	delete(claims, "scope")
+	claims["scp"] = []string{ScopeAdmin}
+
+	got, err := v.Verify(signToken(t, map[string]interface{}{"alg": "HS256"}, claims, hs256(testSecret)))
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !got.HasScope(ScopeAdmin) || len(got.Audience) != 2 {
+		t.Errorf("Unexpected claims: %+v", got)
+	}
+}
+
+// ============================================================================
+// JWKS Tests
+// ============================================================================
+
+func TestVerify_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	edPub, edPriv, _ := ed25519.GenerateKey(nil)
+
+	v := testVerifier(t, &VerifierConfig{JWKSFile: writeJWKS(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ed25519JWK("ed-1", edPub))})
+	if got := fmt.Sprint(v.Algorithms()); got != "[EdDSA RS256]" {
+		t.Errorf("Unexpected algorithms: %s", got)
+	}
+
+	signRS256 := func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("SignPKCS1v15 failed: %v", err)
+		}
+		return sig
+	}
+	signEdDSA := func(input []byte) []byte { return ed25519.Sign(edPriv, input) }
+
+	tests := []struct {
+		name   string
+		header map[string]interface{}
+		sign   func([]byte) []byte
+		want   error
+	}{
+		{"RS256 with kid", map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}, signRS256, nil},
+		{"RS256 without kid", map[string]interface{}{"alg": "RS256"}, signRS256, nil},
+		{"EdDSA with kid", map[string]interface{}{"alg": "EdDSA", "kid": "ed-1"}, signEdDSA, nil},
+		{"unknown kid", map[string]interface{}{"alg": "EdDSA", "kid": "ed-2"}, signEdDSA, ErrInvalidToken},
+		{"alg does not match key", map[string]interface{}{"alg": "EdDSA", "kid": "rsa-1"}, signEdDSA, ErrInvalidToken},
+		{"HS256 without secret", map[string]interface{}{"alg": "HS256", "kid": "rsa-1"}, hs256(rsaKey.PublicKey.N.Bytes()), ErrUnsupportedAlgorithm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(signToken(t, tt.header, validClaims(), tt.sign))
+			if tt.want == nil && err != nil {
+				t.Errorf("Verify failed: %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseKeySet_Rejects(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	edPub, _, _ := ed25519.GenerateKey(nil)
+	encOnly := ed25519JWK("enc", edPub)
+	encOnly["use"] = "enc"
+
+	tests := []struct {
+		name string
+		keys []map[string]interface{}
+	}{
+		{"small RSA key", []map[string]interface{}{rsaJWK("small", &small.PublicKey)}},
+		{"unsupported type", []map[string]interface{}{{"kty": "EC", "crv": "P-256", "kid": "ec"}}},
+		{"duplicate kid", []map[string]interface{}{ed25519JWK("a", edPub), ed25519JWK("a", edPub)}},
+		{"no signing keys", []map[string]interface{}{encOnly}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(map[string]interface{}{"keys": tt.keys})
+			if _, err := ParseKeySet(data); err == nil {
+				t.Error("Expected key set to be rejected")
+			}
+		})
+	}
+}
+
+func TestNewVerifier_Disabled(t *testing.T) {
+	v, err := NewVerifier(&VerifierConfig{})
+	if err != nil || v != nil {
+		t.Errorf("Expected no verifier without keys, got %v, %v", v, err)
+	}
+}
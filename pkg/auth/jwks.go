@@ -0,0 +1,177 @@
+// Copyright 2025 Certen Protocol
+//
+// JWKS Loading
+// Public keys for RS256 and EdDSA tokens from a local JSON Web Key Set file
+//
+// Only signing keys are loaded: RSA keys (kty "RSA", at least 2048 bits) for
+// RS256 and Ed25519 keys (kty "OKP", crv "Ed25519") for EdDSA. Keys marked
+// for encryption (use "enc") are skipped.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted
+const minRSAKeyBits = 2048
+
+// KeySet holds token verification keys by key ID
+type KeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// jsonWebKey is a key of a JWKS document (RFC 7517)
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+}
+
+// LoadKeySet reads a JWKS file
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	keys, err := ParseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// ParseKeySet parses a JWKS document. It fails if the document has no usable
+// signing keys or a key cannot be decoded.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{keys: make(map[string]crypto.PublicKey)}
+	for i, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (%q): %w", i, jwk.KeyID, err)
+		}
+		if _, dup := ks.keys[jwk.KeyID]; dup {
+			return nil, fmt.Errorf("duplicate key ID %q", jwk.KeyID)
+		}
+		ks.keys[jwk.KeyID] = key
+	}
+	if len(ks.keys) == 0 {
+		return nil, fmt.Errorf("no signing keys")
+	}
+	return ks, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		if jwk.Algorithm != "" && jwk.Algorithm != AlgRS256 {
+			return nil, fmt.Errorf("unsupported RSA algorithm %q", jwk.Algorithm)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid e")
+		}
+		key := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key is %d bits, need at least %d", key.N.BitLen(), minRSAKeyBits)
+		}
+		return key, nil
+
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		if jwk.Algorithm != "" && jwk.Algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("unsupported Ed25519 algorithm %q", jwk.Algorithm)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid x")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
+
+// Key returns the key for a token's kid and alg. Without a kid, the set's
+// only key of the matching type is used.
+func (ks *KeySet) Key(keyID, alg string) (crypto.PublicKey, error) {
+	if keyID != "" {
+		key, ok := ks.keys[keyID]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown key ID %q", ErrInvalidToken, keyID)
+		}
+		return key, nil
+	}
+
+	var match crypto.PublicKey
+	for _, key := range ks.keys {
+		if keyAlgorithm(key) != alg {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("%w: kid is required", ErrInvalidToken)
+		}
+		match = key
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: no %s key", ErrUnsupportedAlgorithm, alg)
+	}
+	return match, nil
+}
+
+// Algorithms lists the algorithms of the set's keys
+func (ks *KeySet) Algorithms() []string {
+	seen := make(map[string]bool)
+	var algs []string
+	for _, key := range ks.keys {
+		if alg := keyAlgorithm(key); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+func keyAlgorithm(key crypto.PublicKey) string {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return AlgRS256
+	case ed25519.PublicKey:
+		return AlgEdDSA
+	}
+	return ""
+}
@@ -0,0 +1,305 @@
+// Copyright 2025 Certen Protocol
+//
+// JWT Verification
+// Verifies bearer tokens issued to web app users and maps them to scopes
+//
+// Tokens are compact JWS (RFC 7515) signed with HS256 using the configured
+// shared secret, or with RS256 or EdDSA (Ed25519) using a key from a local
+// JWKS file. The algorithm must match the kind of key it is verified with, so
+// a public JWKS key can never be used as an HMAC secret, and unsigned ("none")
+// tokens are always rejected. Tokens must carry exp; nbf, iss and aud are
+// checked when present or configured.
+//
+// Scopes come from the space-separated "scope" claim (RFC 8693) or the "scp"
+// array used by some identity providers. The "org_adi" claim names the
+// organisation ADI a user belongs to.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scopes granted by tokens
+const (
+	ScopeProofsRead    = "proofs:read"
+	ScopeProofsRequest = "proofs:request"
+	ScopeBulkExport    = "bulk:export"
+	ScopeAdmin         = "admin"
+	ScopeOrgAdmin      = "org:admin" // Read the intents of the token's organisation
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Token verification errors
+var (
+	// ErrInvalidToken is returned when a token is malformed or its signature does not verify
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrTokenExpired is returned when a token is past its exp or before its nbf
+	ErrTokenExpired = errors.New("token expired or not yet valid")
+
+	// ErrUnsupportedAlgorithm is returned for algorithms without a configured key
+	ErrUnsupportedAlgorithm = errors.New("unsupported token algorithm")
+)
+
+// Claims are the verified claims of a token
+type Claims struct {
+	Subject   string    `json:"sub"`
+	Issuer    string    `json:"iss,omitempty"`
+	Audience  []string  `json:"aud,omitempty"`
+	ExpiresAt time.Time `json:"exp"`
+	IssuedAt  time.Time `json:"iat,omitempty"`
+	ID        string    `json:"jti,omitempty"`
+	Scopes    []string  `json:"scopes"`
+	OrgADI    string    `json:"org_adi,omitempty"`
+}
+
+// HasScope reports whether the token grants scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifierConfig contains configuration for token verification
+type VerifierConfig struct {
+	HMACSecret []byte        // Enables HS256
+	JWKSFile   string        // Enables RS256 and EdDSA with the file's keys
+	Issuer     string        // Required iss, if set
+	Audience   string        // Required aud entry, if set
+	Leeway     time.Duration // Allowed clock skew for exp and nbf
+}
+
+// Verifier verifies bearer tokens
+type Verifier struct {
+	hmacSecret []byte
+	keys       *KeySet
+	issuer     string
+	audience   string
+	leeway     time.Duration
+	now        func() time.Time
+}
+
+// NewVerifier creates a verifier. It returns nil, nil when neither a secret
+// nor a JWKS file is configured.
+func NewVerifier(config *VerifierConfig) (*Verifier, error) {
+	if config == nil || (len(config.HMACSecret) == 0 && config.JWKSFile == "") {
+		return nil, nil
+	}
+
+	v := &Verifier{
+		hmacSecret: config.HMACSecret,
+		issuer:     config.Issuer,
+		audience:   config.Audience,
+		leeway:     config.Leeway,
+		now:        time.Now,
+	}
+	if config.JWKSFile != "" {
+		keys, err := LoadKeySet(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+	}
+	return v, nil
+}
+
+// Algorithms lists the algorithms the verifier accepts
+func (v *Verifier) Algorithms() []string {
+	var algs []string
+	if len(v.hmacSecret) > 0 {
+		algs = append(algs, AlgHS256)
+	}
+	if v.keys != nil {
+		algs = append(algs, v.keys.Algorithms()...)
+	}
+	return algs
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Type      string `json:"typ"`
+}
+
+// Verify checks a compact JWS token and returns its claims
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected three segments", ErrInvalidToken)
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	if err := v.verifySignature(&header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var raw rawClaims
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	claims, err := raw.claims()
+	if err != nil {
+		return nil, err
+	}
+	if err := v.validate(claims, raw.NotBefore); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(header *tokenHeader, signingInput string, signature []byte) error {
+	switch header.Algorithm {
+	case AlgHS256:
+		if len(v.hmacSecret) == 0 {
+			return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, header.Algorithm)
+		}
+		mac := hmac.New(sha256.New, v.hmacSecret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+		return nil
+
+	case AlgRS256, AlgEdDSA:
+		if v.keys == nil {
+			return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, header.Algorithm)
+		}
+		key, err := v.keys.Key(header.KeyID, header.Algorithm)
+		if err != nil {
+			return err
+		}
+		if !verifyWithKey(key, header.Algorithm, []byte(signingInput), signature) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Algorithm)
+	}
+}
+
+func verifyWithKey(key crypto.PublicKey, alg string, message, signature []byte) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg != AlgRS256 {
+			return false
+		}
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return alg == AlgEdDSA && ed25519.Verify(k, message, signature)
+	}
+	return false
+}
+
+func (v *Verifier) validate(claims *Claims, notBefore *float64) error {
+	now := v.now()
+	if claims.ExpiresAt.IsZero() {
+		return fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	}
+	if !now.Before(claims.ExpiresAt.Add(v.leeway)) {
+		return ErrTokenExpired
+	}
+	if notBefore != nil && now.Add(v.leeway).Before(numericDate(*notBefore)) {
+		return ErrTokenExpired
+	}
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: sub is required", ErrInvalidToken)
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if v.audience != "" {
+		found := false
+		for _, aud := range claims.Audience {
+			if aud == v.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+		}
+	}
+	return nil
+}
+
+// rawClaims is the JSON form of the registered and scope claims
+type rawClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	IssuedAt  *float64        `json:"iat"`
+	ID        string          `json:"jti"`
+	Scope     string          `json:"scope"`
+	Scp       []string        `json:"scp"`
+	OrgADI    string          `json:"org_adi"`
+}
+
+func (raw *rawClaims) claims() (*Claims, error) {
+	claims := &Claims{
+		Subject: raw.Subject,
+		Issuer:  raw.Issuer,
+		ID:      raw.ID,
+		Scopes:  append(strings.Fields(raw.Scope), raw.Scp...),
+		OrgADI:  raw.OrgADI,
+	}
+	if raw.ExpiresAt != nil {
+		claims.ExpiresAt = numericDate(*raw.ExpiresAt)
+	}
+	if raw.IssuedAt != nil {
+		claims.IssuedAt = numericDate(*raw.IssuedAt)
+	}
+
+	// aud is a single string or an array of strings
+	if len(raw.Audience) > 0 && string(raw.Audience) != "null" {
+		var single string
+		if err := json.Unmarshal(raw.Audience, &single); err == nil {
+			claims.Audience = []string{single}
+		} else if err := json.Unmarshal(raw.Audience, &claims.Audience); err != nil {
+			return nil, fmt.Errorf("%w: aud must be a string or array of strings", ErrInvalidToken)
+		}
+	}
+	return claims, nil
+}
+
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
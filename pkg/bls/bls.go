@@ -0,0 +1,230 @@
+// Copyright 2025 Certen Protocol
+//
+// BLS12-381 Signatures
+// Decoding, pairing verification and aggregation of validator BLS signatures
+//
+// Validators use the minimal-signature-size variant: signatures are G1 points
+// and public keys are G2 points, in the ZCash compressed serialization (48 and
+// 96 bytes). Messages are hashed to G1 with the proof-of-possession ciphersuite,
+// which lets signatures over the same message be checked against the sum of
+// their public keys. Curve arithmetic, hashing and pairings are provided by
+// gnark-crypto.
+
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+// Sizes of compressed point encodings
+const (
+	G1Size = bls12381.SizeOfG1AffineCompressed
+	G2Size = bls12381.SizeOfG2AffineCompressed
+)
+
+// DST is the hash-to-curve domain separation tag validators sign with
+const DST = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"
+
+// Errors returned when decoding and verifying
+var (
+	ErrInvalidLength      = errors.New("invalid point length")
+	ErrInvalidEncoding    = errors.New("invalid point encoding")
+	ErrPointAtInfinity    = errors.New("point at infinity")
+	ErrNothingToAggregate = errors.New("nothing to aggregate")
+	ErrInvalidSignature   = errors.New("signature does not verify")
+)
+
+// =============================================================================
+// DECODING
+// =============================================================================
+
+// decodeG1 parses a compressed G1 point. gnark-crypto checks that it lies on
+// the curve and in the prime-order subgroup.
+func decodeG1(b []byte) (*bls12381.G1Affine, error) {
+	if len(b) != G1Size {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidLength, len(b), G1Size)
+	}
+	pt := new(bls12381.G1Affine)
+	if _, err := pt.SetBytes(b); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	return pt, nil
+}
+
+// decodeG2 parses a compressed G2 point with the same checks as decodeG1
+func decodeG2(b []byte) (*bls12381.G2Affine, error) {
+	if len(b) != G2Size {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidLength, len(b), G2Size)
+	}
+	pt := new(bls12381.G2Affine)
+	if _, err := pt.SetBytes(b); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	return pt, nil
+}
+
+// ValidateSignature checks that sig is a compressed G1 point in the subgroup
+// and not the identity
+func ValidateSignature(sig []byte) error {
+	pt, err := decodeG1(sig)
+	if err != nil {
+		return err
+	}
+	if pt.IsInfinity() {
+		return ErrPointAtInfinity
+	}
+	return nil
+}
+
+// ValidatePublicKey checks that pub is a compressed G2 point in the subgroup
+// and not the identity
+func ValidatePublicKey(pub []byte) error {
+	pt, err := decodeG2(pub)
+	if err != nil {
+		return err
+	}
+	if pt.IsInfinity() {
+		return ErrPointAtInfinity
+	}
+	return nil
+}
+
+// =============================================================================
+// VERIFICATION
+// =============================================================================
+
+// Verify checks sig against pub and msg with the pairing equation
+// e(sig, g2) == e(H(msg), pub). It returns ErrInvalidSignature if the
+// signature does not verify.
+func Verify(pub, msg, sig []byte) error {
+	pk, err := decodeG2(pub)
+	if err != nil {
+		return fmt.Errorf("public key: %w", err)
+	}
+	s, err := decodeG1(sig)
+	if err != nil {
+		return fmt.Errorf("signature: %w", err)
+	}
+	if pk.IsInfinity() || s.IsInfinity() {
+		return ErrPointAtInfinity
+	}
+
+	h, err := bls12381.HashToG1(msg, []byte(DST))
+	if err != nil {
+		return fmt.Errorf("failed to hash message: %w", err)
+	}
+	_, _, _, g2 := bls12381.Generators()
+	var negG2 bls12381.G2Affine
+	negG2.Neg(&g2)
+
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{*s, h}, []bls12381.G2Affine{negG2, *pk})
+	if err != nil {
+		return fmt.Errorf("pairing check failed: %w", err)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// FastAggregateVerify checks an aggregate signature by every key in pubs over
+// the same msg. The keys must each have proven possession of their secret
+// (here, by being registered in a validator set), otherwise a rogue key could
+// cancel the others out.
+func FastAggregateVerify(pubs [][]byte, msg, aggSig []byte) error {
+	aggPub, err := AggregatePublicKeys(pubs)
+	if err != nil {
+		return err
+	}
+	return Verify(aggPub, msg, aggSig)
+}
+
+// =============================================================================
+// AGGREGATION
+// =============================================================================
+
+// AggregateSignatures returns the compressed sum of the given G1 signatures
+func AggregateSignatures(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, ErrNothingToAggregate
+	}
+	var sum bls12381.G1Jac
+	for i, sig := range sigs {
+		pt, err := decodeG1(sig)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+		sum.AddMixed(pt)
+	}
+	var out bls12381.G1Affine
+	out.FromJacobian(&sum)
+	b := out.Bytes()
+	return b[:], nil
+}
+
+// AggregatePublicKeys returns the compressed sum of the given G2 public keys
+func AggregatePublicKeys(pubs [][]byte) ([]byte, error) {
+	if len(pubs) == 0 {
+		return nil, ErrNothingToAggregate
+	}
+	var sum bls12381.G2Jac
+	for i, pub := range pubs {
+		pt, err := decodeG2(pub)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d: %w", i, err)
+		}
+		sum.AddMixed(pt)
+	}
+	var out bls12381.G2Affine
+	out.FromJacobian(&sum)
+	b := out.Bytes()
+	return b[:], nil
+}
+
+// =============================================================================
+// SIGNING
+// =============================================================================
+
+// SecretKey is a BLS secret scalar. The service never signs attestations;
+// signing is provided for validator tooling and tests.
+type SecretKey struct {
+	s big.Int
+}
+
+// NewSecretKey derives a secret key from seed bytes, reduced modulo the
+// group order. It returns an error if the result is zero.
+func NewSecretKey(seed []byte) (*SecretKey, error) {
+	var e fr.Element
+	e.SetBytes(seed)
+	if e.IsZero() {
+		return nil, errors.New("secret key is zero")
+	}
+	sk := &SecretKey{}
+	e.BigInt(&sk.s)
+	return sk, nil
+}
+
+// PublicKey returns the compressed G2 public key of sk
+func (sk *SecretKey) PublicKey() []byte {
+	var pk bls12381.G2Affine
+	pk.ScalarMultiplicationBase(&sk.s)
+	b := pk.Bytes()
+	return b[:]
+}
+
+// Sign returns the compressed G1 signature of msg under sk
+func (sk *SecretKey) Sign(msg []byte) ([]byte, error) {
+	h, err := bls12381.HashToG1(msg, []byte(DST))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash message: %w", err)
+	}
+	var sig bls12381.G1Affine
+	sig.ScalarMultiplication(&h, &sk.s)
+	b := sig.Bytes()
+	return b[:], nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for BLS12-381 decoding, verification and aggregation
+
+package bls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+const (
+	g1GeneratorHex = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	g2GeneratorHex = "93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e" +
+		"024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Invalid hex: %v", err)
+	}
+	return b
+}
+
+func testKey(t *testing.T, seed string) *SecretKey {
+	t.Helper()
+	sum := sha256.Sum256([]byte(seed))
+	sk, err := NewSecretKey(sum[:])
+	if err != nil {
+		t.Fatalf("Failed to derive key: %v", err)
+	}
+	return sk
+}
+
+func mustSign(t *testing.T, sk *SecretKey, msg []byte) []byte {
+	t.Helper()
+	sig, err := sk.Sign(msg)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	return sig
+}
+
+// ============================================================================
+// Decoding Tests
+// ============================================================================
+
+func TestValidate_Generators(t *testing.T) {
+	if err := ValidateSignature(mustHex(t, g1GeneratorHex)); err != nil {
+		t.Errorf("G1 generator should be a valid signature point: %v", err)
+	}
+	if err := ValidatePublicKey(mustHex(t, g2GeneratorHex)); err != nil {
+		t.Errorf("G2 generator should be a valid public key point: %v", err)
+	}
+}
+
+func TestValidateSignature_Invalid(t *testing.T) {
+	infinity := make([]byte, G1Size)
+	infinity[0] = 0xc0
+
+	uncompressed := mustHex(t, g1GeneratorHex)
+	uncompressed[0] &^= 0x80
+
+	outOfRange := bytes.Repeat([]byte{0xff}, G1Size)
+	outOfRange[0] = 0x9f
+
+	// (0, 2) is on the curve but has order 3
+	lowOrder := make([]byte, G1Size)
+	lowOrder[0] = 0x80
+
+	tests := []struct {
+		name string
+		enc  []byte
+		want error
+	}{
+		{"short", make([]byte, 47), ErrInvalidLength},
+		{"infinity", infinity, ErrPointAtInfinity},
+		{"uncompressed flag", uncompressed, ErrInvalidEncoding},
+		{"x out of range", outOfRange, ErrInvalidEncoding},
+		{"outside subgroup", lowOrder, ErrInvalidEncoding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSignature(tt.enc); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidatePublicKey_WrongLength(t *testing.T) {
+	if err := ValidatePublicKey(mustHex(t, g1GeneratorHex)); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+}
+
+// ============================================================================
+// Verification Tests
+// ============================================================================
+
+func TestVerify(t *testing.T) {
+	sk := testKey(t, "validator-1")
+	other := testKey(t, "validator-2")
+	msg := sha256.Sum256([]byte("result"))
+	sig := mustSign(t, sk, msg[:])
+
+	if err := ValidateSignature(sig); err != nil {
+		t.Fatalf("Signature should be a valid point: %v", err)
+	}
+	if err := ValidatePublicKey(sk.PublicKey()); err != nil {
+		t.Fatalf("Public key should be a valid point: %v", err)
+	}
+	if err := Verify(sk.PublicKey(), msg[:], sig); err != nil {
+		t.Errorf("Expected signature to verify, got %v", err)
+	}
+
+	wrongMsg := sha256.Sum256([]byte("other result"))
+	tests := []struct {
+		name string
+		pub  []byte
+		msg  []byte
+		sig  []byte
+	}{
+		{"wrong message", sk.PublicKey(), wrongMsg[:], sig},
+		{"wrong key", other.PublicKey(), msg[:], sig},
+		{"valid point, not a signature", sk.PublicKey(), msg[:], mustHex(t, g1GeneratorHex)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.pub, tt.msg, tt.sig); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	msg := sha256.Sum256([]byte("result"))
+	var pubs, sigs [][]byte
+	for _, seed := range []string{"validator-1", "validator-2", "validator-3"} {
+		sk := testKey(t, seed)
+		pubs = append(pubs, sk.PublicKey())
+		sigs = append(sigs, mustSign(t, sk, msg[:]))
+	}
+
+	agg, err := AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatalf("Failed to aggregate: %v", err)
+	}
+	if err := FastAggregateVerify(pubs, msg[:], agg); err != nil {
+		t.Errorf("Expected aggregate to verify, got %v", err)
+	}
+	if err := FastAggregateVerify(pubs[:2], msg[:], agg); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for a missing signer, got %v", err)
+	}
+
+	partial, _ := AggregateSignatures(sigs[:2])
+	if err := FastAggregateVerify(pubs, msg[:], partial); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for a missing signature, got %v", err)
+	}
+}
+
+// ============================================================================
+// Aggregation Tests
+// ============================================================================
+
+func TestAggregateSignatures_KnownDouble(t *testing.T) {
+	g := mustHex(t, g1GeneratorHex)
+	want := "a572cbea904d67468808c8eb50a9450c9721db309128012543902d0ac358a62ae28f75bb8f1c7c42c39a8c5529bf0f4e"
+
+	agg, err := AggregateSignatures([][]byte{g, g})
+	if err != nil {
+		t.Fatalf("Failed to aggregate: %v", err)
+	}
+	if hex.EncodeToString(agg) != want {
+		t.Errorf("Expected 2G = %s, got %x", want, agg)
+	}
+}
+
+func TestAggregate_InverseCancels(t *testing.T) {
+	g := mustHex(t, g1GeneratorHex)
+	neg := append([]byte(nil), g...)
+	neg[0] ^= 0x20
+
+	agg, err := AggregateSignatures([][]byte{g, neg})
+	if err != nil {
+		t.Fatalf("Failed to aggregate: %v", err)
+	}
+	if err := ValidateSignature(agg); !errors.Is(err, ErrPointAtInfinity) {
+		t.Errorf("Expected G + (-G) to be infinity, got %x", agg)
+	}
+}
+
+func TestAggregate_Errors(t *testing.T) {
+	if _, err := AggregateSignatures(nil); !errors.Is(err, ErrNothingToAggregate) {
+		t.Errorf("Expected ErrNothingToAggregate, got %v", err)
+	}
+	if _, err := AggregatePublicKeys([][]byte{make([]byte, 10)}); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+}
@@ -36,12 +36,82 @@ type Config struct {
 	CORSOrigins []string
 	TLSEnabled  bool
 
+	// TLS Serving
+	TLSCertFile           string // PEM certificate chain
+	TLSKeyFile            string // PEM private key
+	TLSReloadInterval     int    // seconds between checks for renewed certificates
+	ValidatorListenAddr   string // mTLS listener for validators; empty disables it
+	ValidatorClientCAFile string // CAs that issue validator client certificates
+
+	// Client IP Resolution
+	TrustedProxies     []string // CIDRs of proxies whose forwarding header is trusted
+	TrustedProxyHeader string   // The one forwarding header those proxies set
+
+	// Bearer Tokens
+	JWTJWKSFile string // JWKS file with RS256/EdDSA verification keys
+	JWTIssuer   string // Required iss claim, if set
+	JWTAudience string // Required aud claim, if set
+	JWTLeeway   int    // seconds of clock skew allowed
+
 	// Rate Limiting
-	RateLimitRequests int
-	RateLimitWindow   int
+	RateLimitRequests          int  // per window per anonymous client IP
+	RateLimitValidatorRequests int  // per window per validator client certificate
+	RateLimitWindow            int  // seconds
+	RateLimitBulkCost          int  // requests a bulk export or import counts as
+	RateLimitShared            bool // share counters between replicas through Postgres
+
+	// Access Log
+	AccessLogEnabled       bool
+	AccessLogRetentionDays int // days entries are kept
+	AccessLogPurgeInterval int // seconds
+
+	// Signed Download URLs
+	DownloadURLSecret string // HMAC key; empty disables signed URLs
+	DownloadURLMaxTTL int    // seconds
 
 	// API Configuration
 	APIKeyRequired bool
+
+	// Request Processing
+	RequestWorkerEnabled bool
+	RequestPollInterval  int // seconds
+	RequestBatchSize     int
+	RequestTimeout       int // seconds
+
+	// Batching
+	BatcherEnabled         bool
+	BatchMaxSize           int
+	BatchOnCadenceInterval int // seconds
+	BatchOnDemandInterval  int // seconds
+
+	// Anchoring
+	AnchorBackend      string // "" (disabled) or "simulated"
+	AnchorPollInterval int    // seconds
+	AnchorSimBlockTime int    // seconds, simulated backend only
+
+	// Merkle Audit
+	MerkleAuditEnabled  bool
+	MerkleAuditInterval int // seconds
+
+	// BLS Result Consensus
+	ConsensusTimeout       int // seconds
+	ConsensusSweepInterval int // seconds
+
+	// Bulk Import
+	BulkImportMaxMB int
+
+	// Idempotency Keys
+	IdempotencyTTL           int // seconds
+	IdempotencyLease         int // seconds
+	IdempotencyPurgeInterval int // seconds
+
+	// API Key Administration
+	APIKeyRotationOverlap int // seconds a rotated key keeps working
+
+	// Proof Bundles
+	BundleTTL         int      // seconds, 0 = built bundles never expire
+	BundleSigningKey  string   // Ed25519 seed or private key (hex/base64); enables certen_v2
+	BundleRetiredKeys []string // Ed25519 public keys (hex/base64) of previous signing keys
 }
 
 // Load reads configuration from environment variables
@@ -69,12 +139,85 @@ func Load() (*Config, error) {
 		CORSOrigins: strings.Split(getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:3001"), ","),
 		TLSEnabled:  getEnvBool("TLS_ENABLED", false),
 
+		// TLS Serving
+		TLSCertFile:           getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:            getEnv("TLS_KEY_FILE", ""),
+		TLSReloadInterval:     getEnvInt("TLS_RELOAD_INTERVAL", 30),
+		ValidatorClientCAFile: getEnv("VALIDATOR_CLIENT_CA_FILE", ""),
+
+		// Client IP Resolution
+		TrustedProxies:     splitNonEmpty(getEnv("TRUSTED_PROXIES", "")),
+		TrustedProxyHeader: getEnv("TRUSTED_PROXY_HEADER", "X-Forwarded-For"),
+
+		// Bearer Tokens
+		JWTJWKSFile: getEnv("JWT_JWKS_FILE", ""),
+		JWTIssuer:   getEnv("JWT_ISSUER", ""),
+		JWTAudience: getEnv("JWT_AUDIENCE", ""),
+		JWTLeeway:   getEnvInt("JWT_LEEWAY", 60),
+
 		// Rate Limiting
-		RateLimitRequests: getEnvInt("RATE_LIMIT_REQUESTS", 100),
-		RateLimitWindow:   getEnvInt("RATE_LIMIT_WINDOW", 60),
+		RateLimitRequests:          getEnvInt("RATE_LIMIT_REQUESTS", 100),
+		RateLimitValidatorRequests: getEnvInt("RATE_LIMIT_VALIDATOR_REQUESTS", 1000),
+		RateLimitWindow:            getEnvInt("RATE_LIMIT_WINDOW", 60),
+		RateLimitBulkCost:          getEnvInt("RATE_LIMIT_BULK_COST", 10),
+		RateLimitShared:            getEnvBool("RATE_LIMIT_SHARED", false),
+
+		// Access Log
+		AccessLogEnabled:       getEnvBool("ACCESS_LOG_ENABLED", true),
+		AccessLogRetentionDays: getEnvInt("ACCESS_LOG_RETENTION_DAYS", 365),
+		AccessLogPurgeInterval: getEnvInt("ACCESS_LOG_PURGE_INTERVAL", 3600),
+
+		// Signed Download URLs
+		DownloadURLSecret: getEnv("DOWNLOAD_URL_SECRET", ""),
+		DownloadURLMaxTTL: getEnvInt("DOWNLOAD_URL_MAX_TTL", 604800),
 
 		// API Configuration
 		APIKeyRequired: getEnvBool("API_KEY_REQUIRED", false),
+
+		// Request Processing
+		RequestWorkerEnabled: getEnvBool("REQUEST_WORKER_ENABLED", true),
+		RequestPollInterval:  getEnvInt("REQUEST_POLL_INTERVAL", 5),
+		RequestBatchSize:     getEnvInt("REQUEST_BATCH_SIZE", 100),
+		RequestTimeout:       getEnvInt("REQUEST_TIMEOUT", 3600),
+
+		// Batching
+		BatcherEnabled:         getEnvBool("BATCHER_ENABLED", true),
+		BatchMaxSize:           getEnvInt("BATCH_MAX_SIZE", 1000),
+		BatchOnCadenceInterval: getEnvInt("BATCH_ON_CADENCE_INTERVAL", 900),
+		BatchOnDemandInterval:  getEnvInt("BATCH_ON_DEMAND_INTERVAL", 0),
+
+		// Anchoring
+		AnchorBackend:      getEnv("ANCHOR_BACKEND", ""),
+		AnchorPollInterval: getEnvInt("ANCHOR_POLL_INTERVAL", 10),
+		AnchorSimBlockTime: getEnvInt("ANCHOR_SIM_BLOCK_TIME", 12),
+
+		// Merkle Audit
+		MerkleAuditEnabled:  getEnvBool("MERKLE_AUDIT_ENABLED", true),
+		MerkleAuditInterval: getEnvInt("MERKLE_AUDIT_INTERVAL", 3600),
+
+		// BLS Result Consensus
+		ConsensusTimeout:       getEnvInt("CONSENSUS_TIMEOUT", 600),
+		ConsensusSweepInterval: getEnvInt("CONSENSUS_SWEEP_INTERVAL", 30),
+
+		// Bulk Import
+		BulkImportMaxMB: getEnvInt("BULK_IMPORT_MAX_MB", 256),
+
+		// Idempotency Keys
+		IdempotencyTTL:           getEnvInt("IDEMPOTENCY_TTL", 86400),
+		IdempotencyLease:         getEnvInt("IDEMPOTENCY_LEASE", 60),
+		IdempotencyPurgeInterval: getEnvInt("IDEMPOTENCY_PURGE_INTERVAL", 3600),
+
+		// API Key Administration
+		APIKeyRotationOverlap: getEnvInt("API_KEY_ROTATION_OVERLAP", 86400),
+
+		// Proof Bundles
+		BundleTTL:         getEnvInt("BUNDLE_TTL", 0),
+		BundleSigningKey:  getEnv("BUNDLE_SIGNING_KEY", ""),
+		BundleRetiredKeys: splitNonEmpty(getEnv("BUNDLE_RETIRED_KEYS", "")),
+	}
+
+	if port := getEnv("VALIDATOR_PORT", ""); port != "" {
+		cfg.ValidatorListenAddr = getEnv("API_HOST", "0.0.0.0") + ":" + port
 	}
 
 	return cfg, nil
@@ -123,6 +266,17 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// splitNonEmpty splits a comma-separated list, dropping empty entries
+func splitNonEmpty(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
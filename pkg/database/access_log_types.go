@@ -0,0 +1,51 @@
+// Copyright 2025 Certen Protocol
+//
+// Access Log Types - Entries of the append-only API access log
+
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Access log principal types; the others match the server's principal types
+const (
+	AccessPrincipalAnonymous = "anonymous"
+	AccessPrincipalRejected  = "rejected" // Credentials were presented and refused
+)
+
+// AccessLogEntry represents a row in the api_access_log table
+type AccessLogEntry struct {
+	AccessID      int64      `json:"access_id"`
+	OccurredAt    time.Time  `json:"occurred_at"`
+	PrincipalType string     `json:"principal_type"`
+	PrincipalName *string    `json:"principal_name,omitempty"`
+	KeyID         *uuid.UUID `json:"key_id,omitempty"`
+	ClientIP      *string    `json:"client_ip,omitempty"`
+	Method        string     `json:"method"`
+	Route         string     `json:"route"`
+	Path          string     `json:"path"`
+	ProofID       *uuid.UUID `json:"proof_id,omitempty"`
+	IntentID      *string    `json:"intent_id,omitempty"`
+	AccountURL    *string    `json:"account_url,omitempty"`
+	UserID        *string    `json:"user_id,omitempty"`
+	StatusCode    int        `json:"status_code"`
+	ResponseBytes int64      `json:"response_bytes"`
+	LatencyMS     int        `json:"latency_ms"`
+}
+
+// AccessLogFilter selects access log entries for listing
+type AccessLogFilter struct {
+	PrincipalName *string
+	KeyID         *uuid.UUID
+	ProofID       *uuid.UUID
+	IntentID      *string
+	AccountURL    *string
+	UserID        *string
+	Since         *time.Time
+	Until         *time.Time
+	Limit         int
+	Offset        int
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Certen Protocol
+//
+// Consensus Types - Batch attestations and consensus state for multi-validator anchoring
+
+package database
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConsensusState represents the state of a batch's multi-validator consensus
+type ConsensusState string
+
+const (
+	ConsensusStateInitiated  ConsensusState = "initiated"
+	ConsensusStateCollecting ConsensusState = "collecting"
+	ConsensusStateQuorumMet  ConsensusState = "quorum_met"
+	ConsensusStateCompleted  ConsensusState = "completed"
+	ConsensusStateFailed     ConsensusState = "failed"
+	ConsensusStateTimeout    ConsensusState = "timeout"
+)
+
+// BatchAttestation represents a row in the batch_attestations table
+type BatchAttestation struct {
+	AttestationID   uuid.UUID  `json:"attestation_id"`
+	BatchID         uuid.UUID  `json:"batch_id"`
+	ValidatorID     string     `json:"validator_id"`
+	MerkleRoot      []byte     `json:"merkle_root"`    // 32 bytes
+	BLSSignature    []byte     `json:"bls_signature"`  // 48 bytes BLS12-381
+	BLSPublicKey    []byte     `json:"bls_public_key"` // 96 bytes BLS12-381 G2
+	TxCount         int        `json:"tx_count"`
+	BlockHeight     int64      `json:"block_height"`
+	AttestationTime time.Time  `json:"attestation_time"`
+	SignatureValid  *bool      `json:"signature_valid,omitempty"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// ConsensusEntry represents a row in the consensus_entries table
+type ConsensusEntry struct {
+	EntryID            uuid.UUID       `json:"entry_id"`
+	BatchID            uuid.UUID       `json:"batch_id"`
+	MerkleRoot         []byte          `json:"merkle_root"`
+	AnchorTxHash       *string         `json:"anchor_tx_hash,omitempty"`
+	BlockNumber        *int64          `json:"block_number,omitempty"`
+	TxCount            int             `json:"tx_count"`
+	State              ConsensusState  `json:"state"`
+	AttestationCount   int             `json:"attestation_count"`
+	RequiredCount      int             `json:"required_count"`
+	QuorumFraction     float64         `json:"quorum_fraction"`
+	AggregateSignature []byte          `json:"aggregate_signature,omitempty"`
+	AggregatePubkey    []byte          `json:"aggregate_pubkey,omitempty"`
+	StartTime          time.Time       `json:"start_time"`
+	LastUpdate         time.Time       `json:"last_update"`
+	CompletedAt        *time.Time      `json:"completed_at,omitempty"`
+	ResultJSON         json.RawMessage `json:"result_json,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+}
+
+// ResultConsensusEntry represents a consensus_entries row that collects BLS
+// attestations for an external chain result rather than a batch
+type ResultConsensusEntry struct {
+	EntryID            uuid.UUID       `json:"entry_id"`
+	ResultID           uuid.UUID       `json:"result_id"`
+	SnapshotID         uuid.UUID       `json:"snapshot_id"`
+	MessageHash        []byte          `json:"message_hash"` // Stored in merkle_root
+	State              ConsensusState  `json:"state"`
+	AttestationCount   int             `json:"attestation_count"`
+	RequiredCount      int             `json:"required_count"` // Fewest validators that can reach the threshold
+	QuorumFraction     float64         `json:"quorum_fraction"`
+	TotalWeight        int64           `json:"total_weight"`
+	ThresholdWeight    int64           `json:"threshold_weight"`
+	AchievedWeight     int64           `json:"achieved_weight"`
+	AggregateSignature []byte          `json:"aggregate_signature,omitempty"`
+	AggregatePubkey    []byte          `json:"aggregate_pubkey,omitempty"`
+	AggregationID      *uuid.UUID      `json:"aggregation_id,omitempty"`
+	StartTime          time.Time       `json:"start_time"`
+	LastUpdate         time.Time       `json:"last_update"`
+	Deadline           time.Time       `json:"deadline"`
+	CompletedAt        *time.Time      `json:"completed_at,omitempty"`
+	ResultJSON         json.RawMessage `json:"result_json,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+}
+
+// IsOpen reports whether the entry still accepts attestations
+func (e *ResultConsensusEntry) IsOpen() bool {
+	return e.State == ConsensusStateInitiated || e.State == ConsensusStateCollecting
+}
@@ -30,6 +30,63 @@ var (
 	// ErrTransactionNotFound is returned when a batch transaction is not found
 	ErrTransactionNotFound = errors.New("transaction not found")
 
+	// ErrPricingTierNotFound is returned when no active pricing tier matches a request type
+	ErrPricingTierNotFound = errors.New("pricing tier not found")
+
+	// ErrMerkleAuditRunNotFound is returned when no Merkle audit run has been recorded
+	ErrMerkleAuditRunNotFound = errors.New("merkle audit run not found")
+
+	// ErrConsensusEntryNotFound is returned when a batch has no consensus entry
+	ErrConsensusEntryNotFound = errors.New("consensus entry not found")
+
 	// ErrIntentLifecycleNotFound is returned when an intent lifecycle record is not found
 	ErrIntentLifecycleNotFound = errors.New("intent lifecycle not found")
+
+	// ErrIntentNotFound is returned when a multi-leg intent record is not found
+	ErrIntentNotFound = errors.New("intent not found")
+
+	// ErrValidatorKeyNotRegistered is returned when an attestation is signed by a key not registered to its validator
+	ErrValidatorKeyNotRegistered = errors.New("validator key not registered")
+
+	// ErrValidatorKeyNotFound is returned when a validator key record is not found
+	ErrValidatorKeyNotFound = errors.New("validator key not found")
+
+	// ErrValidatorKeyRevoked is returned when revoking a validator key that is already revoked
+	ErrValidatorKeyRevoked = errors.New("validator key is already revoked")
+
+	// ErrDuplicateAttestation is returned when a validator resubmits the attestation it already made
+	ErrDuplicateAttestation = errors.New("duplicate attestation")
+
+	// ErrConflictingAttestation is returned when a validator attests to different content than it already attested
+	ErrConflictingAttestation = errors.New("conflicting attestation")
+
+	// ErrAttestationMismatch is returned when an attestation does not match the stored Merkle root or anchor
+	ErrAttestationMismatch = errors.New("attestation does not match stored record")
+
+	// ErrExternalResultNotFound is returned when an external chain result is not found
+	ErrExternalResultNotFound = errors.New("external chain result not found")
+
+	// ErrValidatorSetNotFound is returned when no validator set snapshot exists for a chain
+	ErrValidatorSetNotFound = errors.New("validator set snapshot not found")
+
+	// ErrValidatorNotInSet is returned when an attesting validator is not part of the validator set snapshot
+	ErrValidatorNotInSet = errors.New("validator not in validator set")
+
+	// ErrInvalidBLSSignature is returned when a BLS signature does not verify against the validator's key and message
+	ErrInvalidBLSSignature = errors.New("BLS signature does not verify")
+
+	// ErrConsensusClosed is returned when an attestation arrives after its consensus entry reached quorum or timed out
+	ErrConsensusClosed = errors.New("consensus entry is closed")
+
+	// ErrImportConflict is returned when an imported record differs from the one already stored under its ID
+	ErrImportConflict = errors.New("import conflicts with stored record")
+
+	// ErrAPIKeyNotFound is returned when an API key record is not found
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrAPIKeyInactive is returned when an administrative action needs an active key
+	ErrAPIKeyInactive = errors.New("API key is inactive")
+
+	// ErrSignedURLNotRedeemable is returned when a signed URL is unknown, expired or already used
+	ErrSignedURLNotRedeemable = errors.New("signed URL is expired or already used")
 )
@@ -49,3 +49,11 @@ type IntentLifecycleEnriched struct {
 	LegCount    int      `json:"leg_count,omitempty"`
 	AllChains   []string `json:"all_chains,omitempty"`
 }
+
+// IntentScope limits lifecycle listings to the intents of one user and,
+// when OrganizationADI is set, those of one organisation. A nil scope is
+// unrestricted.
+type IntentScope struct {
+	UserID          string
+	OrganizationADI string
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Certen Protocol
+//
+// Merkle Audit Types - Runs and findings of the Merkle consistency audit
+
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerkleFindingType classifies a Merkle consistency discrepancy
+type MerkleFindingType string
+
+const (
+	MerkleFindingTreeRebuildFailed  MerkleFindingType = "tree_rebuild_failed"  // Leaves cannot form a tree (index gap, bad hash)
+	MerkleFindingBatchRootMismatch  MerkleFindingType = "batch_root_mismatch"  // anchor_batches.merkle_root differs
+	MerkleFindingAnchorRootMismatch MerkleFindingType = "anchor_root_mismatch" // anchor_records.merkle_root differs
+	MerkleFindingProofRootMismatch  MerkleFindingType = "proof_root_mismatch"  // proof_artifacts.merkle_root differs
+	MerkleFindingProofLeafMismatch  MerkleFindingType = "proof_leaf_mismatch"  // proof_artifacts.leaf_hash differs from its leaf
+	MerkleFindingLeafPathMismatch   MerkleFindingType = "leaf_path_mismatch"   // batch_transactions.merkle_path differs
+)
+
+// MerkleAuditRunStatus represents the state of an audit run
+type MerkleAuditRunStatus string
+
+const (
+	MerkleAuditRunRunning   MerkleAuditRunStatus = "running"
+	MerkleAuditRunCompleted MerkleAuditRunStatus = "completed"
+	MerkleAuditRunFailed    MerkleAuditRunStatus = "failed"
+)
+
+// MerkleAuditRun represents a row in the merkle_audit_runs table
+type MerkleAuditRun struct {
+	RunID            uuid.UUID            `json:"run_id"`
+	StartedAt        time.Time            `json:"started_at"`
+	CompletedAt      *time.Time           `json:"completed_at,omitempty"`
+	BatchesChecked   int                  `json:"batches_checked"`
+	FindingsOpened   int                  `json:"findings_opened"`
+	FindingsResolved int                  `json:"findings_resolved"`
+	Status           MerkleAuditRunStatus `json:"status"`
+	ErrorMessage     *string              `json:"error_message,omitempty"`
+}
+
+// MerkleAuditFinding represents a row in the merkle_audit_findings table
+type MerkleAuditFinding struct {
+	FindingID     uuid.UUID         `json:"finding_id"`
+	BatchID       uuid.UUID         `json:"batch_id"`
+	FindingType   MerkleFindingType `json:"finding_type"`
+	Subject       string            `json:"subject"`
+	ProofID       *uuid.UUID        `json:"proof_id,omitempty"`
+	AnchorID      *uuid.UUID        `json:"anchor_id,omitempty"`
+	TreeIndex     *int              `json:"tree_index,omitempty"`
+	ExpectedValue *string           `json:"expected_value,omitempty"`
+	ActualValue   *string           `json:"actual_value,omitempty"`
+	Details       *string           `json:"details,omitempty"`
+	FirstRunID    uuid.UUID         `json:"first_run_id"`
+	LastRunID     uuid.UUID         `json:"last_run_id"`
+	DetectedAt    time.Time         `json:"detected_at"`
+	LastSeenAt    time.Time         `json:"last_seen_at"`
+	ResolvedAt    *time.Time        `json:"resolved_at,omitempty"`
+}
+
+// NewMerkleAuditFinding contains the fields of a detected discrepancy
+type NewMerkleAuditFinding struct {
+	BatchID       uuid.UUID
+	FindingType   MerkleFindingType
+	Subject       string
+	ProofID       *uuid.UUID
+	AnchorID      *uuid.UUID
+	TreeIndex     *int
+	ExpectedValue string
+	ActualValue   string
+	Details       string
+}
+
+// MerkleAuditFindingFilter selects findings for listing
+type MerkleAuditFindingFilter struct {
+	BatchID     *uuid.UUID
+	FindingType *MerkleFindingType
+	Status      string // "open" (default), "resolved" or "all"
+	Limit       int
+	Offset      int
+}
@@ -0,0 +1,136 @@
+-- ============================================================================
+-- CERTEN PROOF REQUEST PROCESSING
+-- Migration: 010_proof_request_processing
+-- Version: 1.0.0
+-- Description: Reconcile the two proof_requests definitions so the request
+--              processing worker and the bundle API share one queue
+--
+-- proof_requests is declared by both 001_initial_schema (accumulate_tx_hash,
+-- request_type, priority, requester_id, batch_id, requested_at) and
+-- 003_proof_service_enhancements (accum_tx_hash, proof_class, governance_level,
+-- api_key_id, callback_url, created_at). Whichever ran first owns the table.
+-- This migration:
+-- - Adds whichever column set is missing
+-- - Keeps the paired columns in sync with a trigger
+-- - Widens the status constraint to cover both lifecycles (batched, cancelled)
+-- - Relaxes proof_id so it may reference proof_artifacts or certen_anchor_proofs
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- COLUMNS FROM 001_initial_schema
+-- ============================================================================
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS accumulate_tx_hash VARCHAR(128);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS request_type       VARCHAR(20);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS priority           VARCHAR(10) NOT NULL DEFAULT 'normal';
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS requester_id       VARCHAR(256);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS batch_id           UUID REFERENCES anchor_batches(batch_id);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS requested_at       TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- ============================================================================
+-- COLUMNS FROM 003_proof_service_enhancements
+-- ============================================================================
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS accum_tx_hash      VARCHAR(128);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS proof_class        VARCHAR(20);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS governance_level   VARCHAR(10);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS api_key_id         UUID REFERENCES api_keys(key_id);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS callback_url       VARCHAR(1024);
+ALTER TABLE proof_requests ADD COLUMN IF NOT EXISTS created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- 001 sized accumulate_tx_hash for 64 hex chars; 003 allows 128
+ALTER TABLE proof_requests ALTER COLUMN accumulate_tx_hash TYPE VARCHAR(128);
+
+-- Backfill the paired columns for rows written before this migration
+UPDATE proof_requests SET
+    accumulate_tx_hash = COALESCE(accumulate_tx_hash, accum_tx_hash),
+    accum_tx_hash      = COALESCE(accum_tx_hash, accumulate_tx_hash),
+    proof_class        = COALESCE(proof_class, request_type),
+    request_type       = COALESCE(proof_class, request_type, 'on_cadence'),
+    requested_at       = LEAST(requested_at, created_at),
+    created_at         = LEAST(requested_at, created_at)
+WHERE accumulate_tx_hash IS DISTINCT FROM accum_tx_hash
+   OR proof_class IS DISTINCT FROM request_type
+   OR requested_at IS DISTINCT FROM created_at;
+
+-- ============================================================================
+-- CONSTRAINTS
+-- ============================================================================
+
+-- Union of both status lifecycles
+ALTER TABLE proof_requests DROP CONSTRAINT IF EXISTS valid_request_status;
+ALTER TABLE proof_requests ADD CONSTRAINT valid_request_status CHECK (
+    status IN ('pending', 'processing', 'batched', 'completed', 'failed', 'cancelled')
+);
+
+-- proof_id is resolved against proof_artifacts first and certen_anchor_proofs
+-- second, so it cannot carry a foreign key to either table
+ALTER TABLE proof_requests DROP CONSTRAINT IF EXISTS proof_requests_proof_id_fkey;
+
+-- ============================================================================
+-- COLUMN SYNC TRIGGER
+-- Writers using either column set see a consistent row
+-- ============================================================================
+CREATE OR REPLACE FUNCTION sync_proof_request_columns()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.accum_tx_hash IS DISTINCT FROM OLD.accum_tx_hash THEN
+            NEW.accumulate_tx_hash = NEW.accum_tx_hash;
+        ELSIF NEW.accumulate_tx_hash IS DISTINCT FROM OLD.accumulate_tx_hash THEN
+            NEW.accum_tx_hash = NEW.accumulate_tx_hash;
+        END IF;
+        IF NEW.proof_class IS DISTINCT FROM OLD.proof_class THEN
+            NEW.request_type = NEW.proof_class;
+        ELSIF NEW.request_type IS DISTINCT FROM OLD.request_type THEN
+            NEW.proof_class = NEW.request_type;
+        END IF;
+        RETURN NEW;
+    END IF;
+
+    NEW.accum_tx_hash = COALESCE(NEW.accum_tx_hash, NEW.accumulate_tx_hash);
+    NEW.accumulate_tx_hash = NEW.accum_tx_hash;
+
+    -- request_type may carry its 001 column default; proof_class never does
+    IF NEW.proof_class IS NOT NULL THEN
+        NEW.request_type = NEW.proof_class;
+    ELSE
+        NEW.request_type = COALESCE(NEW.request_type, 'on_cadence');
+        NEW.proof_class = NEW.request_type;
+    END IF;
+
+    IF NEW.proof_class = 'on_demand' AND NEW.priority = 'normal' THEN
+        NEW.priority = 'high';
+    END IF;
+
+    NEW.created_at = LEAST(NEW.created_at, NEW.requested_at);
+    NEW.requested_at = NEW.created_at;
+
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS sync_proof_request_columns ON proof_requests;
+CREATE TRIGGER sync_proof_request_columns
+    BEFORE INSERT OR UPDATE ON proof_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION sync_proof_request_columns();
+
+-- ============================================================================
+-- INDEXES
+-- ============================================================================
+CREATE INDEX IF NOT EXISTS idx_request_pending_type ON proof_requests(request_type, requested_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_request_batched ON proof_requests(batch_id) WHERE status = 'batched';
+
+COMMENT ON COLUMN proof_requests.request_type IS 'Mirrors proof_class (001 schema name)';
+COMMENT ON COLUMN proof_requests.accumulate_tx_hash IS 'Mirrors accum_tx_hash (001 schema name)';
+
+-- ============================================================================
+-- MIGRATION RECORD
+-- ============================================================================
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('010', 'Proof request processing queue reconciliation', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
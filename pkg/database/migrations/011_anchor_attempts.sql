@@ -0,0 +1,27 @@
+-- ============================================================================
+-- CERTEN ANCHOR SUBMISSION ATTEMPTS
+-- Migration: 011_anchor_attempts
+-- Version: 1.0.0
+-- Description: Persist anchor submission attempts per batch
+--
+-- The anchoring pipeline claims a closed batch by moving it to 'anchoring'
+-- and counting an attempt in the same statement, so the count survives
+-- restarts. A batch left in 'anchoring' without an anchor_records row (the
+-- process stopped or the write failed mid-submission) is returned to
+-- 'closed' by the pipeline's recovery sweep, or failed once its attempts
+-- are spent.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE anchor_batches
+    ADD COLUMN IF NOT EXISTS anchor_attempts INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_batches_anchoring ON anchor_batches(updated_at)
+    WHERE status = 'anchoring';
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('011', 'Persistent anchor submission attempts', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
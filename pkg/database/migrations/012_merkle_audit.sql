@@ -0,0 +1,80 @@
+-- ============================================================================
+-- CERTEN MERKLE CONSISTENCY AUDIT
+-- Migration: 012_merkle_audit
+-- Version: 1.0.0
+-- Description: Findings table for the Merkle consistency audit job
+--
+-- The Merkle root of a batch is stored three times (anchor_batches,
+-- anchor_records, proof_artifacts) alongside every leaf's inclusion path in
+-- batch_transactions. The audit job rebuilds each closed batch's tree from
+-- its transaction hashes and records every copy that disagrees.
+--
+-- A finding stays open while the discrepancy persists. Re-detection in a
+-- later run refreshes it rather than adding a duplicate; a finding that is
+-- no longer detected is marked resolved.
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- AUDIT RUNS
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS merkle_audit_runs (
+    run_id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    started_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at        TIMESTAMPTZ,
+    batches_checked     INTEGER NOT NULL DEFAULT 0,
+    findings_opened     INTEGER NOT NULL DEFAULT 0,
+    findings_resolved   INTEGER NOT NULL DEFAULT 0,
+    status              VARCHAR(20) NOT NULL DEFAULT 'running',
+    error_message       TEXT,
+
+    CONSTRAINT valid_audit_run_status CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_merkle_audit_runs_started ON merkle_audit_runs(started_at DESC);
+
+-- ============================================================================
+-- AUDIT FINDINGS
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS merkle_audit_findings (
+    finding_id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    batch_id            UUID NOT NULL REFERENCES anchor_batches(batch_id),
+    finding_type        VARCHAR(32) NOT NULL,
+    -- What disagrees: 'batch', 'anchor:<anchor_id>', 'proof:<proof_id>' or 'leaf:<tree_index>'
+    subject             VARCHAR(128) NOT NULL,
+    proof_id            UUID,
+    anchor_id           UUID,
+    tree_index          INTEGER,
+    expected_value      TEXT,                   -- Recomputed value (hex root or JSON path)
+    actual_value        TEXT,                   -- Stored value
+    details             TEXT,
+    first_run_id        UUID NOT NULL REFERENCES merkle_audit_runs(run_id),
+    last_run_id         UUID NOT NULL REFERENCES merkle_audit_runs(run_id),
+    detected_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at         TIMESTAMPTZ,
+
+    CONSTRAINT valid_merkle_finding_type CHECK (finding_type IN (
+        'tree_rebuild_failed',
+        'batch_root_mismatch',
+        'anchor_root_mismatch',
+        'proof_root_mismatch',
+        'proof_leaf_mismatch',
+        'leaf_path_mismatch'
+    ))
+);
+
+-- One open finding per discrepancy
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merkle_audit_findings_open
+    ON merkle_audit_findings(batch_id, finding_type, subject)
+    WHERE resolved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_merkle_audit_findings_batch ON merkle_audit_findings(batch_id);
+CREATE INDEX IF NOT EXISTS idx_merkle_audit_findings_detected ON merkle_audit_findings(detected_at DESC);
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('012', 'Merkle consistency audit findings', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
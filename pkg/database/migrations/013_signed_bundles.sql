@@ -0,0 +1,22 @@
+-- ============================================================================
+-- CERTEN SIGNED PROOF BUNDLES
+-- Migration: 013_signed_bundles
+-- Version: 1.0.0
+-- Description: Allow the certen_v2 bundle format
+--
+-- certen_v2 bundles embed a detached Ed25519 signature by the proofs-service
+-- instance key, with its key ID and signing time. The signature lives inside
+-- the bundle JSON, so only the format constraint changes.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE proof_bundles DROP CONSTRAINT IF EXISTS valid_bundle_format;
+ALTER TABLE proof_bundles ADD CONSTRAINT valid_bundle_format
+    CHECK (bundle_format IN ('certen_v1', 'certen_v2', 'json', 'cbor'));
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('013', 'Signed proof bundle format certen_v2', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
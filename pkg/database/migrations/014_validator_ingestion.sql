@@ -0,0 +1,30 @@
+-- ============================================================================
+-- CERTEN VALIDATOR PROOF INGESTION
+-- Migration: 014_validator_ingestion
+-- Version: 1.0.0
+-- Description: API keys that let validators submit proofs
+--
+-- Validators submit complete proofs through POST /api/v1/proofs/ingest. The
+-- key must carry can_submit_proofs and is bound to the validator it was
+-- issued to, so a validator cannot submit proofs under another's ID.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS validator_id VARCHAR(256);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS can_submit_proofs BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS valid_client_type;
+ALTER TABLE api_keys ADD CONSTRAINT valid_client_type CHECK (client_type IN (
+    'auditor', 'service', 'institution', 'developer', 'internal', 'validator'
+));
+
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS submitter_has_validator;
+ALTER TABLE api_keys ADD CONSTRAINT submitter_has_validator
+    CHECK (NOT can_submit_proofs OR validator_id IS NOT NULL);
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('014', 'Validator proof ingestion API keys', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- ============================================================================
+-- CERTEN VALIDATOR KEYS
+-- Migration: 015_validator_keys
+-- Version: 1.0.0
+-- Description: Registered Ed25519 keys for validator attestations
+--
+-- Attestations submitted through POST /api/v1/attestations are accepted only
+-- when signed by an active key registered here for the attesting validator.
+-- The set of validators with an active key is the set quorum is counted over.
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS validator_keys (
+    key_id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    validator_id        VARCHAR(128) NOT NULL,
+    public_key          BYTEA NOT NULL,           -- Ed25519 public key (32 bytes)
+
+    is_active           BOOLEAN NOT NULL DEFAULT TRUE,
+    description         TEXT,
+
+    registered_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at          TIMESTAMPTZ,
+
+    CONSTRAINT unique_validator_key UNIQUE (validator_id, public_key),
+    CONSTRAINT valid_validator_pubkey_length CHECK (length(public_key) = 32)
+);
+
+CREATE INDEX IF NOT EXISTS idx_validator_keys_active ON validator_keys(validator_id)
+    WHERE is_active = TRUE AND revoked_at IS NULL;
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('015', 'Registered validator attestation keys', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
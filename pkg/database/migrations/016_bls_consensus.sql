@@ -0,0 +1,45 @@
+-- ============================================================================
+-- CERTEN BLS RESULT CONSENSUS
+-- Migration: 016_bls_consensus
+-- Version: 1.0.0
+-- Description: Consensus entries for BLS attestation of external chain results
+--
+-- BLS attestations submitted through POST /api/v1/attestations/bls are
+-- collected into a consensus entry per external chain result. The entry is
+-- bound to the validator set snapshot that was current when collection began,
+-- tracks the attested weight against the snapshot's threshold, and times out
+-- at its deadline if the threshold is not reached.
+--
+-- For result entries merkle_root holds the attested message hash (the result
+-- hash) and batch_id is NULL.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE consensus_entries ALTER COLUMN batch_id DROP NOT NULL;
+
+ALTER TABLE consensus_entries
+    ADD COLUMN IF NOT EXISTS result_id UUID UNIQUE
+        REFERENCES external_chain_results(result_id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS snapshot_id UUID REFERENCES validator_set_snapshots(snapshot_id),
+    ADD COLUMN IF NOT EXISTS total_weight BIGINT,
+    ADD COLUMN IF NOT EXISTS threshold_weight BIGINT,
+    ADD COLUMN IF NOT EXISTS achieved_weight BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS aggregation_id UUID REFERENCES aggregated_attestations(aggregation_id),
+    ADD COLUMN IF NOT EXISTS deadline TIMESTAMPTZ;
+
+ALTER TABLE consensus_entries DROP CONSTRAINT IF EXISTS consensus_subject;
+ALTER TABLE consensus_entries ADD CONSTRAINT consensus_subject CHECK (
+    (batch_id IS NOT NULL AND result_id IS NULL) OR
+    (batch_id IS NULL AND result_id IS NOT NULL AND snapshot_id IS NOT NULL AND deadline IS NOT NULL)
+);
+
+-- Used by the timeout sweep
+CREATE INDEX IF NOT EXISTS idx_ce_deadline ON consensus_entries(deadline)
+    WHERE state IN ('initiated', 'collecting') AND deadline IS NOT NULL;
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('016', 'Consensus entries for BLS result attestation', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,41 @@
+-- ============================================================================
+-- CERTEN IDEMPOTENCY KEYS
+-- Migration: 017_idempotency_keys
+-- Version: 1.0.0
+-- Description: Stored responses for requests sent with an Idempotency-Key
+--
+-- A mutating request with an Idempotency-Key header claims the key for its
+-- caller (scope) before it runs. Its response is stored when it succeeds, and
+-- repeats of the same request with the same key replay it until the key
+-- expires. response_status is NULL while the first request is in progress;
+-- until then expires_at is a short lease that the request renews.
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope               VARCHAR(255) NOT NULL,    -- Caller identity, e.g. key:<api key id>
+    idempotency_key     VARCHAR(255) NOT NULL,
+
+    method              VARCHAR(10) NOT NULL,
+    path                TEXT NOT NULL,
+    fingerprint         BYTEA NOT NULL,           -- SHA-256 of method, path and body
+
+    response_status     INTEGER,
+    response_headers    JSONB,
+    response_body       BYTEA,
+
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at        TIMESTAMPTZ,
+    expires_at          TIMESTAMPTZ NOT NULL,     -- Lease end while in progress, replay end once completed
+
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys(expires_at);
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('017', 'Idempotency keys for mutating requests', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
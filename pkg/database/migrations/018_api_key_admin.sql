@@ -0,0 +1,61 @@
+-- ============================================================================
+-- CERTEN API KEY ADMINISTRATION
+-- Migration: 018_api_key_admin
+-- Version: 1.0.0
+-- Description: Admin permission, rotation tracking and audit log for API keys
+--
+-- Keys are created, rotated, deactivated and edited through the admin API
+-- (/api/v1/admin/api-keys) or the proof-apikey CLI. Keys with can_admin may
+-- use the admin API. The plaintext key is shown once at creation; key_prefix
+-- keeps enough of it to recognise a key in listings. Every admin action is
+-- recorded in api_key_audit_log.
+--
+-- Validator attestation keys are registered and revoked the same way
+-- (/api/v1/admin/validator-keys). Their entries name the validator key
+-- instead of an API key, so every entry names exactly one of key_id and
+-- validator_key_id.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS can_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS key_prefix VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS rotated_from UUID REFERENCES api_keys(key_id),
+    ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS api_key_audit_log (
+    audit_id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key_id              UUID REFERENCES api_keys(key_id) ON DELETE CASCADE,
+    validator_key_id    UUID REFERENCES validator_keys(key_id) ON DELETE CASCADE,
+
+    action              VARCHAR(30) NOT NULL,
+
+    -- Who performed the action
+    actor_type          VARCHAR(20) NOT NULL,
+    actor_key_id        UUID REFERENCES api_keys(key_id) ON DELETE SET NULL,
+    actor_name          VARCHAR(256) NOT NULL,
+    client_ip           VARCHAR(64),
+
+    details             JSONB,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_api_key_audit_subject CHECK ((key_id IS NULL) <> (validator_key_id IS NULL)),
+    CONSTRAINT valid_api_key_audit_action CHECK (action IN (
+        'create', 'rotate', 'deactivate', 'set_expiry', 'update_permissions',
+        'register_validator_key', 'revoke_validator_key'
+    )),
+    CONSTRAINT valid_api_key_audit_actor CHECK (actor_type IN ('api_key', 'cli'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_audit_key ON api_key_audit_log(key_id, created_at DESC)
+    WHERE key_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_api_key_audit_validator_key ON api_key_audit_log(validator_key_id, created_at DESC)
+    WHERE validator_key_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_api_key_audit_actor ON api_key_audit_log(actor_key_id) WHERE actor_key_id IS NOT NULL;
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('018', 'API key administration and audit log', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
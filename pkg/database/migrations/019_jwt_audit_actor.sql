@@ -0,0 +1,22 @@
+-- ============================================================================
+-- CERTEN JWT AUDIT ACTOR
+-- Migration: 019_jwt_audit_actor
+-- Version: 1.0.0
+-- Description: Record admin actions taken with bearer tokens
+--
+-- Web app users with the admin scope can use the admin API with a JWT
+-- instead of an API key. Their actions are recorded with actor_type 'jwt'
+-- and the token subject as actor_name.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE api_key_audit_log DROP CONSTRAINT IF EXISTS valid_api_key_audit_actor;
+ALTER TABLE api_key_audit_log ADD CONSTRAINT valid_api_key_audit_actor
+    CHECK (actor_type IN ('api_key', 'jwt', 'cli'));
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('019', 'JWT actors in API key audit log', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,30 @@
+-- ============================================================================
+-- CERTEN RATE LIMIT COUNTERS
+-- Migration: 020_rate_limit_counters
+-- Version: 1.0.0
+-- Description: Request counters shared by service replicas for rate limiting
+--
+-- Each row counts the cost of the requests a caller (an API key, token
+-- subject or client IP) made in one fixed window. Replicas increment the same
+-- row, so a caller's limit holds across all of them. Rows are purged once
+-- their window has ended.
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS rate_limit_counters (
+    bucket              VARCHAR(255) NOT NULL,    -- 'key:<key_id>', 'jwt:<sub>' or 'ip:<address>'
+    window_start        TIMESTAMPTZ NOT NULL,
+    request_cost        INTEGER NOT NULL DEFAULT 0,
+    expires_at          TIMESTAMPTZ NOT NULL,     -- End of the window
+
+    PRIMARY KEY (bucket, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_counters_expires ON rate_limit_counters(expires_at);
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('020', 'Shared rate limit counters', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,69 @@
+-- ============================================================================
+-- CERTEN API ACCESS LOG
+-- Migration: 021_api_access_log
+-- Version: 1.0.0
+-- Description: Append-only log of who called which API route for which data
+--
+-- One row per API request: the caller (API key, token subject, validator
+-- certificate, anonymous, or rejected credentials), client IP, route, the proof, intent, account and
+-- user the request concerned, and the response status, size and latency.
+-- Rows cannot be updated; they are only deleted by the retention purge once
+-- older than ACCESS_LOG_RETENTION_DAYS.
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS api_access_log (
+    access_id           BIGSERIAL PRIMARY KEY,
+    occurred_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Who made the request
+    principal_type      VARCHAR(20) NOT NULL,
+    principal_name      VARCHAR(256),
+    key_id              UUID,                     -- Not a foreign key: entries outlive keys
+    client_ip           VARCHAR(64),
+
+    -- What was requested
+    method              VARCHAR(10) NOT NULL,
+    route               VARCHAR(255) NOT NULL,    -- Registered route pattern
+    path                TEXT NOT NULL,
+    proof_id            UUID,
+    intent_id           VARCHAR(256),
+    account_url         VARCHAR(512),
+    user_id             VARCHAR(256),
+
+    -- Outcome
+    status_code         INTEGER NOT NULL,
+    response_bytes      BIGINT NOT NULL DEFAULT 0,
+    latency_ms          INTEGER NOT NULL DEFAULT 0,
+
+    CONSTRAINT valid_access_principal_type CHECK (principal_type IN ('api_key', 'jwt', 'certificate', 'anonymous', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_access_log_occurred ON api_access_log(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_api_access_log_principal ON api_access_log(principal_name, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_api_access_log_key ON api_access_log(key_id, occurred_at DESC) WHERE key_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_api_access_log_proof ON api_access_log(proof_id, occurred_at DESC) WHERE proof_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_api_access_log_intent ON api_access_log(intent_id, occurred_at DESC) WHERE intent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_api_access_log_account ON api_access_log(account_url, occurred_at DESC) WHERE account_url IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_api_access_log_user ON api_access_log(user_id, occurred_at DESC) WHERE user_id IS NOT NULL;
+
+-- Entries are never modified
+CREATE OR REPLACE FUNCTION prevent_api_access_log_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'api_access_log is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS prevent_api_access_log_update ON api_access_log;
+CREATE TRIGGER prevent_api_access_log_update
+    BEFORE UPDATE ON api_access_log
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_api_access_log_update();
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('021', 'API access log', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
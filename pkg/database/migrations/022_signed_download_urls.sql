@@ -0,0 +1,52 @@
+-- ============================================================================
+-- CERTEN SIGNED DOWNLOAD URLS
+-- Migration: 022_signed_download_urls
+-- Version: 1.0.0
+-- Description: Time-limited signed URLs for one proof bundle or export job
+--
+-- A caller mints a URL for a single bundle or finished export and hands it to
+-- a third party, who can download it without an API key until it expires.
+-- The URL carries an HMAC over its ID, resource and expiry; this table records
+-- who minted it and enforces single use. Every redemption is written to
+-- bundle_downloads with the URL's ID, which now also logs export downloads.
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS signed_download_urls (
+    url_id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- What the URL grants
+    resource_type       VARCHAR(10) NOT NULL,
+    resource_id         UUID NOT NULL,            -- proof_id or export job_id
+    single_use          BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at          TIMESTAMPTZ NOT NULL,
+
+    -- Who minted it
+    created_by_key_id   UUID REFERENCES api_keys(key_id) ON DELETE SET NULL,
+    created_by          VARCHAR(256) NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Redemptions
+    redemption_count    INTEGER NOT NULL DEFAULT 0,
+    last_redeemed_at    TIMESTAMPTZ,
+
+    CONSTRAINT valid_signed_url_resource CHECK (resource_type IN ('bundle', 'export'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_signed_download_urls_resource ON signed_download_urls(resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_signed_download_urls_expires ON signed_download_urls(expires_at);
+
+-- Export downloads have no bundle
+ALTER TABLE bundle_downloads ALTER COLUMN bundle_id DROP NOT NULL;
+ALTER TABLE bundle_downloads
+    ADD COLUMN IF NOT EXISTS export_job_id UUID,
+    ADD COLUMN IF NOT EXISTS signed_url_id UUID REFERENCES signed_download_urls(url_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bundle_downloads_signed_url ON bundle_downloads(signed_url_id) WHERE signed_url_id IS NOT NULL;
+
+INSERT INTO schema_migrations (version, description, applied_at)
+VALUES ('022', 'Signed download URLs', NOW())
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
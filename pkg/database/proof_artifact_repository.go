@@ -21,7 +21,14 @@ import (
 
 // ProofArtifactRepository provides access to proof artifact storage
 type ProofArtifactRepository struct {
-	db *sql.DB
+	db queryer
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // NewProofArtifactRepository creates a new proof artifact repository
@@ -29,6 +36,11 @@ func NewProofArtifactRepository(db *sql.DB) *ProofArtifactRepository {
 	return &ProofArtifactRepository{db: db}
 }
 
+// WithTx returns a repository whose operations run inside the transaction
+func (r *ProofArtifactRepository) WithTx(tx *Tx) *ProofArtifactRepository {
+	return &ProofArtifactRepository{db: tx.tx}
+}
+
 // ============================================================================
 // CORE PROOF ARTIFACT OPERATIONS
 // ============================================================================
@@ -1004,6 +1016,50 @@ func (r *ProofArtifactRepository) GetAnchorReference(ctx context.Context, proofI
 	return &ref, nil
 }
 
+// CreateAnchorReference records a proof's anchor on an external chain
+func (r *ProofArtifactRepository) CreateAnchorReference(ctx context.Context, input *NewAnchorReference) (*AnchorReferenceRecord, error) {
+	query := `
+		INSERT INTO anchor_references (
+			proof_id, target_chain, chain_id, network_name,
+			anchor_tx_hash, anchor_block_number, anchor_block_hash, anchor_timestamp,
+			contract_address, confirmations, is_confirmed, confirmed_at,
+			gas_used, gas_price_wei, total_cost_wei, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW()
+		)
+		RETURNING reference_id, created_at`
+
+	var ref AnchorReferenceRecord
+	ref.ProofID = input.ProofID
+	ref.TargetChain = input.TargetChain
+	ref.ChainID = input.ChainID
+	ref.NetworkName = input.NetworkName
+	ref.AnchorTxHash = input.AnchorTxHash
+	ref.AnchorBlockNumber = input.AnchorBlockNumber
+	ref.AnchorBlockHash = input.AnchorBlockHash
+	ref.AnchorTimestamp = input.AnchorTimestamp
+	ref.ContractAddress = input.ContractAddress
+	ref.Confirmations = input.Confirmations
+	ref.IsConfirmed = input.IsConfirmed
+	ref.ConfirmedAt = input.ConfirmedAt
+	ref.GasUsed = input.GasUsed
+	ref.GasPriceWei = input.GasPriceWei
+	ref.TotalCostWei = input.TotalCostWei
+
+	err := r.db.QueryRowContext(ctx, query,
+		input.ProofID, input.TargetChain, input.ChainID, input.NetworkName,
+		input.AnchorTxHash, input.AnchorBlockNumber, input.AnchorBlockHash, input.AnchorTimestamp,
+		input.ContractAddress, input.Confirmations, input.IsConfirmed, input.ConfirmedAt,
+		input.GasUsed, input.GasPriceWei, input.TotalCostWei,
+	).Scan(&ref.ReferenceID, &ref.CreatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anchor reference: %w", err)
+	}
+
+	return &ref, nil
+}
+
 // ============================================================================
 // SYNC OPERATIONS (For Auditing Nodes)
 // ============================================================================
@@ -1107,11 +1163,12 @@ func (r *ProofArtifactRepository) VerifyArtifactIntegrity(ctx context.Context, p
 func (r *ProofArtifactRepository) CreateProofBundle(ctx context.Context, input *NewProofBundle) (*ProofBundle, error) {
 	query := `
 		INSERT INTO proof_bundles (
-			proof_id, bundle_format, bundle_version,
+			bundle_id, proof_id, bundle_format, bundle_version,
 			bundle_data, bundle_hash, bundle_size_bytes,
 			includes_chained, includes_governance, includes_merkle, includes_anchor,
 			attestation_count, expires_at, created_at
 		) VALUES (
+			COALESCE($13::uuid, gen_random_uuid()),
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW()
 		)
 		RETURNING bundle_id, created_at`
@@ -1134,7 +1191,7 @@ func (r *ProofArtifactRepository) CreateProofBundle(ctx context.Context, input *
 		input.ProofID, input.BundleFormat, input.BundleVersion,
 		input.BundleData, input.BundleHash, input.BundleSizeBytes,
 		input.IncludesChained, input.IncludesGovernance, input.IncludesMerkle, input.IncludesAnchor,
-		input.AttestationCount, input.ExpiresAt,
+		input.AttestationCount, input.ExpiresAt, input.BundleID,
 	).Scan(&bundle.BundleID, &bundle.CreatedAt)
 
 	if err != nil {
@@ -1325,6 +1382,37 @@ func (r *ProofArtifactRepository) GetLatestCustodyHash(ctx context.Context, proo
 	return hash, nil
 }
 
+// AppendCustodyEvent adds an event to the end of a proof's custody chain,
+// linking it to the latest event's hash
+func (r *ProofArtifactRepository) AppendCustodyEvent(ctx context.Context, proofID uuid.UUID, eventType, actorType string, actorID *string, details json.RawMessage) (*CustodyChainEvent, error) {
+	previousHash, err := r.GetLatestCustodyHash(ctx, proofID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.CreateCustodyChainEvent(ctx, &NewCustodyChainEvent{
+		ProofID:      proofID,
+		EventType:    eventType,
+		ActorType:    actorType,
+		ActorID:      actorID,
+		PreviousHash: previousHash,
+		CurrentHash:  ComputeCustodyHash(previousHash, proofID, eventType, details),
+		EventDetails: details,
+	})
+}
+
+// ComputeCustodyHash returns the hash of a custody event:
+// SHA256(previous_hash || proof_id || event_type || event_details).
+// The first event of a chain has no previous hash.
+func ComputeCustodyHash(previousHash []byte, proofID uuid.UUID, eventType string, details json.RawMessage) []byte {
+	h := sha256.New()
+	h.Write(previousHash)
+	h.Write(proofID[:])
+	h.Write([]byte(eventType))
+	h.Write(details)
+	return h.Sum(nil)
+}
+
 // ============================================================================
 // BULK EXPORT OPERATIONS
 // ============================================================================
@@ -1414,20 +1502,24 @@ func (r *ProofArtifactRepository) RecordBundleDownloadLegacy(ctx context.Context
 	return nil
 }
 
-// RecordBundleDownload records a bundle download for auditing using NewBundleDownload type
+// RecordBundleDownload records a bundle or export download for auditing using NewBundleDownload type
 func (r *ProofArtifactRepository) RecordBundleDownload(ctx context.Context, input *NewBundleDownload) error {
 	query := `
 		INSERT INTO bundle_downloads (
-			bundle_id, api_key_id, client_ip, user_agent,
+			bundle_id, export_job_id, signed_url_id, api_key_id, client_ip, user_agent,
 			response_code, bytes_sent, downloaded_at
-		) VALUES ($1, $2, $3, $4, $5, $6, NOW())`
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())`
 
 	var userAgent string
 	if input.UserAgent != nil {
 		userAgent = *input.UserAgent
 	}
+	var bundleID *uuid.UUID
+	if input.BundleID != uuid.Nil {
+		bundleID = &input.BundleID
+	}
 
-	_, err := r.db.ExecContext(ctx, query, input.BundleID, input.APIKeyID, input.ClientIP, userAgent, input.ResponseCode, input.BytesSent)
+	_, err := r.db.ExecContext(ctx, query, bundleID, input.ExportJobID, input.SignedURLID, input.APIKeyID, input.ClientIP, userAgent, input.ResponseCode, input.BytesSent)
 	if err != nil {
 		return fmt.Errorf("failed to record bundle download: %w", err)
 	}
@@ -1629,9 +1721,9 @@ func (r *ProofArtifactRepository) SaveBLSAttestation(ctx context.Context, input
 		INSERT INTO bls_attestations (
 			result_id, snapshot_id, validator_id, public_key,
 			message_hash, signature, weight, subgroup_valid,
-			attested_at, created_at
+			signature_valid, verified_at, attested_at, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, NOW()
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW()
 		)
 		RETURNING attestation_id, created_at`
 
@@ -1644,12 +1736,14 @@ func (r *ProofArtifactRepository) SaveBLSAttestation(ctx context.Context, input
 	att.Signature = input.Signature
 	att.Weight = input.Weight
 	att.SubgroupValid = input.SubgroupValid
+	att.SignatureValid = input.SignatureValid
+	att.VerifiedAt = input.VerifiedAt
 	att.AttestedAt = input.AttestedAt
 
 	err := r.db.QueryRowContext(ctx, query,
 		input.ResultID, input.SnapshotID, input.ValidatorID, input.PublicKey,
 		input.MessageHash, input.Signature, input.Weight, input.SubgroupValid,
-		input.AttestedAt,
+		input.SignatureValid, input.VerifiedAt, input.AttestedAt,
 	).Scan(&att.AttestationID, &att.CreatedAt)
 
 	if err != nil {
@@ -1776,9 +1870,9 @@ func (r *ProofArtifactRepository) SaveAggregatedAttestation(ctx context.Context,
 			participant_ids, participant_count,
 			total_weight, threshold_weight, achieved_weight,
 			threshold_met, message_consistency_valid,
-			aggregated_at, created_at
+			aggregation_valid, verified_at, aggregated_at, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW()
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW()
 		)
 		RETURNING aggregation_id, created_at`
 
@@ -1795,6 +1889,8 @@ func (r *ProofArtifactRepository) SaveAggregatedAttestation(ctx context.Context,
 	agg.AchievedWeight = input.AchievedWeight
 	agg.ThresholdMet = input.ThresholdMet
 	agg.MessageConsistencyValid = input.MessageConsistencyValid
+	agg.AggregationValid = input.AggregationValid
+	agg.VerifiedAt = input.VerifiedAt
 	agg.AggregatedAt = input.AggregatedAt
 
 	err := r.db.QueryRowContext(ctx, query,
@@ -1803,7 +1899,7 @@ func (r *ProofArtifactRepository) SaveAggregatedAttestation(ctx context.Context,
 		input.ParticipantIDs, input.ParticipantCount,
 		input.TotalWeight, input.ThresholdWeight, input.AchievedWeight,
 		input.ThresholdMet, input.MessageConsistencyValid,
-		input.AggregatedAt,
+		input.AggregationValid, input.VerifiedAt, input.AggregatedAt,
 	).Scan(&agg.AggregationID, &agg.CreatedAt)
 
 	if err != nil {
@@ -2276,7 +2372,8 @@ func (r *ProofArtifactRepository) GetIncompleteProofCycles(ctx context.Context,
 func (r *ProofArtifactRepository) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (*APIKey, error) {
 	query := `
 		SELECT key_id, key_hash, client_name, client_type,
-			   can_read_proofs, can_request_proofs, can_bulk_download,
+			   can_read_proofs, can_request_proofs, can_bulk_download, can_submit_proofs, can_admin,
+			   validator_id, key_prefix, rotated_from, deactivated_at,
 			   rate_limit_per_min, is_active, expires_at,
 			   description, contact_email, created_at, last_used_at
 		FROM api_keys
@@ -2285,7 +2382,8 @@ func (r *ProofArtifactRepository) GetAPIKeyByHash(ctx context.Context, keyHash [
 	var key APIKey
 	err := r.db.QueryRowContext(ctx, query, keyHash).Scan(
 		&key.KeyID, &key.KeyHash, &key.ClientName, &key.ClientType,
-		&key.CanReadProofs, &key.CanRequestProofs, &key.CanBulkDownload,
+		&key.CanReadProofs, &key.CanRequestProofs, &key.CanBulkDownload, &key.CanSubmitProofs, &key.CanAdmin,
+		&key.ValidatorID, &key.KeyPrefix, &key.RotatedFrom, &key.DeactivatedAt,
 		&key.RateLimitPerMin, &key.IsActive, &key.ExpiresAt,
 		&key.Description, &key.ContactEmail, &key.CreatedAt, &key.LastUsedAt,
 	)
@@ -2312,11 +2410,11 @@ func (r *ProofArtifactRepository) CreateAPIKey(ctx context.Context, input *NewAP
 	query := `
 		INSERT INTO api_keys (
 			key_hash, client_name, client_type,
-			can_read_proofs, can_request_proofs, can_bulk_download,
-			rate_limit_per_min, is_active, expires_at,
+			can_read_proofs, can_request_proofs, can_bulk_download, can_submit_proofs, can_admin,
+			validator_id, key_prefix, rotated_from, rate_limit_per_min, is_active, expires_at,
 			description, contact_email, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW()
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW()
 		)
 		RETURNING key_id, created_at`
 
@@ -2327,6 +2425,11 @@ func (r *ProofArtifactRepository) CreateAPIKey(ctx context.Context, input *NewAP
 	key.CanReadProofs = input.CanReadProofs
 	key.CanRequestProofs = input.CanRequestProofs
 	key.CanBulkDownload = input.CanBulkDownload
+	key.CanSubmitProofs = input.CanSubmitProofs
+	key.CanAdmin = input.CanAdmin
+	key.ValidatorID = input.ValidatorID
+	key.KeyPrefix = input.KeyPrefix
+	key.RotatedFrom = input.RotatedFrom
 	key.RateLimitPerMin = input.RateLimitPerMin
 	key.IsActive = input.IsActive
 	key.ExpiresAt = input.ExpiresAt
@@ -2335,8 +2438,8 @@ func (r *ProofArtifactRepository) CreateAPIKey(ctx context.Context, input *NewAP
 
 	err := r.db.QueryRowContext(ctx, query,
 		input.KeyHash, input.ClientName, input.ClientType,
-		input.CanReadProofs, input.CanRequestProofs, input.CanBulkDownload,
-		input.RateLimitPerMin, input.IsActive, input.ExpiresAt,
+		input.CanReadProofs, input.CanRequestProofs, input.CanBulkDownload, input.CanSubmitProofs, input.CanAdmin,
+		input.ValidatorID, input.KeyPrefix, input.RotatedFrom, input.RateLimitPerMin, input.IsActive, input.ExpiresAt,
 		input.Description, input.ContactEmail,
 	).Scan(&key.KeyID, &key.CreatedAt)
 
@@ -3428,8 +3531,9 @@ func (r *ProofArtifactRepository) GetAttestationsByIntentID(ctx context.Context,
 	return summary, nil
 }
 
-// GetIntentsByUserID retrieves all intents for a user (paginated)
-func (r *ProofArtifactRepository) GetIntentsByUserID(ctx context.Context, userID string, limit, offset int) ([]IntentSummary, error) {
+// GetIntentsByUserID retrieves all intents for a user (paginated). A non-empty
+// organizationADI limits them to intents of that organisation.
+func (r *ProofArtifactRepository) GetIntentsByUserID(ctx context.Context, userID, organizationADI string, limit, offset int) ([]IntentSummary, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -3452,10 +3556,13 @@ func (r *ProofArtifactRepository) GetIntentsByUserID(ctx context.Context, userID
 		LEFT JOIN anchor_records ar ON ab.id = ar.batch_id
 		LEFT JOIN proof_artifacts pa ON bt.intent_id = pa.intent_id
 		WHERE bt.user_id = $1
+		  AND ($4 = '' OR EXISTS (
+		      SELECT 1 FROM certen_intents ci
+		      WHERE ci.intent_id = bt.intent_id AND ci.organization_adi = $4))
 		ORDER BY bt.created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset, organizationADI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query intents by user: %w", err)
 	}
@@ -7,15 +7,20 @@ package database
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq" // PostgreSQL driver
+
+	"github.com/certen/proofs-service/pkg/bls"
 )
 
 // Test database connection string (use test database or skip)
@@ -641,3 +646,791 @@ func TestQueryProofsWithFilters(t *testing.T) {
 		t.Errorf("Expected 1 on-demand proof, got %d", len(results2))
 	}
 }
+
+// ============================================================================
+// Ingestion Tests
+// ============================================================================
+
+func TestIngestProof(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	proofs := NewProofArtifactRepository(testDB)
+	repo := NewIngestionRepository(&Client{db: testDB}, proofs)
+	ctx := context.Background()
+
+	sub := &ProofSubmission{
+		Artifact: NewProofArtifact{
+			ProofType:    ProofTypeChained,
+			AccumTxHash:  "test_ingest_" + uuid.New().String()[:8],
+			AccountURL:   "acc://test.acme/tokens",
+			ProofClass:   ProofClassOnDemand,
+			ValidatorID:  "test-validator-1",
+			ArtifactJSON: json.RawMessage(`{"ingest": true}`),
+		},
+		Layers: []NewChainedProofLayer{
+			{LayerNumber: 1, LayerName: "L1", LayerJSON: json.RawMessage(`{}`)},
+		},
+		GovernanceLevels: []NewGovernanceProofLevel{
+			{GovLevel: GovLevelG0, LevelName: "G0", LevelJSON: json.RawMessage(`{}`)},
+		},
+		AnchorReference: &NewAnchorReference{
+			TargetChain:       "ethereum",
+			ChainID:           "11155111",
+			NetworkName:       "sepolia",
+			AnchorTxHash:      "0x" + uuid.New().String()[:8],
+			AnchorBlockNumber: 100,
+		},
+	}
+
+	result, created, err := repo.IngestProof(ctx, sub)
+	if err != nil {
+		t.Fatalf("Failed to ingest proof: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM proof_artifacts WHERE proof_id = $1", result.Proof.ProofID)
+	}()
+	if !created {
+		t.Error("Expected first submission to create the proof")
+	}
+
+	// Custody chain starts with a created event hashed from its contents
+	events, err := proofs.GetCustodyChainEvents(ctx, result.Proof.ProofID)
+	if err != nil {
+		t.Fatalf("Failed to get custody events: %v", err)
+	}
+	if len(events) != 1 || events[0].EventType != "created" {
+		t.Fatalf("Expected one created custody event, got %d", len(events))
+	}
+	expected := ComputeCustodyHash(nil, result.Proof.ProofID, "created", events[0].EventDetails)
+	if string(events[0].CurrentHash) != string(expected) {
+		t.Error("Custody hash does not match event contents")
+	}
+
+	// Resubmission returns the stored proof
+	again, created, err := repo.IngestProof(ctx, sub)
+	if err != nil {
+		t.Fatalf("Failed to resubmit proof: %v", err)
+	}
+	if created {
+		t.Error("Expected resubmission to be deduplicated")
+	}
+	if again.Proof.ProofID != result.Proof.ProofID {
+		t.Errorf("Expected proof %s, got %s", result.Proof.ProofID, again.Proof.ProofID)
+	}
+}
+
+func TestSubmitAttestation(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	proofs := NewProofArtifactRepository(testDB)
+	repo := NewIngestionRepository(&Client{db: testDB}, proofs)
+	ctx := context.Background()
+
+	root := sha256.Sum256([]byte("attested root"))
+	ingested, _, err := repo.IngestProof(ctx, &ProofSubmission{
+		Artifact: NewProofArtifact{
+			ProofType:    ProofTypeMerkle,
+			AccumTxHash:  "test_attest_" + uuid.New().String()[:8],
+			AccountURL:   "acc://test.acme/tokens",
+			MerkleRoot:   root[:],
+			ProofClass:   ProofClassOnCadence,
+			ValidatorID:  "test-validator-1",
+			ArtifactJSON: json.RawMessage(`{"attest": true}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to ingest proof: %v", err)
+	}
+	proofID := ingested.Proof.ProofID
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM proof_artifacts WHERE proof_id = $1", proofID)
+	}()
+
+	validatorID := "test-validator-" + uuid.New().String()[:8]
+	pubkey := sha256.Sum256([]byte(validatorID)) // Stored as-is; signatures are checked by the caller
+	if _, err := repo.RegisterValidatorKey(ctx, validatorID, pubkey[:], nil, &APIKeyActor{Type: "cli", Name: "test"}); err != nil {
+		t.Fatalf("Failed to register validator key: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM validator_keys WHERE validator_id = $1", validatorID)
+	}()
+
+	sub := &AttestationSubmission{
+		ProofID:         &proofID,
+		ValidatorID:     validatorID,
+		ValidatorPubkey: pubkey[:],
+		MerkleRoot:      root[:],
+		Signature:       make([]byte, 64),
+		AttestedAt:      time.Now(),
+	}
+	majority := func(total int) int { return total/2 + 1 }
+
+	result, err := repo.SubmitAttestation(ctx, sub, majority)
+	if err != nil {
+		t.Fatalf("Failed to submit attestation: %v", err)
+	}
+	if result.ValidAttestations != 1 || result.CustodyEvents != 1 {
+		t.Errorf("Expected 1 valid attestation and 1 custody event, got %d and %d",
+			result.ValidAttestations, result.CustodyEvents)
+	}
+
+	// Same content again is a duplicate
+	if _, err := repo.SubmitAttestation(ctx, sub, majority); err != ErrDuplicateAttestation {
+		t.Errorf("Expected ErrDuplicateAttestation, got %v", err)
+	}
+
+	// Different content from the same validator is a conflict
+	anchor := "0xother"
+	sub.AnchorTxHash = &anchor
+	if _, err := repo.SubmitAttestation(ctx, sub, majority); err != ErrConflictingAttestation {
+		t.Errorf("Expected ErrConflictingAttestation, got %v", err)
+	}
+}
+
+func TestValidatorKeyRevocation(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	client := &Client{db: testDB}
+	proofs := NewProofArtifactRepository(testDB)
+	repo := NewIngestionRepository(client, proofs)
+	audit := NewAPIKeyRepository(client, proofs)
+	ctx := context.Background()
+	actor := &APIKeyActor{Type: "cli", Name: "test"}
+
+	validatorID := "test-validator-" + uuid.New().String()[:8]
+	pubkey := sha256.Sum256([]byte(validatorID))
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM validator_keys WHERE validator_id = $1", validatorID)
+	}()
+
+	key, err := repo.RegisterValidatorKey(ctx, validatorID, pubkey[:], nil, actor)
+	if err != nil || !key.IsActive {
+		t.Fatalf("Failed to register validator key: %v", err)
+	}
+	keys, err := repo.ListValidatorKeys(ctx, validatorID, false)
+	if err != nil || len(keys) != 1 || keys[0].KeyID != key.KeyID {
+		t.Fatalf("Expected the registered key to be listed, got %d (%v)", len(keys), err)
+	}
+
+	revoked, err := repo.RevokeValidatorKey(ctx, key.KeyID, actor)
+	if err != nil || revoked.IsActive || revoked.RevokedAt == nil {
+		t.Fatalf("Failed to revoke validator key: %v", err)
+	}
+	if _, err := repo.RevokeValidatorKey(ctx, key.KeyID, actor); !errors.Is(err, ErrValidatorKeyRevoked) {
+		t.Errorf("Expected ErrValidatorKeyRevoked, got %v", err)
+	}
+	if _, err := repo.RevokeValidatorKey(ctx, uuid.New(), actor); !errors.Is(err, ErrValidatorKeyNotFound) {
+		t.Errorf("Expected ErrValidatorKeyNotFound, got %v", err)
+	}
+	if active, err := repo.GetActiveValidatorKeys(ctx, validatorID); err != nil || len(active) != 0 {
+		t.Errorf("Expected no active keys after revocation, got %d (%v)", len(active), err)
+	}
+
+	// Registering the key again reactivates it
+	key, err = repo.RegisterValidatorKey(ctx, validatorID, pubkey[:], nil, actor)
+	if err != nil || !key.IsActive || key.RevokedAt != nil {
+		t.Fatalf("Expected re-registration to reactivate the key, got %+v (%v)", key, err)
+	}
+
+	events, err := audit.ListValidatorKeyAuditEvents(ctx, key.KeyID, 10)
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	var actions []string
+	for _, e := range events {
+		actions = append(actions, e.Action)
+	}
+	if got := strings.Join(actions, ","); got != "register_validator_key,revoke_validator_key,register_validator_key" {
+		t.Errorf("Unexpected audit log: %s", got)
+	}
+}
+
+// testBLSKey derives a deterministic BLS key for a test validator
+func testBLSKey(t *testing.T, validatorID string) *bls.SecretKey {
+	t.Helper()
+	seed := sha256.Sum256([]byte(validatorID))
+	sk, err := bls.NewSecretKey(seed[:])
+	if err != nil {
+		t.Fatalf("Failed to derive BLS key: %v", err)
+	}
+	return sk
+}
+
+func TestSubmitBLSAttestation(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	proofs := NewProofArtifactRepository(testDB)
+	repo := NewConsensusRepository(&Client{db: testDB}, proofs)
+	ctx := context.Background()
+
+	proof, err := proofs.CreateProofArtifact(ctx, &NewProofArtifact{
+		ProofType:    ProofTypeChained,
+		AccumTxHash:  "test_bls_" + uuid.New().String()[:8],
+		AccountURL:   "acc://test.acme/tokens",
+		ProofClass:   ProofClassOnDemand,
+		ValidatorID:  "test-validator-1",
+		ArtifactJSON: json.RawMessage(`{"bls": true}`),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create proof: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM proof_artifacts WHERE proof_id = $1", proof.ProofID)
+	}()
+
+	chainID := "test-chain-" + uuid.New().String()[:8]
+	keys := make(map[string]*bls.SecretKey)
+	var validators []ValidatorEntry
+	for i, id := range []string{"bls-validator-1", "bls-validator-2", "bls-validator-3"} {
+		keys[id] = testBLSKey(t, chainID+id)
+		validators = append(validators, ValidatorEntry{ValidatorID: id, PublicKey: keys[id].PublicKey(), Weight: 1, Index: i})
+	}
+	validatorsJSON, _ := json.Marshal(validators)
+	snapshotHash := sha256.Sum256(append([]byte(chainID), validatorsJSON...))
+	snapshot, err := proofs.SaveValidatorSetSnapshot(ctx, &NewValidatorSetSnapshot{
+		BlockNumber:     1,
+		ValidatorsJSON:  validatorsJSON,
+		ValidatorRoot:   make([]byte, 32),
+		ValidatorCount:  3,
+		TotalWeight:     3,
+		ThresholdWeight: 2,
+		SnapshotHash:    snapshotHash[:],
+		ChainID:         chainID,
+		ChainName:       "test",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save validator set: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM validator_set_snapshots WHERE snapshot_id = $1", snapshot.SnapshotID)
+	}()
+
+	newResult := func(seq int64) *ExternalChainResultRecord {
+		resultHash := sha256.Sum256([]byte(chainID + string(rune('a'+seq))))
+		result, err := proofs.SaveExternalChainResult(ctx, &NewExternalChainResult{
+			ProofID:         proof.ProofID,
+			ChainID:         chainID,
+			ChainName:       "test",
+			BlockNumber:     100,
+			BlockHash:       make([]byte, 32),
+			TransactionHash: make([]byte, 32),
+			ExecutionStatus: 1,
+			SequenceNumber:  seq,
+			ResultHash:      resultHash[:],
+			AnchorProofHash: make([]byte, 32),
+			ArtifactJSON:    json.RawMessage(`{}`),
+		})
+		if err != nil {
+			t.Fatalf("Failed to save external chain result: %v", err)
+		}
+		return result
+	}
+	submission := func(result *ExternalChainResultRecord, validatorID string) *BLSAttestationSubmission {
+		sk, ok := keys[validatorID]
+		if !ok {
+			sk = testBLSKey(t, validatorID)
+		}
+		sig, err := sk.Sign(result.ResultHash)
+		if err != nil {
+			t.Fatalf("Failed to sign result: %v", err)
+		}
+		return &BLSAttestationSubmission{
+			ResultID:    result.ResultID,
+			ValidatorID: validatorID,
+			PublicKey:   sk.PublicKey(),
+			MessageHash: result.ResultHash,
+			Signature:   sig,
+			AttestedAt:  time.Now(),
+		}
+	}
+
+	chainResult := newResult(0)
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM consensus_entries WHERE result_id = $1", chainResult.ResultID)
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM aggregated_attestations WHERE result_id = $1", chainResult.ResultID)
+	}()
+
+	first, err := repo.SubmitBLSAttestation(ctx, submission(chainResult, "bls-validator-1"), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to submit first attestation: %v", err)
+	}
+	if first.Entry.State != ConsensusStateCollecting || first.Entry.AchievedWeight != 1 || first.Aggregate != nil {
+		t.Errorf("Expected collecting with weight 1 and no aggregate, got %s with %d", first.Entry.State, first.Entry.AchievedWeight)
+	}
+	if first.Entry.RequiredCount != 2 {
+		t.Errorf("Expected 2 required signers, got %d", first.Entry.RequiredCount)
+	}
+	if !first.Attestation.SignatureValid || first.Attestation.VerifiedAt == nil {
+		t.Error("Expected the stored attestation to be marked verified")
+	}
+
+	if _, err := repo.SubmitBLSAttestation(ctx, submission(chainResult, "bls-validator-1"), time.Hour); err != ErrDuplicateAttestation {
+		t.Errorf("Expected ErrDuplicateAttestation, got %v", err)
+	}
+	if _, err := repo.SubmitBLSAttestation(ctx, submission(chainResult, "bls-validator-9"), time.Hour); err != ErrValidatorNotInSet {
+		t.Errorf("Expected ErrValidatorNotInSet, got %v", err)
+	}
+
+	// A signature over another message is rejected and does not count
+	forged := submission(chainResult, "bls-validator-3")
+	forged.Signature, _ = keys["bls-validator-3"].Sign(make([]byte, 32))
+	if _, err := repo.SubmitBLSAttestation(ctx, forged, time.Hour); !errors.Is(err, ErrInvalidBLSSignature) {
+		t.Errorf("Expected ErrInvalidBLSSignature, got %v", err)
+	}
+	if entry, _ := repo.GetResultConsensusEntry(ctx, chainResult.ResultID); entry.AchievedWeight != 1 {
+		t.Errorf("Expected an invalid signature not to add weight, got %d", entry.AchievedWeight)
+	}
+
+	second, err := repo.SubmitBLSAttestation(ctx, submission(chainResult, "bls-validator-2"), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to submit second attestation: %v", err)
+	}
+	if second.Entry.State != ConsensusStateQuorumMet || second.Aggregate == nil {
+		t.Fatalf("Expected quorum_met with an aggregate, got %s", second.Entry.State)
+	}
+	if second.Aggregate.ParticipantCount != 2 || !second.Aggregate.ThresholdMet || !second.Aggregate.MessageConsistencyValid {
+		t.Errorf("Unexpected aggregate: %+v", second.Aggregate)
+	}
+	if !second.Aggregate.AggregationValid || second.Aggregate.VerifiedAt == nil {
+		t.Error("Expected the aggregate to be marked verified")
+	}
+	signers := [][]byte{keys["bls-validator-1"].PublicKey(), keys["bls-validator-2"].PublicKey()}
+	if err := bls.FastAggregateVerify(signers, chainResult.ResultHash, second.Aggregate.AggregatedSignature); err != nil {
+		t.Errorf("Expected aggregate signature to verify: %v", err)
+	}
+
+	if _, err := repo.SubmitBLSAttestation(ctx, submission(chainResult, "bls-validator-3"), time.Hour); err != ErrConsensusClosed {
+		t.Errorf("Expected ErrConsensusClosed after quorum, got %v", err)
+	}
+
+	// An entry past its deadline times out instead of accepting attestations
+	stalled := newResult(1)
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM consensus_entries WHERE result_id = $1", stalled.ResultID)
+	}()
+	closed, err := repo.SubmitBLSAttestation(ctx, submission(stalled, "bls-validator-1"), -time.Second)
+	if err != ErrConsensusClosed {
+		t.Fatalf("Expected ErrConsensusClosed past deadline, got %v", err)
+	}
+	if closed.Entry.State != ConsensusStateTimeout {
+		t.Errorf("Expected timeout state, got %s", closed.Entry.State)
+	}
+}
+
+func TestImportProof(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	proofs := NewProofArtifactRepository(testDB)
+	repo := NewIngestionRepository(&Client{db: testDB}, proofs)
+	ctx := context.Background()
+
+	artifact := json.RawMessage(`{"imported": true}`)
+	sum := sha256.Sum256(artifact)
+	missingBatch := uuid.New()
+	anchorTxHash := "0x" + uuid.New().String()
+	twoOfAny := func(int) int { return 2 }
+	imp := &ProofImport{
+		Proof: ProofArtifact{
+			ProofID:      uuid.New(),
+			ProofType:    ProofTypeChained,
+			ProofVersion: "1.0",
+			AccumTxHash:  "test_import_" + uuid.New().String()[:8],
+			AccountURL:   "acc://test.acme/tokens",
+			BatchID:      &missingBatch,
+			AnchorTxHash: &anchorTxHash,
+			MerkleRoot:   sum[:],
+			ProofClass:   ProofClassOnCadence,
+			ValidatorID:  "test-validator-1",
+			Status:       ProofStatusAttested,
+			CreatedAt:    time.Now().UTC().Add(-time.Hour),
+			ArtifactJSON: artifact,
+			ArtifactHash: sum[:],
+		},
+	}
+
+	// A registered validator's signed attestation and a forged one for the
+	// same validator, marked valid by the file but signed with another key
+	validatorID := "test-validator-" + uuid.New().String()[:8]
+	registeredPub, registeredKey, _ := ed25519.GenerateKey(nil)
+	forgedPub, forgedKey, _ := ed25519.GenerateKey(nil)
+	if _, err := repo.RegisterValidatorKey(ctx, validatorID, registeredPub, nil, &APIKeyActor{Type: "cli", Name: "test"}); err != nil {
+		t.Fatalf("Failed to register validator key: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM validator_keys WHERE validator_id = $1", validatorID)
+	}()
+	attestedHash := ComputeAttestedHash(sum[:], "")
+	imp.Attestations = []ProofAttestation{{
+		AttestationID:   uuid.New(),
+		ValidatorID:     validatorID,
+		ValidatorPubkey: registeredPub,
+		AttestedHash:    attestedHash,
+		Signature:       ed25519.Sign(registeredKey, attestedHash),
+		MerkleRoot:      sum[:],
+		AttestedAt:      time.Now().UTC(),
+		CreatedAt:       time.Now().UTC(),
+	}, {
+		AttestationID:   uuid.New(),
+		ValidatorID:     validatorID + "-forged",
+		ValidatorPubkey: forgedPub,
+		AttestedHash:    attestedHash,
+		Signature:       ed25519.Sign(forgedKey, attestedHash),
+		MerkleRoot:      sum[:],
+		SignatureValid:  true,
+		AttestedAt:      time.Now().UTC(),
+		CreatedAt:       time.Now().UTC(),
+	}}
+
+	changed, err := repo.ImportProof(ctx, imp, twoOfAny)
+	if err != nil {
+		t.Fatalf("Failed to import proof: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM proof_artifacts WHERE proof_id = $1", imp.Proof.ProofID)
+	}()
+	if !changed {
+		t.Error("Expected first import to write the proof")
+	}
+
+	// The proof keeps its ID and the unknown batch is dropped. One valid
+	// attestation is short of quorum, so the file's attested status is not kept
+	stored, err := proofs.GetProofByID(ctx, imp.Proof.ProofID)
+	if err != nil || stored == nil {
+		t.Fatalf("Failed to get imported proof: %v", err)
+	}
+	if stored.Status != ProofStatusAnchored || stored.BatchID != nil {
+		t.Errorf("Expected anchored proof without batch, got status %s batch %v", stored.Status, stored.BatchID)
+	}
+	attestations, err := proofs.GetProofAttestationsByProof(ctx, imp.Proof.ProofID)
+	if err != nil || len(attestations) != 2 {
+		t.Fatalf("Expected two imported attestations, got %d (%v)", len(attestations), err)
+	}
+	for _, a := range attestations {
+		if want := a.ValidatorID == validatorID; a.SignatureValid != want {
+			t.Errorf("Expected attestation by %s to have signature_valid=%v", a.ValidatorID, want)
+		}
+	}
+	events, err := proofs.GetCustodyChainEvents(ctx, imp.Proof.ProofID)
+	if err != nil || len(events) != 1 || events[0].EventType != "created" {
+		t.Fatalf("Expected one created custody event, got %d (%v)", len(events), err)
+	}
+
+	// Importing again writes nothing
+	if changed, err = repo.ImportProof(ctx, imp, twoOfAny); err != nil || changed {
+		t.Errorf("Expected re-import to be skipped, got changed=%v err=%v", changed, err)
+	}
+
+	// A second registered validator's attestation reaches quorum
+	secondID := validatorID + "-second"
+	secondPub, secondKey, _ := ed25519.GenerateKey(nil)
+	if _, err := repo.RegisterValidatorKey(ctx, secondID, secondPub, nil, &APIKeyActor{Type: "cli", Name: "test"}); err != nil {
+		t.Fatalf("Failed to register validator key: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM validator_keys WHERE validator_id = $1", secondID)
+	}()
+	imp.Attestations = append(imp.Attestations, ProofAttestation{
+		AttestationID:   uuid.New(),
+		ValidatorID:     secondID,
+		ValidatorPubkey: secondPub,
+		AttestedHash:    attestedHash,
+		Signature:       ed25519.Sign(secondKey, attestedHash),
+		MerkleRoot:      sum[:],
+		AttestedAt:      time.Now().UTC(),
+		CreatedAt:       time.Now().UTC(),
+	})
+	if changed, err = repo.ImportProof(ctx, imp, twoOfAny); err != nil || !changed {
+		t.Fatalf("Expected the new attestation to be added, got changed=%v err=%v", changed, err)
+	}
+	if stored, err = proofs.GetProofByID(ctx, imp.Proof.ProofID); err != nil || stored.Status != ProofStatusAttested {
+		t.Errorf("Expected proof to be attested at quorum, got %v (%v)", stored, err)
+	}
+
+	// A different artifact under the same ID is a conflict
+	conflicting := *imp
+	conflicting.Proof.ArtifactHash = make([]byte, 32)
+	if _, err = repo.ImportProof(ctx, &conflicting, twoOfAny); !errors.Is(err, ErrImportConflict) {
+		t.Errorf("Expected ErrImportConflict, got %v", err)
+	}
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	repo := NewIdempotencyRepository(&Client{db: testDB})
+	ctx := context.Background()
+
+	scope, key := "test-scope", "test_key_"+uuid.New().String()[:8]
+	fingerprint := sha256.Sum256([]byte("POST /api/v1/proofs/request {}"))
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2", scope, key)
+	}()
+
+	now := time.Now().UTC()
+	if _, claimed, err := repo.ClaimKey(ctx, scope, key, "POST", "/api/v1/proofs/request", fingerprint[:], now, now.Add(time.Minute)); err != nil || !claimed {
+		t.Fatalf("Expected first claim to succeed, got claimed=%v err=%v", claimed, err)
+	}
+
+	// A second claim sees the in-progress record
+	rec, claimed, err := repo.ClaimKey(ctx, scope, key, "POST", "/api/v1/proofs/request", fingerprint[:], now, now.Add(time.Minute))
+	if err != nil || claimed || rec.ResponseStatus != nil {
+		t.Fatalf("Expected in-progress record, got claimed=%v err=%v", claimed, err)
+	}
+
+	// A renewed lease holds the key; an abandoned one can be taken over
+	if err := repo.ExtendKey(ctx, scope, key, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Failed to extend key: %v", err)
+	}
+	if _, claimed, err = repo.ClaimKey(ctx, scope, key, "POST", "/api/v1/proofs/request", fingerprint[:], now.Add(90*time.Second), now.Add(3*time.Minute)); err != nil || claimed {
+		t.Fatalf("Expected renewed claim to hold, got claimed=%v err=%v", claimed, err)
+	}
+	if _, claimed, err = repo.ClaimKey(ctx, scope, key, "POST", "/api/v1/proofs/request", fingerprint[:], now.Add(3*time.Minute), now.Add(4*time.Minute)); err != nil || !claimed {
+		t.Fatalf("Expected lapsed claim to be taken over, got claimed=%v err=%v", claimed, err)
+	}
+
+	if err := repo.CompleteKey(ctx, scope, key, 201, json.RawMessage(`{"Content-Type":["application/json"]}`), []byte(`{"ok":true}`), now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to complete key: %v", err)
+	}
+	rec, err = repo.GetKey(ctx, scope, key)
+	if err != nil || rec == nil || rec.ResponseStatus == nil || *rec.ResponseStatus != 201 || string(rec.ResponseBody) != `{"ok":true}` {
+		t.Fatalf("Expected stored response, got %+v (%v)", rec, err)
+	}
+	if rec.ExpiresAt.Sub(now.Add(time.Hour)).Abs() > time.Millisecond {
+		t.Errorf("Expected completed key to expire after the TTL, got %s", rec.ExpiresAt)
+	}
+
+	// Completed keys are not released, but can be reclaimed once expired
+	if err := repo.ReleaseKey(ctx, scope, key); err != nil {
+		t.Fatalf("Failed to release key: %v", err)
+	}
+	later := now.Add(2 * time.Hour)
+	if _, claimed, err = repo.ClaimKey(ctx, scope, key, "POST", "/api/v1/proofs/request", fingerprint[:], later, later.Add(time.Hour)); err != nil || !claimed {
+		t.Errorf("Expected expired key to be reclaimed, got claimed=%v err=%v", claimed, err)
+	}
+}
+
+func TestAPIKeyAdministration(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	client := &Client{db: testDB}
+	repo := NewAPIKeyRepository(client, NewProofArtifactRepository(testDB))
+	ctx := context.Background()
+	actor := &APIKeyActor{Type: "cli", Name: "test"}
+
+	created, err := repo.CreateKey(ctx, &NewAPIKey{
+		ClientName:    "test_admin_" + uuid.New().String()[:8],
+		ClientType:    "service",
+		CanReadProofs: true,
+	}, actor)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	keyID := created.Key.KeyID
+	var rotatedID uuid.UUID
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM api_keys WHERE key_id = $1", rotatedID)
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM api_keys WHERE key_id = $1", keyID)
+	}()
+
+	// Only the hash of the plaintext is stored
+	hash := sha256.Sum256([]byte(created.Plaintext))
+	stored, err := repo.proofs.GetAPIKeyByHash(ctx, hash[:])
+	if err != nil || stored == nil || stored.KeyID != keyID || *stored.KeyPrefix != created.Plaintext[:12] {
+		t.Fatalf("Expected key to be found by plaintext hash, got %+v (%v)", stored, err)
+	}
+
+	bulk := true
+	key, err := repo.UpdateKeyPermissions(ctx, keyID, &APIKeyPermissions{CanBulkDownload: &bulk}, actor)
+	if err != nil || !key.CanBulkDownload || !key.CanReadProofs {
+		t.Fatalf("Expected bulk download added, got %+v (%v)", key, err)
+	}
+
+	rotated, previous, err := repo.RotateKey(ctx, keyID, time.Hour, actor)
+	if err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	rotatedID = rotated.Key.KeyID
+	if *rotated.Key.RotatedFrom != keyID || !rotated.Key.CanBulkDownload || rotated.Plaintext == created.Plaintext {
+		t.Errorf("Expected rotated key to copy permissions, got %+v", rotated.Key)
+	}
+	if previous.ExpiresAt == nil || time.Until(*previous.ExpiresAt) > time.Hour {
+		t.Errorf("Expected old key to expire within the overlap, got %v", previous.ExpiresAt)
+	}
+
+	if _, err := repo.DeactivateKey(ctx, keyID, actor); err != nil {
+		t.Fatalf("Failed to deactivate key: %v", err)
+	}
+	if _, err := repo.DeactivateKey(ctx, keyID, actor); !errors.Is(err, ErrAPIKeyInactive) {
+		t.Errorf("Expected ErrAPIKeyInactive, got %v", err)
+	}
+	if _, err := repo.SetKeyExpiry(ctx, uuid.New(), nil, actor); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound, got %v", err)
+	}
+
+	events, err := repo.ListAuditEvents(ctx, keyID, 10)
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	var actions []string
+	for _, e := range events {
+		actions = append(actions, e.Action)
+	}
+	if got := strings.Join(actions, ","); got != "deactivate,rotate,update_permissions,create" {
+		t.Errorf("Unexpected audit log: %s", got)
+	}
+}
+
+func TestRateLimitCounters(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	repo := NewRateLimitRepository(&Client{db: testDB})
+	ctx := context.Background()
+
+	bucket := "ip:test-" + uuid.New().String()[:8]
+	windowStart := time.Now().UTC().Truncate(time.Minute)
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM rate_limit_counters WHERE bucket = $1", bucket)
+	}()
+
+	for _, step := range []struct{ cost, want int }{{1, 1}, {10, 11}} {
+		total, err := repo.IncrementCounter(ctx, bucket, windowStart, step.cost, windowStart.Add(time.Minute))
+		if err != nil || total != step.want {
+			t.Fatalf("Expected total %d, got %d (%v)", step.want, total, err)
+		}
+	}
+
+	// A new window starts from zero
+	if total, err := repo.IncrementCounter(ctx, bucket, windowStart.Add(time.Minute), 1, windowStart.Add(2*time.Minute)); err != nil || total != 1 {
+		t.Fatalf("Expected new window total 1, got %d (%v)", total, err)
+	}
+
+	if _, err := repo.PurgeExpiredCounters(ctx, windowStart.Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to purge counters: %v", err)
+	}
+	var remaining int
+	if err := testDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM rate_limit_counters WHERE bucket = $1", bucket).Scan(&remaining); err != nil || remaining != 1 {
+		t.Errorf("Expected only the current window to remain, got %d (%v)", remaining, err)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	repo := NewAccessLogRepository(&Client{db: testDB})
+	ctx := context.Background()
+
+	principal := "test-" + uuid.New().String()[:8]
+	proofID := uuid.New()
+	defer func() {
+		_, _ = testDB.ExecContext(ctx, "DELETE FROM api_access_log WHERE principal_name = $1", principal)
+	}()
+
+	now := time.Now().UTC()
+	for _, occurredAt := range []time.Time{now.AddDate(0, 0, -400), now} {
+		entry := &AccessLogEntry{
+			OccurredAt:    occurredAt,
+			PrincipalType: "api_key",
+			PrincipalName: &principal,
+			Method:        "GET",
+			Route:         "/api/v1/proofs/",
+			Path:          "/api/v1/proofs/" + proofID.String(),
+			ProofID:       &proofID,
+			StatusCode:    200,
+			ResponseBytes: 512,
+			LatencyMS:     3,
+		}
+		if err := repo.RecordAccess(ctx, entry); err != nil || entry.AccessID == 0 {
+			t.Fatalf("Failed to record access: %v", err)
+		}
+	}
+
+	entries, err := repo.ListAccess(ctx, &AccessLogFilter{PrincipalName: &principal, ProofID: &proofID})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d (%v)", len(entries), err)
+	}
+	if !entries[0].OccurredAt.After(entries[1].OccurredAt) {
+		t.Error("Expected the newest entry first")
+	}
+
+	// Entries cannot be modified
+	if _, err := testDB.ExecContext(ctx, "UPDATE api_access_log SET status_code = 500 WHERE principal_name = $1", principal); err == nil {
+		t.Error("Expected updating the access log to fail")
+	}
+
+	if _, err := repo.PurgeAccessLog(ctx, now.AddDate(0, 0, -399)); err != nil {
+		t.Fatalf("Failed to purge access log: %v", err)
+	}
+	entries, err = repo.ListAccess(ctx, &AccessLogFilter{PrincipalName: &principal})
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the recent entry to remain, got %d (%v)", len(entries), err)
+	}
+}
+
+func TestSignedDownloadURLs(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Test database not configured")
+	}
+
+	repo := NewSignedURLRepository(&Client{db: testDB})
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	var created []uuid.UUID
+	defer func() {
+		for _, urlID := range created {
+			_, _ = testDB.ExecContext(ctx, "DELETE FROM signed_download_urls WHERE url_id = $1", urlID)
+		}
+	}()
+	create := func(singleUse bool, expiresAt time.Time) *SignedDownloadURL {
+		u, err := repo.CreateSignedURL(ctx, &NewSignedDownloadURL{
+			ResourceType: SignedResourceBundle,
+			ResourceID:   uuid.New(),
+			SingleUse:    singleUse,
+			ExpiresAt:    expiresAt,
+			CreatedBy:    "test",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create signed URL: %v", err)
+		}
+		created = append(created, u.URLID)
+		return u
+	}
+
+	single := create(true, now.Add(time.Hour))
+	if u, err := repo.RedeemSignedURL(ctx, single.URLID, now); err != nil || u.RedemptionCount != 1 {
+		t.Fatalf("Expected first redemption to succeed, got %v", err)
+	}
+	if _, err := repo.RedeemSignedURL(ctx, single.URLID, now); !errors.Is(err, ErrSignedURLNotRedeemable) {
+		t.Errorf("Expected second redemption of a single-use URL to fail, got %v", err)
+	}
+
+	reusable := create(false, now.Add(time.Hour))
+	for i := 0; i < 2; i++ {
+		if _, err := repo.RedeemSignedURL(ctx, reusable.URLID, now); err != nil {
+			t.Fatalf("Expected redemption %d to succeed, got %v", i+1, err)
+		}
+	}
+
+	expired := create(false, now.Add(-time.Minute))
+	if _, err := repo.RedeemSignedURL(ctx, expired.URLID, now); !errors.Is(err, ErrSignedURLNotRedeemable) {
+		t.Errorf("Expected expired URL to be rejected, got %v", err)
+	}
+}
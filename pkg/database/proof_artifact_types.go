@@ -366,6 +366,25 @@ type AnchorReferenceRecord struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// NewAnchorReference is used to create a new anchor reference record
+type NewAnchorReference struct {
+	ProofID           uuid.UUID  `json:"proof_id"`
+	TargetChain       string     `json:"target_chain"`
+	ChainID           string     `json:"chain_id"`
+	NetworkName       string     `json:"network_name"`
+	AnchorTxHash      string     `json:"anchor_tx_hash"`
+	AnchorBlockNumber int64      `json:"anchor_block_number"`
+	AnchorBlockHash   *string    `json:"anchor_block_hash,omitempty"`
+	AnchorTimestamp   *time.Time `json:"anchor_timestamp,omitempty"`
+	ContractAddress   *string    `json:"contract_address,omitempty"`
+	Confirmations     int        `json:"confirmations"`
+	IsConfirmed       bool       `json:"is_confirmed"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty"`
+	GasUsed           *int64     `json:"gas_used,omitempty"`
+	GasPriceWei       *string    `json:"gas_price_wei,omitempty"`
+	TotalCostWei      *string    `json:"total_cost_wei,omitempty"`
+}
+
 // ============================================================================
 // Receipt Steps
 // ============================================================================
@@ -585,6 +604,7 @@ type ProofBundle struct {
 
 // NewProofBundle is used to create a new bundle record
 type NewProofBundle struct {
+	BundleID           *uuid.UUID `json:"bundle_id,omitempty"` // Generated if nil
 	ProofID            uuid.UUID `json:"proof_id"`
 	BundleFormat       string    `json:"bundle_format"`
 	BundleVersion      string    `json:"bundle_version"`
@@ -650,6 +670,16 @@ type APIKey struct {
 	CanReadProofs   bool `json:"can_read_proofs" db:"can_read_proofs"`
 	CanRequestProofs bool `json:"can_request_proofs" db:"can_request_proofs"`
 	CanBulkDownload bool `json:"can_bulk_download" db:"can_bulk_download"`
+	CanSubmitProofs bool `json:"can_submit_proofs" db:"can_submit_proofs"`
+	CanAdmin        bool `json:"can_admin" db:"can_admin"`
+
+	// Validator the key was issued to; required to submit proofs
+	ValidatorID *string `json:"validator_id,omitempty" db:"validator_id"`
+
+	// Administration
+	KeyPrefix     *string    `json:"key_prefix,omitempty" db:"key_prefix"`
+	RotatedFrom   *uuid.UUID `json:"rotated_from,omitempty" db:"rotated_from"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
 
 	// Rate limiting
 	RateLimitPerMin int `json:"rate_limit_per_min" db:"rate_limit_per_min"`
@@ -674,6 +704,11 @@ type NewAPIKey struct {
 	CanReadProofs    bool       `json:"can_read_proofs"`
 	CanRequestProofs bool       `json:"can_request_proofs"`
 	CanBulkDownload  bool       `json:"can_bulk_download"`
+	CanSubmitProofs  bool       `json:"can_submit_proofs"`
+	CanAdmin         bool       `json:"can_admin"`
+	ValidatorID      *string    `json:"validator_id,omitempty"`
+	KeyPrefix        *string    `json:"key_prefix,omitempty"`
+	RotatedFrom      *uuid.UUID `json:"rotated_from,omitempty"`
 	RateLimitPerMin  int        `json:"rate_limit_per_min"`
 	IsActive         bool       `json:"is_active"`
 	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
@@ -713,7 +748,9 @@ type NewBundleProofRequest struct {
 
 // NewBundleDownload is used to record a bundle download
 type NewBundleDownload struct {
-	BundleID     uuid.UUID  `json:"bundle_id"`
+	BundleID     uuid.UUID  `json:"bundle_id"` // uuid.Nil for export downloads
+	ExportJobID  *uuid.UUID `json:"export_job_id,omitempty"`
+	SignedURLID  *uuid.UUID `json:"signed_url_id,omitempty"`
 	APIKeyID     *uuid.UUID `json:"api_key_id,omitempty"`
 	ClientIP     string     `json:"client_ip"`
 	UserAgent    *string    `json:"user_agent,omitempty"`
@@ -844,15 +881,17 @@ type BLSAttestationRecord struct {
 
 // NewBLSAttestation is used to create a new BLS attestation record
 type NewBLSAttestation struct {
-	ResultID      uuid.UUID `json:"result_id"`
-	SnapshotID    uuid.UUID `json:"snapshot_id"`
-	ValidatorID   string    `json:"validator_id"`
-	PublicKey     []byte    `json:"public_key"`
-	MessageHash   []byte    `json:"message_hash"`
-	Signature     []byte    `json:"signature"`
-	Weight        int64     `json:"weight"`
-	SubgroupValid bool      `json:"subgroup_valid"`
-	AttestedAt    time.Time `json:"attested_at"`
+	ResultID       uuid.UUID  `json:"result_id"`
+	SnapshotID     uuid.UUID  `json:"snapshot_id"`
+	ValidatorID    string     `json:"validator_id"`
+	PublicKey      []byte     `json:"public_key"`
+	MessageHash    []byte     `json:"message_hash"`
+	Signature      []byte     `json:"signature"`
+	Weight         int64      `json:"weight"`
+	SubgroupValid  bool       `json:"subgroup_valid"`
+	SignatureValid bool       `json:"signature_valid"`
+	VerifiedAt     *time.Time `json:"verified_at,omitempty"`
+	AttestedAt     time.Time  `json:"attested_at"`
 }
 
 // AggregatedAttestationRecord stores BLS aggregated attestations
@@ -905,6 +944,8 @@ type NewAggregatedAttestation struct {
 	AchievedWeight          int64           `json:"achieved_weight"`
 	ThresholdMet            bool            `json:"threshold_met"`
 	MessageConsistencyValid bool            `json:"message_consistency_valid"`
+	AggregationValid        bool            `json:"aggregation_valid"`
+	VerifiedAt              *time.Time      `json:"verified_at,omitempty"`
 	AggregatedAt            time.Time       `json:"aggregated_at"`
 }
 
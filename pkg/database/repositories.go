@@ -14,17 +14,36 @@ type Repositories struct {
 	Attestations   *AttestationRepository
 	Requests       *RequestRepository
 	IntentLifecycle *IntentLifecycleRepository
+	MerkleAudit     *MerkleAuditRepository
+	Consensus       *ConsensusRepository
+	MultiLeg        *MultiLegRepository
+	Ingestion       *IngestionRepository
+	Idempotency     *IdempotencyRepository
+	APIKeys         *APIKeyRepository
+	RateLimits      *RateLimitRepository
+	AccessLog       *AccessLogRepository
+	SignedURLs      *SignedURLRepository
 }
 
 // NewRepositories creates all repositories with the given client
 func NewRepositories(client *Client) *Repositories {
+	proofArtifacts := NewProofArtifactRepository(client.DB()) // NEW: Uses raw *sql.DB
 	return &Repositories{
 		Batches:         NewBatchRepository(client),
 		Anchors:         NewAnchorRepository(client),
 		Proofs:          NewProofRepository(client),
-		ProofArtifacts:  NewProofArtifactRepository(client.DB()), // NEW: Uses raw *sql.DB
+		ProofArtifacts:  proofArtifacts,
 		Attestations:    NewAttestationRepository(client),
 		Requests:        NewRequestRepository(client),
 		IntentLifecycle: NewIntentLifecycleRepository(client),
+		MerkleAudit:     NewMerkleAuditRepository(client),
+		Consensus:       NewConsensusRepository(client, proofArtifacts),
+		MultiLeg:        NewMultiLegRepository(client),
+		Ingestion:       NewIngestionRepository(client, proofArtifacts),
+		Idempotency:     NewIdempotencyRepository(client),
+		APIKeys:         NewAPIKeyRepository(client, proofArtifacts),
+		RateLimits:      NewRateLimitRepository(client),
+		AccessLog:       NewAccessLogRepository(client),
+		SignedURLs:      NewSignedURLRepository(client),
 	}
 }
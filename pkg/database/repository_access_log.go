@@ -0,0 +1,141 @@
+// Copyright 2025 Certen Protocol
+//
+// Access Log Repository - Append-only record of API requests
+//
+// Entries are only ever inserted, listed for auditors, and deleted once they
+// fall outside the retention period.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AccessLogRepository handles API access log operations
+type AccessLogRepository struct {
+	client *Client
+}
+
+// NewAccessLogRepository creates a new access log repository
+func NewAccessLogRepository(client *Client) *AccessLogRepository {
+	return &AccessLogRepository{client: client}
+}
+
+// ============================================================================
+// ACCESS LOG OPERATIONS
+// ============================================================================
+
+// RecordAccess appends an entry to the access log
+func (r *AccessLogRepository) RecordAccess(ctx context.Context, entry *AccessLogEntry) error {
+	query := `
+		INSERT INTO api_access_log (
+			occurred_at, principal_type, principal_name, key_id, client_ip,
+			method, route, path, proof_id, intent_id, account_url, user_id,
+			status_code, response_bytes, latency_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING access_id`
+
+	err := r.client.QueryRowContext(ctx, query,
+		entry.OccurredAt, entry.PrincipalType, entry.PrincipalName, entry.KeyID, entry.ClientIP,
+		entry.Method, entry.Route, entry.Path, entry.ProofID, entry.IntentID, entry.AccountURL, entry.UserID,
+		entry.StatusCode, entry.ResponseBytes, entry.LatencyMS,
+	).Scan(&entry.AccessID)
+	if err != nil {
+		return fmt.Errorf("failed to record access: %w", err)
+	}
+	return nil
+}
+
+// ListAccess returns access log entries matching the filter, newest first
+func (r *AccessLogRepository) ListAccess(ctx context.Context, filter *AccessLogFilter) ([]*AccessLogEntry, error) {
+	if filter == nil {
+		filter = &AccessLogFilter{}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(column string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s $%d", column, len(args)))
+	}
+
+	if filter.PrincipalName != nil {
+		addCondition("principal_name =", *filter.PrincipalName)
+	}
+	if filter.KeyID != nil {
+		addCondition("key_id =", *filter.KeyID)
+	}
+	if filter.ProofID != nil {
+		addCondition("proof_id =", *filter.ProofID)
+	}
+	if filter.IntentID != nil {
+		addCondition("intent_id =", *filter.IntentID)
+	}
+	if filter.AccountURL != nil {
+		addCondition("account_url =", *filter.AccountURL)
+	}
+	if filter.UserID != nil {
+		addCondition("user_id =", *filter.UserID)
+	}
+	if filter.Since != nil {
+		addCondition("occurred_at >=", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("occurred_at <", *filter.Until)
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT access_id, occurred_at, principal_type, principal_name, key_id, client_ip,
+			method, route, path, proof_id, intent_id, account_url, user_id,
+			status_code, response_bytes, latency_ms
+		FROM api_access_log
+		%s
+		ORDER BY occurred_at DESC, access_id DESC
+		LIMIT $%d OFFSET $%d`, whereClause, len(args)+1, len(args)+2)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.client.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query access log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AccessLogEntry
+	for rows.Next() {
+		e := &AccessLogEntry{}
+		if err := rows.Scan(
+			&e.AccessID, &e.OccurredAt, &e.PrincipalType, &e.PrincipalName, &e.KeyID, &e.ClientIP,
+			&e.Method, &e.Route, &e.Path, &e.ProofID, &e.IntentID, &e.AccountURL, &e.UserID,
+			&e.StatusCode, &e.ResponseBytes, &e.LatencyMS,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan access log entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// PurgeAccessLog deletes entries that occurred before cutoff and returns how
+// many
+func (r *AccessLogRepository) PurgeAccessLog(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.client.ExecContext(ctx, `DELETE FROM api_access_log WHERE occurred_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge access log: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -192,6 +192,45 @@ func (r *AnchorRepository) GetAnchorByBatchID(ctx context.Context, batchID uuid.
 	return anchor, nil
 }
 
+// GetAnchorsByBatchID returns every anchor recorded for a batch, oldest first
+func (r *AnchorRepository) GetAnchorsByBatchID(ctx context.Context, batchID uuid.UUID) ([]*AnchorRecord, error) {
+	query := `
+		SELECT anchor_id, batch_id, target_chain, chain_id, network_name,
+			contract_address, anchor_tx_hash, anchor_block_number, anchor_block_hash,
+			anchor_timestamp, merkle_root, accumulate_height, operation_commitment,
+			cross_chain_commitment, governance_root, confirmations, required_confirmations,
+			confirmed_at, is_final, gas_used, gas_price_wei, total_cost_wei, total_cost_usd,
+			validator_id, created_at, updated_at
+		FROM anchor_records
+		WHERE batch_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.client.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchors by batch ID: %w", err)
+	}
+	defer rows.Close()
+
+	var anchors []*AnchorRecord
+	for rows.Next() {
+		anchor := &AnchorRecord{}
+		err := rows.Scan(
+			&anchor.AnchorID, &anchor.BatchID, &anchor.TargetChain, &anchor.ChainID, &anchor.NetworkName,
+			&anchor.ContractAddress, &anchor.AnchorTxHash, &anchor.AnchorBlockNumber, &anchor.AnchorBlockHash,
+			&anchor.AnchorTimestamp, &anchor.MerkleRoot, &anchor.AccumHeight, &anchor.OperationCommitment,
+			&anchor.CrossChainCommitment, &anchor.GovernanceRoot, &anchor.Confirmations, &anchor.RequiredConfirms,
+			&anchor.ConfirmedAt, &anchor.IsFinal, &anchor.GasUsed, &anchor.GasPriceWei, &anchor.TotalCostWei,
+			&anchor.TotalCostUSD, &anchor.ValidatorID, &anchor.CreatedAt, &anchor.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan anchor: %w", err)
+		}
+		anchors = append(anchors, anchor)
+	}
+
+	return anchors, rows.Err()
+}
+
 // GetUnconfirmedAnchors returns anchors that haven't reached required confirmations
 func (r *AnchorRepository) GetUnconfirmedAnchors(ctx context.Context) ([]*AnchorRecord, error) {
 	query := `
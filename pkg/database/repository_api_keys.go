@@ -0,0 +1,480 @@
+// Copyright 2025 Certen Protocol
+//
+// API Key Repository - Administration of API keys with an audit log
+//
+// Keys are generated here so their plaintext is only ever returned once, to
+// the caller that created or rotated them; only the SHA-256 hash and a short
+// prefix are stored. Every change to a key is written in one transaction
+// together with its api_key_audit_log entry.
+
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every generated API key
+const APIKeyPrefix = "cpk_"
+
+// apiKeyPrefixLength is how much of a key is stored in key_prefix
+const apiKeyPrefixLength = 12
+
+// API key audit actions
+const (
+	APIKeyActionCreate            = "create"
+	APIKeyActionRotate            = "rotate"
+	APIKeyActionDeactivate        = "deactivate"
+	APIKeyActionSetExpiry         = "set_expiry"
+	APIKeyActionUpdatePermissions = "update_permissions"
+)
+
+// Validator key audit actions
+const (
+	ValidatorKeyActionRegister = "register_validator_key"
+	ValidatorKeyActionRevoke   = "revoke_validator_key"
+)
+
+// APIKeyRepository handles API key administration
+type APIKeyRepository struct {
+	client *Client
+	proofs *ProofArtifactRepository
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(client *Client, proofs *ProofArtifactRepository) *APIKeyRepository {
+	return &APIKeyRepository{client: client, proofs: proofs}
+}
+
+// APIKeyActor identifies who performed an administrative action
+type APIKeyActor struct {
+	Type     string     `json:"actor_type"` // "api_key", "jwt" or "cli"
+	KeyID    *uuid.UUID `json:"actor_key_id,omitempty"`
+	Name     string     `json:"actor_name"`
+	ClientIP *string    `json:"client_ip,omitempty"`
+}
+
+// APIKeyAuditEvent is one entry of the admin audit log. It concerns either
+// an API key (KeyID) or a validator key (ValidatorKeyID).
+type APIKeyAuditEvent struct {
+	AuditID        uuid.UUID       `json:"audit_id"`
+	KeyID          *uuid.UUID      `json:"key_id,omitempty"`
+	ValidatorKeyID *uuid.UUID      `json:"validator_key_id,omitempty"`
+	Action         string          `json:"action"`
+	ActorType      string          `json:"actor_type"`
+	ActorKeyID     *uuid.UUID      `json:"actor_key_id,omitempty"`
+	ActorName      string          `json:"actor_name"`
+	ClientIP       *string         `json:"client_ip,omitempty"`
+	Details        json.RawMessage `json:"details,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// APIKeyPermissions is an edit of a key's permissions; nil fields are unchanged
+type APIKeyPermissions struct {
+	CanReadProofs    *bool `json:"can_read_proofs,omitempty"`
+	CanRequestProofs *bool `json:"can_request_proofs,omitempty"`
+	CanBulkDownload  *bool `json:"can_bulk_download,omitempty"`
+}
+
+// CreatedAPIKey is a newly created key and its plaintext, which is not stored
+type CreatedAPIKey struct {
+	Key       *APIKey `json:"key"`
+	Plaintext string  `json:"api_key"`
+}
+
+// GenerateAPIKey returns a new random plaintext key and its SHA-256 hash
+func GenerateAPIKey() (string, []byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plaintext := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	hash := sha256.Sum256([]byte(plaintext))
+	return plaintext, hash[:], nil
+}
+
+// ============================================================================
+// KEY OPERATIONS
+// ============================================================================
+
+const apiKeyColumns = `
+	key_id, key_hash, client_name, client_type,
+	can_read_proofs, can_request_proofs, can_bulk_download, can_submit_proofs, can_admin,
+	validator_id, key_prefix, rotated_from, deactivated_at,
+	rate_limit_per_min, is_active, expires_at,
+	description, contact_email, created_at, last_used_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*APIKey, error) {
+	var key APIKey
+	err := row.Scan(
+		&key.KeyID, &key.KeyHash, &key.ClientName, &key.ClientType,
+		&key.CanReadProofs, &key.CanRequestProofs, &key.CanBulkDownload, &key.CanSubmitProofs, &key.CanAdmin,
+		&key.ValidatorID, &key.KeyPrefix, &key.RotatedFrom, &key.DeactivatedAt,
+		&key.RateLimitPerMin, &key.IsActive, &key.ExpiresAt,
+		&key.Description, &key.ContactEmail, &key.CreatedAt, &key.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateKey generates and stores a new key. input.KeyHash is ignored.
+func (r *APIKeyRepository) CreateKey(ctx context.Context, input *NewAPIKey, actor *APIKeyActor) (result *CreatedAPIKey, err error) {
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if result, err = r.createKey(ctx, tx, *input); err != nil {
+		return nil, err
+	}
+	if err = writeAPIKeyAudit(ctx, tx.Tx(), result.Key.KeyID, APIKeyActionCreate, actor, apiKeySummary(result.Key)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit API key creation: %w", err)
+	}
+	return result, nil
+}
+
+func (r *APIKeyRepository) createKey(ctx context.Context, tx *Tx, input NewAPIKey) (*CreatedAPIKey, error) {
+	plaintext, hash, err := GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	prefix := plaintext[:apiKeyPrefixLength]
+	input.KeyHash = hash
+	input.KeyPrefix = &prefix
+	input.IsActive = true
+	if input.RateLimitPerMin <= 0 {
+		input.RateLimitPerMin = 100
+	}
+
+	key, err := r.proofs.WithTx(tx).CreateAPIKey(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{Key: key, Plaintext: plaintext}, nil
+}
+
+// GetKey retrieves a key by ID. Returns nil, nil if not found.
+func (r *APIKeyRepository) GetKey(ctx context.Context, keyID uuid.UUID) (*APIKey, error) {
+	key, err := scanAPIKey(r.client.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_id = $1`, keyID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListKeys lists keys, newest first. Inactive and expired keys are included
+// only when includeInactive is set.
+func (r *APIKeyRepository) ListKeys(ctx context.Context, includeInactive bool) ([]*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys`
+	if !includeInactive {
+		query += ` WHERE is_active = TRUE AND (expires_at IS NULL OR expires_at > NOW())`
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.client.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RotateKey replaces an active key with a new one that has the same client,
+// permissions and expiry. The old key keeps working for overlap, or until
+// its own expiry if that is sooner.
+func (r *APIKeyRepository) RotateKey(ctx context.Context, keyID uuid.UUID, overlap time.Duration, actor *APIKeyActor) (result *CreatedAPIKey, previous *APIKey, err error) {
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	db := tx.Tx()
+
+	if previous, err = lockAPIKey(ctx, db, keyID); err != nil {
+		return nil, nil, err
+	}
+	now := time.Now().UTC()
+	if !previous.IsActive || (previous.ExpiresAt != nil && !previous.ExpiresAt.After(now)) {
+		return nil, nil, ErrAPIKeyInactive
+	}
+
+	result, err = r.createKey(ctx, tx, NewAPIKey{
+		ClientName:       previous.ClientName,
+		ClientType:       previous.ClientType,
+		CanReadProofs:    previous.CanReadProofs,
+		CanRequestProofs: previous.CanRequestProofs,
+		CanBulkDownload:  previous.CanBulkDownload,
+		CanSubmitProofs:  previous.CanSubmitProofs,
+		CanAdmin:         previous.CanAdmin,
+		ValidatorID:      previous.ValidatorID,
+		RotatedFrom:      &previous.KeyID,
+		RateLimitPerMin:  previous.RateLimitPerMin,
+		ExpiresAt:        previous.ExpiresAt,
+		Description:      previous.Description,
+		ContactEmail:     previous.ContactEmail,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	overlapEnd := now.Add(overlap)
+	if previous.ExpiresAt == nil || overlapEnd.Before(*previous.ExpiresAt) {
+		if _, err = db.ExecContext(ctx, `UPDATE api_keys SET expires_at = $2 WHERE key_id = $1`, keyID, overlapEnd); err != nil {
+			return nil, nil, fmt.Errorf("failed to expire rotated API key: %w", err)
+		}
+		previous.ExpiresAt = &overlapEnd
+	}
+
+	if err = writeAPIKeyAudit(ctx, db, keyID, APIKeyActionRotate, actor, map[string]interface{}{
+		"new_key_id":      result.Key.KeyID,
+		"overlap_seconds": int64(overlap / time.Second),
+		"expires_at":      previous.ExpiresAt,
+	}); err != nil {
+		return nil, nil, err
+	}
+	details := apiKeySummary(result.Key)
+	details["rotated_from"] = keyID
+	if err = writeAPIKeyAudit(ctx, db, result.Key.KeyID, APIKeyActionCreate, actor, details); err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit API key rotation: %w", err)
+	}
+	return result, previous, nil
+}
+
+// DeactivateKey deactivates a key immediately. Returns ErrAPIKeyInactive if
+// it already is.
+func (r *APIKeyRepository) DeactivateKey(ctx context.Context, keyID uuid.UUID, actor *APIKeyActor) (*APIKey, error) {
+	return r.updateKey(ctx, keyID, APIKeyActionDeactivate, actor, func(ctx context.Context, db *sql.Tx, key *APIKey) (map[string]interface{}, error) {
+		if !key.IsActive {
+			return nil, ErrAPIKeyInactive
+		}
+		if err := db.QueryRowContext(ctx, `
+			UPDATE api_keys SET is_active = FALSE, deactivated_at = NOW()
+			WHERE key_id = $1
+			RETURNING deactivated_at`, key.KeyID).Scan(&key.DeactivatedAt); err != nil {
+			return nil, fmt.Errorf("failed to deactivate API key: %w", err)
+		}
+		key.IsActive = false
+		return map[string]interface{}{"client_name": key.ClientName}, nil
+	})
+}
+
+// SetKeyExpiry sets or, with nil, clears a key's expiry
+func (r *APIKeyRepository) SetKeyExpiry(ctx context.Context, keyID uuid.UUID, expiresAt *time.Time, actor *APIKeyActor) (*APIKey, error) {
+	return r.updateKey(ctx, keyID, APIKeyActionSetExpiry, actor, func(ctx context.Context, db *sql.Tx, key *APIKey) (map[string]interface{}, error) {
+		details := map[string]interface{}{
+			"previous_expires_at": key.ExpiresAt,
+			"expires_at":          expiresAt,
+		}
+		if _, err := db.ExecContext(ctx, `UPDATE api_keys SET expires_at = $2 WHERE key_id = $1`, key.KeyID, expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to set API key expiry: %w", err)
+		}
+		key.ExpiresAt = expiresAt
+		return details, nil
+	})
+}
+
+// UpdateKeyPermissions changes the permissions set in perms
+func (r *APIKeyRepository) UpdateKeyPermissions(ctx context.Context, keyID uuid.UUID, perms *APIKeyPermissions, actor *APIKeyActor) (*APIKey, error) {
+	return r.updateKey(ctx, keyID, APIKeyActionUpdatePermissions, actor, func(ctx context.Context, db *sql.Tx, key *APIKey) (map[string]interface{}, error) {
+		before := apiKeyPermissions(key)
+		if perms.CanReadProofs != nil {
+			key.CanReadProofs = *perms.CanReadProofs
+		}
+		if perms.CanRequestProofs != nil {
+			key.CanRequestProofs = *perms.CanRequestProofs
+		}
+		if perms.CanBulkDownload != nil {
+			key.CanBulkDownload = *perms.CanBulkDownload
+		}
+		if _, err := db.ExecContext(ctx, `
+			UPDATE api_keys SET can_read_proofs = $2, can_request_proofs = $3, can_bulk_download = $4
+			WHERE key_id = $1`, key.KeyID, key.CanReadProofs, key.CanRequestProofs, key.CanBulkDownload); err != nil {
+			return nil, fmt.Errorf("failed to update API key permissions: %w", err)
+		}
+		return map[string]interface{}{"before": before, "after": apiKeyPermissions(key)}, nil
+	})
+}
+
+// updateKey locks a key, applies update and records it in the audit log
+func (r *APIKeyRepository) updateKey(
+	ctx context.Context,
+	keyID uuid.UUID,
+	action string,
+	actor *APIKeyActor,
+	update func(ctx context.Context, db *sql.Tx, key *APIKey) (map[string]interface{}, error),
+) (key *APIKey, err error) {
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	db := tx.Tx()
+
+	if key, err = lockAPIKey(ctx, db, keyID); err != nil {
+		return nil, err
+	}
+	details, err := update(ctx, db, key)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeAPIKeyAudit(ctx, db, keyID, action, actor, details); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit API key update: %w", err)
+	}
+	return key, nil
+}
+
+func lockAPIKey(ctx context.Context, db *sql.Tx, keyID uuid.UUID) (*APIKey, error) {
+	key, err := scanAPIKey(db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_id = $1 FOR UPDATE`, keyID))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock API key: %w", err)
+	}
+	return key, nil
+}
+
+func apiKeyPermissions(key *APIKey) map[string]bool {
+	return map[string]bool{
+		"can_read_proofs":    key.CanReadProofs,
+		"can_request_proofs": key.CanRequestProofs,
+		"can_bulk_download":  key.CanBulkDownload,
+		"can_submit_proofs":  key.CanSubmitProofs,
+		"can_admin":          key.CanAdmin,
+	}
+}
+
+func apiKeySummary(key *APIKey) map[string]interface{} {
+	return map[string]interface{}{
+		"client_name": key.ClientName,
+		"client_type": key.ClientType,
+		"key_prefix":  key.KeyPrefix,
+		"permissions": apiKeyPermissions(key),
+		"expires_at":  key.ExpiresAt,
+	}
+}
+
+// ============================================================================
+// AUDIT LOG
+// ============================================================================
+
+func writeAPIKeyAudit(ctx context.Context, db *sql.Tx, keyID uuid.UUID, action string, actor *APIKeyActor, details map[string]interface{}) error {
+	return writeAuditEntry(ctx, db, &keyID, nil, action, actor, details)
+}
+
+func writeValidatorKeyAudit(ctx context.Context, db *sql.Tx, key *ValidatorKey, action string, actor *APIKeyActor) error {
+	return writeAuditEntry(ctx, db, nil, &key.KeyID, action, actor, map[string]interface{}{
+		"validator_id": key.ValidatorID,
+		"public_key":   hex.EncodeToString(key.PublicKey),
+		"description":  key.Description,
+	})
+}
+
+func writeAuditEntry(ctx context.Context, db *sql.Tx, keyID, validatorKeyID *uuid.UUID, action string, actor *APIKeyActor, details map[string]interface{}) error {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO api_key_audit_log (key_id, validator_key_id, action, actor_type, actor_key_id, actor_name, client_ip, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		keyID, validatorKeyID, action, actor.Type, actor.KeyID, actor.Name, actor.ClientIP, encoded)
+	if err != nil {
+		return fmt.Errorf("failed to write admin audit log: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEvents returns the most recent audit log entries for an API key
+func (r *APIKeyRepository) ListAuditEvents(ctx context.Context, keyID uuid.UUID, limit int) ([]*APIKeyAuditEvent, error) {
+	return r.listAuditEvents(ctx, "key_id", keyID, limit)
+}
+
+// ListValidatorKeyAuditEvents returns the most recent audit log entries for a
+// validator key
+func (r *APIKeyRepository) ListValidatorKeyAuditEvents(ctx context.Context, validatorKeyID uuid.UUID, limit int) ([]*APIKeyAuditEvent, error) {
+	return r.listAuditEvents(ctx, "validator_key_id", validatorKeyID, limit)
+}
+
+func (r *APIKeyRepository) listAuditEvents(ctx context.Context, column string, id uuid.UUID, limit int) ([]*APIKeyAuditEvent, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := r.client.QueryContext(ctx, fmt.Sprintf(`
+		SELECT audit_id, key_id, validator_key_id, action, actor_type, actor_key_id, actor_name, client_ip, details, created_at
+		FROM api_key_audit_log
+		WHERE %s = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, column), id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*APIKeyAuditEvent
+	for rows.Next() {
+		var e APIKeyAuditEvent
+		var details []byte
+		if err := rows.Scan(&e.AuditID, &e.KeyID, &e.ValidatorKeyID, &e.Action, &e.ActorType, &e.ActorKeyID,
+			&e.ActorName, &e.ClientIP, &details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key audit event: %w", err)
+		}
+		if details != nil {
+			e.Details = details
+		}
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
@@ -156,15 +156,55 @@ func (r *BatchRepository) GetBatchesReadyForAnchoring(ctx context.Context) ([]*A
 	return batches, rows.Err()
 }
 
+// GetSealedBatches pages through batches whose Merkle root has been fixed
+// (closed or later, excluding failed), ordered by creation time. Pass the
+// last batch of the previous page as the cursor, or zero values to start.
+func (r *BatchRepository) GetSealedBatches(ctx context.Context, afterCreated time.Time, afterID uuid.UUID, limit int) ([]*AnchorBatch, error) {
+	query := `
+		SELECT batch_id, batch_type, merkle_root, transaction_count,
+			batch_start_time, batch_end_time, accumulate_block_height,
+			accumulate_block_hash, validator_id, status, error_message,
+			created_at, updated_at
+		FROM anchor_batches
+		WHERE status IN ('closed', 'anchoring', 'anchored', 'confirmed')
+			AND (created_at, batch_id) > ($1, $2)
+		ORDER BY created_at ASC, batch_id ASC
+		LIMIT $3`
+
+	rows, err := r.client.QueryContext(ctx, query, afterCreated, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sealed batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*AnchorBatch
+	for rows.Next() {
+		batch := &AnchorBatch{}
+		err := rows.Scan(
+			&batch.BatchID, &batch.BatchType, &batch.MerkleRoot, &batch.TxCount,
+			&batch.StartTime, &batch.EndTime, &batch.AccumHeight,
+			&batch.AccumHash, &batch.ValidatorID, &batch.Status, &batch.ErrorMessage,
+			&batch.CreatedAt, &batch.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch: %w", err)
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches, rows.Err()
+}
+
 // CloseBatch closes a batch with the computed merkle root
+// A zero accumHeight or empty accumHash leaves the Accumulate state unset
 func (r *BatchRepository) CloseBatch(ctx context.Context, batchID uuid.UUID, merkleRoot []byte, accumHeight int64, accumHash string) error {
 	query := `
 		UPDATE anchor_batches
 		SET status = 'closed',
 			merkle_root = $2,
 			batch_end_time = $3,
-			accumulate_block_height = $4,
-			accumulate_block_hash = $5,
+			accumulate_block_height = NULLIF($4, 0),
+			accumulate_block_hash = NULLIF($5, ''),
 			updated_at = $6
 		WHERE batch_id = $1 AND status = 'pending'`
 
@@ -209,6 +249,60 @@ func (r *BatchRepository) UpdateBatchStatus(ctx context.Context, batchID uuid.UU
 	return nil
 }
 
+// ClaimBatchForAnchoring moves a closed batch to 'anchoring' and counts a
+// submission attempt. It returns the attempts made so far, including this
+// one, or ErrBatchNotFound if the batch is no longer closed.
+func (r *BatchRepository) ClaimBatchForAnchoring(ctx context.Context, batchID uuid.UUID) (int, error) {
+	query := `
+		UPDATE anchor_batches
+		SET status = 'anchoring', anchor_attempts = anchor_attempts + 1, updated_at = $2
+		WHERE batch_id = $1 AND status = 'closed'
+		RETURNING anchor_attempts`
+
+	var attempts int
+	err := r.client.QueryRowContext(ctx, query, batchID, time.Now()).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, ErrBatchNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim batch for anchoring: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// RequeueInterruptedAnchoring returns batches that have been 'anchoring' for
+// longer than staleAfter without an anchor record to 'closed', or fails them
+// once maxAttempts submissions have been made. It returns the batches changed.
+func (r *BatchRepository) RequeueInterruptedAnchoring(ctx context.Context, staleAfter time.Duration, maxAttempts int) ([]uuid.UUID, error) {
+	query := `
+		UPDATE anchor_batches b
+		SET status = CASE WHEN b.anchor_attempts >= $2 THEN 'failed' ELSE 'closed' END,
+			error_message = 'anchor submission interrupted before the anchor was recorded',
+			updated_at = NOW()
+		WHERE b.status = 'anchoring'
+		  AND b.updated_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM anchor_records ar WHERE ar.batch_id = b.batch_id)
+		RETURNING b.batch_id`
+
+	rows, err := r.client.QueryContext(ctx, query, time.Now().Add(-staleAfter), maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue interrupted batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batchIDs []uuid.UUID
+	for rows.Next() {
+		var batchID uuid.UUID
+		if err := rows.Scan(&batchID); err != nil {
+			return nil, fmt.Errorf("failed to scan batch id: %w", err)
+		}
+		batchIDs = append(batchIDs, batchID)
+	}
+
+	return batchIDs, rows.Err()
+}
+
 // IncrementTxCount increments the transaction count for a batch
 func (r *BatchRepository) IncrementTxCount(ctx context.Context, batchID uuid.UUID) error {
 	query := `
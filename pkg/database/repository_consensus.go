@@ -0,0 +1,478 @@
+// Copyright 2025 Certen Protocol
+//
+// Consensus Repository - Batch attestations and consensus entries
+//
+// Also collects BLS attestations of external chain results into consensus
+// entries, aggregating them once the validator set's threshold weight is met
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/bls"
+)
+
+// ConsensusRepository handles batch attestation and consensus entry operations
+type ConsensusRepository struct {
+	client *Client
+	proofs *ProofArtifactRepository
+}
+
+// NewConsensusRepository creates a new consensus repository
+func NewConsensusRepository(client *Client, proofs *ProofArtifactRepository) *ConsensusRepository {
+	return &ConsensusRepository{client: client, proofs: proofs}
+}
+
+// ============================================================================
+// BATCH ATTESTATION OPERATIONS
+// ============================================================================
+
+// GetBatchAttestations returns all BLS attestations for a batch, oldest first
+func (r *ConsensusRepository) GetBatchAttestations(ctx context.Context, batchID uuid.UUID) ([]*BatchAttestation, error) {
+	query := `
+		SELECT attestation_id, batch_id, validator_id, merkle_root, bls_signature,
+			bls_public_key, tx_count, block_height, attestation_time,
+			signature_valid, verified_at, created_at
+		FROM batch_attestations
+		WHERE batch_id = $1
+		ORDER BY attestation_time ASC, validator_id ASC`
+
+	rows, err := r.client.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch attestations: %w", err)
+	}
+	defer rows.Close()
+
+	var attestations []*BatchAttestation
+	for rows.Next() {
+		a := &BatchAttestation{}
+		if err := rows.Scan(
+			&a.AttestationID, &a.BatchID, &a.ValidatorID, &a.MerkleRoot, &a.BLSSignature,
+			&a.BLSPublicKey, &a.TxCount, &a.BlockHeight, &a.AttestationTime,
+			&a.SignatureValid, &a.VerifiedAt, &a.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan batch attestation: %w", err)
+		}
+		attestations = append(attestations, a)
+	}
+
+	return attestations, rows.Err()
+}
+
+// ============================================================================
+// CONSENSUS ENTRY OPERATIONS
+// ============================================================================
+
+// GetConsensusEntry returns the consensus entry for a batch
+func (r *ConsensusRepository) GetConsensusEntry(ctx context.Context, batchID uuid.UUID) (*ConsensusEntry, error) {
+	query := `
+		SELECT entry_id, batch_id, merkle_root, anchor_tx_hash, block_number, tx_count,
+			state, attestation_count, required_count, quorum_fraction,
+			aggregate_signature, aggregate_pubkey, start_time, last_update,
+			completed_at, result_json, created_at
+		FROM consensus_entries
+		WHERE batch_id = $1`
+
+	e := &ConsensusEntry{}
+	var resultJSON []byte
+	err := r.client.QueryRowContext(ctx, query, batchID).Scan(
+		&e.EntryID, &e.BatchID, &e.MerkleRoot, &e.AnchorTxHash, &e.BlockNumber, &e.TxCount,
+		&e.State, &e.AttestationCount, &e.RequiredCount, &e.QuorumFraction,
+		&e.AggregateSignature, &e.AggregatePubkey, &e.StartTime, &e.LastUpdate,
+		&e.CompletedAt, &resultJSON, &e.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrConsensusEntryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consensus entry: %w", err)
+	}
+	e.ResultJSON = resultJSON
+
+	return e, nil
+}
+
+// ============================================================================
+// RESULT CONSENSUS OPERATIONS
+// ============================================================================
+
+// BLSAttestationSubmission is a validator's BLS attestation of an external
+// chain result. The caller has checked that both points decode and lie in the
+// prime-order subgroup.
+type BLSAttestationSubmission struct {
+	ResultID    uuid.UUID
+	ValidatorID string
+	PublicKey   []byte // Compressed G2 point; must match the validator set snapshot
+	MessageHash []byte // Must equal the result hash
+	Signature   []byte // Compressed G1 point
+	AttestedAt  time.Time
+}
+
+// SubmittedBLSAttestation is the outcome of a BLS attestation submission
+type SubmittedBLSAttestation struct {
+	Attestation *BLSAttestationRecord
+	Entry       *ResultConsensusEntry
+	Aggregate   *AggregatedAttestationRecord // Set when this attestation reached the threshold
+}
+
+const resultConsensusColumns = `
+		entry_id, result_id, snapshot_id, merkle_root, state, attestation_count,
+		required_count, quorum_fraction, total_weight, threshold_weight, achieved_weight,
+		aggregate_signature, aggregate_pubkey, aggregation_id, start_time, last_update,
+		deadline, completed_at, result_json, created_at`
+
+// timeoutEntriesQuery moves open entries whose deadline has passed to timeout
+const timeoutEntriesQuery = `
+		UPDATE consensus_entries
+		SET state = 'timeout', last_update = $1, completed_at = $1,
+			result_json = jsonb_build_object(
+				'reason', 'deadline exceeded',
+				'achieved_weight', achieved_weight,
+				'threshold_weight', threshold_weight)
+		WHERE state IN ('initiated', 'collecting') AND deadline IS NOT NULL AND deadline <= $1`
+
+func scanResultConsensusEntry(row *sql.Row) (*ResultConsensusEntry, error) {
+	e := &ResultConsensusEntry{}
+	var resultJSON []byte
+	err := row.Scan(
+		&e.EntryID, &e.ResultID, &e.SnapshotID, &e.MessageHash, &e.State, &e.AttestationCount,
+		&e.RequiredCount, &e.QuorumFraction, &e.TotalWeight, &e.ThresholdWeight, &e.AchievedWeight,
+		&e.AggregateSignature, &e.AggregatePubkey, &e.AggregationID, &e.StartTime, &e.LastUpdate,
+		&e.Deadline, &e.CompletedAt, &resultJSON, &e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	e.ResultJSON = resultJSON
+	return e, nil
+}
+
+// GetResultConsensusEntry returns the consensus entry for an external chain result
+func (r *ConsensusRepository) GetResultConsensusEntry(ctx context.Context, resultID uuid.UUID) (*ResultConsensusEntry, error) {
+	e, err := scanResultConsensusEntry(r.client.QueryRowContext(ctx,
+		`SELECT`+resultConsensusColumns+` FROM consensus_entries WHERE result_id = $1`, resultID))
+	if err == sql.ErrNoRows {
+		return nil, ErrConsensusEntryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get result consensus entry: %w", err)
+	}
+	return e, nil
+}
+
+// SubmitBLSAttestation records a validator's BLS attestation of an external
+// chain result and advances the result's consensus entry.
+//
+// The first attestation for a result opens an entry bound to the latest
+// validator set snapshot for the result's chain, with a deadline timeout from
+// now. Each attestation adds the validator's snapshot weight. When the
+// achieved weight reaches the snapshot's threshold, the collected signatures
+// and public keys are aggregated, the aggregate is verified and saved, and the
+// entry moves to quorum_met.
+//
+// Each signature is pairing-verified against the validator's snapshot key and
+// the result hash before it is stored or counted; one that does not verify is
+// rejected with ErrInvalidBLSSignature.
+//
+// Attestations for an entry that reached quorum or timed out are rejected
+// with ErrConsensusClosed. A repeated attestation returns the existing one
+// with ErrDuplicateAttestation, or ErrConflictingAttestation if the
+// signature differs.
+func (r *ConsensusRepository) SubmitBLSAttestation(ctx context.Context, sub *BLSAttestationSubmission, timeout time.Duration) (result *SubmittedBLSAttestation, err error) {
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+	db := tx.Tx()
+	proofs := r.proofs.WithTx(tx)
+
+	if _, err = db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "bls-consensus:"+sub.ResultID.String()); err != nil {
+		return nil, fmt.Errorf("failed to lock consensus entry: %w", err)
+	}
+
+	chainResult, err := proofs.GetExternalChainResultByID(ctx, sub.ResultID)
+	if err != nil {
+		return nil, err
+	}
+	if chainResult == nil {
+		return nil, ErrExternalResultNotFound
+	}
+	if !bytes.Equal(chainResult.ResultHash, sub.MessageHash) {
+		return nil, fmt.Errorf("%w: message_hash differs from result %s", ErrAttestationMismatch, sub.ResultID)
+	}
+
+	now := time.Now().UTC()
+	entry, err := scanResultConsensusEntry(db.QueryRowContext(ctx,
+		`SELECT`+resultConsensusColumns+` FROM consensus_entries WHERE result_id = $1`, sub.ResultID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get result consensus entry: %w", err)
+	}
+
+	var snapshot *ValidatorSetSnapshotRecord
+	if entry == nil {
+		snapshot, err = proofs.GetLatestValidatorSetSnapshot(ctx, chainResult.ChainID)
+	} else {
+		snapshot, err = proofs.GetValidatorSetSnapshotByID(ctx, entry.SnapshotID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, ErrValidatorSetNotFound
+	}
+	var validators []ValidatorEntry
+	if err = json.Unmarshal(snapshot.ValidatorsJSON, &validators); err != nil {
+		return nil, fmt.Errorf("failed to decode validator set %s: %w", snapshot.SnapshotID, err)
+	}
+
+	if entry == nil {
+		entry, err = r.createResultEntry(ctx, db, chainResult, snapshot, validators, now, timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if entry.IsOpen() && !now.Before(entry.Deadline) {
+		entry, err = scanResultConsensusEntry(db.QueryRowContext(ctx,
+			timeoutEntriesQuery+` AND entry_id = $2 RETURNING`+resultConsensusColumns, now, entry.EntryID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to time out consensus entry: %w", err)
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit consensus timeout: %w", err)
+		}
+		committed = true
+		return &SubmittedBLSAttestation{Entry: entry}, ErrConsensusClosed
+	}
+	if !entry.IsOpen() {
+		return &SubmittedBLSAttestation{Entry: entry}, ErrConsensusClosed
+	}
+
+	var validator *ValidatorEntry
+	for i := range validators {
+		if validators[i].ValidatorID == sub.ValidatorID {
+			validator = &validators[i]
+			break
+		}
+	}
+	if validator == nil {
+		return nil, ErrValidatorNotInSet
+	}
+	if !bytes.Equal(validator.PublicKey, sub.PublicKey) {
+		return nil, ErrValidatorKeyNotRegistered
+	}
+	if err = bls.Verify(validator.PublicKey, chainResult.ResultHash, sub.Signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBLSSignature, err)
+	}
+
+	// One attestation per validator and result
+	existing := &BLSAttestationRecord{}
+	err = db.QueryRowContext(ctx, `
+		SELECT attestation_id, result_id, snapshot_id, validator_id, public_key,
+			   message_hash, signature, weight, subgroup_valid,
+			   signature_valid, verified_at, attested_at, created_at
+		FROM bls_attestations
+		WHERE result_id = $1 AND validator_id = $2`, sub.ResultID, sub.ValidatorID).Scan(
+		&existing.AttestationID, &existing.ResultID, &existing.SnapshotID, &existing.ValidatorID, &existing.PublicKey,
+		&existing.MessageHash, &existing.Signature, &existing.Weight, &existing.SubgroupValid,
+		&existing.SignatureValid, &existing.VerifiedAt, &existing.AttestedAt, &existing.CreatedAt,
+	)
+	switch {
+	case err == nil:
+		err = ErrConflictingAttestation
+		if bytes.Equal(existing.Signature, sub.Signature) {
+			err = ErrDuplicateAttestation
+		}
+		return &SubmittedBLSAttestation{Attestation: existing, Entry: entry}, err
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to check existing BLS attestation: %w", err)
+	}
+
+	result = &SubmittedBLSAttestation{}
+	result.Attestation, err = proofs.SaveBLSAttestation(ctx, &NewBLSAttestation{
+		ResultID:       sub.ResultID,
+		SnapshotID:     entry.SnapshotID,
+		ValidatorID:    sub.ValidatorID,
+		PublicKey:      sub.PublicKey,
+		MessageHash:    sub.MessageHash,
+		Signature:      sub.Signature,
+		Weight:         validator.Weight,
+		SubgroupValid:  true,
+		SignatureValid: true,
+		VerifiedAt:     &now,
+		AttestedAt:     sub.AttestedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entry.State = ConsensusStateCollecting
+	entry.AttestationCount++
+	entry.AchievedWeight += validator.Weight
+	entry.LastUpdate = now
+	if entry.AchievedWeight >= entry.ThresholdWeight {
+		result.Aggregate, err = r.aggregateResult(ctx, proofs, entry, now)
+		if err != nil {
+			return nil, err
+		}
+		entry.State = ConsensusStateQuorumMet
+		entry.AggregateSignature = result.Aggregate.AggregatedSignature
+		entry.AggregatePubkey = result.Aggregate.AggregatedPublicKey
+		entry.AggregationID = &result.Aggregate.AggregationID
+		entry.CompletedAt = &now
+	}
+
+	_, err = db.ExecContext(ctx, `
+		UPDATE consensus_entries
+		SET state = $2, attestation_count = $3, achieved_weight = $4,
+			aggregate_signature = $5, aggregate_pubkey = $6, aggregation_id = $7,
+			last_update = $8, completed_at = $9
+		WHERE entry_id = $1`,
+		entry.EntryID, entry.State, entry.AttestationCount, entry.AchievedWeight,
+		entry.AggregateSignature, entry.AggregatePubkey, entry.AggregationID,
+		entry.LastUpdate, entry.CompletedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update consensus entry: %w", err)
+	}
+	result.Entry = entry
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit BLS attestation: %w", err)
+	}
+	committed = true
+	return result, nil
+}
+
+// createResultEntry opens the consensus entry for a result
+func (r *ConsensusRepository) createResultEntry(
+	ctx context.Context,
+	db *sql.Tx,
+	chainResult *ExternalChainResultRecord,
+	snapshot *ValidatorSetSnapshotRecord,
+	validators []ValidatorEntry,
+	now time.Time,
+	timeout time.Duration,
+) (*ResultConsensusEntry, error) {
+	var fraction float64
+	if snapshot.TotalWeight > 0 {
+		fraction = float64(snapshot.ThresholdWeight) / float64(snapshot.TotalWeight)
+	}
+
+	e, err := scanResultConsensusEntry(db.QueryRowContext(ctx, `
+		INSERT INTO consensus_entries (
+			result_id, snapshot_id, merkle_root, tx_count, state,
+			required_count, quorum_fraction, total_weight, threshold_weight,
+			start_time, last_update, deadline
+		) VALUES (
+			$1, $2, $3, 0, 'initiated', $4, $5, $6, $7, $8, $8, $9
+		)
+		RETURNING`+resultConsensusColumns,
+		chainResult.ResultID, snapshot.SnapshotID, chainResult.ResultHash,
+		RequiredSignerCount(validators, snapshot.ThresholdWeight), fraction,
+		snapshot.TotalWeight, snapshot.ThresholdWeight,
+		now, now.Add(timeout),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create result consensus entry: %w", err)
+	}
+	return e, nil
+}
+
+// aggregateResult aggregates every attestation collected for the entry's
+// result, verifies the aggregate against the aggregate key and saves it
+func (r *ConsensusRepository) aggregateResult(ctx context.Context, proofs *ProofArtifactRepository, entry *ResultConsensusEntry, now time.Time) (*AggregatedAttestationRecord, error) {
+	attestations, err := proofs.GetBLSAttestationsByResult(ctx, entry.ResultID)
+	if err != nil {
+		return nil, err
+	}
+
+	sigs := make([][]byte, len(attestations))
+	pubs := make([][]byte, len(attestations))
+	ids := make([]string, len(attestations))
+	var achieved int64
+	consistent := true
+	for i, att := range attestations {
+		sigs[i], pubs[i], ids[i] = att.Signature, att.PublicKey, att.ValidatorID
+		achieved += att.Weight
+		consistent = consistent && bytes.Equal(att.MessageHash, entry.MessageHash)
+	}
+
+	aggSig, err := bls.AggregateSignatures(sigs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate signatures: %w", err)
+	}
+	aggPub, err := bls.AggregatePublicKeys(pubs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate public keys: %w", err)
+	}
+	if err := bls.Verify(aggPub, entry.MessageHash, aggSig); err != nil {
+		return nil, fmt.Errorf("aggregate signature for result %s does not verify: %w", entry.ResultID, err)
+	}
+	participantIDs, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal participant IDs: %w", err)
+	}
+
+	return proofs.SaveAggregatedAttestation(ctx, &NewAggregatedAttestation{
+		ResultID:                entry.ResultID,
+		SnapshotID:              entry.SnapshotID,
+		MessageHash:             entry.MessageHash,
+		AggregatedSignature:     aggSig,
+		AggregatedPublicKey:     aggPub,
+		ParticipantIDs:          participantIDs,
+		ParticipantCount:        len(attestations),
+		TotalWeight:             entry.TotalWeight,
+		ThresholdWeight:         entry.ThresholdWeight,
+		AchievedWeight:          achieved,
+		ThresholdMet:            achieved >= entry.ThresholdWeight,
+		MessageConsistencyValid: consistent,
+		AggregationValid:        true,
+		VerifiedAt:              &now,
+		AggregatedAt:            now,
+	})
+}
+
+// TimeoutExpiredEntries moves open result consensus entries whose deadline
+// has passed to the timeout state and returns how many were changed
+func (r *ConsensusRepository) TimeoutExpiredEntries(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.client.ExecContext(ctx, timeoutEntriesQuery, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to time out consensus entries: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// RequiredSignerCount returns the fewest validators whose combined weight
+// reaches threshold, or the whole set if even all of them fall short
+func RequiredSignerCount(validators []ValidatorEntry, threshold int64) int {
+	weights := make([]int64, len(validators))
+	for i, v := range validators {
+		weights[i] = v.Weight
+	}
+	sort.Slice(weights, func(i, j int) bool { return weights[i] > weights[j] })
+
+	var sum int64
+	for i, w := range weights {
+		sum += w
+		if sum >= threshold {
+			return i + 1
+		}
+	}
+	return len(weights)
+}
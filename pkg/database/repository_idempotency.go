@@ -0,0 +1,179 @@
+// Copyright 2025 Certen Protocol
+//
+// Idempotency Repository - Claimed Idempotency-Key values and their stored responses
+//
+// A key is claimed with an insert that only succeeds when the key is new or
+// its previous claim has expired, so two concurrent requests with the same
+// key cannot both run. An in-progress claim expires after a short lease that
+// its request renews; a completed key expires after the replay TTL.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IdempotencyRepository handles idempotency key operations
+type IdempotencyRepository struct {
+	client *Client
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(client *Client) *IdempotencyRepository {
+	return &IdempotencyRepository{client: client}
+}
+
+// IdempotencyRecord is a claimed key and, once the request completed, its response
+type IdempotencyRecord struct {
+	Scope           string          `json:"scope"`
+	Key             string          `json:"idempotency_key"`
+	Method          string          `json:"method"`
+	Path            string          `json:"path"`
+	Fingerprint     []byte          `json:"fingerprint"`
+	ResponseStatus  *int            `json:"response_status,omitempty"`
+	ResponseHeaders json.RawMessage `json:"response_headers,omitempty"`
+	ResponseBody    []byte          `json:"response_body,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+}
+
+// ============================================================================
+// IDEMPOTENCY KEY OPERATIONS
+// ============================================================================
+
+// ClaimKey claims a key for a request until expiresAt, the end of its lease.
+// If the key is already claimed and has not expired, the existing record is
+// returned with claimed set to false.
+func (r *IdempotencyRepository) ClaimKey(ctx context.Context, scope, key, method, path string, fingerprint []byte, now, expiresAt time.Time) (*IdempotencyRecord, bool, error) {
+	claim := `
+		INSERT INTO idempotency_keys (scope, idempotency_key, method, path, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+			method = EXCLUDED.method,
+			path = EXCLUDED.path,
+			fingerprint = EXCLUDED.fingerprint,
+			response_status = NULL,
+			response_headers = NULL,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			completed_at = NULL,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING created_at`
+
+	// The existing claim can expire or be released between the two
+	// statements; one retry picks that up
+	for attempt := 0; attempt < 2; attempt++ {
+		rec := &IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			Method:      method,
+			Path:        path,
+			Fingerprint: fingerprint,
+			ExpiresAt:   expiresAt,
+		}
+		err := r.client.QueryRowContext(ctx, claim, scope, key, method, path, fingerprint, now, expiresAt).Scan(&rec.CreatedAt)
+		if err == nil {
+			return rec, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+
+		existing, err := r.GetKey(ctx, scope, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing != nil {
+			return existing, false, nil
+		}
+	}
+
+	return nil, false, fmt.Errorf("failed to claim idempotency key: claim changed concurrently")
+}
+
+// GetKey retrieves a claimed key. Returns nil, nil if not found.
+func (r *IdempotencyRepository) GetKey(ctx context.Context, scope, key string) (*IdempotencyRecord, error) {
+	query := `
+		SELECT scope, idempotency_key, method, path, fingerprint,
+			   response_status, response_headers, response_body,
+			   created_at, completed_at, expires_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2`
+
+	rec := &IdempotencyRecord{}
+	var status sql.NullInt64
+	var headers []byte
+	err := r.client.QueryRowContext(ctx, query, scope, key).Scan(
+		&rec.Scope, &rec.Key, &rec.Method, &rec.Path, &rec.Fingerprint,
+		&status, &headers, &rec.ResponseBody,
+		&rec.CreatedAt, &rec.CompletedAt, &rec.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if status.Valid {
+		s := int(status.Int64)
+		rec.ResponseStatus = &s
+	}
+	if headers != nil {
+		rec.ResponseHeaders = headers
+	}
+
+	return rec, nil
+}
+
+// ExtendKey renews the lease of an in-progress claim until expiresAt
+func (r *IdempotencyRepository) ExtendKey(ctx context.Context, scope, key string, expiresAt time.Time) error {
+	query := `
+		UPDATE idempotency_keys SET expires_at = $3
+		WHERE scope = $1 AND idempotency_key = $2 AND response_status IS NULL`
+
+	if _, err := r.client.ExecContext(ctx, query, scope, key, expiresAt); err != nil {
+		return fmt.Errorf("failed to extend idempotency key: %w", err)
+	}
+	return nil
+}
+
+// CompleteKey stores the response of the request that claimed a key and
+// keeps it for replay until expiresAt
+func (r *IdempotencyRepository) CompleteKey(ctx context.Context, scope, key string, status int, headers json.RawMessage, body []byte, expiresAt time.Time) error {
+	query := `
+		UPDATE idempotency_keys
+		SET response_status = $3, response_headers = $4, response_body = $5, completed_at = NOW(), expires_at = $6
+		WHERE scope = $1 AND idempotency_key = $2 AND response_status IS NULL`
+
+	if _, err := r.client.ExecContext(ctx, query, scope, key, status, []byte(headers), body, expiresAt); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// ReleaseKey drops an in-progress claim so the key can be retried
+func (r *IdempotencyRepository) ReleaseKey(ctx context.Context, scope, key string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND response_status IS NULL`
+
+	if _, err := r.client.ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredKeys deletes keys that expired before now and returns how many
+func (r *IdempotencyRepository) PurgeExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.client.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
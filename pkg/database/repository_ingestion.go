@@ -0,0 +1,857 @@
+// Copyright 2025 Certen Protocol
+//
+// Ingestion Repository - Atomic writes of validator-submitted proofs and attestations
+//
+// A submitted proof (artifact, chained layers, governance levels and anchor
+// reference) is written in one transaction together with its first custody
+// event, so a proof is never visible half-written. Submissions are deduped on
+// accum_tx_hash under a transaction-scoped advisory lock, which keeps two
+// validators submitting the same transaction from both inserting it.
+//
+// Attestations are written the same way: under a lock on the attested proof
+// or batch, together with the quorum update and their custody events.
+//
+// Proofs restored from a bulk export keep their original IDs and are written
+// under the same transaction lock; an import never modifies a stored record.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IngestionRepository writes validator-submitted proofs
+type IngestionRepository struct {
+	client *Client
+	proofs *ProofArtifactRepository
+}
+
+// NewIngestionRepository creates a new ingestion repository
+func NewIngestionRepository(client *Client, proofs *ProofArtifactRepository) *IngestionRepository {
+	return &IngestionRepository{client: client, proofs: proofs}
+}
+
+// ProofSubmission is a complete proof submitted by a validator
+type ProofSubmission struct {
+	Artifact         NewProofArtifact          `json:"artifact"`
+	Layers           []NewChainedProofLayer    `json:"layers,omitempty"`
+	GovernanceLevels []NewGovernanceProofLevel `json:"governance_levels,omitempty"`
+	AnchorReference  *NewAnchorReference       `json:"anchor_reference,omitempty"`
+}
+
+// IngestedProof is the stored result of a proof submission
+type IngestedProof struct {
+	Proof            *ProofArtifact          `json:"proof"`
+	Layers           []*ChainedProofLayer    `json:"layers,omitempty"`
+	GovernanceLevels []*GovernanceProofLevel `json:"governance_levels,omitempty"`
+	AnchorReference  *AnchorReferenceRecord  `json:"anchor_reference,omitempty"`
+	CustodyEvent     *CustodyChainEvent      `json:"custody_event,omitempty"`
+}
+
+// IngestProof writes a submitted proof atomically. If a proof already exists
+// for the transaction it is returned unchanged with created set to false.
+func (r *IngestionRepository) IngestProof(ctx context.Context, sub *ProofSubmission) (result *IngestedProof, created bool, err error) {
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Tx().ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, sub.Artifact.AccumTxHash); err != nil {
+		return nil, false, fmt.Errorf("failed to lock transaction hash: %w", err)
+	}
+
+	proofs := r.proofs.WithTx(tx)
+
+	existing, err := proofs.GetProofByTxHash(ctx, sub.Artifact.AccumTxHash)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		if err = tx.Rollback(); err != nil {
+			return nil, false, fmt.Errorf("failed to release transaction: %w", err)
+		}
+		return &IngestedProof{Proof: existing}, false, nil
+	}
+
+	result = &IngestedProof{}
+	if result.Proof, err = proofs.CreateProofArtifact(ctx, &sub.Artifact); err != nil {
+		return nil, false, err
+	}
+	proofID := result.Proof.ProofID
+
+	for i := range sub.Layers {
+		layer := sub.Layers[i]
+		layer.ProofID = proofID
+		rec, err := proofs.CreateChainedProofLayer(ctx, &layer)
+		if err != nil {
+			return nil, false, err
+		}
+		result.Layers = append(result.Layers, rec)
+	}
+
+	for i := range sub.GovernanceLevels {
+		level := sub.GovernanceLevels[i]
+		level.ProofID = proofID
+		rec, err := proofs.CreateGovernanceProofLevel(ctx, &level)
+		if err != nil {
+			return nil, false, err
+		}
+		result.GovernanceLevels = append(result.GovernanceLevels, rec)
+	}
+
+	if sub.AnchorReference != nil {
+		ref := *sub.AnchorReference
+		ref.ProofID = proofID
+		if result.AnchorReference, err = proofs.CreateAnchorReference(ctx, &ref); err != nil {
+			return nil, false, err
+		}
+	}
+
+	details, err := json.Marshal(map[string]interface{}{
+		"accum_tx_hash":     sub.Artifact.AccumTxHash,
+		"artifact_hash":     fmt.Sprintf("%x", result.Proof.ArtifactHash),
+		"layer_count":       len(result.Layers),
+		"governance_levels": len(result.GovernanceLevels),
+		"anchored":          result.AnchorReference != nil,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode custody event details: %w", err)
+	}
+	validatorID := sub.Artifact.ValidatorID
+	if result.CustodyEvent, err = proofs.AppendCustodyEvent(ctx, proofID, "created", "validator", &validatorID, details); err != nil {
+		return nil, false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit proof ingestion: %w", err)
+	}
+
+	return result, true, nil
+}
+
+// ============================================================================
+// VALIDATOR KEY OPERATIONS
+// ============================================================================
+
+// ValidatorKey is an Ed25519 key registered to a validator for attestations
+type ValidatorKey struct {
+	KeyID        uuid.UUID  `json:"key_id"`
+	ValidatorID  string     `json:"validator_id"`
+	PublicKey    []byte     `json:"public_key"` // Ed25519 32 bytes
+	IsActive     bool       `json:"is_active"`
+	Description  *string    `json:"description,omitempty"`
+	RegisteredAt time.Time  `json:"registered_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+// RegisterValidatorKey registers an attestation key for a validator and
+// records it in the admin audit log. Registering a key that is already
+// registered reactivates it.
+func (r *IngestionRepository) RegisterValidatorKey(ctx context.Context, validatorID string, publicKey []byte, description *string, actor *APIKeyActor) (key *ValidatorKey, err error) {
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	db := tx.Tx()
+
+	query := `
+		INSERT INTO validator_keys (validator_id, public_key, description)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (validator_id, public_key) DO UPDATE
+		SET is_active = TRUE, revoked_at = NULL, description = EXCLUDED.description
+		RETURNING key_id, is_active, registered_at, revoked_at`
+
+	key = &ValidatorKey{
+		ValidatorID: validatorID,
+		PublicKey:   publicKey,
+		Description: description,
+	}
+	err = db.QueryRowContext(ctx, query, validatorID, publicKey, description).Scan(
+		&key.KeyID, &key.IsActive, &key.RegisteredAt, &key.RevokedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register validator key: %w", err)
+	}
+
+	if err = writeValidatorKeyAudit(ctx, db, key, ValidatorKeyActionRegister, actor); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit validator key registration: %w", err)
+	}
+
+	return key, nil
+}
+
+// RevokeValidatorKey revokes an attestation key and records it in the admin
+// audit log. Attestations it signed are kept; new ones are rejected.
+// Returns ErrValidatorKeyNotFound for an unknown key and
+// ErrValidatorKeyRevoked if it is already revoked.
+func (r *IngestionRepository) RevokeValidatorKey(ctx context.Context, keyID uuid.UUID, actor *APIKeyActor) (key *ValidatorKey, err error) {
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	db := tx.Tx()
+
+	key, err = scanValidatorKey(db.QueryRowContext(ctx,
+		`SELECT `+validatorKeyColumns+` FROM validator_keys WHERE key_id = $1 FOR UPDATE`, keyID))
+	if err == sql.ErrNoRows {
+		return nil, ErrValidatorKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock validator key: %w", err)
+	}
+	if !key.IsActive || key.RevokedAt != nil {
+		return nil, ErrValidatorKeyRevoked
+	}
+
+	err = db.QueryRowContext(ctx, `
+		UPDATE validator_keys SET is_active = FALSE, revoked_at = NOW()
+		WHERE key_id = $1
+		RETURNING is_active, revoked_at`, keyID).Scan(&key.IsActive, &key.RevokedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke validator key: %w", err)
+	}
+
+	if err = writeValidatorKeyAudit(ctx, db, key, ValidatorKeyActionRevoke, actor); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit validator key revocation: %w", err)
+	}
+
+	return key, nil
+}
+
+const validatorKeyColumns = `key_id, validator_id, public_key, is_active, description, registered_at, revoked_at`
+
+func scanValidatorKey(row interface{ Scan(...interface{}) error }) (*ValidatorKey, error) {
+	k := &ValidatorKey{}
+	if err := row.Scan(
+		&k.KeyID, &k.ValidatorID, &k.PublicKey, &k.IsActive, &k.Description, &k.RegisteredAt, &k.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// GetValidatorKey returns a validator key by ID, or nil if it does not exist
+func (r *IngestionRepository) GetValidatorKey(ctx context.Context, keyID uuid.UUID) (*ValidatorKey, error) {
+	key, err := scanValidatorKey(r.client.QueryRowContext(ctx,
+		`SELECT `+validatorKeyColumns+` FROM validator_keys WHERE key_id = $1`, keyID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validator key: %w", err)
+	}
+	return key, nil
+}
+
+// ListValidatorKeys returns registered validator keys, optionally only those
+// of one validator. Revoked keys are included only with includeRevoked.
+func (r *IngestionRepository) ListValidatorKeys(ctx context.Context, validatorID string, includeRevoked bool) ([]*ValidatorKey, error) {
+	query := `
+		SELECT ` + validatorKeyColumns + `
+		FROM validator_keys
+		WHERE ($1 = '' OR validator_id = $1)
+			AND ($2 OR (is_active = TRUE AND revoked_at IS NULL))
+		ORDER BY validator_id, registered_at`
+
+	rows, err := r.client.QueryContext(ctx, query, validatorID, includeRevoked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validator keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*ValidatorKey
+	for rows.Next() {
+		k, err := scanValidatorKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan validator key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetActiveValidatorKeys returns a validator's active attestation keys
+func (r *IngestionRepository) GetActiveValidatorKeys(ctx context.Context, validatorID string) ([]*ValidatorKey, error) {
+	query := `
+		SELECT ` + validatorKeyColumns + `
+		FROM validator_keys
+		WHERE validator_id = $1 AND is_active = TRUE AND revoked_at IS NULL
+		ORDER BY registered_at`
+
+	rows, err := r.client.QueryContext(ctx, query, validatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query validator keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*ValidatorKey
+	for rows.Next() {
+		k, err := scanValidatorKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan validator key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// ============================================================================
+// ATTESTATION SUBMISSION
+// ============================================================================
+
+// AttestationSubmission is a signed attestation for a proof or a batch.
+// Exactly one of ProofID and BatchID is set.
+type AttestationSubmission struct {
+	ProofID         *uuid.UUID
+	BatchID         *uuid.UUID
+	ValidatorID     string
+	ValidatorPubkey []byte // Ed25519 32 bytes
+	MerkleRoot      []byte
+	AnchorTxHash    *string
+	BlockNumber     *int64
+	Signature       []byte // Ed25519 64 bytes over ComputeAttestedHash
+	AttestedAt      time.Time
+}
+
+// SubmittedAttestation is the stored attestation and the quorum it leaves
+type SubmittedAttestation struct {
+	Attestation          *ProofAttestation `json:"attestation"`
+	ValidAttestations    int               `json:"valid_attestations"`
+	RegisteredValidators int               `json:"registered_validators"`
+	RequiredQuorum       int               `json:"required_quorum"`
+	QuorumMet            bool              `json:"quorum_met"`
+	CustodyEvents        int               `json:"custody_events"`
+}
+
+// ComputeAttestedHash returns the hash a validator signs when attesting:
+// SHA256(merkle_root || anchor_tx_hash). anchor_tx_hash is empty when the
+// attestation does not cover an anchor.
+func ComputeAttestedHash(merkleRoot []byte, anchorTxHash string) []byte {
+	h := sha256.New()
+	h.Write(merkleRoot)
+	h.Write([]byte(anchorTxHash))
+	return h.Sum(nil)
+}
+
+// SubmitAttestation stores a validator attestation whose signature the caller
+// has verified, updates quorum for the attested proof or batch, and appends an
+// attested custody event to every proof it covers. quorum returns the number
+// of valid attestations required out of the registered validators.
+//
+// A validator attests to a proof or batch once. If it already has, the
+// existing attestation is returned with ErrDuplicateAttestation when it
+// covers the same hash, or ErrConflictingAttestation when it does not.
+func (r *IngestionRepository) SubmitAttestation(ctx context.Context, sub *AttestationSubmission, quorum func(total int) int) (result *SubmittedAttestation, err error) {
+	var column string
+	var targetID uuid.UUID
+	switch {
+	case sub.ProofID != nil && sub.BatchID == nil:
+		column, targetID = "proof_id", *sub.ProofID
+	case sub.BatchID != nil && sub.ProofID == nil:
+		column, targetID = "batch_id", *sub.BatchID
+	default:
+		return nil, fmt.Errorf("attestation must reference exactly one of a proof or a batch")
+	}
+
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	db := tx.Tx()
+
+	if _, err = db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "attestation:"+targetID.String()); err != nil {
+		return nil, fmt.Errorf("failed to lock attestation target: %w", err)
+	}
+
+	var registered bool
+	err = db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM validator_keys
+			WHERE validator_id = $1 AND public_key = $2 AND is_active = TRUE AND revoked_at IS NULL
+		)`, sub.ValidatorID, sub.ValidatorPubkey).Scan(&registered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check validator key: %w", err)
+	}
+	if !registered {
+		return nil, ErrValidatorKeyNotRegistered
+	}
+
+	// The attestation must cover what we hold for the proof or batch
+	var storedRoot []byte
+	var storedAnchor sql.NullString
+	if sub.ProofID != nil {
+		err = db.QueryRowContext(ctx, `SELECT merkle_root, anchor_tx_hash FROM proof_artifacts WHERE proof_id = $1`,
+			targetID).Scan(&storedRoot, &storedAnchor)
+		if err == sql.ErrNoRows {
+			return nil, ErrProofNotFound
+		}
+	} else {
+		err = db.QueryRowContext(ctx, `SELECT merkle_root FROM anchor_batches WHERE batch_id = $1`,
+			targetID).Scan(&storedRoot)
+		if err == sql.ErrNoRows {
+			return nil, ErrBatchNotFound
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attestation target: %w", err)
+	}
+	if len(storedRoot) == 0 || !bytes.Equal(storedRoot, sub.MerkleRoot) {
+		return nil, fmt.Errorf("%w: merkle_root differs from %s %s", ErrAttestationMismatch, column, targetID)
+	}
+	anchorTxHash := ""
+	if sub.AnchorTxHash != nil {
+		anchorTxHash = *sub.AnchorTxHash
+		if storedAnchor.Valid && storedAnchor.String != anchorTxHash {
+			return nil, fmt.Errorf("%w: anchor_tx_hash differs from %s %s", ErrAttestationMismatch, column, targetID)
+		}
+	}
+	attestedHash := ComputeAttestedHash(sub.MerkleRoot, anchorTxHash)
+
+	// One attestation per validator and proof or batch
+	existing := &ProofAttestation{}
+	err = db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT attestation_id, proof_id, batch_id, validator_id, validator_pubkey,
+			   attested_hash, signature, anchor_tx_hash, merkle_root, block_number,
+			   COALESCE(signature_valid, FALSE), verified_at, attested_at, created_at
+		FROM validator_attestations
+		WHERE %s = $1 AND validator_id = $2`, column), targetID, sub.ValidatorID).Scan(
+		&existing.AttestationID, &existing.ProofArtifactID, &existing.BatchID, &existing.ValidatorID, &existing.ValidatorPubkey,
+		&existing.AttestedHash, &existing.Signature, &existing.AnchorTxHash, &existing.MerkleRoot, &existing.BlockNumber,
+		&existing.SignatureValid, &existing.VerifiedAt, &existing.AttestedAt, &existing.CreatedAt,
+	)
+	switch {
+	case err == nil:
+		err = ErrConflictingAttestation
+		if bytes.Equal(existing.AttestedHash, attestedHash) {
+			err = ErrDuplicateAttestation
+		}
+		return &SubmittedAttestation{Attestation: existing}, err
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to check existing attestation: %w", err)
+	}
+
+	proofs := r.proofs.WithTx(tx)
+	result = &SubmittedAttestation{}
+	result.Attestation, err = proofs.CreateProofAttestation(ctx, &NewProofAttestation{
+		ProofArtifactID: sub.ProofID,
+		BatchID:         sub.BatchID,
+		ValidatorID:     sub.ValidatorID,
+		ValidatorPubkey: sub.ValidatorPubkey,
+		AttestedHash:    attestedHash,
+		Signature:       sub.Signature,
+		AnchorTxHash:    sub.AnchorTxHash,
+		MerkleRoot:      sub.MerkleRoot,
+		BlockNumber:     sub.BlockNumber,
+		AttestedAt:      sub.AttestedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.QueryRowContext(ctx, `
+		UPDATE validator_attestations SET signature_valid = TRUE, verified_at = NOW()
+		WHERE attestation_id = $1
+		RETURNING verified_at`, result.Attestation.AttestationID).Scan(&result.Attestation.VerifiedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark attestation verified: %w", err)
+	}
+	result.Attestation.SignatureValid = true
+
+	// Quorum over the validators with a registered key
+	err = db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT validator_id) FROM validator_keys
+		WHERE is_active = TRUE AND revoked_at IS NULL`).Scan(&result.RegisteredValidators)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count registered validators: %w", err)
+	}
+	err = db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*) FROM validator_attestations
+		WHERE %s = $1 AND signature_valid = TRUE`, column), targetID).Scan(&result.ValidAttestations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count valid attestations: %w", err)
+	}
+	result.RequiredQuorum = quorum(result.RegisteredValidators)
+	result.QuorumMet = result.ValidAttestations >= result.RequiredQuorum
+
+	var proofIDs []uuid.UUID
+	if sub.ProofID != nil {
+		if result.QuorumMet {
+			_, err = db.ExecContext(ctx, `
+				UPDATE proof_artifacts SET status = 'attested'
+				WHERE proof_id = $1 AND status IN ('pending', 'batched', 'anchored')`, targetID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update proof status: %w", err)
+			}
+		}
+		proofIDs = []uuid.UUID{targetID}
+	} else {
+		_, err = db.ExecContext(ctx, `
+			UPDATE anchor_batches
+			SET attestation_count = $2, quorum_reached = (COALESCE(quorum_reached, FALSE) OR $3)
+			WHERE batch_id = $1`, targetID, result.ValidAttestations, result.QuorumMet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update batch quorum: %w", err)
+		}
+		if proofIDs, err = batchProofIDs(ctx, db, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	event := map[string]interface{}{
+		"attestation_id":     result.Attestation.AttestationID,
+		"attested_hash":      hex.EncodeToString(attestedHash),
+		"valid_attestations": result.ValidAttestations,
+		"required_quorum":    result.RequiredQuorum,
+		"quorum_met":         result.QuorumMet,
+	}
+	if sub.BatchID != nil {
+		event["batch_id"] = *sub.BatchID
+	}
+	details, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode custody event details: %w", err)
+	}
+	validatorID := sub.ValidatorID
+	for _, proofID := range proofIDs {
+		if _, err = proofs.AppendCustodyEvent(ctx, proofID, "attested", "validator", &validatorID, details); err != nil {
+			return nil, err
+		}
+	}
+	result.CustodyEvents = len(proofIDs)
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit attestation: %w", err)
+	}
+
+	return result, nil
+}
+
+func batchProofIDs(ctx context.Context, db *sql.Tx, batchID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := db.QueryContext(ctx, `SELECT proof_id FROM proof_artifacts WHERE batch_id = $1 ORDER BY created_at`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch proofs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan batch proof: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ============================================================================
+// BULK IMPORT
+// ============================================================================
+
+// ProofImport is a proof and its attestations restored from a bulk export
+type ProofImport struct {
+	Proof        ProofArtifact
+	Attestations []ProofAttestation
+}
+
+// ImportProof restores an exported proof under its original ID together with
+// its attestations and a created custody event. Batch and anchor references
+// are kept only when the batch or anchor exists here. Stored records are
+// never modified: a proof already stored with the same artifact hash is kept
+// and only attestations missing from it are added. changed is false when
+// nothing was written.
+//
+// An attested or verified status in the file is not kept. The proof is
+// stored at the stage its batch and anchor references imply, and moves to
+// attested only when its attestations that verify here reach quorum, as
+// with SubmitAttestation.
+//
+// ErrImportConflict is returned when the proof ID, its transaction or one of
+// its attestations is already stored with different content.
+func (r *IngestionRepository) ImportProof(ctx context.Context, imp *ProofImport, quorum func(total int) int) (changed bool, err error) {
+	p := &imp.Proof
+
+	tx, err := r.client.BeginTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	db := tx.Tx()
+
+	// Same lock as IngestProof, so an import and a submission of the same
+	// transaction cannot both insert it
+	if _, err = db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, p.AccumTxHash); err != nil {
+		return false, fmt.Errorf("failed to lock transaction hash: %w", err)
+	}
+
+	var storedID uuid.UUID
+	var storedHash []byte
+	err = db.QueryRowContext(ctx, `
+		SELECT proof_id, artifact_hash FROM proof_artifacts
+		WHERE proof_id = $1 OR accum_tx_hash = $2
+		ORDER BY proof_id = $1 DESC
+		LIMIT 1`, p.ProofID, p.AccumTxHash).Scan(&storedID, &storedHash)
+	switch {
+	case err == sql.ErrNoRows:
+		err = nil
+		if err = insertImportedProof(ctx, db, p); err != nil {
+			return false, err
+		}
+		changed = true
+	case err != nil:
+		return false, fmt.Errorf("failed to check existing proof: %w", err)
+	case storedID != p.ProofID:
+		return false, fmt.Errorf("%w: transaction %s is stored as proof %s", ErrImportConflict, p.AccumTxHash, storedID)
+	case !bytes.Equal(storedHash, p.ArtifactHash):
+		return false, fmt.Errorf("%w: proof %s is stored with artifact hash %x", ErrImportConflict, p.ProofID, storedHash)
+	}
+
+	added := 0
+	for i := range imp.Attestations {
+		ok, err := insertImportedAttestation(ctx, db, p.ProofID, &imp.Attestations[i])
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			added++
+		}
+	}
+	if changed || added > 0 {
+		if err = markImportedProofAttested(ctx, db, p.ProofID, quorum); err != nil {
+			return false, err
+		}
+	}
+
+	if changed {
+		details, err := json.Marshal(map[string]interface{}{
+			"source":        "bulk_import",
+			"accum_tx_hash": p.AccumTxHash,
+			"artifact_hash": hex.EncodeToString(p.ArtifactHash),
+			"attestations":  added,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to encode custody event details: %w", err)
+		}
+		if _, err = r.proofs.WithTx(tx).AppendCustodyEvent(ctx, p.ProofID, "created", "system", nil, details); err != nil {
+			return false, err
+		}
+	}
+	changed = changed || added > 0
+
+	if !changed {
+		if err = tx.Rollback(); err != nil {
+			return false, fmt.Errorf("failed to release transaction: %w", err)
+		}
+		return false, nil
+	}
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit proof import: %w", err)
+	}
+
+	return true, nil
+}
+
+// importedProofStatus is the status an imported proof is stored with before
+// its attestations are counted
+func importedProofStatus(p *ProofArtifact) ProofStatus {
+	if p.Status != ProofStatusAttested && p.Status != ProofStatusVerified {
+		return p.Status
+	}
+	switch {
+	case p.AnchorTxHash != nil:
+		return ProofStatusAnchored
+	case p.BatchID != nil:
+		return ProofStatusBatched
+	}
+	return ProofStatusPending
+}
+
+// markImportedProofAttested moves an imported proof to attested when its
+// valid attestations reach quorum over the validators with a registered key
+func markImportedProofAttested(ctx context.Context, db *sql.Tx, proofID uuid.UUID, quorum func(total int) int) error {
+	var registered, valid int
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT validator_id) FROM validator_keys
+		WHERE is_active = TRUE AND revoked_at IS NULL`).Scan(&registered)
+	if err != nil {
+		return fmt.Errorf("failed to count registered validators: %w", err)
+	}
+	err = db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM validator_attestations
+		WHERE proof_id = $1 AND signature_valid = TRUE`, proofID).Scan(&valid)
+	if err != nil {
+		return fmt.Errorf("failed to count valid attestations: %w", err)
+	}
+	if valid == 0 || valid < quorum(registered) {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, `
+		UPDATE proof_artifacts SET status = 'attested'
+		WHERE proof_id = $1 AND status IN ('pending', 'batched', 'anchored')`, proofID)
+	if err != nil {
+		return fmt.Errorf("failed to update proof status: %w", err)
+	}
+	return nil
+}
+
+func insertImportedProof(ctx context.Context, db *sql.Tx, p *ProofArtifact) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO proof_artifacts (
+			proof_id, proof_type, proof_version, accum_tx_hash, account_url,
+			batch_id, batch_position, anchor_id, anchor_tx_hash, anchor_block_number, anchor_chain,
+			merkle_root, leaf_hash, leaf_index, gov_level, proof_class, validator_id,
+			status, verification_status, created_at, anchored_at, verified_at,
+			artifact_json, artifact_hash
+		) VALUES (
+			$1, $2, $3, $4, $5,
+			(SELECT batch_id FROM anchor_batches WHERE batch_id = $6), $7,
+			(SELECT anchor_id FROM anchor_records WHERE anchor_id = $8), $9, $10, $11,
+			$12, $13, $14, $15, $16, $17,
+			$18, $19, $20, $21, $22,
+			$23, $24
+		)`,
+		p.ProofID, p.ProofType, p.ProofVersion, p.AccumTxHash, p.AccountURL,
+		p.BatchID, p.BatchPosition, p.AnchorID, p.AnchorTxHash, p.AnchorBlockNumber, p.AnchorChain,
+		p.MerkleRoot, p.LeafHash, p.LeafIndex, p.GovLevel, p.ProofClass, p.ValidatorID,
+		importedProofStatus(p), p.VerificationStatus, p.CreatedAt, p.AnchoredAt, p.VerifiedAt,
+		p.ArtifactJSON, p.ArtifactHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to import proof artifact: %w", err)
+	}
+	return nil
+}
+
+// insertImportedAttestation adds an attestation to an imported proof. It
+// reports false if the validator's attestation for the proof is already
+// stored with the same attested hash.
+//
+// The file's signature_valid and verified_at are not trusted: the attestation
+// is stored as valid only when importedAttestationValid holds here.
+func insertImportedAttestation(ctx context.Context, db *sql.Tx, proofID uuid.UUID, a *ProofAttestation) (bool, error) {
+	var storedHash []byte
+	err := db.QueryRowContext(ctx, `
+		SELECT attested_hash FROM validator_attestations
+		WHERE proof_id = $1 AND validator_id = $2`, proofID, a.ValidatorID).Scan(&storedHash)
+	switch {
+	case err == nil:
+		if !bytes.Equal(storedHash, a.AttestedHash) {
+			return false, fmt.Errorf("%w: validator %s attested proof %s with hash %x",
+				ErrImportConflict, a.ValidatorID, proofID, storedHash)
+		}
+		return false, nil
+	case err != sql.ErrNoRows:
+		return false, fmt.Errorf("failed to check existing attestation: %w", err)
+	}
+
+	valid, err := importedAttestationValid(ctx, db, proofID, a)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.ExecContext(ctx, `
+		INSERT INTO validator_attestations (
+			attestation_id, proof_id, validator_id, validator_pubkey,
+			attested_hash, signature, anchor_tx_hash, merkle_root, block_number,
+			signature_valid, verified_at, attested_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CASE WHEN $10 THEN NOW() END, $11, $12)
+		ON CONFLICT (attestation_id) DO NOTHING`,
+		a.AttestationID, proofID, a.ValidatorID, a.ValidatorPubkey,
+		a.AttestedHash, a.Signature, a.AnchorTxHash, a.MerkleRoot, a.BlockNumber,
+		valid, a.AttestedAt, a.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to import attestation: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, fmt.Errorf("%w: attestation %s is stored for another proof", ErrImportConflict, a.AttestationID)
+	}
+	return true, nil
+}
+
+// importedAttestationValid reports whether an imported attestation counts
+// toward quorum, under the same rules as SubmitAttestation: it covers the
+// proof's stored Merkle root and anchor, and its signature verifies under an
+// active key registered here for the validator.
+func importedAttestationValid(ctx context.Context, db *sql.Tx, proofID uuid.UUID, a *ProofAttestation) (bool, error) {
+	if len(a.ValidatorPubkey) != ed25519.PublicKeySize || len(a.Signature) != ed25519.SignatureSize {
+		return false, nil
+	}
+
+	var storedRoot []byte
+	var storedAnchor sql.NullString
+	err := db.QueryRowContext(ctx, `SELECT merkle_root, anchor_tx_hash FROM proof_artifacts WHERE proof_id = $1`,
+		proofID).Scan(&storedRoot, &storedAnchor)
+	if err != nil {
+		return false, fmt.Errorf("failed to get imported proof: %w", err)
+	}
+	if len(storedRoot) == 0 || !bytes.Equal(storedRoot, a.MerkleRoot) {
+		return false, nil
+	}
+	anchorTxHash := ""
+	if a.AnchorTxHash != nil {
+		anchorTxHash = *a.AnchorTxHash
+		if storedAnchor.Valid && storedAnchor.String != anchorTxHash {
+			return false, nil
+		}
+	}
+	attestedHash := ComputeAttestedHash(a.MerkleRoot, anchorTxHash)
+	if !bytes.Equal(attestedHash, a.AttestedHash) ||
+		!ed25519.Verify(ed25519.PublicKey(a.ValidatorPubkey), attestedHash, a.Signature) {
+		return false, nil
+	}
+
+	var registered bool
+	err = db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM validator_keys
+			WHERE validator_id = $1 AND public_key = $2 AND is_active = TRUE AND revoked_at IS NULL
+		)`, a.ValidatorID, a.ValidatorPubkey).Scan(&registered)
+	if err != nil {
+		return false, fmt.Errorf("failed to check validator key: %w", err)
+	}
+	return registered, nil
+}
@@ -74,8 +74,9 @@ func (r *IntentLifecycleRepository) GetByTxHash(ctx context.Context, txHash stri
 	return lc, nil
 }
 
-// ListRecentEnriched returns recent lifecycle records joined with batch_transactions
-func (r *IntentLifecycleRepository) ListRecentEnriched(ctx context.Context, limit int) ([]*IntentLifecycleEnriched, error) {
+// ListRecentEnriched returns recent lifecycle records joined with
+// batch_transactions, limited to scope
+func (r *IntentLifecycleRepository) ListRecentEnriched(ctx context.Context, scope *IntentScope, limit int) ([]*IntentLifecycleEnriched, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -101,16 +102,19 @@ func (r *IntentLifecycleRepository) ListRecentEnriched(ctx context.Context, limi
 		    FROM batch_transactions bt2
 		    WHERE bt2.intent_id = il.intent_id
 		) bt_agg ON TRUE
+		WHERE ` + intentScopeClause(2) + `
 		ORDER BY il.intent_id, bt.id ASC NULLS LAST
 	`
 
 	// Wrap with outer query to apply limit and final ordering
 	wrappedQuery := fmt.Sprintf(`SELECT * FROM (%s) sub ORDER BY created_at DESC LIMIT $1`, query)
-	return r.scanEnrichedRows(ctx, wrappedQuery, limit)
+	return r.scanEnrichedRows(ctx, wrappedQuery, append([]interface{}{limit}, intentScopeArgs(scope)...)...)
 }
 
-// ListByUserEnriched returns lifecycle records for a user joined with batch_transactions
-func (r *IntentLifecycleRepository) ListByUserEnriched(ctx context.Context, userID string, limit int) ([]*IntentLifecycleEnriched, error) {
+// ListByUserEnriched returns lifecycle records for a user joined with
+// batch_transactions. A non-empty organizationADI limits them to intents of
+// that organisation.
+func (r *IntentLifecycleRepository) ListByUserEnriched(ctx context.Context, userID, organizationADI string, limit int) ([]*IntentLifecycleEnriched, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -137,15 +141,18 @@ func (r *IntentLifecycleRepository) ListByUserEnriched(ctx context.Context, user
 		    WHERE bt2.intent_id = il.intent_id
 		) bt_agg ON TRUE
 		WHERE il.user_id = $1
+		  AND ($3 = '' OR EXISTS (
+		      SELECT 1 FROM certen_intents ci
+		      WHERE ci.intent_id = il.intent_id AND ci.organization_adi = $3))
 		ORDER BY il.intent_id, bt.id ASC NULLS LAST
 	`
 
 	wrappedQuery := fmt.Sprintf(`SELECT * FROM (%s) sub ORDER BY created_at DESC LIMIT $2`, query)
-	return r.scanEnrichedRows(ctx, wrappedQuery, userID, limit)
+	return r.scanEnrichedRows(ctx, wrappedQuery, userID, limit, organizationADI)
 }
 
-// ListByStatus returns lifecycle records filtered by status
-func (r *IntentLifecycleRepository) ListByStatus(ctx context.Context, status IntentLifecycleStatus, limit int) ([]*IntentLifecycleEnriched, error) {
+// ListByStatus returns lifecycle records filtered by status, limited to scope
+func (r *IntentLifecycleRepository) ListByStatus(ctx context.Context, status IntentLifecycleStatus, scope *IntentScope, limit int) ([]*IntentLifecycleEnriched, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -172,11 +179,27 @@ func (r *IntentLifecycleRepository) ListByStatus(ctx context.Context, status Int
 		    WHERE bt2.intent_id = il.intent_id
 		) bt_agg ON TRUE
 		WHERE il.status = $1
+		  AND ` + intentScopeClause(3) + `
 		ORDER BY il.intent_id, bt.id ASC NULLS LAST
 	`
 
 	wrappedQuery := fmt.Sprintf(`SELECT * FROM (%s) sub ORDER BY created_at DESC LIMIT $2`, query)
-	return r.scanEnrichedRows(ctx, wrappedQuery, string(status), limit)
+	return r.scanEnrichedRows(ctx, wrappedQuery, append([]interface{}{string(status), limit}, intentScopeArgs(scope)...)...)
+}
+
+// intentScopeClause returns the condition on il that applies an IntentScope
+// whose intentScopeArgs start at placeholder $n
+func intentScopeClause(n int) string {
+	return fmt.Sprintf(`($%d OR il.user_id = $%d OR ($%d <> '' AND EXISTS (
+		      SELECT 1 FROM certen_intents ci
+		      WHERE ci.intent_id = il.intent_id AND ci.organization_adi = $%d)))`, n, n+1, n+2, n+2)
+}
+
+func intentScopeArgs(scope *IntentScope) []interface{} {
+	if scope == nil {
+		return []interface{}{true, "", ""}
+	}
+	return []interface{}{false, scope.UserID, scope.OrganizationADI}
 }
 
 // scanEnrichedRows scans rows from a joined lifecycle + batch_transactions query
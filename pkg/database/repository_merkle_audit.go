@@ -0,0 +1,219 @@
+// Copyright 2025 Certen Protocol
+//
+// Merkle Audit Repository - Runs and findings of the Merkle consistency audit
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerkleAuditRepository handles Merkle audit run and finding operations
+type MerkleAuditRepository struct {
+	client *Client
+}
+
+// NewMerkleAuditRepository creates a new Merkle audit repository
+func NewMerkleAuditRepository(client *Client) *MerkleAuditRepository {
+	return &MerkleAuditRepository{client: client}
+}
+
+// ============================================================================
+// AUDIT RUN OPERATIONS
+// ============================================================================
+
+// CreateRun records the start of an audit run
+func (r *MerkleAuditRepository) CreateRun(ctx context.Context) (*MerkleAuditRun, error) {
+	run := &MerkleAuditRun{
+		RunID:     uuid.New(),
+		StartedAt: time.Now(),
+		Status:    MerkleAuditRunRunning,
+	}
+
+	query := `
+		INSERT INTO merkle_audit_runs (run_id, started_at, status)
+		VALUES ($1, $2, $3)`
+
+	if _, err := r.client.ExecContext(ctx, query, run.RunID, run.StartedAt, run.Status); err != nil {
+		return nil, fmt.Errorf("failed to create audit run: %w", err)
+	}
+
+	return run, nil
+}
+
+// FinishRun records the outcome and counters of an audit run
+func (r *MerkleAuditRepository) FinishRun(ctx context.Context, run *MerkleAuditRun) error {
+	query := `
+		UPDATE merkle_audit_runs
+		SET completed_at = $2, batches_checked = $3, findings_opened = $4,
+			findings_resolved = $5, status = $6, error_message = $7
+		WHERE run_id = $1`
+
+	_, err := r.client.ExecContext(ctx, query,
+		run.RunID, run.CompletedAt, run.BatchesChecked, run.FindingsOpened,
+		run.FindingsResolved, run.Status, run.ErrorMessage,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish audit run: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestRun returns the most recently started audit run
+func (r *MerkleAuditRepository) GetLatestRun(ctx context.Context) (*MerkleAuditRun, error) {
+	query := `
+		SELECT run_id, started_at, completed_at, batches_checked, findings_opened,
+			findings_resolved, status, error_message
+		FROM merkle_audit_runs
+		ORDER BY started_at DESC
+		LIMIT 1`
+
+	run := &MerkleAuditRun{}
+	err := r.client.QueryRowContext(ctx, query).Scan(
+		&run.RunID, &run.StartedAt, &run.CompletedAt, &run.BatchesChecked, &run.FindingsOpened,
+		&run.FindingsResolved, &run.Status, &run.ErrorMessage,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrMerkleAuditRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest audit run: %w", err)
+	}
+
+	return run, nil
+}
+
+// ============================================================================
+// FINDING OPERATIONS
+// ============================================================================
+
+// RecordFinding opens a finding, or refreshes the matching open finding if the
+// discrepancy was already detected. Reports whether a new finding was opened.
+func (r *MerkleAuditRepository) RecordFinding(ctx context.Context, runID uuid.UUID, input *NewMerkleAuditFinding) (bool, error) {
+	query := `
+		INSERT INTO merkle_audit_findings (
+			batch_id, finding_type, subject, proof_id, anchor_id, tree_index,
+			expected_value, actual_value, details, first_run_id, last_run_id
+		) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10, $10)
+		ON CONFLICT (batch_id, finding_type, subject) WHERE resolved_at IS NULL
+		DO UPDATE SET
+			expected_value = EXCLUDED.expected_value,
+			actual_value = EXCLUDED.actual_value,
+			details = EXCLUDED.details,
+			last_run_id = EXCLUDED.last_run_id,
+			last_seen_at = NOW()
+		RETURNING (xmax = 0)`
+
+	var opened bool
+	err := r.client.QueryRowContext(ctx, query,
+		input.BatchID, input.FindingType, input.Subject, input.ProofID, input.AnchorID, input.TreeIndex,
+		input.ExpectedValue, input.ActualValue, input.Details, runID,
+	).Scan(&opened)
+	if err != nil {
+		return false, fmt.Errorf("failed to record audit finding: %w", err)
+	}
+
+	return opened, nil
+}
+
+// ResolveStaleFindings resolves a batch's open findings that the given run did not re-detect
+func (r *MerkleAuditRepository) ResolveStaleFindings(ctx context.Context, batchID, runID uuid.UUID) (int, error) {
+	query := `
+		UPDATE merkle_audit_findings
+		SET resolved_at = NOW()
+		WHERE batch_id = $1 AND resolved_at IS NULL AND last_run_id <> $2`
+
+	result, err := r.client.ExecContext(ctx, query, batchID, runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve audit findings: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve audit findings: %w", err)
+	}
+
+	return int(n), nil
+}
+
+// ListFindings returns findings matching the filter, newest first
+func (r *MerkleAuditRepository) ListFindings(ctx context.Context, filter *MerkleAuditFindingFilter) ([]*MerkleAuditFinding, error) {
+	if filter == nil {
+		filter = &MerkleAuditFindingFilter{}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	switch filter.Status {
+	case "", "open":
+		conditions = append(conditions, "resolved_at IS NULL")
+	case "resolved":
+		conditions = append(conditions, "resolved_at IS NOT NULL")
+	case "all":
+	default:
+		return nil, fmt.Errorf("invalid finding status filter: %s", filter.Status)
+	}
+	if filter.BatchID != nil {
+		conditions = append(conditions, fmt.Sprintf("batch_id = $%d", argIndex))
+		args = append(args, *filter.BatchID)
+		argIndex++
+	}
+	if filter.FindingType != nil {
+		conditions = append(conditions, fmt.Sprintf("finding_type = $%d", argIndex))
+		args = append(args, *filter.FindingType)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT finding_id, batch_id, finding_type, subject, proof_id, anchor_id, tree_index,
+			expected_value, actual_value, details, first_run_id, last_run_id,
+			detected_at, last_seen_at, resolved_at
+		FROM merkle_audit_findings
+		%s
+		ORDER BY detected_at DESC, finding_id
+		LIMIT $%d OFFSET $%d`, whereClause, argIndex, argIndex+1)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.client.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit findings: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*MerkleAuditFinding
+	for rows.Next() {
+		f := &MerkleAuditFinding{}
+		if err := rows.Scan(
+			&f.FindingID, &f.BatchID, &f.FindingType, &f.Subject, &f.ProofID, &f.AnchorID, &f.TreeIndex,
+			&f.ExpectedValue, &f.ActualValue, &f.Details, &f.FirstRunID, &f.LastRunID,
+			&f.DetectedAt, &f.LastSeenAt, &f.ResolvedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit finding: %w", err)
+		}
+		findings = append(findings, f)
+	}
+
+	return findings, rows.Err()
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 Certen Protocol
+//
+// Multi-Leg Repository - Cross-chain intents, leg dependencies and chain groups
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// MultiLegRepository handles read operations on multi-leg intent records
+type MultiLegRepository struct {
+	client *Client
+}
+
+// NewMultiLegRepository creates a new multi-leg repository
+func NewMultiLegRepository(client *Client) *MultiLegRepository {
+	return &MultiLegRepository{client: client}
+}
+
+// ============================================================================
+// INTENT OPERATIONS
+// ============================================================================
+
+// GetIntent returns the certen_intents record for an intent
+func (r *MultiLegRepository) GetIntent(ctx context.Context, intentID string) (*CertenIntent, error) {
+	query := `
+		SELECT intent_id, operation_id, user_id, organization_adi, accumulate_tx_hash,
+			account_url, partition, leg_count, execution_mode, proof_class, status,
+			current_leg_index, legs_completed, legs_failed, legs_pending,
+			intent_data, cross_chain_data, governance_data, replay_data,
+			created_at, updated_at, completed_at, expires_at, error_message
+		FROM certen_intents
+		WHERE intent_id = $1`
+
+	i := &CertenIntent{}
+	var intentData, crossChainData, governanceData, replayData []byte
+	err := r.client.QueryRowContext(ctx, query, intentID).Scan(
+		&i.IntentID, &i.OperationID, &i.UserID, &i.OrganizationADI, &i.AccumulateTxHash,
+		&i.AccountURL, &i.Partition, &i.LegCount, &i.ExecutionMode, &i.ProofClass, &i.Status,
+		&i.CurrentLegIndex, &i.LegsCompleted, &i.LegsFailed, &i.LegsPending,
+		&intentData, &crossChainData, &governanceData, &replayData,
+		&i.CreatedAt, &i.UpdatedAt, &i.CompletedAt, &i.ExpiresAt, &i.ErrorMessage,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrIntentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intent: %w", err)
+	}
+	i.IntentData = intentData
+	i.CrossChainData = crossChainData
+	i.GovernanceData = governanceData
+	i.ReplayData = replayData
+
+	return i, nil
+}
+
+// ============================================================================
+// LEG DEPENDENCY OPERATIONS
+// ============================================================================
+
+// GetLegDependencies returns the dependency edges between an intent's legs
+func (r *MultiLegRepository) GetLegDependencies(ctx context.Context, intentID string) ([]*LegDependency, error) {
+	query := `
+		SELECT dependency_id, intent_id, leg_id, depends_on_leg_id, condition_type,
+			is_satisfied, satisfied_at, created_at
+		FROM leg_dependencies
+		WHERE intent_id = $1
+		ORDER BY created_at ASC, dependency_id ASC`
+
+	rows, err := r.client.QueryContext(ctx, query, intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leg dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	var dependencies []*LegDependency
+	for rows.Next() {
+		d := &LegDependency{}
+		if err := rows.Scan(
+			&d.DependencyID, &d.IntentID, &d.LegID, &d.DependsOnLegID, &d.ConditionType,
+			&d.IsSatisfied, &d.SatisfiedAt, &d.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan leg dependency: %w", err)
+		}
+		dependencies = append(dependencies, d)
+	}
+
+	return dependencies, rows.Err()
+}
+
+// ============================================================================
+// CHAIN GROUP OPERATIONS
+// ============================================================================
+
+// GetChainGroups returns an intent's legs grouped by target chain, with the
+// anchor each group was committed in
+func (r *MultiLegRepository) GetChainGroups(ctx context.Context, intentID string) ([]*IntentChainGroup, error) {
+	query := `
+		SELECT group_id, intent_id, target_chain, chain_id, chain_key, leg_count,
+			leg_ids, status, batch_id, anchor_id, anchor_tx_hash, anchor_block,
+			created_at, anchored_at, completed_at
+		FROM intent_chain_groups
+		WHERE intent_id = $1
+		ORDER BY chain_key ASC`
+
+	rows, err := r.client.QueryContext(ctx, query, intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chain groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*IntentChainGroup
+	for rows.Next() {
+		g := &IntentChainGroup{}
+		var legIDs pq.StringArray
+		if err := rows.Scan(
+			&g.GroupID, &g.IntentID, &g.TargetChain, &g.ChainID, &g.ChainKey, &g.LegCount,
+			&legIDs, &g.Status, &g.BatchID, &g.AnchorID, &g.AnchorTxHash, &g.AnchorBlock,
+			&g.CreatedAt, &g.AnchoredAt, &g.CompletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan chain group: %w", err)
+		}
+		for _, s := range legIDs {
+			legID, err := uuid.Parse(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid leg id in chain group %s: %w", g.GroupID, err)
+			}
+			g.LegIDs = append(g.LegIDs, legID)
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
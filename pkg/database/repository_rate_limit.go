@@ -0,0 +1,55 @@
+// Copyright 2025 Certen Protocol
+//
+// Rate Limit Repository - Fixed-window request counters shared by replicas
+//
+// A counter is incremented with a single upsert, so concurrent requests on
+// different replicas each see the total including their own cost.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RateLimitRepository handles rate limit counter operations
+type RateLimitRepository struct {
+	client *Client
+}
+
+// NewRateLimitRepository creates a new rate limit repository
+func NewRateLimitRepository(client *Client) *RateLimitRepository {
+	return &RateLimitRepository{client: client}
+}
+
+// ============================================================================
+// RATE LIMIT COUNTER OPERATIONS
+// ============================================================================
+
+// IncrementCounter adds cost to a bucket's counter for the window starting at
+// windowStart and returns the window's total
+func (r *RateLimitRepository) IncrementCounter(ctx context.Context, bucket string, windowStart time.Time, cost int, expiresAt time.Time) (int, error) {
+	query := `
+		INSERT INTO rate_limit_counters (bucket, window_start, request_cost, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (bucket, window_start) DO UPDATE SET
+			request_cost = rate_limit_counters.request_cost + EXCLUDED.request_cost
+		RETURNING request_cost`
+
+	var total int
+	if err := r.client.QueryRowContext(ctx, query, bucket, windowStart, cost, expiresAt).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to increment rate limit counter: %w", err)
+	}
+	return total, nil
+}
+
+// PurgeExpiredCounters deletes counters whose window ended before now and
+// returns how many
+func (r *RateLimitRepository) PurgeExpiredCounters(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.client.ExecContext(ctx, `DELETE FROM rate_limit_counters WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge rate limit counters: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -276,6 +276,40 @@ func (r *RequestRepository) GetRequestsByBatch(ctx context.Context, batchID uuid
 	return requests, rows.Err()
 }
 
+// GetBatchedRequests retrieves requests that are waiting on their batch to produce a proof
+func (r *RequestRepository) GetBatchedRequests(ctx context.Context, limit int) ([]*ProofRequest, error) {
+	query := `
+		SELECT request_id, accumulate_tx_hash, account_url, request_type,
+			priority, status, batch_id, proof_id, requested_at,
+			processed_at, completed_at, requester_id, error_message, retry_count
+		FROM proof_requests
+		WHERE status = 'batched'
+		ORDER BY requested_at ASC
+		LIMIT $1`
+
+	rows, err := r.client.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batched requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*ProofRequest
+	for rows.Next() {
+		request := &ProofRequest{}
+		err := rows.Scan(
+			&request.RequestID, &request.AccumTxHash, &request.AccountURL, &request.RequestType,
+			&request.Priority, &request.Status, &request.BatchID, &request.ProofID, &request.RequestedAt,
+			&request.ProcessedAt, &request.CompletedAt, &request.RequesterID, &request.ErrorMessage, &request.RetryCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, rows.Err()
+}
+
 // ============================================================================
 // STATUS UPDATE OPERATIONS
 // ============================================================================
@@ -530,3 +564,32 @@ func (r *RequestRepository) GetRequestsByRequester(ctx context.Context, requeste
 
 	return requests, rows.Err()
 }
+
+// ============================================================================
+// PRICING TIER OPERATIONS
+// ============================================================================
+
+// GetPricingTier retrieves the active pricing tier for a request type
+func (r *RequestRepository) GetPricingTier(ctx context.Context, requestType RequestType) (*ProofPricingTier, error) {
+	query := `
+		SELECT tier_id, tier_name, base_cost_usd, batch_delay_seconds,
+			priority, is_active, created_at
+		FROM proof_pricing_tiers
+		WHERE tier_id = $1 AND is_active = TRUE`
+
+	tier := &ProofPricingTier{}
+	err := r.client.QueryRowContext(ctx, query, string(requestType)).Scan(
+		&tier.TierID, &tier.TierName, &tier.BaseCostUSD, &tier.BatchDelaySeconds,
+		&tier.Priority, &tier.IsActive, &tier.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		// F.4 remediation: Return explicit error instead of nil, nil
+		return nil, ErrPricingTierNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pricing tier: %w", err)
+	}
+
+	return tier, nil
+}
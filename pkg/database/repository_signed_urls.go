@@ -0,0 +1,85 @@
+// Copyright 2025 Certen Protocol
+//
+// Signed URL Repository - Minting and redemption of signed download URLs
+//
+// Redemption is a single conditional update, so a single-use URL redeemed
+// concurrently on several replicas succeeds only once.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignedURLRepository handles signed download URL operations
+type SignedURLRepository struct {
+	client *Client
+}
+
+// NewSignedURLRepository creates a new signed URL repository
+func NewSignedURLRepository(client *Client) *SignedURLRepository {
+	return &SignedURLRepository{client: client}
+}
+
+// ============================================================================
+// SIGNED URL OPERATIONS
+// ============================================================================
+
+// CreateSignedURL records a newly minted URL
+func (r *SignedURLRepository) CreateSignedURL(ctx context.Context, input *NewSignedDownloadURL) (*SignedDownloadURL, error) {
+	query := `
+		INSERT INTO signed_download_urls (
+			resource_type, resource_id, single_use, expires_at, created_by_key_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING url_id, created_at`
+
+	u := &SignedDownloadURL{
+		ResourceType:   input.ResourceType,
+		ResourceID:     input.ResourceID,
+		SingleUse:      input.SingleUse,
+		ExpiresAt:      input.ExpiresAt,
+		CreatedByKeyID: input.CreatedByKeyID,
+		CreatedBy:      input.CreatedBy,
+	}
+	err := r.client.QueryRowContext(ctx, query,
+		input.ResourceType, input.ResourceID, input.SingleUse, input.ExpiresAt, input.CreatedByKeyID, input.CreatedBy,
+	).Scan(&u.URLID, &u.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signed URL: %w", err)
+	}
+	return u, nil
+}
+
+// RedeemSignedURL counts a redemption of a URL. Returns
+// ErrSignedURLNotRedeemable if the URL does not exist, has expired, or is
+// single-use and was already redeemed.
+func (r *SignedURLRepository) RedeemSignedURL(ctx context.Context, urlID uuid.UUID, now time.Time) (*SignedDownloadURL, error) {
+	query := `
+		UPDATE signed_download_urls SET
+			redemption_count = redemption_count + 1,
+			last_redeemed_at = $2
+		WHERE url_id = $1
+			AND expires_at > $2
+			AND (NOT single_use OR redemption_count = 0)
+		RETURNING url_id, resource_type, resource_id, single_use, expires_at,
+			created_by_key_id, created_by, created_at, redemption_count, last_redeemed_at`
+
+	u := &SignedDownloadURL{}
+	err := r.client.QueryRowContext(ctx, query, urlID, now).Scan(
+		&u.URLID, &u.ResourceType, &u.ResourceID, &u.SingleUse, &u.ExpiresAt,
+		&u.CreatedByKeyID, &u.CreatedBy, &u.CreatedAt, &u.RedemptionCount, &u.LastRedeemedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSignedURLNotRedeemable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem signed URL: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 Certen Protocol
+//
+// Signed URL Types - Time-limited download URLs for bundles and exports
+
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Signed URL resource types
+const (
+	SignedResourceBundle = "bundle" // A proof's bundle; resource_id is the proof ID
+	SignedResourceExport = "export" // A finished bulk export; resource_id is the job ID
+)
+
+// SignedDownloadURL represents a row in the signed_download_urls table
+type SignedDownloadURL struct {
+	URLID           uuid.UUID  `json:"url_id"`
+	ResourceType    string     `json:"resource_type"`
+	ResourceID      uuid.UUID  `json:"resource_id"`
+	SingleUse       bool       `json:"single_use"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	CreatedByKeyID  *uuid.UUID `json:"created_by_key_id,omitempty"`
+	CreatedBy       string     `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	RedemptionCount int        `json:"redemption_count"`
+	LastRedeemedAt  *time.Time `json:"last_redeemed_at,omitempty"`
+}
+
+// NewSignedDownloadURL contains the fields of a URL to mint
+type NewSignedDownloadURL struct {
+	ResourceType   string
+	ResourceID     uuid.UUID
+	SingleUse      bool
+	ExpiresAt      time.Time
+	CreatedByKeyID *uuid.UUID
+	CreatedBy      string
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Certen Protocol
+//
+// Batch Helpers
+// Rebuilds batch trees from stored batch_transactions rows and compares
+// stored inclusion paths against freshly computed ones
+
+package merkle
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// LeavesFromTransactions returns the leaf hashes of a batch in tree order.
+// txs must be ordered by TreeIndex (as GetTransactionsInBatch returns them)
+// and the indices must run 0..n-1 without gaps.
+func LeavesFromTransactions(txs []*database.BatchTransaction) ([][]byte, error) {
+	leaves := make([][]byte, len(txs))
+	for i, tx := range txs {
+		if tx.TreeIndex != i {
+			return nil, fmt.Errorf("gap in tree indices at %d (found %d)", i, tx.TreeIndex)
+		}
+		leaves[i] = tx.TxHash
+	}
+	return leaves, nil
+}
+
+// TreeFromTransactions builds the Merkle tree for a batch from its transactions
+func TreeFromTransactions(txs []*database.BatchTransaction) (*Tree, error) {
+	leaves, err := LeavesFromTransactions(txs)
+	if err != nil {
+		return nil, err
+	}
+	return NewTree(leaves)
+}
+
+// ParseStoredPath decodes a batch_transactions.merkle_path column
+func ParseStoredPath(raw json.RawMessage) ([]database.MerklePathNode, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var path []database.MerklePathNode
+	if err := json.Unmarshal(raw, &path); err != nil {
+		return nil, fmt.Errorf("invalid stored merkle path: %w", err)
+	}
+	return path, nil
+}
+
+// PathsEqual reports whether two paths have the same hashes and positions.
+// Hash comparison ignores hex case.
+func PathsEqual(a, b []database.MerklePathNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i].Hash, b[i].Hash) || a[i].Position != b[i].Position {
+			return false
+		}
+	}
+	return true
+}
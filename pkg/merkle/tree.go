@@ -0,0 +1,157 @@
+// Copyright 2025 Certen Protocol
+//
+// Merkle Tree for Anchor Batches
+// Binary SHA-256 Merkle tree over batch transaction hashes
+//
+// Conventions (shared with POST /api/v1/proofs/verify/merkle):
+// - Parent = SHA256(left || right)
+// - A path node's Position is the side the SIBLING sits on:
+//   "right" means hash(current || sibling), "left" means hash(sibling || current)
+// - An odd node at the end of a level is paired with itself
+// - A single-leaf tree has root == leaf and an empty path
+
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// HashSize is the size in bytes of every leaf and node hash
+const HashSize = sha256.Size
+
+// Sibling positions used in database.MerklePathNode
+const (
+	PositionLeft  = "left"
+	PositionRight = "right"
+)
+
+// Tree is a fully materialised Merkle tree.
+// levels[0] holds the leaves and levels[len-1] holds the root.
+type Tree struct {
+	levels [][][]byte
+}
+
+// NewTree builds a tree over the given 32-byte leaf hashes, in order
+func NewTree(leaves [][]byte) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("cannot build merkle tree with no leaves")
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		if len(leaf) != HashSize {
+			return nil, fmt.Errorf("leaf %d has length %d, expected %d", i, len(leaf), HashSize)
+		}
+		level[i] = append([]byte(nil), leaf...)
+	}
+
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			next = append(next, HashPair(level[i], right))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return &Tree{levels: levels}, nil
+}
+
+// Root returns the Merkle root
+func (t *Tree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// LeafCount returns the number of leaves
+func (t *Tree) LeafCount() int {
+	return len(t.levels[0])
+}
+
+// Depth returns the number of levels above the leaves (0 for a single leaf)
+func (t *Tree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Levels returns every level of the tree, leaves first and root last.
+// The returned slices must not be modified.
+func (t *Tree) Levels() [][][]byte {
+	return t.levels
+}
+
+// Path returns the inclusion path for the leaf at index, ordered leaf to root
+func (t *Tree) Path(index int) ([]database.MerklePathNode, error) {
+	if index < 0 || index >= t.LeafCount() {
+		return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, t.LeafCount())
+	}
+
+	path := make([]database.MerklePathNode, 0, t.Depth())
+	for _, level := range t.levels[:len(t.levels)-1] {
+		var node database.MerklePathNode
+		if index%2 == 0 {
+			sibling := level[index]
+			if index+1 < len(level) {
+				sibling = level[index+1]
+			}
+			node = database.MerklePathNode{Hash: hex.EncodeToString(sibling), Position: PositionRight}
+		} else {
+			node = database.MerklePathNode{Hash: hex.EncodeToString(level[index-1]), Position: PositionLeft}
+		}
+		path = append(path, node)
+		index /= 2
+	}
+
+	return path, nil
+}
+
+// HashPair returns SHA256(left || right)
+func HashPair(left, right []byte) []byte {
+	combined := make([]byte, 0, len(left)+len(right))
+	combined = append(combined, left...)
+	combined = append(combined, right...)
+	sum := sha256.Sum256(combined)
+	return sum[:]
+}
+
+// ComputeRoot folds a leaf up through its path and returns the resulting root
+func ComputeRoot(leaf []byte, path []database.MerklePathNode) ([]byte, error) {
+	if len(leaf) != HashSize {
+		return nil, fmt.Errorf("leaf has length %d, expected %d", len(leaf), HashSize)
+	}
+
+	current := leaf
+	for i, node := range path {
+		sibling, err := hex.DecodeString(node.Hash)
+		if err != nil || len(sibling) != HashSize {
+			return nil, fmt.Errorf("invalid hash at path entry %d", i)
+		}
+		switch node.Position {
+		case PositionRight:
+			current = HashPair(current, sibling)
+		case PositionLeft:
+			current = HashPair(sibling, current)
+		default:
+			return nil, fmt.Errorf("invalid position %q at path entry %d", node.Position, i)
+		}
+	}
+
+	return current, nil
+}
+
+// VerifyPath reports whether leaf and path fold up to root
+func VerifyPath(leaf []byte, path []database.MerklePathNode, root []byte) bool {
+	computed, err := ComputeRoot(leaf, path)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(computed, root)
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the batch Merkle tree
+
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("tx-%d", i)))
+		leaves[i] = sum[:]
+	}
+	return leaves
+}
+
+// ============================================================================
+// Construction Tests
+// ============================================================================
+
+func TestNewTree_Empty(t *testing.T) {
+	if _, err := NewTree(nil); err == nil {
+		t.Error("Expected error for empty leaf set")
+	}
+}
+
+func TestNewTree_InvalidLeafLength(t *testing.T) {
+	if _, err := NewTree([][]byte{make([]byte, 31)}); err == nil {
+		t.Error("Expected error for 31-byte leaf")
+	}
+}
+
+func TestNewTree_SingleLeaf(t *testing.T) {
+	leaves := testLeaves(1)
+	tree, err := NewTree(leaves)
+	if err != nil {
+		t.Fatalf("NewTree failed: %v", err)
+	}
+
+	if !bytes.Equal(tree.Root(), leaves[0]) {
+		t.Error("Expected single-leaf root to equal the leaf")
+	}
+	path, err := tree.Path(0)
+	if err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+	if len(path) != 0 {
+		t.Errorf("Expected empty path, got %d entries", len(path))
+	}
+}
+
+func TestNewTree_KnownRoot(t *testing.T) {
+	leaves := testLeaves(3)
+	tree, err := NewTree(leaves)
+	if err != nil {
+		t.Fatalf("NewTree failed: %v", err)
+	}
+
+	// Odd leaf is paired with itself
+	left := HashPair(leaves[0], leaves[1])
+	right := HashPair(leaves[2], leaves[2])
+	expected := HashPair(left, right)
+
+	if !bytes.Equal(tree.Root(), expected) {
+		t.Errorf("Expected root %x, got %x", expected, tree.Root())
+	}
+	if tree.Depth() != 2 {
+		t.Errorf("Expected depth 2, got %d", tree.Depth())
+	}
+}
+
+// ============================================================================
+// Path Tests
+// ============================================================================
+
+func TestPath_AllLeavesVerify(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		leaves := testLeaves(n)
+		tree, err := NewTree(leaves)
+		if err != nil {
+			t.Fatalf("NewTree(%d) failed: %v", n, err)
+		}
+		for i := range leaves {
+			path, err := tree.Path(i)
+			if err != nil {
+				t.Fatalf("Path(%d) of %d failed: %v", i, n, err)
+			}
+			if !VerifyPath(leaves[i], path, tree.Root()) {
+				t.Errorf("Path for leaf %d of %d does not verify", i, n)
+			}
+		}
+	}
+}
+
+func TestPath_OutOfRange(t *testing.T) {
+	tree, _ := NewTree(testLeaves(4))
+	if _, err := tree.Path(4); err == nil {
+		t.Error("Expected error for out-of-range index")
+	}
+	if _, err := tree.Path(-1); err == nil {
+		t.Error("Expected error for negative index")
+	}
+}
+
+func TestPath_MatchesVerifyEndpointConvention(t *testing.T) {
+	// HandleVerifyMerkle folds with: right => hash(current || sibling), else hash(sibling || current)
+	leaves := testLeaves(5)
+	tree, _ := NewTree(leaves)
+
+	for i := range leaves {
+		path, _ := tree.Path(i)
+		current := leaves[i]
+		for _, node := range path {
+			sibling, _ := hex.DecodeString(node.Hash)
+			var combined []byte
+			if node.Position == PositionRight {
+				combined = append(append([]byte{}, current...), sibling...)
+			} else {
+				combined = append(append([]byte{}, sibling...), current...)
+			}
+			sum := sha256.Sum256(combined)
+			current = sum[:]
+		}
+		if !bytes.Equal(current, tree.Root()) {
+			t.Errorf("Leaf %d does not fold to root under verify endpoint convention", i)
+		}
+	}
+}
+
+func TestVerifyPath_Tampered(t *testing.T) {
+	leaves := testLeaves(4)
+	tree, _ := NewTree(leaves)
+	path, _ := tree.Path(2)
+
+	path[0].Position = PositionLeft
+	if VerifyPath(leaves[2], path, tree.Root()) {
+		t.Error("Expected tampered path to fail verification")
+	}
+}
+
+// ============================================================================
+// Batch Helper Tests
+// ============================================================================
+
+func TestTreeFromTransactions_Gap(t *testing.T) {
+	leaves := testLeaves(3)
+	txs := []*database.BatchTransaction{
+		{TreeIndex: 0, TxHash: leaves[0]},
+		{TreeIndex: 2, TxHash: leaves[2]},
+	}
+	if _, err := TreeFromTransactions(txs); err == nil {
+		t.Error("Expected error for gap in tree indices")
+	}
+}
+
+func TestStoredPath_RoundTrip(t *testing.T) {
+	leaves := testLeaves(6)
+	txs := make([]*database.BatchTransaction, len(leaves))
+	for i, leaf := range leaves {
+		txs[i] = &database.BatchTransaction{TreeIndex: i, TxHash: leaf}
+	}
+	tree, err := TreeFromTransactions(txs)
+	if err != nil {
+		t.Fatalf("TreeFromTransactions failed: %v", err)
+	}
+
+	path, _ := tree.Path(3)
+	raw, _ := json.Marshal(path)
+	stored, err := ParseStoredPath(raw)
+	if err != nil {
+		t.Fatalf("ParseStoredPath failed: %v", err)
+	}
+	if !PathsEqual(stored, path) {
+		t.Error("Expected stored path to equal computed path")
+	}
+
+	stored[0].Hash = strings.ToUpper(stored[0].Hash)
+	if !PathsEqual(stored, path) {
+		t.Error("Expected hash comparison to ignore case")
+	}
+
+	other, _ := tree.Path(4)
+	if PathsEqual(stored, other) {
+		t.Error("Expected paths of different leaves to differ")
+	}
+}
+
+func TestParseStoredPath_Empty(t *testing.T) {
+	for _, raw := range []string{"", "null"} {
+		path, err := ParseStoredPath(json.RawMessage(raw))
+		if err != nil || path != nil {
+			t.Errorf("Expected nil path for %q, got %v (err %v)", raw, path, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Certen Protocol
+//
+// Access Log Purger
+// Background job that deletes access log entries past their retention period
+//
+// The access log is append-only; this purge is the only way entries leave it.
+
+package pipeline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// AccessLogPurgerConfig contains configuration for the access log purger
+type AccessLogPurgerConfig struct {
+	Retention time.Duration // How long entries are kept
+	Interval  time.Duration // Time between purges
+}
+
+// AccessLogPurger periodically deletes access log entries older than the
+// retention period
+type AccessLogPurger struct {
+	repos  *database.Repositories
+	config *AccessLogPurgerConfig
+	logger *log.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewAccessLogPurger creates a new access log purger
+func NewAccessLogPurger(
+	repos *database.Repositories,
+	config *AccessLogPurgerConfig,
+	logger *log.Logger,
+) *AccessLogPurger {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[AccessLogPurger] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &AccessLogPurgerConfig{}
+	}
+	if config.Retention <= 0 {
+		config.Retention = 365 * 24 * time.Hour
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+
+	return &AccessLogPurger{
+		repos:  repos,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Start launches the purge loop in the background
+func (p *AccessLogPurger) Start() {
+	p.stopCh = make(chan struct{})
+	p.wg.Add(1)
+	go p.run()
+}
+
+// Stop signals the purge loop to exit and waits for it
+func (p *AccessLogPurger) Stop() {
+	if p.stopCh != nil {
+		close(p.stopCh)
+	}
+	p.wg.Wait()
+}
+
+func (p *AccessLogPurger) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.config.Interval)
+			if err := p.ProcessOnce(ctx); err != nil {
+				p.logger.Printf("Access log purge failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ProcessOnce deletes every entry older than the retention period
+func (p *AccessLogPurger) ProcessOnce(ctx context.Context) error {
+	n, err := p.repos.AccessLog.PurgeAccessLog(ctx, time.Now().UTC().Add(-p.config.Retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		p.logger.Printf("Purged %d access log entries older than %s", n, p.config.Retention)
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+// Copyright 2025 Certen Protocol
+//
+// Anchoring Pipeline
+// Submits closed batch Merkle roots to an external chain and tracks confirmations
+//
+// Batch lifecycle driven here (per Whitepaper Section 3.4.2):
+//   closed -> anchoring  (batch claimed and attempt counted, then root submitted and recorded)
+//   anchoring -> closed  (submission failed or was interrupted before the anchor was recorded)
+//   anchoring -> anchored (1+ confirmations, proof artifacts written for the batch)
+//   anchored -> confirmed (required confirmations reached, anchor marked final)
+//   * -> failed          (submission failed repeatedly or the anchor tx vanished)
+
+package pipeline
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// ErrAnchorTxNotFound is returned by a backend when it has no record of an anchor transaction
+var ErrAnchorTxNotFound = errors.New("anchor transaction not found")
+
+// AnchorBackend submits Merkle roots to an external chain
+type AnchorBackend interface {
+	// TargetChain identifies the chain this backend writes to
+	TargetChain() database.TargetChain
+
+	// SubmitAnchor writes the batch Merkle root to the chain
+	SubmitAnchor(ctx context.Context, batch *database.AnchorBatch) (*AnchorSubmission, error)
+
+	// GetConfirmation reports the current confirmation state of an anchor transaction
+	GetConfirmation(ctx context.Context, txHash string) (*AnchorConfirmation, error)
+}
+
+// AnchorSubmission describes an anchor transaction accepted by a backend
+type AnchorSubmission struct {
+	TxHash          string
+	BlockNumber     int64
+	BlockHash       string
+	ChainID         string
+	NetworkName     string
+	ContractAddress string
+	GasUsed         int64
+	GasPriceWei     *big.Int
+}
+
+// AnchorConfirmation describes how deeply an anchor transaction is buried
+type AnchorConfirmation struct {
+	Confirmations  int
+	BlockHash      string
+	BlockTimestamp time.Time
+}
+
+// AnchorerConfig contains configuration for the anchoring pipeline
+type AnchorerConfig struct {
+	ValidatorID       string
+	PollInterval      time.Duration // Time between pipeline sweeps
+	MaxSubmitAttempts int           // Submission attempts before a batch is marked failed
+	InterruptedAfter  time.Duration // How long a batch may stay 'anchoring' without an anchor record
+}
+
+// Anchorer moves closed batches through anchoring to confirmation
+type Anchorer struct {
+	repos   *database.Repositories
+	backend AnchorBackend
+	config  *AnchorerConfig
+	logger  *log.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewAnchorer creates a new anchoring pipeline
+func NewAnchorer(
+	repos *database.Repositories,
+	backend AnchorBackend,
+	config *AnchorerConfig,
+	logger *log.Logger,
+) *Anchorer {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[Anchorer] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &AnchorerConfig{}
+	}
+	if config.ValidatorID == "" {
+		config.ValidatorID = "default-validator"
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 10 * time.Second
+	}
+	if config.MaxSubmitAttempts <= 0 {
+		config.MaxSubmitAttempts = 3
+	}
+	if config.InterruptedAfter <= 0 {
+		// Longer than one sweep's timeout, so in-flight submissions are left alone
+		config.InterruptedAfter = config.PollInterval * 5
+	}
+
+	return &Anchorer{
+		repos:   repos,
+		backend: backend,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Start launches the anchoring loop in the background
+func (a *Anchorer) Start() {
+	a.stopCh = make(chan struct{})
+	a.wg.Add(1)
+	go a.run()
+}
+
+// Stop signals the anchoring loop to exit and waits for it
+func (a *Anchorer) Stop() {
+	if a.stopCh != nil {
+		close(a.stopCh)
+	}
+	a.wg.Wait()
+}
+
+func (a *Anchorer) run() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), a.config.PollInterval*4)
+			if err := a.ProcessOnce(ctx); err != nil {
+				a.logger.Printf("Anchoring sweep failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ProcessOnce requeues interrupted submissions, submits every closed batch
+// and refreshes every unconfirmed anchor
+func (a *Anchorer) ProcessOnce(ctx context.Context) error {
+	requeued, err := a.repos.Batches.RequeueInterruptedAnchoring(ctx, a.config.InterruptedAfter, a.config.MaxSubmitAttempts)
+	if err != nil {
+		return err
+	}
+	for _, batchID := range requeued {
+		a.logger.Printf("Batch %s was left anchoring without an anchor record; requeued", batchID)
+	}
+
+	batches, err := a.repos.Batches.GetBatchesReadyForAnchoring(ctx)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		a.submitBatch(ctx, batch)
+	}
+
+	anchors, err := a.repos.Anchors.GetUnconfirmedAnchors(ctx)
+	if err != nil {
+		return err
+	}
+	for _, anchor := range anchors {
+		if anchor.TargetChain != a.backend.TargetChain() {
+			continue
+		}
+		a.refreshAnchor(ctx, anchor)
+	}
+
+	return nil
+}
+
+// =============================================================================
+// SUBMISSION
+// =============================================================================
+
+// submitBatch writes a closed batch's root to the chain and records the anchor
+func (a *Anchorer) submitBatch(ctx context.Context, batch *database.AnchorBatch) {
+	attempts, err := a.repos.Batches.ClaimBatchForAnchoring(ctx, batch.BatchID)
+	if errors.Is(err, database.ErrBatchNotFound) {
+		// Claimed by another sweep since it was listed
+		return
+	}
+	if err != nil {
+		a.logger.Printf("Error marking batch %s anchoring: %v", batch.BatchID, err)
+		return
+	}
+
+	submission, err := a.backend.SubmitAnchor(ctx, batch)
+	if err != nil {
+		a.handleSubmitFailure(ctx, batch.BatchID, attempts, err)
+		return
+	}
+
+	var gasPriceWei, totalCostWei string
+	if submission.GasPriceWei != nil {
+		gasPriceWei = submission.GasPriceWei.String()
+		totalCostWei = new(big.Int).Mul(submission.GasPriceWei, big.NewInt(submission.GasUsed)).String()
+	}
+
+	anchor, err := a.repos.Anchors.CreateAnchor(ctx, &database.NewAnchorRecord{
+		BatchID:             batch.BatchID,
+		TargetChain:         a.backend.TargetChain(),
+		ChainID:             submission.ChainID,
+		NetworkName:         submission.NetworkName,
+		ContractAddress:     submission.ContractAddress,
+		AnchorTxHash:        submission.TxHash,
+		AnchorBlockNumber:   submission.BlockNumber,
+		AnchorBlockHash:     submission.BlockHash,
+		MerkleRoot:          batch.MerkleRoot,
+		AccumHeight:         batch.AccumHeight.Int64,
+		OperationCommitment: batch.MerkleRoot,
+		ValidatorID:         a.config.ValidatorID,
+		GasUsed:             submission.GasUsed,
+		GasPriceWei:         gasPriceWei,
+		TotalCostWei:        totalCostWei,
+	})
+	if err != nil {
+		// The root is on chain but unrecorded; fail loudly rather than resubmit
+		a.logger.Printf("Error recording anchor %s for batch %s: %v", submission.TxHash, batch.BatchID, err)
+		msg := fmt.Sprintf("anchor tx %s submitted but not recorded: %v", submission.TxHash, err)
+		if err := a.repos.Batches.UpdateBatchStatus(ctx, batch.BatchID, database.BatchStatusFailed, msg); err != nil {
+			a.logger.Printf("Error failing batch %s: %v", batch.BatchID, err)
+		}
+		return
+	}
+
+	a.logger.Printf("Submitted batch %s to %s in tx %s (block %d)",
+		batch.BatchID, anchor.TargetChain, anchor.AnchorTxHash, anchor.AnchorBlockNumber)
+}
+
+// handleSubmitFailure returns a batch to 'closed' for retry, or fails it once attempts run out
+func (a *Anchorer) handleSubmitFailure(ctx context.Context, batchID uuid.UUID, attempts int, submitErr error) {
+	a.logger.Printf("Anchor submission for batch %s failed (attempt %d/%d): %v",
+		batchID, attempts, a.config.MaxSubmitAttempts, submitErr)
+
+	status := database.BatchStatusClosed
+	if attempts >= a.config.MaxSubmitAttempts {
+		status = database.BatchStatusFailed
+	}
+
+	msg := fmt.Sprintf("anchor submission failed: %v", submitErr)
+	if err := a.repos.Batches.UpdateBatchStatus(ctx, batchID, status, msg); err != nil {
+		a.logger.Printf("Error updating batch %s after failed submission: %v", batchID, err)
+	}
+}
+
+// =============================================================================
+// CONFIRMATION TRACKING
+// =============================================================================
+
+// refreshAnchor updates an anchor's confirmations and advances its batch
+func (a *Anchorer) refreshAnchor(ctx context.Context, anchor *database.AnchorRecord) {
+	confirmation, err := a.backend.GetConfirmation(ctx, anchor.AnchorTxHash)
+	if errors.Is(err, ErrAnchorTxNotFound) {
+		msg := fmt.Sprintf("anchor tx %s no longer found on %s", anchor.AnchorTxHash, anchor.TargetChain)
+		if err := a.repos.Batches.UpdateBatchStatus(ctx, anchor.BatchID, database.BatchStatusFailed, msg); err != nil {
+			a.logger.Printf("Error failing batch %s: %v", anchor.BatchID, err)
+		}
+		return
+	}
+	if err != nil {
+		a.logger.Printf("Error checking anchor %s: %v", anchor.AnchorTxHash, err)
+		return
+	}
+
+	if confirmation.Confirmations != anchor.Confirmations {
+		if err := a.repos.Anchors.UpdateConfirmations(ctx, anchor.AnchorID,
+			confirmation.Confirmations, confirmation.BlockHash, confirmation.BlockTimestamp); err != nil {
+			a.logger.Printf("Error updating confirmations for anchor %s: %v", anchor.AnchorID, err)
+			return
+		}
+	}
+
+	batch, err := a.repos.Batches.GetBatch(ctx, anchor.BatchID)
+	if err != nil {
+		a.logger.Printf("Error loading batch %s: %v", anchor.BatchID, err)
+		return
+	}
+
+	if confirmation.Confirmations >= 1 && batch.Status == database.BatchStatusAnchoring {
+		if err := a.markProofsAnchored(ctx, batch, anchor); err != nil {
+			a.logger.Printf("Error anchoring proofs for batch %s: %v", anchor.BatchID, err)
+			return
+		}
+		if err := a.repos.Batches.UpdateBatchStatus(ctx, anchor.BatchID, database.BatchStatusAnchored, ""); err != nil {
+			a.logger.Printf("Error marking batch %s anchored: %v", anchor.BatchID, err)
+			return
+		}
+		batch.Status = database.BatchStatusAnchored
+	}
+
+	if confirmation.Confirmations >= anchor.RequiredConfirms && batch.Status == database.BatchStatusAnchored {
+		if err := a.repos.Anchors.MarkAnchorFinal(ctx, anchor.AnchorID); err != nil {
+			a.logger.Printf("Error marking anchor %s final: %v", anchor.AnchorID, err)
+			return
+		}
+		if err := a.repos.Batches.UpdateBatchStatus(ctx, anchor.BatchID, database.BatchStatusConfirmed, ""); err != nil {
+			a.logger.Printf("Error marking batch %s confirmed: %v", anchor.BatchID, err)
+			return
+		}
+		a.logger.Printf("Anchor %s for batch %s is final (%d confirmations)",
+			anchor.AnchorTxHash, anchor.BatchID, confirmation.Confirmations)
+	}
+}
+
+// markProofsAnchored links every proof in the batch to its anchor, first
+// writing a proof artifact for each batch transaction that has none
+func (a *Anchorer) markProofsAnchored(ctx context.Context, batch *database.AnchorBatch, anchor *database.AnchorRecord) error {
+	if err := a.createBatchProofs(ctx, batch); err != nil {
+		return err
+	}
+
+	artifacts, err := a.repos.ProofArtifacts.GetProofsByBatch(ctx, anchor.BatchID)
+	if err != nil {
+		return err
+	}
+	for _, proof := range artifacts {
+		if err := a.repos.ProofArtifacts.UpdateProofAnchored(ctx, proof.ProofID, anchor.AnchorID,
+			anchor.AnchorTxHash, anchor.AnchorBlockNumber, string(anchor.TargetChain)); err != nil {
+			return err
+		}
+	}
+
+	legacy, err := a.repos.Proofs.GetProofsByBatchID(ctx, anchor.BatchID)
+	if err != nil {
+		return err
+	}
+	for _, proof := range legacy {
+		if err := a.repos.Proofs.UpdateAnchorID(ctx, proof.ProofID, anchor.AnchorID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchProofArtifact is the artifact_json recorded for a batched transaction
+type batchProofArtifact struct {
+	AccumTxHash string          `json:"accum_tx_hash"`
+	BatchID     uuid.UUID       `json:"batch_id"`
+	MerkleRoot  string          `json:"merkle_root"`
+	LeafHash    string          `json:"leaf_hash"`
+	LeafIndex   int             `json:"leaf_index"`
+	MerklePath  json.RawMessage `json:"merkle_path"`
+}
+
+// createBatchProofs writes a certen_anchor proof artifact for every transaction
+// in the batch that does not have one yet. Transactions already holding an
+// artifact (for example from an earlier, interrupted sweep) are left as they are.
+func (a *Anchorer) createBatchProofs(ctx context.Context, batch *database.AnchorBatch) error {
+	txs, err := a.repos.Batches.GetTransactionsInBatch(ctx, batch.BatchID)
+	if err != nil {
+		return err
+	}
+
+	proofClass := database.ProofClassOnCadence
+	if batch.BatchType == database.BatchTypeOnDemand {
+		proofClass = database.ProofClassOnDemand
+	}
+
+	for _, tx := range txs {
+		existing, err := a.repos.ProofArtifacts.GetProofByTxHash(ctx, tx.AccumTxHash)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+
+		artifactJSON, err := json.Marshal(batchProofArtifact{
+			AccumTxHash: tx.AccumTxHash,
+			BatchID:     batch.BatchID,
+			MerkleRoot:  hex.EncodeToString(batch.MerkleRoot),
+			LeafHash:    hex.EncodeToString(tx.TxHash),
+			LeafIndex:   tx.TreeIndex,
+			MerklePath:  tx.MerklePath,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode proof artifact for %s: %w", tx.AccumTxHash, err)
+		}
+
+		batchID := batch.BatchID
+		leafIndex := tx.TreeIndex
+		if _, err := a.repos.ProofArtifacts.CreateProofArtifact(ctx, &database.NewProofArtifact{
+			ProofType:    database.ProofTypeCertenAnchor,
+			AccumTxHash:  tx.AccumTxHash,
+			AccountURL:   tx.AccountURL,
+			BatchID:      &batchID,
+			MerkleRoot:   batch.MerkleRoot,
+			LeafHash:     tx.TxHash,
+			LeafIndex:    &leafIndex,
+			ProofClass:   proofClass,
+			ValidatorID:  batch.ValidatorID,
+			ArtifactJSON: artifactJSON,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
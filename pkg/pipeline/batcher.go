@@ -0,0 +1,247 @@
+// Copyright 2025 Certen Protocol
+//
+// Batcher
+// Accumulates transactions into on-cadence and on-demand anchor batches
+//
+// A batch stays open (status 'pending') until it reaches MaxBatchSize
+// transactions or its time window elapses. Closing a batch builds the
+// SHA-256 Merkle tree over the transaction hashes in tree_index order,
+// writes every leaf's inclusion path, then records the root.
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// BatcherConfig contains configuration for the batcher
+type BatcherConfig struct {
+	ValidatorID       string
+	MaxBatchSize      int           // Close a batch once it holds this many transactions
+	OnCadenceInterval time.Duration // Close on-cadence batches this long after they open
+	OnDemandInterval  time.Duration // Close on-demand batches this long after they open
+	PollInterval      time.Duration // Time between checks for expired batches
+}
+
+// Batcher assigns transactions to open batches and closes them
+type Batcher struct {
+	repos  *database.Repositories
+	config *BatcherConfig
+	logger *log.Logger
+
+	// mu serialises tree index assignment and batch closing within this process
+	mu sync.Mutex
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewBatcher creates a new batcher
+func NewBatcher(
+	repos *database.Repositories,
+	config *BatcherConfig,
+	logger *log.Logger,
+) *Batcher {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[Batcher] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &BatcherConfig{}
+	}
+	if config.ValidatorID == "" {
+		config.ValidatorID = "default-validator"
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = 1000
+	}
+	if config.OnCadenceInterval <= 0 {
+		config.OnCadenceInterval = 15 * time.Minute
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+
+	return &Batcher{
+		repos:  repos,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Start launches the batch closing loop in the background
+func (b *Batcher) Start() {
+	b.stopCh = make(chan struct{})
+	b.wg.Add(1)
+	go b.run()
+}
+
+// Stop signals the batch closing loop to exit and waits for it
+func (b *Batcher) Stop() {
+	if b.stopCh != nil {
+		close(b.stopCh)
+	}
+	b.wg.Wait()
+}
+
+func (b *Batcher) run() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), b.config.PollInterval*4)
+			if err := b.CloseExpired(ctx); err != nil {
+				b.logger.Printf("Batch close sweep failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// AddTransaction appends a transaction to the open batch of the given type,
+// opening a new batch if none exists. The batch is closed immediately if the
+// transaction fills it.
+func (b *Batcher) AddTransaction(ctx context.Context, batchType database.BatchType, input *database.NewBatchTransaction) (*database.BatchTransaction, error) {
+	if len(input.TxHash) != merkle.HashSize {
+		return nil, fmt.Errorf("transaction hash must be %d bytes, got %d", merkle.HashSize, len(input.TxHash))
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch, err := b.repos.Batches.GetPendingBatch(ctx, b.config.ValidatorID, batchType)
+	if errors.Is(err, database.ErrBatchNotFound) {
+		batch, err = b.repos.Batches.CreateBatch(ctx, &database.NewAnchorBatch{
+			BatchType:   batchType,
+			ValidatorID: b.config.ValidatorID,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open batch: %w", err)
+	}
+
+	treeIndex, err := b.repos.Batches.GetNextTreeIndex(ctx, batch.BatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	input.BatchID = batch.BatchID
+	input.TreeIndex = treeIndex
+	// The real path is written when the batch closes
+	input.MerklePath = []database.MerklePathNode{}
+
+	tx, err := b.repos.Batches.AddTransaction(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if treeIndex+1 >= b.config.MaxBatchSize {
+		if err := b.closeBatch(ctx, batch.BatchID); err != nil {
+			// The transaction is stored; the expiry sweep will retry the close
+			b.logger.Printf("Error closing full batch %s: %v", batch.BatchID, err)
+		}
+	}
+
+	return tx, nil
+}
+
+// CloseExpired closes every open batch whose time window has elapsed or
+// that already holds MaxBatchSize transactions
+func (b *Batcher) CloseExpired(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	windows := []struct {
+		batchType database.BatchType
+		interval  time.Duration
+	}{
+		{database.BatchTypeOnDemand, b.config.OnDemandInterval},
+		{database.BatchTypeOnCadence, b.config.OnCadenceInterval},
+	}
+
+	for _, w := range windows {
+		batch, err := b.repos.Batches.GetPendingBatch(ctx, b.config.ValidatorID, w.batchType)
+		if errors.Is(err, database.ErrBatchNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Empty batches stay open; there is nothing to anchor
+		if batch.TxCount == 0 {
+			continue
+		}
+		if batch.TxCount < b.config.MaxBatchSize && time.Since(batch.StartTime) < w.interval {
+			continue
+		}
+
+		if err := b.closeBatch(ctx, batch.BatchID); err != nil {
+			return fmt.Errorf("failed to close %s batch %s: %w", w.batchType, batch.BatchID, err)
+		}
+	}
+
+	return nil
+}
+
+// CloseBatch builds the Merkle tree for a batch, writes its inclusion paths and closes it
+func (b *Batcher) CloseBatch(ctx context.Context, batchID uuid.UUID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closeBatch(ctx, batchID)
+}
+
+func (b *Batcher) closeBatch(ctx context.Context, batchID uuid.UUID) error {
+	txs, err := b.repos.Batches.GetTransactionsInBatch(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	if len(txs) == 0 {
+		return fmt.Errorf("batch %s has no transactions", batchID)
+	}
+
+	tree, err := merkle.TreeFromTransactions(txs)
+	if err != nil {
+		return fmt.Errorf("failed to build merkle tree for batch %s: %w", batchID, err)
+	}
+
+	// Paths are written before the batch leaves 'pending', so a failure part
+	// way through is repaired by the next close attempt
+	for i := range txs {
+		path, err := tree.Path(i)
+		if err != nil {
+			return err
+		}
+		pathJSON, err := json.Marshal(path)
+		if err != nil {
+			return fmt.Errorf("failed to serialize merkle path: %w", err)
+		}
+		if err := b.repos.Batches.UpdateMerklePathByTreeIndex(ctx, batchID, i, pathJSON); err != nil {
+			return err
+		}
+	}
+
+	if err := b.repos.Batches.CloseBatch(ctx, batchID, tree.Root(), 0, ""); err != nil {
+		return err
+	}
+
+	b.logger.Printf("Closed batch %s with %d transactions (root %x)", batchID, len(txs), tree.Root())
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Certen Protocol
+//
+// Consensus Sweeper
+// Background job that times out BLS result consensus entries past their deadline
+//
+// Entries are also timed out when an attestation arrives after the deadline;
+// the sweeper makes stalled entries visible without waiting for one.
+
+package pipeline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// ConsensusSweeperConfig contains configuration for the consensus sweeper
+type ConsensusSweeperConfig struct {
+	Interval time.Duration // Time between sweeps
+}
+
+// ConsensusSweeper periodically times out stalled consensus entries
+type ConsensusSweeper struct {
+	repos  *database.Repositories
+	config *ConsensusSweeperConfig
+	logger *log.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewConsensusSweeper creates a new consensus sweeper
+func NewConsensusSweeper(
+	repos *database.Repositories,
+	config *ConsensusSweeperConfig,
+	logger *log.Logger,
+) *ConsensusSweeper {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[ConsensusSweeper] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &ConsensusSweeperConfig{}
+	}
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+
+	return &ConsensusSweeper{
+		repos:  repos,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Start launches the sweep loop in the background
+func (s *ConsensusSweeper) Start() {
+	s.stopCh = make(chan struct{})
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop signals the sweep loop to exit and waits for it
+func (s *ConsensusSweeper) Stop() {
+	if s.stopCh != nil {
+		close(s.stopCh)
+	}
+	s.wg.Wait()
+}
+
+func (s *ConsensusSweeper) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.Interval)
+			if err := s.ProcessOnce(ctx); err != nil {
+				s.logger.Printf("Consensus sweep failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ProcessOnce times out every open entry whose deadline has passed
+func (s *ConsensusSweeper) ProcessOnce(ctx context.Context) error {
+	n, err := s.repos.Consensus.TimeoutExpiredEntries(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.logger.Printf("Timed out %d consensus entries", n)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Certen Protocol
+//
+// Idempotency Purger
+// Background job that deletes expired idempotency keys
+//
+// Expired keys are already ignored and reclaimed on their next use; the
+// purger keeps keys that are never reused from accumulating.
+
+package pipeline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// IdempotencyPurgerConfig contains configuration for the idempotency purger
+type IdempotencyPurgerConfig struct {
+	Interval time.Duration // Time between purges
+}
+
+// IdempotencyPurger periodically deletes expired idempotency keys
+type IdempotencyPurger struct {
+	repos  *database.Repositories
+	config *IdempotencyPurgerConfig
+	logger *log.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewIdempotencyPurger creates a new idempotency purger
+func NewIdempotencyPurger(
+	repos *database.Repositories,
+	config *IdempotencyPurgerConfig,
+	logger *log.Logger,
+) *IdempotencyPurger {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[IdempotencyPurger] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &IdempotencyPurgerConfig{}
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+
+	return &IdempotencyPurger{
+		repos:  repos,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Start launches the purge loop in the background
+func (p *IdempotencyPurger) Start() {
+	p.stopCh = make(chan struct{})
+	p.wg.Add(1)
+	go p.run()
+}
+
+// Stop signals the purge loop to exit and waits for it
+func (p *IdempotencyPurger) Stop() {
+	if p.stopCh != nil {
+		close(p.stopCh)
+	}
+	p.wg.Wait()
+}
+
+func (p *IdempotencyPurger) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.config.Interval)
+			if err := p.ProcessOnce(ctx); err != nil {
+				p.logger.Printf("Idempotency purge failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ProcessOnce deletes every key that has expired
+func (p *IdempotencyPurger) ProcessOnce(ctx context.Context) error {
+	n, err := p.repos.Idempotency.PurgeExpiredKeys(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		p.logger.Printf("Purged %d expired idempotency keys", n)
+	}
+	return nil
+}
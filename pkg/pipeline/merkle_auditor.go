@@ -0,0 +1,340 @@
+// Copyright 2025 Certen Protocol
+//
+// Merkle Auditor
+// Background job that checks every sealed batch's Merkle data for consistency
+//
+// For each batch that is closed or later, the auditor rebuilds the tree from
+// the batch_transactions leaf hashes and compares the result with:
+// - anchor_batches.merkle_root
+// - anchor_records.merkle_root of every anchor for the batch
+// - proof_artifacts.merkle_root and leaf_hash of every proof in the batch
+// - batch_transactions.merkle_path of every leaf
+//
+// Discrepancies are recorded in merkle_audit_findings. Findings that are not
+// re-detected by a later run are marked resolved.
+
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// MerkleAuditorConfig contains configuration for the Merkle auditor
+type MerkleAuditorConfig struct {
+	Interval time.Duration // Time between audit runs
+	PageSize int           // Batches loaded per query
+}
+
+// MerkleAuditor periodically verifies stored Merkle roots and paths
+type MerkleAuditor struct {
+	repos  *database.Repositories
+	config *MerkleAuditorConfig
+	logger *log.Logger
+
+	// mu prevents overlapping runs within this process
+	mu sync.Mutex
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewMerkleAuditor creates a new Merkle auditor
+func NewMerkleAuditor(
+	repos *database.Repositories,
+	config *MerkleAuditorConfig,
+	logger *log.Logger,
+) *MerkleAuditor {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[MerkleAuditor] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &MerkleAuditorConfig{}
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 100
+	}
+
+	return &MerkleAuditor{
+		repos:  repos,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Start launches the audit loop in the background
+func (a *MerkleAuditor) Start() {
+	a.stopCh = make(chan struct{})
+	a.wg.Add(1)
+	go a.run()
+}
+
+// Stop signals the audit loop to exit and waits for the current run to finish
+func (a *MerkleAuditor) Stop() {
+	if a.stopCh != nil {
+		close(a.stopCh)
+	}
+	a.wg.Wait()
+}
+
+func (a *MerkleAuditor) run() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), a.config.Interval)
+			if err := a.ProcessOnce(ctx); err != nil {
+				a.logger.Printf("Merkle audit failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ProcessOnce performs a single audit run over all sealed batches
+func (a *MerkleAuditor) ProcessOnce(ctx context.Context) error {
+	_, err := a.RunAudit(ctx)
+	return err
+}
+
+// RunAudit audits all sealed batches and returns the recorded run
+func (a *MerkleAuditor) RunAudit(ctx context.Context) (*database.MerkleAuditRun, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	run, err := a.repos.MerkleAudit.CreateRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	auditErr := a.auditAll(ctx, run)
+
+	completed := time.Now()
+	run.CompletedAt = &completed
+	run.Status = database.MerkleAuditRunCompleted
+	if auditErr != nil {
+		msg := auditErr.Error()
+		run.Status = database.MerkleAuditRunFailed
+		run.ErrorMessage = &msg
+	}
+
+	if err := a.repos.MerkleAudit.FinishRun(ctx, run); err != nil {
+		return run, err
+	}
+	if auditErr != nil {
+		return run, auditErr
+	}
+
+	if run.FindingsOpened > 0 || run.FindingsResolved > 0 {
+		a.logger.Printf("Merkle audit checked %d batches: %d findings opened, %d resolved",
+			run.BatchesChecked, run.FindingsOpened, run.FindingsResolved)
+	}
+	return run, nil
+}
+
+func (a *MerkleAuditor) auditAll(ctx context.Context, run *database.MerkleAuditRun) error {
+	var afterCreated time.Time
+	var afterID uuid.UUID
+
+	for {
+		batches, err := a.repos.Batches.GetSealedBatches(ctx, afterCreated, afterID, a.config.PageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, batch := range batches {
+			opened, resolved, err := a.auditBatch(ctx, run.RunID, batch)
+			if err != nil {
+				return fmt.Errorf("failed to audit batch %s: %w", batch.BatchID, err)
+			}
+			run.BatchesChecked++
+			run.FindingsOpened += opened
+			run.FindingsResolved += resolved
+		}
+
+		if len(batches) < a.config.PageSize {
+			return nil
+		}
+		last := batches[len(batches)-1]
+		afterCreated, afterID = last.CreatedAt, last.BatchID
+	}
+}
+
+func (a *MerkleAuditor) auditBatch(ctx context.Context, runID uuid.UUID, batch *database.AnchorBatch) (int, int, error) {
+	txs, err := a.repos.Batches.GetTransactionsInBatch(ctx, batch.BatchID)
+	if err != nil {
+		return 0, 0, err
+	}
+	anchors, err := a.repos.Anchors.GetAnchorsByBatchID(ctx, batch.BatchID)
+	if err != nil {
+		return 0, 0, err
+	}
+	proofs, err := a.repos.ProofArtifacts.GetProofsByBatch(ctx, batch.BatchID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	opened := 0
+	for _, finding := range CheckBatchConsistency(batch, txs, anchors, proofs) {
+		isNew, err := a.repos.MerkleAudit.RecordFinding(ctx, runID, finding)
+		if err != nil {
+			return 0, 0, err
+		}
+		if isNew {
+			opened++
+			a.logger.Printf("Batch %s: %s (%s)", batch.BatchID, finding.FindingType, finding.Subject)
+		}
+	}
+
+	resolved, err := a.repos.MerkleAudit.ResolveStaleFindings(ctx, batch.BatchID, runID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return opened, resolved, nil
+}
+
+// CheckBatchConsistency rebuilds a batch's tree from its transactions and
+// returns a finding for every stored root, leaf hash or path that disagrees.
+// txs must be ordered by tree index.
+func CheckBatchConsistency(
+	batch *database.AnchorBatch,
+	txs []*database.BatchTransaction,
+	anchors []*database.AnchorRecord,
+	proofs []database.ProofArtifact,
+) []*database.NewMerkleAuditFinding {
+	tree, err := merkle.TreeFromTransactions(txs)
+	if err != nil {
+		// Nothing else can be compared without a tree
+		return []*database.NewMerkleAuditFinding{{
+			BatchID:     batch.BatchID,
+			FindingType: database.MerkleFindingTreeRebuildFailed,
+			Subject:     "batch",
+			ActualValue: hex.EncodeToString(batch.MerkleRoot),
+			Details:     err.Error(),
+		}}
+	}
+
+	root := tree.Root()
+	expected := hex.EncodeToString(root)
+	var findings []*database.NewMerkleAuditFinding
+
+	if !bytes.Equal(batch.MerkleRoot, root) {
+		findings = append(findings, &database.NewMerkleAuditFinding{
+			BatchID:       batch.BatchID,
+			FindingType:   database.MerkleFindingBatchRootMismatch,
+			Subject:       "batch",
+			ExpectedValue: expected,
+			ActualValue:   hex.EncodeToString(batch.MerkleRoot),
+		})
+	}
+
+	for _, anchor := range anchors {
+		if bytes.Equal(anchor.MerkleRoot, root) {
+			continue
+		}
+		anchorID := anchor.AnchorID
+		findings = append(findings, &database.NewMerkleAuditFinding{
+			BatchID:       batch.BatchID,
+			FindingType:   database.MerkleFindingAnchorRootMismatch,
+			Subject:       "anchor:" + anchorID.String(),
+			AnchorID:      &anchorID,
+			ExpectedValue: expected,
+			ActualValue:   hex.EncodeToString(anchor.MerkleRoot),
+			Details:       fmt.Sprintf("anchor tx %s", anchor.AnchorTxHash),
+		})
+	}
+
+	for i := range proofs {
+		proof := &proofs[i]
+		proofID := proof.ProofID
+
+		if len(proof.MerkleRoot) > 0 && !bytes.Equal(proof.MerkleRoot, root) {
+			findings = append(findings, &database.NewMerkleAuditFinding{
+				BatchID:       batch.BatchID,
+				FindingType:   database.MerkleFindingProofRootMismatch,
+				Subject:       "proof:" + proofID.String(),
+				ProofID:       &proofID,
+				ExpectedValue: expected,
+				ActualValue:   hex.EncodeToString(proof.MerkleRoot),
+			})
+		}
+
+		if proof.LeafIndex == nil || len(proof.LeafHash) == 0 {
+			continue
+		}
+		index := *proof.LeafIndex
+		if index < 0 || index >= len(txs) {
+			findings = append(findings, &database.NewMerkleAuditFinding{
+				BatchID:     batch.BatchID,
+				FindingType: database.MerkleFindingProofLeafMismatch,
+				Subject:     "proof:" + proofID.String(),
+				ProofID:     &proofID,
+				TreeIndex:   &index,
+				ActualValue: hex.EncodeToString(proof.LeafHash),
+				Details:     fmt.Sprintf("leaf index %d outside batch of %d", index, len(txs)),
+			})
+			continue
+		}
+		if !bytes.Equal(proof.LeafHash, txs[index].TxHash) {
+			findings = append(findings, &database.NewMerkleAuditFinding{
+				BatchID:       batch.BatchID,
+				FindingType:   database.MerkleFindingProofLeafMismatch,
+				Subject:       "proof:" + proofID.String(),
+				ProofID:       &proofID,
+				TreeIndex:     &index,
+				ExpectedValue: hex.EncodeToString(txs[index].TxHash),
+				ActualValue:   hex.EncodeToString(proof.LeafHash),
+			})
+		}
+	}
+
+	for i, tx := range txs {
+		fresh, err := tree.Path(i)
+		if err != nil {
+			continue
+		}
+		stored, parseErr := merkle.ParseStoredPath(tx.MerklePath)
+		if parseErr == nil && merkle.PathsEqual(stored, fresh) {
+			continue
+		}
+
+		index := i
+		freshJSON, _ := json.Marshal(fresh)
+		finding := &database.NewMerkleAuditFinding{
+			BatchID:       batch.BatchID,
+			FindingType:   database.MerkleFindingLeafPathMismatch,
+			Subject:       fmt.Sprintf("leaf:%d", i),
+			TreeIndex:     &index,
+			ExpectedValue: string(freshJSON),
+			ActualValue:   string(tx.MerklePath),
+			Details:       fmt.Sprintf("accumulate tx %s", tx.AccumTxHash),
+		}
+		if parseErr != nil {
+			finding.Details = parseErr.Error()
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
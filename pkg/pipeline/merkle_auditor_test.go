@@ -0,0 +1,122 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the Merkle consistency checks
+
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// consistentBatch builds a batch of n transactions with correct roots and paths
+func consistentBatch(t *testing.T, n int) (*database.AnchorBatch, []*database.BatchTransaction, []database.ProofArtifact) {
+	t.Helper()
+
+	batchID := uuid.New()
+	txs := make([]*database.BatchTransaction, n)
+	for i := range txs {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("audit-tx-%d", i)))
+		txs[i] = &database.BatchTransaction{BatchID: batchID, TreeIndex: i, TxHash: sum[:]}
+	}
+
+	tree, err := merkle.TreeFromTransactions(txs)
+	if err != nil {
+		t.Fatalf("TreeFromTransactions failed: %v", err)
+	}
+
+	proofs := make([]database.ProofArtifact, n)
+	for i, tx := range txs {
+		path, _ := tree.Path(i)
+		tx.MerklePath, _ = json.Marshal(path)
+
+		index := i
+		proofs[i] = database.ProofArtifact{
+			ProofID:    uuid.New(),
+			BatchID:    &batchID,
+			MerkleRoot: tree.Root(),
+			LeafHash:   tx.TxHash,
+			LeafIndex:  &index,
+		}
+	}
+
+	batch := &database.AnchorBatch{BatchID: batchID, MerkleRoot: tree.Root(), Status: database.BatchStatusClosed}
+	return batch, txs, proofs
+}
+
+func findingTypes(findings []*database.NewMerkleAuditFinding) map[database.MerkleFindingType]int {
+	counts := make(map[database.MerkleFindingType]int)
+	for _, f := range findings {
+		counts[f.FindingType]++
+	}
+	return counts
+}
+
+func TestCheckBatchConsistency_Clean(t *testing.T) {
+	batch, txs, proofs := consistentBatch(t, 5)
+	anchors := []*database.AnchorRecord{{AnchorID: uuid.New(), MerkleRoot: batch.MerkleRoot}}
+
+	if findings := CheckBatchConsistency(batch, txs, anchors, proofs); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %d: %+v", len(findings), findings[0])
+	}
+}
+
+func TestCheckBatchConsistency_RootCopiesDiverge(t *testing.T) {
+	batch, txs, proofs := consistentBatch(t, 4)
+	wrong := sha256.Sum256([]byte("wrong"))
+
+	anchors := []*database.AnchorRecord{{AnchorID: uuid.New(), MerkleRoot: wrong[:]}}
+	proofs[1].MerkleRoot = wrong[:]
+	batch.MerkleRoot = wrong[:]
+
+	counts := findingTypes(CheckBatchConsistency(batch, txs, anchors, proofs))
+	if counts[database.MerkleFindingBatchRootMismatch] != 1 {
+		t.Errorf("Expected 1 batch root finding, got %d", counts[database.MerkleFindingBatchRootMismatch])
+	}
+	if counts[database.MerkleFindingAnchorRootMismatch] != 1 {
+		t.Errorf("Expected 1 anchor root finding, got %d", counts[database.MerkleFindingAnchorRootMismatch])
+	}
+	if counts[database.MerkleFindingProofRootMismatch] != 1 {
+		t.Errorf("Expected 1 proof root finding, got %d", counts[database.MerkleFindingProofRootMismatch])
+	}
+}
+
+func TestCheckBatchConsistency_LeafMismatches(t *testing.T) {
+	batch, txs, proofs := consistentBatch(t, 4)
+
+	// Placeholder path left behind by an interrupted close
+	txs[2].MerklePath = json.RawMessage(`[]`)
+	proofs[3].LeafHash = txs[0].TxHash
+
+	findings := CheckBatchConsistency(batch, txs, nil, proofs)
+	counts := findingTypes(findings)
+	if counts[database.MerkleFindingLeafPathMismatch] != 1 {
+		t.Errorf("Expected 1 leaf path finding, got %d", counts[database.MerkleFindingLeafPathMismatch])
+	}
+	if counts[database.MerkleFindingProofLeafMismatch] != 1 {
+		t.Errorf("Expected 1 proof leaf finding, got %d", counts[database.MerkleFindingProofLeafMismatch])
+	}
+
+	for _, f := range findings {
+		if f.FindingType == database.MerkleFindingLeafPathMismatch && f.Subject != "leaf:2" {
+			t.Errorf("Expected subject 'leaf:2', got '%s'", f.Subject)
+		}
+	}
+}
+
+func TestCheckBatchConsistency_IndexGap(t *testing.T) {
+	batch, txs, proofs := consistentBatch(t, 3)
+	txs = append(txs[:1], txs[2:]...)
+
+	findings := CheckBatchConsistency(batch, txs, nil, proofs)
+	if len(findings) != 1 || findings[0].FindingType != database.MerkleFindingTreeRebuildFailed {
+		t.Errorf("Expected a single tree_rebuild_failed finding, got %+v", findings)
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2025 Certen Protocol
+//
+// Integration tests for the proof pipeline
+// Runs request -> batch -> anchor -> completed proof against the simulated chain.
+// Requires CERTEN_TEST_DB; skipped otherwise.
+
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/config"
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+func testRepos(t *testing.T) (*database.Client, *database.Repositories) {
+	t.Helper()
+
+	connStr := os.Getenv("CERTEN_TEST_DB")
+	if connStr == "" {
+		t.Skip("Test database not configured")
+	}
+
+	client, err := database.NewClient(&config.Config{
+		DatabaseURL:         connStr,
+		DatabaseMaxConns:    5,
+		DatabaseMinConns:    1,
+		DatabaseMaxIdleTime: 60,
+		DatabaseMaxLifetime: 300,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client, database.NewRepositories(client)
+}
+
+func randomTxHash() string {
+	sum := sha256.Sum256([]byte(uuid.New().String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPipeline_RequestToAnchoredProof(t *testing.T) {
+	client, repos := testRepos(t)
+	ctx := context.Background()
+	validatorID := "test-pipeline-" + uuid.New().String()[:8]
+
+	// 1. Request proofs for two transactions that have not been batched yet
+	var requests []*database.ProofRequest
+	var leaves [][]byte
+	for i := 0; i < 2; i++ {
+		hash := randomTxHash()
+		request, err := repos.Requests.CreateRequest(ctx, &database.NewProofRequest{
+			AccumTxHash: hash,
+			AccountURL:  "acc://pipeline-test.acme/tokens",
+			RequestType: database.RequestTypeOnDemand,
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest failed: %v", err)
+		}
+		leaf, _ := hex.DecodeString(hash)
+		requests = append(requests, request)
+		leaves = append(leaves, leaf)
+	}
+	accumTxHash := requests[0].AccumTxHash.String
+
+	// 2. The processor batches both; MaxBatchSize 2 closes the batch
+	batcher := NewBatcher(repos, &BatcherConfig{ValidatorID: validatorID, MaxBatchSize: 2}, nil)
+	processor := NewRequestProcessor(repos, &RequestProcessorConfig{Batcher: batcher}, nil)
+	if err := processor.ProcessOnce(ctx); err != nil {
+		t.Fatalf("ProcessOnce failed: %v", err)
+	}
+
+	stored, err := repos.Batches.GetTransactionByAccumHash(ctx, accumTxHash)
+	if err != nil {
+		t.Fatalf("GetTransactionByAccumHash failed: %v", err)
+	}
+	batchID := stored.BatchID
+	defer func() {
+		for _, request := range requests {
+			_, _ = client.ExecContext(ctx, "DELETE FROM proof_requests WHERE request_id = $1", request.RequestID)
+		}
+		_, _ = client.ExecContext(ctx, "DELETE FROM proof_artifacts WHERE batch_id = $1", batchID)
+		_, _ = client.ExecContext(ctx, "DELETE FROM anchor_batches WHERE batch_id = $1", batchID)
+	}()
+
+	for _, request := range requests {
+		current, _ := repos.Requests.GetRequest(ctx, request.RequestID)
+		if current.Status != database.RequestStatusBatched {
+			t.Fatalf("Expected batched request, got %s", current.Status)
+		}
+	}
+
+	batch, err := repos.Batches.GetBatch(ctx, batchID)
+	if err != nil {
+		t.Fatalf("GetBatch failed: %v", err)
+	}
+	if batch.Status != database.BatchStatusClosed {
+		t.Fatalf("Expected closed batch, got %s", batch.Status)
+	}
+
+	tree, _ := merkle.NewTree(leaves)
+	if hex.EncodeToString(batch.MerkleRoot) != hex.EncodeToString(tree.Root()) {
+		t.Errorf("Batch root %x does not match tree root %x", batch.MerkleRoot, tree.Root())
+	}
+	stored, _ = repos.Batches.GetTransactionByAccumHash(ctx, accumTxHash)
+	var path []database.MerklePathNode
+	if err := json.Unmarshal(stored.MerklePath, &path); err != nil {
+		t.Fatalf("Failed to decode stored path: %v", err)
+	}
+	if !merkle.VerifyPath(leaves[0], path, batch.MerkleRoot) {
+		t.Error("Stored merkle path does not verify against batch root")
+	}
+
+	// 3. No proof exists until the batch is anchored
+	if proof, _ := repos.ProofArtifacts.GetProofByTxHash(ctx, accumTxHash); proof != nil {
+		t.Fatal("Expected no proof artifact before anchoring")
+	}
+
+	// 4. Anchor the batch on the simulated chain and confirm it
+	chain := NewSimulatedChain(nil)
+	anchorer := NewAnchorer(repos, chain, &AnchorerConfig{ValidatorID: validatorID}, nil)
+
+	if err := anchorer.ProcessOnce(ctx); err != nil {
+		t.Fatalf("Anchorer ProcessOnce failed: %v", err)
+	}
+	batch, _ = repos.Batches.GetBatch(ctx, batchID)
+	if batch.Status != database.BatchStatusAnchored {
+		t.Fatalf("Expected anchored batch, got %s", batch.Status)
+	}
+
+	anchor, err := repos.Anchors.GetAnchorByBatchID(ctx, batchID)
+	if err != nil {
+		t.Fatalf("GetAnchorByBatchID failed: %v", err)
+	}
+	if !anchor.GasUsed.Valid || !anchor.TotalCostWei.Valid {
+		t.Error("Expected gas costs on anchor record")
+	}
+
+	chain.Mine(anchor.RequiredConfirms)
+	if err := anchorer.ProcessOnce(ctx); err != nil {
+		t.Fatalf("Anchorer ProcessOnce failed: %v", err)
+	}
+	batch, _ = repos.Batches.GetBatch(ctx, batchID)
+	if batch.Status != database.BatchStatusConfirmed {
+		t.Errorf("Expected confirmed batch, got %s", batch.Status)
+	}
+
+	// 5. The anchorer wrote a proof artifact for each batched transaction
+	proof, err := repos.ProofArtifacts.GetProofByTxHash(ctx, accumTxHash)
+	if err != nil || proof == nil {
+		t.Fatalf("Expected proof artifact for batched transaction, got %v", err)
+	}
+	if proof.BatchID == nil || *proof.BatchID != batchID {
+		t.Error("Expected proof to reference the batch")
+	}
+	if proof.Status != database.ProofStatusAnchored {
+		t.Errorf("Expected anchored proof, got %s", proof.Status)
+	}
+	if proof.AnchorTxHash == nil || *proof.AnchorTxHash != anchor.AnchorTxHash {
+		t.Error("Expected proof to reference the anchor transaction")
+	}
+
+	// 6. The processor completes the request against the proof
+	if err := processor.ProcessOnce(ctx); err != nil {
+		t.Fatalf("ProcessOnce failed: %v", err)
+	}
+	request, _ := repos.Requests.GetRequest(ctx, requests[0].RequestID)
+	if request.Status != database.RequestStatusCompleted {
+		t.Errorf("Expected completed request, got %s", request.Status)
+	}
+	if !request.ProofID.Valid || request.ProofID.UUID != proof.ProofID {
+		t.Error("Expected request to reference the proof")
+	}
+}
+
+func TestRequestProcessor_BatchesRequestedTransaction(t *testing.T) {
+	client, repos := testRepos(t)
+	ctx := context.Background()
+	validatorID := "test-intake-" + uuid.New().String()[:8]
+
+	accumTxHash := randomTxHash()
+	request, err := repos.Requests.CreateRequest(ctx, &database.NewProofRequest{
+		AccumTxHash: accumTxHash,
+		AccountURL:  "acc://pipeline-test.acme/tokens",
+		RequestType: database.RequestTypeOnCadence,
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+	defer func() {
+		_, _ = client.ExecContext(ctx, "DELETE FROM proof_requests WHERE request_id = $1", request.RequestID)
+		_, _ = client.ExecContext(ctx, "DELETE FROM anchor_batches WHERE validator_id = $1", validatorID)
+	}()
+
+	batcher := NewBatcher(repos, &BatcherConfig{ValidatorID: validatorID, MaxBatchSize: 1}, nil)
+	processor := NewRequestProcessor(repos, &RequestProcessorConfig{Batcher: batcher}, nil)
+	if err := processor.ProcessOnce(ctx); err != nil {
+		t.Fatalf("ProcessOnce failed: %v", err)
+	}
+
+	request, _ = repos.Requests.GetRequest(ctx, request.RequestID)
+	if request.Status != database.RequestStatusBatched {
+		t.Fatalf("Expected batched request, got %s", request.Status)
+	}
+
+	tx, err := repos.Batches.GetTransactionByAccumHash(ctx, accumTxHash)
+	if err != nil {
+		t.Fatalf("GetTransactionByAccumHash failed: %v", err)
+	}
+	if !request.BatchID.Valid || request.BatchID.UUID != tx.BatchID {
+		t.Error("Expected request to reference the transaction's batch")
+	}
+
+	batch, _ := repos.Batches.GetBatch(ctx, tx.BatchID)
+	if batch.BatchType != database.BatchTypeOnCadence {
+		t.Errorf("Expected on_cadence batch, got %s", batch.BatchType)
+	}
+	if batch.Status != database.BatchStatusClosed {
+		t.Errorf("Expected full batch to close, got %s", batch.Status)
+	}
+}
+
+func TestBatcher_CloseExpiredClosesFullBatch(t *testing.T) {
+	client, repos := testRepos(t)
+	ctx := context.Background()
+	validatorID := "test-full-" + uuid.New().String()[:8]
+	defer func() {
+		_, _ = client.ExecContext(ctx, "DELETE FROM anchor_batches WHERE validator_id = $1", validatorID)
+	}()
+
+	// A batch filled past MaxBatchSize by another writer closes on the next
+	// sweep even though its time window is still open
+	batch, err := repos.Batches.CreateBatch(ctx, &database.NewAnchorBatch{
+		BatchType:   database.BatchTypeOnCadence,
+		ValidatorID: validatorID,
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		hash := randomTxHash()
+		leaf, _ := hex.DecodeString(hash)
+		if _, err := repos.Batches.AddTransaction(ctx, &database.NewBatchTransaction{
+			BatchID:     batch.BatchID,
+			AccumTxHash: hash,
+			TreeIndex:   i,
+			MerklePath:  []database.MerklePathNode{},
+			TxHash:      leaf,
+		}); err != nil {
+			t.Fatalf("AddTransaction failed: %v", err)
+		}
+	}
+
+	batcher := NewBatcher(repos, &BatcherConfig{ValidatorID: validatorID, MaxBatchSize: 2}, nil)
+	if err := batcher.CloseExpired(ctx); err != nil {
+		t.Fatalf("CloseExpired failed: %v", err)
+	}
+
+	batch, _ = repos.Batches.GetBatch(ctx, batch.BatchID)
+	if batch.Status != database.BatchStatusClosed {
+		t.Errorf("Expected closed batch, got %s", batch.Status)
+	}
+}
+
+func TestAnchorer_RequeuesInterruptedSubmission(t *testing.T) {
+	client, repos := testRepos(t)
+	ctx := context.Background()
+	validatorID := "test-interrupted-" + uuid.New().String()[:8]
+	defer func() {
+		_, _ = client.ExecContext(ctx, "DELETE FROM anchor_batches WHERE validator_id = $1", validatorID)
+	}()
+
+	batcher := NewBatcher(repos, &BatcherConfig{ValidatorID: validatorID, MaxBatchSize: 1}, nil)
+	hash := randomTxHash()
+	leaf, _ := hex.DecodeString(hash)
+	tx, err := batcher.AddTransaction(ctx, database.BatchTypeOnDemand, &database.NewBatchTransaction{
+		AccumTxHash: hash,
+		TxHash:      leaf,
+	})
+	if err != nil {
+		t.Fatalf("AddTransaction failed: %v", err)
+	}
+
+	// A claim with no anchor record is what a crash mid-submission leaves behind
+	attempts, err := repos.Batches.ClaimBatchForAnchoring(ctx, tx.BatchID)
+	if err != nil {
+		t.Fatalf("ClaimBatchForAnchoring failed: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+	if _, err := repos.Batches.ClaimBatchForAnchoring(ctx, tx.BatchID); err != database.ErrBatchNotFound {
+		t.Errorf("Expected ErrBatchNotFound claiming an anchoring batch, got %v", err)
+	}
+
+	anchorer := NewAnchorer(repos, NewSimulatedChain(nil), &AnchorerConfig{
+		ValidatorID:      validatorID,
+		InterruptedAfter: time.Nanosecond,
+	}, nil)
+	if err := anchorer.ProcessOnce(ctx); err != nil {
+		t.Fatalf("Anchorer ProcessOnce failed: %v", err)
+	}
+
+	if _, err := repos.Anchors.GetAnchorByBatchID(ctx, tx.BatchID); err != nil {
+		t.Fatalf("Expected requeued batch to be anchored: %v", err)
+	}
+
+	var stored int
+	if err := client.QueryRowContext(ctx, "SELECT anchor_attempts FROM anchor_batches WHERE batch_id = $1", tx.BatchID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read anchor attempts: %v", err)
+	}
+	if stored != 2 {
+		t.Errorf("Expected 2 stored attempts, got %d", stored)
+	}
+}
@@ -0,0 +1,440 @@
+// Copyright 2025 Certen Protocol
+//
+// Proof Request Processor
+// Background worker that drains the proof_requests queue
+//
+// Each tick the processor:
+// - Pulls pending on-demand requests, then pending on-cadence requests
+// - Holds back requests younger than their tier's batch_delay_seconds
+// - Resolves each request against existing proofs and batch transactions
+// - Adds transactions not yet in a batch to the open batch of the request's type
+// - Completes batched requests once their proof exists, or fails them if the batch failed
+
+package pipeline
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// RequestProcessorConfig contains configuration for the request processor
+type RequestProcessorConfig struct {
+	PollInterval   time.Duration // Time between queue sweeps
+	BatchSize      int           // Requests pulled per request type per sweep
+	RequestTimeout time.Duration // How long a request may wait for its transaction before failing
+	TierCacheTTL   time.Duration // How long pricing tier delays are cached
+
+	// Batcher receives requested transactions that are not yet in a batch.
+	// Without one the processor waits for batch rows written elsewhere.
+	Batcher TransactionBatcher
+}
+
+// TransactionBatcher accepts transactions into anchor batches
+type TransactionBatcher interface {
+	AddTransaction(ctx context.Context, batchType database.BatchType, input *database.NewBatchTransaction) (*database.BatchTransaction, error)
+}
+
+// requestQueue is implemented by database.RequestRepository
+type requestQueue interface {
+	GetPendingOnDemandRequests(ctx context.Context, limit int) ([]*database.ProofRequest, error)
+	GetPendingOnCadenceRequests(ctx context.Context, limit int) ([]*database.ProofRequest, error)
+	GetBatchedRequests(ctx context.Context, limit int) ([]*database.ProofRequest, error)
+	CountPendingByType(ctx context.Context, requestType database.RequestType) (int64, error)
+	GetPricingTier(ctx context.Context, requestType database.RequestType) (*database.ProofPricingTier, error)
+	MarkProcessing(ctx context.Context, requestID uuid.UUID) error
+	MarkBatched(ctx context.Context, requestID uuid.UUID, batchID uuid.UUID) error
+	MarkCompleted(ctx context.Context, requestID uuid.UUID, proofID uuid.UUID) error
+	MarkFailed(ctx context.Context, requestID uuid.UUID, errorMsg string) error
+	UpdateRequestStatus(ctx context.Context, requestID uuid.UUID, status database.RequestStatus, errorMsg string) error
+}
+
+// batchLookup is implemented by database.BatchRepository
+type batchLookup interface {
+	GetBatch(ctx context.Context, batchID uuid.UUID) (*database.AnchorBatch, error)
+	GetTransactionByAccumHash(ctx context.Context, accumTxHash string) (*database.BatchTransaction, error)
+}
+
+// artifactLookup is implemented by database.ProofArtifactRepository
+type artifactLookup interface {
+	GetProofByTxHash(ctx context.Context, txHash string) (*database.ProofArtifact, error)
+	GetProofsByAccount(ctx context.Context, accountURL string, limit, offset int) ([]database.ProofSummary, error)
+}
+
+// legacyProofLookup is implemented by database.ProofRepository
+type legacyProofLookup interface {
+	GetProofByAccumTxHash(ctx context.Context, accumTxHash string) (*database.CertenAnchorProof, error)
+}
+
+// RequestProcessor resolves proof requests into completed proofs
+type RequestProcessor struct {
+	requests  requestQueue
+	batches   batchLookup
+	artifacts artifactLookup
+	legacy    legacyProofLookup
+	config    *RequestProcessorConfig
+	logger    *log.Logger
+
+	tierMu    sync.Mutex
+	tierDelay map[database.RequestType]time.Duration
+	tierAt    time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewRequestProcessor creates a new request processor
+func NewRequestProcessor(
+	repos *database.Repositories,
+	config *RequestProcessorConfig,
+	logger *log.Logger,
+) *RequestProcessor {
+	return newRequestProcessor(repos.Requests, repos.Batches, repos.ProofArtifacts, repos.Proofs, config, logger)
+}
+
+func newRequestProcessor(
+	requests requestQueue,
+	batches batchLookup,
+	artifacts artifactLookup,
+	legacy legacyProofLookup,
+	config *RequestProcessorConfig,
+	logger *log.Logger,
+) *RequestProcessor {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[RequestProcessor] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &RequestProcessorConfig{}
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = time.Hour
+	}
+	if config.TierCacheTTL <= 0 {
+		config.TierCacheTTL = time.Minute
+	}
+
+	return &RequestProcessor{
+		requests:  requests,
+		batches:   batches,
+		artifacts: artifacts,
+		legacy:    legacy,
+		config:    config,
+		logger:    logger,
+		tierDelay: make(map[database.RequestType]time.Duration),
+	}
+}
+
+// Start launches the processing loop in the background
+func (p *RequestProcessor) Start() {
+	p.stopCh = make(chan struct{})
+	p.wg.Add(1)
+	go p.run()
+}
+
+// Stop signals the processing loop to exit and waits for the current sweep to finish
+func (p *RequestProcessor) Stop() {
+	if p.stopCh != nil {
+		close(p.stopCh)
+	}
+	p.wg.Wait()
+}
+
+func (p *RequestProcessor) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.config.PollInterval*4)
+			if err := p.ProcessOnce(ctx); err != nil {
+				p.logger.Printf("Request sweep failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ProcessOnce performs a single sweep of the request queue
+func (p *RequestProcessor) ProcessOnce(ctx context.Context) error {
+	now := time.Now()
+
+	// On-demand requests are always drained before on-cadence requests
+	onDemand, err := p.requests.GetPendingOnDemandRequests(ctx, p.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	onDemandDelay := p.batchDelay(ctx, database.RequestTypeOnDemand)
+	for _, req := range onDemand {
+		if now.Sub(req.RequestedAt) < onDemandDelay {
+			continue
+		}
+		p.processRequest(ctx, req, now)
+	}
+
+	onCadence, err := p.requests.GetPendingOnCadenceRequests(ctx, p.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	onCadenceDelay := p.batchDelay(ctx, database.RequestTypeOnCadence)
+	for _, req := range onCadence {
+		// Ordered by requested_at, so every later request is also still within its delay
+		if now.Sub(req.RequestedAt) < onCadenceDelay {
+			break
+		}
+		p.processRequest(ctx, req, now)
+	}
+
+	batched, err := p.requests.GetBatchedRequests(ctx, p.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, req := range batched {
+		p.processBatchedRequest(ctx, req)
+	}
+
+	return nil
+}
+
+// EstimateWait estimates how long a request of the given type submitted now
+// will wait before the processor resolves it, based on the current queue depth
+func (p *RequestProcessor) EstimateWait(ctx context.Context, requestType database.RequestType) (time.Duration, error) {
+	depth, err := p.requests.CountPendingByType(ctx, database.RequestTypeOnDemand)
+	if err != nil {
+		return 0, err
+	}
+
+	// On-cadence requests queue behind every pending on-demand request
+	if requestType == database.RequestTypeOnCadence {
+		cadenceDepth, err := p.requests.CountPendingByType(ctx, database.RequestTypeOnCadence)
+		if err != nil {
+			return 0, err
+		}
+		depth += cadenceDepth
+	}
+
+	sweeps := depth / int64(p.config.BatchSize)
+	wait := p.batchDelay(ctx, requestType) + time.Duration(sweeps+1)*p.config.PollInterval
+
+	return wait, nil
+}
+
+// =============================================================================
+// REQUEST RESOLUTION
+// =============================================================================
+
+// processRequest resolves a pending request against existing proofs and batch transactions
+func (p *RequestProcessor) processRequest(ctx context.Context, req *database.ProofRequest, now time.Time) {
+	if err := p.requests.MarkProcessing(ctx, req.RequestID); err != nil {
+		p.logger.Printf("Error marking request %s processing: %v", req.RequestID, err)
+		return
+	}
+
+	proofID, batchID, err := p.resolve(ctx, req)
+	if err != nil {
+		// Transient lookup failure: return the request to the queue
+		p.logger.Printf("Error resolving request %s: %v", req.RequestID, err)
+		if err := p.requests.UpdateRequestStatus(ctx, req.RequestID, database.RequestStatusPending, err.Error()); err != nil {
+			p.logger.Printf("Error requeueing request %s: %v", req.RequestID, err)
+		}
+		return
+	}
+
+	switch {
+	case proofID != uuid.Nil:
+		if err := p.requests.MarkCompleted(ctx, req.RequestID, proofID); err != nil {
+			p.logger.Printf("Error completing request %s: %v", req.RequestID, err)
+		}
+	case batchID != uuid.Nil:
+		if err := p.requests.MarkBatched(ctx, req.RequestID, batchID); err != nil {
+			p.logger.Printf("Error batching request %s: %v", req.RequestID, err)
+		}
+	case now.Sub(req.RequestedAt) >= p.config.RequestTimeout:
+		reason := fmt.Sprintf("no batch transaction or proof found for %s within %s", describeTarget(req), p.config.RequestTimeout)
+		if err := p.requests.MarkFailed(ctx, req.RequestID, reason); err != nil {
+			p.logger.Printf("Error failing request %s: %v", req.RequestID, err)
+		}
+	default:
+		// Transaction not seen yet: leave it queued for the next sweep
+		if err := p.requests.UpdateRequestStatus(ctx, req.RequestID, database.RequestStatusPending, ""); err != nil {
+			p.logger.Printf("Error requeueing request %s: %v", req.RequestID, err)
+		}
+	}
+}
+
+// processBatchedRequest completes a batched request once its proof exists
+func (p *RequestProcessor) processBatchedRequest(ctx context.Context, req *database.ProofRequest) {
+	proofID, err := p.findProof(ctx, req)
+	if err != nil {
+		p.logger.Printf("Error looking up proof for request %s: %v", req.RequestID, err)
+		return
+	}
+	if proofID != uuid.Nil {
+		if err := p.requests.MarkCompleted(ctx, req.RequestID, proofID); err != nil {
+			p.logger.Printf("Error completing request %s: %v", req.RequestID, err)
+		}
+		return
+	}
+
+	if !req.BatchID.Valid {
+		return
+	}
+	batch, err := p.batches.GetBatch(ctx, req.BatchID.UUID)
+	if err != nil {
+		p.logger.Printf("Error loading batch %s for request %s: %v", req.BatchID.UUID, req.RequestID, err)
+		return
+	}
+	if batch.Status == database.BatchStatusFailed {
+		reason := fmt.Sprintf("batch %s failed", batch.BatchID)
+		if batch.ErrorMessage.Valid {
+			reason = fmt.Sprintf("%s: %s", reason, batch.ErrorMessage.String)
+		}
+		if err := p.requests.MarkFailed(ctx, req.RequestID, reason); err != nil {
+			p.logger.Printf("Error failing request %s: %v", req.RequestID, err)
+		}
+	}
+}
+
+// resolve returns the proof that satisfies a request, or the batch that will produce it.
+// Both IDs are uuid.Nil when neither exists yet.
+func (p *RequestProcessor) resolve(ctx context.Context, req *database.ProofRequest) (proofID, batchID uuid.UUID, err error) {
+	proofID, err = p.findProof(ctx, req)
+	if err != nil || proofID != uuid.Nil {
+		return proofID, uuid.Nil, err
+	}
+
+	if !req.AccumTxHash.Valid {
+		return uuid.Nil, uuid.Nil, nil
+	}
+
+	tx, err := p.batches.GetTransactionByAccumHash(ctx, req.AccumTxHash.String)
+	if errors.Is(err, database.ErrTransactionNotFound) {
+		return p.batchTransaction(ctx, req)
+	}
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return uuid.Nil, tx.BatchID, nil
+}
+
+// batchTransaction adds a request's transaction to the open batch of the
+// request's type. Hashes that are not hex SHA-256 digests are left for
+// another writer to batch.
+func (p *RequestProcessor) batchTransaction(ctx context.Context, req *database.ProofRequest) (proofID, batchID uuid.UUID, err error) {
+	if p.config.Batcher == nil {
+		return uuid.Nil, uuid.Nil, nil
+	}
+	txHash, err := hex.DecodeString(req.AccumTxHash.String)
+	if err != nil || len(txHash) != merkle.HashSize {
+		return uuid.Nil, uuid.Nil, nil
+	}
+
+	batchType := database.BatchTypeOnCadence
+	if req.RequestType == database.RequestTypeOnDemand {
+		batchType = database.BatchTypeOnDemand
+	}
+
+	tx, err := p.config.Batcher.AddTransaction(ctx, batchType, &database.NewBatchTransaction{
+		AccumTxHash: req.AccumTxHash.String,
+		AccountURL:  req.AccountURL.String,
+		TxHash:      txHash,
+	})
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to batch transaction: %w", err)
+	}
+
+	return uuid.Nil, tx.BatchID, nil
+}
+
+// findProof looks for an existing proof artifact (or legacy anchor proof) for a request
+func (p *RequestProcessor) findProof(ctx context.Context, req *database.ProofRequest) (uuid.UUID, error) {
+	if req.AccumTxHash.Valid {
+		artifact, err := p.artifacts.GetProofByTxHash(ctx, req.AccumTxHash.String)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if artifact != nil {
+			return artifact.ProofID, nil
+		}
+
+		legacy, err := p.legacy.GetProofByAccumTxHash(ctx, req.AccumTxHash.String)
+		if errors.Is(err, database.ErrProofNotFound) {
+			return uuid.Nil, nil
+		}
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return legacy.ProofID, nil
+	}
+
+	if req.AccountURL.Valid {
+		// Account-level requests are satisfied by the newest proof for the account
+		summaries, err := p.artifacts.GetProofsByAccount(ctx, req.AccountURL.String, 1, 0)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if len(summaries) > 0 {
+			return summaries[0].ProofID, nil
+		}
+	}
+
+	return uuid.Nil, nil
+}
+
+// batchDelay returns the batch delay for a request type from proof_pricing_tiers
+func (p *RequestProcessor) batchDelay(ctx context.Context, requestType database.RequestType) time.Duration {
+	p.tierMu.Lock()
+	defer p.tierMu.Unlock()
+
+	if time.Since(p.tierAt) > p.config.TierCacheTTL {
+		p.tierDelay = make(map[database.RequestType]time.Duration)
+		p.tierAt = time.Now()
+	}
+
+	if delay, ok := p.tierDelay[requestType]; ok {
+		return delay
+	}
+
+	tier, err := p.requests.GetPricingTier(ctx, requestType)
+	if err != nil {
+		if !errors.Is(err, database.ErrPricingTierNotFound) {
+			// Don't cache lookup failures; retry on the next sweep
+			p.logger.Printf("Error loading pricing tier %s: %v", requestType, err)
+			return 0
+		}
+		p.tierDelay[requestType] = 0
+		return 0
+	}
+
+	delay := time.Duration(tier.BatchDelaySeconds) * time.Second
+	p.tierDelay[requestType] = delay
+	return delay
+}
+
+// describeTarget formats the transaction or account a request refers to
+func describeTarget(req *database.ProofRequest) string {
+	if req.AccumTxHash.Valid {
+		return "accum_tx_hash " + req.AccumTxHash.String
+	}
+	return "account_url " + req.AccountURL.String
+}
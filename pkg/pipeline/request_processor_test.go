@@ -0,0 +1,273 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the proof request processor
+
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// memoryRequestQueue serves fixed queue contents and records each transition
+type memoryRequestQueue struct {
+	onDemand  []*database.ProofRequest
+	onCadence []*database.ProofRequest
+	depth     map[database.RequestType]int64
+	delay     map[database.RequestType]int
+
+	events []string
+}
+
+func (q *memoryRequestQueue) record(event string, requestID uuid.UUID) error {
+	q.events = append(q.events, event+" "+requestID.String())
+	return nil
+}
+
+func (q *memoryRequestQueue) GetPendingOnDemandRequests(ctx context.Context, limit int) ([]*database.ProofRequest, error) {
+	return q.onDemand, nil
+}
+
+func (q *memoryRequestQueue) GetPendingOnCadenceRequests(ctx context.Context, limit int) ([]*database.ProofRequest, error) {
+	return q.onCadence, nil
+}
+
+func (q *memoryRequestQueue) GetBatchedRequests(ctx context.Context, limit int) ([]*database.ProofRequest, error) {
+	return nil, nil
+}
+
+func (q *memoryRequestQueue) CountPendingByType(ctx context.Context, requestType database.RequestType) (int64, error) {
+	return q.depth[requestType], nil
+}
+
+func (q *memoryRequestQueue) GetPricingTier(ctx context.Context, requestType database.RequestType) (*database.ProofPricingTier, error) {
+	delay, ok := q.delay[requestType]
+	if !ok {
+		return nil, database.ErrPricingTierNotFound
+	}
+	return &database.ProofPricingTier{TierID: string(requestType), BatchDelaySeconds: delay}, nil
+}
+
+func (q *memoryRequestQueue) MarkProcessing(ctx context.Context, requestID uuid.UUID) error {
+	return q.record("processing", requestID)
+}
+
+func (q *memoryRequestQueue) MarkBatched(ctx context.Context, requestID uuid.UUID, batchID uuid.UUID) error {
+	return q.record("batched", requestID)
+}
+
+func (q *memoryRequestQueue) MarkCompleted(ctx context.Context, requestID uuid.UUID, proofID uuid.UUID) error {
+	return q.record("completed", requestID)
+}
+
+func (q *memoryRequestQueue) MarkFailed(ctx context.Context, requestID uuid.UUID, errorMsg string) error {
+	return q.record("failed", requestID)
+}
+
+func (q *memoryRequestQueue) UpdateRequestStatus(ctx context.Context, requestID uuid.UUID, status database.RequestStatus, errorMsg string) error {
+	return q.record("requeued", requestID)
+}
+
+// noBatches has no batch transactions
+type noBatches struct{}
+
+func (noBatches) GetBatch(ctx context.Context, batchID uuid.UUID) (*database.AnchorBatch, error) {
+	return nil, database.ErrBatchNotFound
+}
+
+func (noBatches) GetTransactionByAccumHash(ctx context.Context, accumTxHash string) (*database.BatchTransaction, error) {
+	return nil, database.ErrTransactionNotFound
+}
+
+// artifactsByHash serves proof artifacts keyed by transaction hash
+type artifactsByHash map[string]uuid.UUID
+
+func (a artifactsByHash) GetProofByTxHash(ctx context.Context, txHash string) (*database.ProofArtifact, error) {
+	proofID, ok := a[txHash]
+	if !ok {
+		return nil, nil
+	}
+	return &database.ProofArtifact{ProofID: proofID, AccumTxHash: txHash}, nil
+}
+
+func (a artifactsByHash) GetProofsByAccount(ctx context.Context, accountURL string, limit, offset int) ([]database.ProofSummary, error) {
+	return nil, nil
+}
+
+// noLegacyProofs has no legacy anchor proofs
+type noLegacyProofs struct{}
+
+func (noLegacyProofs) GetProofByAccumTxHash(ctx context.Context, accumTxHash string) (*database.CertenAnchorProof, error) {
+	return nil, database.ErrProofNotFound
+}
+
+func queuedRequest(requestType database.RequestType, txHash string, age time.Duration) *database.ProofRequest {
+	return &database.ProofRequest{
+		RequestID:   uuid.New(),
+		AccumTxHash: sql.NullString{String: txHash, Valid: true},
+		RequestType: requestType,
+		Status:      database.RequestStatusPending,
+		RequestedAt: time.Now().Add(-age),
+	}
+}
+
+func TestProcessOnce_OnDemandBeforeOnCadence(t *testing.T) {
+	cadence := queuedRequest(database.RequestTypeOnCadence, "cadence-tx", time.Hour)
+	demand := queuedRequest(database.RequestTypeOnDemand, "demand-tx", time.Minute)
+	queue := &memoryRequestQueue{
+		onDemand:  []*database.ProofRequest{demand},
+		onCadence: []*database.ProofRequest{cadence},
+	}
+	artifacts := artifactsByHash{"cadence-tx": uuid.New(), "demand-tx": uuid.New()}
+
+	processor := newRequestProcessor(queue, noBatches{}, artifacts, noLegacyProofs{}, nil, nil)
+	if err := processor.ProcessOnce(context.Background()); err != nil {
+		t.Fatalf("ProcessOnce failed: %v", err)
+	}
+
+	want := []string{
+		"processing " + demand.RequestID.String(),
+		"completed " + demand.RequestID.String(),
+		"processing " + cadence.RequestID.String(),
+		"completed " + cadence.RequestID.String(),
+	}
+	if len(queue.events) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, queue.events)
+	}
+	for i := range want {
+		if queue.events[i] != want[i] {
+			t.Errorf("Event %d: expected %q, got %q", i, want[i], queue.events[i])
+		}
+	}
+}
+
+func TestProcessOnce_HoldsOnCadenceRequestsWithinDelay(t *testing.T) {
+	early := queuedRequest(database.RequestTypeOnCadence, "early-tx", time.Minute)
+	queue := &memoryRequestQueue{
+		onCadence: []*database.ProofRequest{early},
+		delay:     map[database.RequestType]int{database.RequestTypeOnCadence: 900},
+	}
+
+	processor := newRequestProcessor(queue, noBatches{}, artifactsByHash{}, noLegacyProofs{}, nil, nil)
+	if err := processor.ProcessOnce(context.Background()); err != nil {
+		t.Fatalf("ProcessOnce failed: %v", err)
+	}
+	if len(queue.events) != 0 {
+		t.Errorf("Expected request within its batch delay to be left alone, got %v", queue.events)
+	}
+}
+
+func TestProcessOnce_RequeuesUntilTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		age  time.Duration
+		want string
+	}{
+		{"within timeout", 10 * time.Minute, "requeued"},
+		{"past timeout", 2 * time.Hour, "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := queuedRequest(database.RequestTypeOnDemand, "unseen-tx", tt.age)
+			queue := &memoryRequestQueue{onDemand: []*database.ProofRequest{req}}
+
+			processor := newRequestProcessor(queue, noBatches{}, artifactsByHash{}, noLegacyProofs{},
+				&RequestProcessorConfig{RequestTimeout: time.Hour}, nil)
+			if err := processor.ProcessOnce(context.Background()); err != nil {
+				t.Fatalf("ProcessOnce failed: %v", err)
+			}
+
+			want := []string{"processing " + req.RequestID.String(), tt.want + " " + req.RequestID.String()}
+			if len(queue.events) != 2 || queue.events[0] != want[0] || queue.events[1] != want[1] {
+				t.Errorf("Expected events %v, got %v", want, queue.events)
+			}
+		})
+	}
+}
+
+func TestEstimateWait(t *testing.T) {
+	queue := &memoryRequestQueue{
+		depth: map[database.RequestType]int64{
+			database.RequestTypeOnDemand:  250,
+			database.RequestTypeOnCadence: 100,
+		},
+		delay: map[database.RequestType]int{
+			database.RequestTypeOnDemand:  10,
+			database.RequestTypeOnCadence: 60,
+		},
+	}
+	processor := newRequestProcessor(queue, noBatches{}, artifactsByHash{}, noLegacyProofs{},
+		&RequestProcessorConfig{PollInterval: 5 * time.Second, BatchSize: 100}, nil)
+
+	tests := []struct {
+		requestType database.RequestType
+		want        time.Duration
+	}{
+		// 250 queued: two full sweeps ahead, resolved on the third
+		{database.RequestTypeOnDemand, 10*time.Second + 3*5*time.Second},
+		// Queues behind all 350 pending requests
+		{database.RequestTypeOnCadence, 60*time.Second + 4*5*time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.requestType), func(t *testing.T) {
+			wait, err := processor.EstimateWait(context.Background(), tt.requestType)
+			if err != nil {
+				t.Fatalf("EstimateWait failed: %v", err)
+			}
+			if wait != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, wait)
+			}
+		})
+	}
+}
+
+// recordingBatcher places every transaction in one batch
+type recordingBatcher struct {
+	batchID uuid.UUID
+	added   map[string]database.BatchType
+}
+
+func (b *recordingBatcher) AddTransaction(ctx context.Context, batchType database.BatchType, input *database.NewBatchTransaction) (*database.BatchTransaction, error) {
+	b.added[input.AccumTxHash] = batchType
+	return &database.BatchTransaction{BatchID: b.batchID, AccumTxHash: input.AccumTxHash, TxHash: input.TxHash}, nil
+}
+
+func TestProcessOnce_BatchesUnseenTransactions(t *testing.T) {
+	hexHash := "5f2b4cd0e3a7c0a4c7b1bb6a3a1d3c8e0f9e2d4c6b8a0f1e3d5c7b9a1f3e5d7c"
+	hashed := queuedRequest(database.RequestTypeOnDemand, hexHash, time.Minute)
+	opaque := queuedRequest(database.RequestTypeOnCadence, "not-a-hash", time.Minute)
+	queue := &memoryRequestQueue{
+		onDemand:  []*database.ProofRequest{hashed},
+		onCadence: []*database.ProofRequest{opaque},
+	}
+	batcher := &recordingBatcher{batchID: uuid.New(), added: make(map[string]database.BatchType)}
+
+	processor := newRequestProcessor(queue, noBatches{}, artifactsByHash{}, noLegacyProofs{},
+		&RequestProcessorConfig{Batcher: batcher}, nil)
+	if err := processor.ProcessOnce(context.Background()); err != nil {
+		t.Fatalf("ProcessOnce failed: %v", err)
+	}
+
+	if batcher.added[hexHash] != database.BatchTypeOnDemand || len(batcher.added) != 1 {
+		t.Errorf("Expected only the hex hash in an on_demand batch, got %v", batcher.added)
+	}
+	want := []string{
+		"processing " + hashed.RequestID.String(),
+		"batched " + hashed.RequestID.String(),
+		"processing " + opaque.RequestID.String(),
+		"requeued " + opaque.RequestID.String(),
+	}
+	for i := range want {
+		if i >= len(queue.events) || queue.events[i] != want[i] {
+			t.Fatalf("Expected events %v, got %v", want, queue.events)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 Certen Protocol
+//
+// Simulated Anchor Chain
+// Deterministic in-process AnchorBackend for development and tests
+//
+// Every submission is mined into its own new block. Transaction and block
+// hashes are derived from the chain ID, block height and Merkle root, so the
+// same sequence of submissions always yields the same hashes. Blocks advance
+// only through Mine, or from the wall clock when BlockTime is set.
+
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// SimulatedChainConfig contains configuration for the simulated chain
+type SimulatedChainConfig struct {
+	TargetChain     database.TargetChain
+	ChainID         string
+	NetworkName     string
+	ContractAddress string
+	GasPerAnchor    int64
+	GasPriceWei     *big.Int
+	BlockTime       time.Duration // Zero disables clock-driven mining
+	GenesisTime     time.Time     // Timestamp of block 0
+}
+
+// SimulatedChain is a deterministic in-memory AnchorBackend
+type SimulatedChain struct {
+	config *SimulatedChainConfig
+
+	mu       sync.Mutex
+	height   int64
+	lastTick time.Time
+	txBlocks map[string]int64
+	now      func() time.Time
+}
+
+// NewSimulatedChain creates a new simulated chain at height 0
+func NewSimulatedChain(config *SimulatedChainConfig) *SimulatedChain {
+	if config == nil {
+		config = &SimulatedChainConfig{}
+	}
+	if config.TargetChain == "" {
+		config.TargetChain = database.TargetChainEthereum
+	}
+	if config.ChainID == "" {
+		config.ChainID = "ethereum-1337"
+	}
+	if config.NetworkName == "" {
+		config.NetworkName = "simulated"
+	}
+	if config.ContractAddress == "" {
+		config.ContractAddress = "0x" + hex.EncodeToString(sha256Bytes([]byte("certen-anchor:" + config.ChainID))[:20])
+	}
+	if config.GasPerAnchor <= 0 {
+		config.GasPerAnchor = 52000
+	}
+	if config.GasPriceWei == nil {
+		config.GasPriceWei = big.NewInt(20_000_000_000) // 20 gwei
+	}
+	if config.GenesisTime.IsZero() {
+		config.GenesisTime = time.Unix(1735689600, 0).UTC() // 2025-01-01T00:00:00Z
+	}
+
+	return &SimulatedChain{
+		config:   config,
+		txBlocks: make(map[string]int64),
+		now:      time.Now,
+		lastTick: time.Now(),
+	}
+}
+
+// TargetChain implements AnchorBackend
+func (c *SimulatedChain) TargetChain() database.TargetChain {
+	return c.config.TargetChain
+}
+
+// SubmitAnchor implements AnchorBackend by mining the root into a new block
+func (c *SimulatedChain) SubmitAnchor(ctx context.Context, batch *database.AnchorBatch) (*AnchorSubmission, error) {
+	if len(batch.MerkleRoot) != 32 {
+		return nil, fmt.Errorf("merkle root must be 32 bytes, got %d", len(batch.MerkleRoot))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advanceLocked()
+	c.height++
+
+	txHash := c.txHash(c.height, batch.MerkleRoot)
+	c.txBlocks[txHash] = c.height
+
+	return &AnchorSubmission{
+		TxHash:          txHash,
+		BlockNumber:     c.height,
+		BlockHash:       c.blockHash(c.height),
+		ChainID:         c.config.ChainID,
+		NetworkName:     c.config.NetworkName,
+		ContractAddress: c.config.ContractAddress,
+		GasUsed:         c.config.GasPerAnchor,
+		GasPriceWei:     new(big.Int).Set(c.config.GasPriceWei),
+	}, nil
+}
+
+// GetConfirmation implements AnchorBackend
+func (c *SimulatedChain) GetConfirmation(ctx context.Context, txHash string) (*AnchorConfirmation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advanceLocked()
+
+	block, ok := c.txBlocks[txHash]
+	if !ok {
+		return nil, ErrAnchorTxNotFound
+	}
+
+	return &AnchorConfirmation{
+		Confirmations:  int(c.height - block + 1),
+		BlockHash:      c.blockHash(block),
+		BlockTimestamp: c.blockTime(block),
+	}, nil
+}
+
+// Mine advances the chain by n empty blocks
+func (c *SimulatedChain) Mine(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.height += int64(n)
+}
+
+// Height returns the current block height
+func (c *SimulatedChain) Height() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.height
+}
+
+// advanceLocked mines blocks for wall-clock time elapsed since the last tick
+func (c *SimulatedChain) advanceLocked() {
+	if c.config.BlockTime <= 0 {
+		return
+	}
+	now := c.now()
+	blocks := int64(now.Sub(c.lastTick) / c.config.BlockTime)
+	if blocks > 0 {
+		c.height += blocks
+		c.lastTick = c.lastTick.Add(time.Duration(blocks) * c.config.BlockTime)
+	}
+}
+
+func (c *SimulatedChain) txHash(height int64, root []byte) string {
+	var h [8]byte
+	binary.BigEndian.PutUint64(h[:], uint64(height))
+	data := append([]byte("tx:"+c.config.ChainID+":"), h[:]...)
+	data = append(data, root...)
+	return "0x" + hex.EncodeToString(sha256Bytes(data))
+}
+
+func (c *SimulatedChain) blockHash(height int64) string {
+	var h [8]byte
+	binary.BigEndian.PutUint64(h[:], uint64(height))
+	data := append([]byte("block:"+c.config.ChainID+":"), h[:]...)
+	return "0x" + hex.EncodeToString(sha256Bytes(data))
+}
+
+func (c *SimulatedChain) blockTime(height int64) time.Time {
+	blockTime := c.config.BlockTime
+	if blockTime <= 0 {
+		blockTime = 12 * time.Second
+	}
+	return c.config.GenesisTime.Add(time.Duration(height) * blockTime)
+}
+
+func sha256Bytes(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the simulated anchor chain
+
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+func testBatch(seed string) *database.AnchorBatch {
+	root := sha256.Sum256([]byte(seed))
+	return &database.AnchorBatch{BatchID: uuid.New(), MerkleRoot: root[:]}
+}
+
+func TestSimulatedChain_Deterministic(t *testing.T) {
+	ctx := context.Background()
+	a := NewSimulatedChain(nil)
+	b := NewSimulatedChain(nil)
+
+	for _, seed := range []string{"one", "two", "three"} {
+		subA, err := a.SubmitAnchor(ctx, testBatch(seed))
+		if err != nil {
+			t.Fatalf("SubmitAnchor failed: %v", err)
+		}
+		subB, _ := b.SubmitAnchor(ctx, testBatch(seed))
+
+		if subA.TxHash != subB.TxHash || subA.BlockHash != subB.BlockHash || subA.BlockNumber != subB.BlockNumber {
+			t.Errorf("Expected identical submissions for %q, got %+v and %+v", seed, subA, subB)
+		}
+		if len(subA.TxHash) != 66 {
+			t.Errorf("Expected 0x-prefixed 32-byte tx hash, got %q", subA.TxHash)
+		}
+	}
+}
+
+func TestSimulatedChain_Confirmations(t *testing.T) {
+	ctx := context.Background()
+	chain := NewSimulatedChain(nil)
+
+	sub, _ := chain.SubmitAnchor(ctx, testBatch("root"))
+
+	conf, err := chain.GetConfirmation(ctx, sub.TxHash)
+	if err != nil {
+		t.Fatalf("GetConfirmation failed: %v", err)
+	}
+	if conf.Confirmations != 1 {
+		t.Errorf("Expected 1 confirmation, got %d", conf.Confirmations)
+	}
+
+	chain.Mine(11)
+	conf, _ = chain.GetConfirmation(ctx, sub.TxHash)
+	if conf.Confirmations != 12 {
+		t.Errorf("Expected 12 confirmations, got %d", conf.Confirmations)
+	}
+	if conf.BlockHash != sub.BlockHash {
+		t.Errorf("Expected block hash %s, got %s", sub.BlockHash, conf.BlockHash)
+	}
+}
+
+func TestSimulatedChain_UnknownTx(t *testing.T) {
+	chain := NewSimulatedChain(nil)
+	_, err := chain.GetConfirmation(context.Background(), "0xdeadbeef")
+	if !errors.Is(err, ErrAnchorTxNotFound) {
+		t.Errorf("Expected ErrAnchorTxNotFound, got %v", err)
+	}
+}
+
+func TestSimulatedChain_InvalidRoot(t *testing.T) {
+	chain := NewSimulatedChain(nil)
+	_, err := chain.SubmitAnchor(context.Background(), &database.AnchorBatch{MerkleRoot: []byte{1, 2, 3}})
+	if err == nil {
+		t.Error("Expected error for short merkle root")
+	}
+}
+
+func TestSimulatedChain_GasCost(t *testing.T) {
+	chain := NewSimulatedChain(&SimulatedChainConfig{GasPerAnchor: 50000, GasPriceWei: big.NewInt(10)})
+	sub, _ := chain.SubmitAnchor(context.Background(), testBatch("gas"))
+
+	if sub.GasUsed != 50000 {
+		t.Errorf("Expected gas used 50000, got %d", sub.GasUsed)
+	}
+	if sub.GasPriceWei.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("Expected gas price 10, got %s", sub.GasPriceWei)
+	}
+}
+
+func TestSimulatedChain_ClockMining(t *testing.T) {
+	chain := NewSimulatedChain(&SimulatedChainConfig{BlockTime: 12 * time.Second})
+	start := time.Now()
+	chain.lastTick = start
+	chain.now = func() time.Time { return start.Add(36 * time.Second) }
+
+	sub, _ := chain.SubmitAnchor(context.Background(), testBatch("clock"))
+	if sub.BlockNumber != 4 {
+		t.Errorf("Expected block 4 after 3 clock blocks, got %d", sub.BlockNumber)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Certen Protocol
+//
+// Proof Archive Import
+// Restores proofs from a JSON lines export, gzipped or not
+//
+// Every line is verified before it is written, and written on its own, so one
+// bad record does not stop the rest of the file. Imports are idempotent:
+// proofs already stored with the same content are skipped, and running the
+// same file twice imports nothing the second time.
+
+package proofarchive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/proofbundle"
+)
+
+// MaxReportedErrors caps the line errors kept in a report
+const MaxReportedErrors = 100
+
+// ErrUnreadable is returned when the import stream cannot be read or decompressed
+var ErrUnreadable = errors.New("unreadable import stream")
+
+// Store writes imported proofs; implemented by database.IngestionRepository.
+// ImportProof reports whether anything was written and returns
+// database.ErrImportConflict when the record differs from what is stored.
+// quorum gives the valid attestations a proof needs to be stored as attested.
+type Store interface {
+	ImportProof(ctx context.Context, imp *database.ProofImport, quorum func(total int) int) (bool, error)
+}
+
+// Report counts the outcome of an import
+type Report struct {
+	Imported int         `json:"imported"`
+	Skipped  int         `json:"skipped"`
+	Rejected int         `json:"rejected"`
+	Errors   []LineError `json:"errors,omitempty"`
+}
+
+// LineError explains why a line was rejected
+type LineError struct {
+	Line    int    `json:"line"`
+	ProofID string `json:"proof_id,omitempty"`
+	Error   string `json:"error"`
+}
+
+// Importer reads exports into a store
+type Importer struct {
+	store Store
+}
+
+// NewImporter creates a new importer
+func NewImporter(store Store) *Importer {
+	return &Importer{store: store}
+}
+
+// ImportJSONLines imports every record in r. Rejected records are counted in
+// the report; an error is returned only when reading fails or the store fails
+// for a reason other than a conflict, together with the report so far.
+func (im *Importer) ImportJSONLines(ctx context.Context, r io.Reader) (*Report, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return &Report{}, fmt.Errorf("%w: failed to open gzip stream: %w", ErrUnreadable, err)
+		}
+		defer gz.Close()
+		br = bufio.NewReader(gz)
+	}
+
+	report := &Report{}
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return report, fmt.Errorf("%w: failed to read line %d: %w", ErrUnreadable, lineNo, readErr)
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if err := im.importLine(ctx, report, lineNo, line); err != nil {
+				return report, err
+			}
+		}
+
+		if readErr == io.EOF {
+			return report, nil
+		}
+	}
+}
+
+func (im *Importer) importLine(ctx context.Context, report *Report, lineNo int, line []byte) error {
+	var rec Record
+	if err := json.Unmarshal(line, &rec); err != nil {
+		report.reject(lineNo, "", fmt.Errorf("invalid JSON: %w", err))
+		return nil
+	}
+	if err := rec.Verify(); err != nil {
+		report.reject(lineNo, rec.ProofID.String(), err)
+		return nil
+	}
+
+	changed, err := im.store.ImportProof(ctx, rec.Import(), proofbundle.RequiredQuorum)
+	switch {
+	case errors.Is(err, database.ErrImportConflict):
+		report.reject(lineNo, rec.ProofID.String(), err)
+	case err != nil:
+		return fmt.Errorf("line %d: %w", lineNo, err)
+	case changed:
+		report.Imported++
+	default:
+		report.Skipped++
+	}
+	return nil
+}
+
+func (r *Report) reject(lineNo int, proofID string, err error) {
+	r.Rejected++
+	if len(r.Errors) < MaxReportedErrors {
+		r.Errors = append(r.Errors, LineError{Line: lineNo, ProofID: proofID, Error: err.Error()})
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for proof archive records and imports
+
+package proofarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// fakeStore keeps imported proofs by ID and treats a different artifact hash
+// under the same ID as a conflict
+type fakeStore struct {
+	proofs map[uuid.UUID]*database.ProofImport
+	err    error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{proofs: make(map[uuid.UUID]*database.ProofImport)}
+}
+
+func (s *fakeStore) ImportProof(ctx context.Context, imp *database.ProofImport, quorum func(total int) int) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if existing, ok := s.proofs[imp.Proof.ProofID]; ok {
+		if !bytes.Equal(existing.Proof.ArtifactHash, imp.Proof.ArtifactHash) {
+			return false, database.ErrImportConflict
+		}
+		return false, nil
+	}
+	s.proofs[imp.Proof.ProofID] = imp
+	return true, nil
+}
+
+func testProof(t *testing.T) *database.ProofArtifact {
+	t.Helper()
+
+	artifact := json.RawMessage(fmt.Sprintf(`{"tx":"%s"}`, uuid.NewString()))
+	sum := sha256.Sum256(artifact)
+	root := sha256.Sum256([]byte("root"))
+	level := database.GovernanceLevel("G1")
+	anchorTx := "0xanchor"
+	block := int64(42)
+
+	return &database.ProofArtifact{
+		ProofID:           uuid.New(),
+		ProofType:         database.ProofTypeCertenAnchor,
+		ProofVersion:      "1.0",
+		AccumTxHash:       "accum-" + uuid.NewString(),
+		AccountURL:        "acc://test.acme",
+		MerkleRoot:        root[:],
+		GovLevel:          &level,
+		ProofClass:        database.ProofClassOnDemand,
+		ValidatorID:       "validator-1",
+		Status:            database.ProofStatusAnchored,
+		AnchorTxHash:      &anchorTx,
+		AnchorBlockNumber: &block,
+		CreatedAt:         time.Now().UTC().Truncate(time.Second),
+		ArtifactJSON:      artifact,
+		ArtifactHash:      sum[:],
+	}
+}
+
+func testAttestation(t *testing.T, proof *database.ProofArtifact) database.ProofAttestation {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	attested := database.ComputeAttestedHash(proof.MerkleRoot, *proof.AnchorTxHash)
+	proofID := proof.ProofID
+	return database.ProofAttestation{
+		AttestationID:   uuid.New(),
+		ProofArtifactID: &proofID,
+		ValidatorID:     "validator-2",
+		ValidatorPubkey: pub,
+		AttestedHash:    attested,
+		Signature:       ed25519.Sign(priv, attested),
+		AnchorTxHash:    proof.AnchorTxHash,
+		MerkleRoot:      proof.MerkleRoot,
+		SignatureValid:  true,
+		AttestedAt:      time.Now().UTC(),
+	}
+}
+
+func encodeLines(t *testing.T, recs ...*Record) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range recs {
+		if err := enc.Encode(rec); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// ============================================================================
+// Record Tests
+// ============================================================================
+
+func TestNewRecord_SummaryOnly(t *testing.T) {
+	proof := testProof(t)
+	data, err := json.Marshal(NewRecord(proof, nil, false))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	for _, key := range []string{"proof_id", "gov_level", "anchor_chain", "anchor_tx_hash", "anchor_block_number", "anchored_at"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("Expected summary field %s", key)
+		}
+	}
+	for _, key := range []string{"artifact_json", "artifact_hash", "attestations"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("Did not expect %s without artifacts or attestations", key)
+		}
+	}
+
+	var rec Record
+	json.Unmarshal(data, &rec)
+	if err := rec.Verify(); !errors.Is(err, ErrNoArtifact) {
+		t.Errorf("Expected ErrNoArtifact, got %v", err)
+	}
+}
+
+func TestRecord_RoundTrip(t *testing.T) {
+	proof := testProof(t)
+	att := testAttestation(t, proof)
+
+	var rec Record
+	if err := json.Unmarshal(encodeLines(t, NewRecord(proof, []database.ProofAttestation{att}, true)), &rec); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := rec.Verify(); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	imp := rec.Import()
+	p := imp.Proof
+	if p.ProofID != proof.ProofID || p.AccumTxHash != proof.AccumTxHash || *p.GovLevel != *proof.GovLevel {
+		t.Errorf("Proof fields not restored: %+v", p)
+	}
+	if p.AnchorChain != nil {
+		t.Errorf("Expected empty anchor_chain to restore as nil, got %q", *p.AnchorChain)
+	}
+	if *p.AnchorBlockNumber != 42 || !bytes.Equal(p.ArtifactHash, proof.ArtifactHash) {
+		t.Errorf("Anchor or artifact not restored: %+v", p)
+	}
+	if len(imp.Attestations) != 1 || imp.Attestations[0].AttestationID != att.AttestationID {
+		t.Errorf("Attestations not restored: %+v", imp.Attestations)
+	}
+}
+
+func TestRecord_VerifyRejectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(rec *Record)
+		want   string
+	}{
+		{"artifact", func(rec *Record) { rec.ArtifactJSON = json.RawMessage(`{"tx":"other"}`) }, "artifact_hash"},
+		{"missing class", func(rec *Record) { rec.ProofClass = "" }, "proof_class"},
+		{"attested hash", func(rec *Record) { rec.Attestations[0].AttestedHash = make([]byte, 32) }, "attested_hash"},
+		{"signature", func(rec *Record) { rec.Attestations[0].Signature[0] ^= 0xff }, "signature"},
+		{"other proof", func(rec *Record) {
+			id := uuid.New()
+			rec.Attestations[0].ProofArtifactID = &id
+		}, "attests to proof"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof := testProof(t)
+			rec := NewRecord(proof, []database.ProofAttestation{testAttestation(t, proof)}, true)
+			tt.tamper(rec)
+			if err := rec.Verify(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// ============================================================================
+// Import Tests
+// ============================================================================
+
+func TestImportJSONLines_Counts(t *testing.T) {
+	first, second := testProof(t), testProof(t)
+	tampered := NewRecord(testProof(t), nil, true)
+	tampered.ArtifactHash = make([]byte, 32)
+
+	data := encodeLines(t, NewRecord(first, nil, true), NewRecord(second, nil, true), tampered)
+	data = append(data, []byte("\nnot json\n")...)
+
+	store := newFakeStore()
+	report, err := NewImporter(store).ImportJSONLines(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.Imported != 2 || report.Skipped != 0 || report.Rejected != 2 {
+		t.Errorf("Expected 2 imported and 2 rejected, got %+v", report)
+	}
+	if len(report.Errors) != 2 || report.Errors[0].Line != 3 || report.Errors[1].Line != 5 {
+		t.Errorf("Expected errors on lines 3 and 5, got %+v", report.Errors)
+	}
+
+	// Importing the same file again writes nothing
+	report, err = NewImporter(store).ImportJSONLines(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if report.Imported != 0 || report.Skipped != 2 || report.Rejected != 2 {
+		t.Errorf("Expected 2 skipped on re-import, got %+v", report)
+	}
+}
+
+func TestImportJSONLines_Gzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(encodeLines(t, NewRecord(testProof(t), nil, true)))
+	gz.Close()
+
+	report, err := NewImporter(newFakeStore()).ImportJSONLines(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.Imported != 1 {
+		t.Errorf("Expected 1 imported, got %+v", report)
+	}
+}
+
+func TestImportJSONLines_Conflict(t *testing.T) {
+	proof := testProof(t)
+	store := newFakeStore()
+	store.proofs[proof.ProofID] = &database.ProofImport{Proof: database.ProofArtifact{ArtifactHash: make([]byte, 32)}}
+
+	report, err := NewImporter(store).ImportJSONLines(context.Background(), bytes.NewReader(encodeLines(t, NewRecord(proof, nil, true))))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.Rejected != 1 || report.Errors[0].ProofID != proof.ProofID.String() {
+		t.Errorf("Expected conflict to be rejected, got %+v", report)
+	}
+}
+
+func TestImportJSONLines_StoreError(t *testing.T) {
+	store := newFakeStore()
+	store.err = errors.New("connection refused")
+
+	_, err := NewImporter(store).ImportJSONLines(context.Background(), bytes.NewReader(encodeLines(t, NewRecord(testProof(t), nil, true))))
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected store error for line 1, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 Certen Protocol
+//
+// Proof Archive Records
+// The JSON lines format written by bulk exports and read by bulk imports
+//
+// Each line is one proof. The summary fields are always present; the stored
+// artifact with its hash, batch and Merkle inclusion fields is added when the
+// export includes artifacts, and the proof's validator attestations when it
+// includes attestations. Only records with an artifact can be imported.
+
+package proofarchive
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// ErrNoArtifact is returned when importing a record exported without artifacts
+var ErrNoArtifact = errors.New("record has no artifact (export with include_artifacts)")
+
+// Record is one line of a JSON lines export
+type Record struct {
+	ProofID           uuid.UUID            `json:"proof_id"`
+	ProofType         database.ProofType   `json:"proof_type"`
+	AccumTxHash       string               `json:"accum_tx_hash"`
+	AccountURL        string               `json:"account_url"`
+	GovLevel          string               `json:"gov_level"`
+	Status            database.ProofStatus `json:"status"`
+	CreatedAt         time.Time            `json:"created_at"`
+	AnchoredAt        *time.Time           `json:"anchored_at"`
+	VerifiedAt        *time.Time           `json:"verified_at"`
+	AnchorChain       string               `json:"anchor_chain"`
+	AnchorTxHash      string               `json:"anchor_tx_hash"`
+	AnchorBlockNumber int64                `json:"anchor_block_number"`
+
+	// Present when the export includes artifacts
+	ProofVersion       string              `json:"proof_version,omitempty"`
+	ProofClass         database.ProofClass `json:"proof_class,omitempty"`
+	ValidatorID        string              `json:"validator_id,omitempty"`
+	VerificationStatus string              `json:"verification_status,omitempty"`
+	BatchID            *uuid.UUID          `json:"batch_id,omitempty"`
+	BatchPosition      *int                `json:"batch_position,omitempty"`
+	AnchorID           *uuid.UUID          `json:"anchor_id,omitempty"`
+	MerkleRoot         []byte              `json:"merkle_root,omitempty"`
+	LeafHash           []byte              `json:"leaf_hash,omitempty"`
+	LeafIndex          *int                `json:"leaf_index,omitempty"`
+	ArtifactJSON       json.RawMessage     `json:"artifact_json,omitempty"`
+	ArtifactHash       []byte              `json:"artifact_hash,omitempty"` // SHA256 of artifact_json
+
+	// Present when the export includes attestations
+	Attestations []database.ProofAttestation `json:"attestations,omitempty"`
+}
+
+// NewRecord builds the export record for a proof. attestations may be nil.
+func NewRecord(proof *database.ProofArtifact, attestations []database.ProofAttestation, includeArtifact bool) *Record {
+	rec := &Record{
+		ProofID:      proof.ProofID,
+		ProofType:    proof.ProofType,
+		AccumTxHash:  proof.AccumTxHash,
+		AccountURL:   proof.AccountURL,
+		Status:       proof.Status,
+		CreatedAt:    proof.CreatedAt,
+		AnchoredAt:   proof.AnchoredAt,
+		VerifiedAt:   proof.VerifiedAt,
+		Attestations: attestations,
+	}
+	if proof.GovLevel != nil {
+		rec.GovLevel = string(*proof.GovLevel)
+	}
+	if proof.AnchorChain != nil {
+		rec.AnchorChain = *proof.AnchorChain
+	}
+	if proof.AnchorTxHash != nil {
+		rec.AnchorTxHash = *proof.AnchorTxHash
+	}
+	if proof.AnchorBlockNumber != nil {
+		rec.AnchorBlockNumber = *proof.AnchorBlockNumber
+	}
+
+	if includeArtifact {
+		rec.ProofVersion = proof.ProofVersion
+		rec.ProofClass = proof.ProofClass
+		rec.ValidatorID = proof.ValidatorID
+		if proof.VerificationStatus != nil {
+			rec.VerificationStatus = string(*proof.VerificationStatus)
+		}
+		rec.BatchID = proof.BatchID
+		rec.BatchPosition = proof.BatchPosition
+		rec.AnchorID = proof.AnchorID
+		rec.MerkleRoot = proof.MerkleRoot
+		rec.LeafHash = proof.LeafHash
+		rec.LeafIndex = proof.LeafIndex
+		rec.ArtifactJSON = proof.ArtifactJSON
+		rec.ArtifactHash = proof.ArtifactHash
+	}
+
+	return rec
+}
+
+// Verify checks that the record is complete enough to import and that its
+// hashes hold: artifact_hash is the SHA256 of artifact_json, and each
+// attestation belongs to the proof, its attested_hash covers its Merkle root
+// and anchor, and a signature marked valid verifies against it. The flag is
+// only checked for consistency; the import decides signature_valid itself
+// from the validator keys registered in the target.
+func (rec *Record) Verify() error {
+	if rec.ProofID == uuid.Nil {
+		return errors.New("proof_id is required")
+	}
+	if len(rec.ArtifactJSON) == 0 {
+		return ErrNoArtifact
+	}
+	switch {
+	case rec.AccumTxHash == "":
+		return errors.New("accum_tx_hash is required")
+	case rec.ProofType == "":
+		return errors.New("proof_type is required")
+	case rec.ProofClass == "":
+		return errors.New("proof_class is required")
+	case rec.ValidatorID == "":
+		return errors.New("validator_id is required")
+	case rec.Status == "":
+		return errors.New("status is required")
+	}
+
+	computed := sha256.Sum256(rec.ArtifactJSON)
+	if !bytes.Equal(computed[:], rec.ArtifactHash) {
+		return fmt.Errorf("artifact_hash %x does not match SHA256 of artifact_json %x", rec.ArtifactHash, computed)
+	}
+
+	for i := range rec.Attestations {
+		if err := verifyAttestation(&rec.Attestations[i], rec.ProofID); err != nil {
+			return fmt.Errorf("attestation %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func verifyAttestation(a *database.ProofAttestation, proofID uuid.UUID) error {
+	if a.ProofArtifactID != nil && *a.ProofArtifactID != proofID {
+		return fmt.Errorf("attests to proof %s", *a.ProofArtifactID)
+	}
+	if a.AttestationID == uuid.Nil || a.ValidatorID == "" {
+		return errors.New("attestation_id and validator_id are required")
+	}
+	if len(a.AttestedHash) == 0 {
+		return errors.New("attested_hash is required")
+	}
+	if len(a.MerkleRoot) > 0 {
+		anchorTxHash := ""
+		if a.AnchorTxHash != nil {
+			anchorTxHash = *a.AnchorTxHash
+		}
+		if !bytes.Equal(a.AttestedHash, database.ComputeAttestedHash(a.MerkleRoot, anchorTxHash)) {
+			return errors.New("attested_hash does not match merkle_root and anchor_tx_hash")
+		}
+	}
+	if a.SignatureValid {
+		if len(a.ValidatorPubkey) != ed25519.PublicKeySize || len(a.Signature) != ed25519.SignatureSize {
+			return errors.New("malformed validator public key or signature")
+		}
+		if !ed25519.Verify(ed25519.PublicKey(a.ValidatorPubkey), a.AttestedHash, a.Signature) {
+			return errors.New("signature marked valid does not match attested hash")
+		}
+	}
+	return nil
+}
+
+// Import returns the stored form of a verified record
+func (rec *Record) Import() *database.ProofImport {
+	p := database.ProofArtifact{
+		ProofID:       rec.ProofID,
+		ProofType:     rec.ProofType,
+		ProofVersion:  rec.ProofVersion,
+		AccumTxHash:   rec.AccumTxHash,
+		AccountURL:    rec.AccountURL,
+		BatchID:       rec.BatchID,
+		BatchPosition: rec.BatchPosition,
+		AnchorID:      rec.AnchorID,
+		MerkleRoot:    rec.MerkleRoot,
+		LeafHash:      rec.LeafHash,
+		LeafIndex:     rec.LeafIndex,
+		ProofClass:    rec.ProofClass,
+		ValidatorID:   rec.ValidatorID,
+		Status:        rec.Status,
+		CreatedAt:     rec.CreatedAt,
+		AnchoredAt:    rec.AnchoredAt,
+		VerifiedAt:    rec.VerifiedAt,
+		ArtifactJSON:  rec.ArtifactJSON,
+		ArtifactHash:  rec.ArtifactHash,
+	}
+	if p.ProofVersion == "" {
+		p.ProofVersion = "1.0"
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now().UTC()
+	}
+	if rec.GovLevel != "" {
+		level := database.GovernanceLevel(rec.GovLevel)
+		p.GovLevel = &level
+	}
+	if rec.VerificationStatus != "" {
+		status := database.VerificationStatus(rec.VerificationStatus)
+		p.VerificationStatus = &status
+	}
+	if rec.AnchorChain != "" {
+		chain := rec.AnchorChain
+		p.AnchorChain = &chain
+	}
+	if rec.AnchorTxHash != "" {
+		txHash := rec.AnchorTxHash
+		p.AnchorTxHash = &txHash
+	}
+	if rec.AnchorBlockNumber != 0 {
+		block := rec.AnchorBlockNumber
+		p.AnchorBlockNumber = &block
+	}
+
+	return &database.ProofImport{Proof: p, Attestations: rec.Attestations}
+}
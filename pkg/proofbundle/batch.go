@@ -0,0 +1,645 @@
+// Copyright 2025 Certen Protocol
+//
+// Batch Bundles
+// One self-contained audit bundle per anchor batch (format certen_batch_v1)
+//
+// A batch bundle packages the batch header, every leaf with its inclusion
+// path, the full tree, the anchor record, per-proof anchor references, all
+// validator and BLS batch attestations and the consensus entry. Its manifest
+// lists the SHA-256 of each section's compact JSON; the manifest hash is the
+// SHA-256 of the compact JSON of that list.
+
+package proofbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// Batch bundle identifiers
+const (
+	BatchBundleSchema  = "https://certen.io/schemas/batch-bundle/v1.0"
+	BatchBundleVersion = "1.0"
+	BatchBundleFormat  = "certen_batch_v1"
+)
+
+// ErrBatchOpen is returned when bundling a batch whose tree is not final
+var ErrBatchOpen = errors.New("batch is still open")
+
+// Manifest section names, in bundle order
+var batchSections = []string{
+	"batch_header",
+	"leaves",
+	"tree",
+	"anchor",
+	"anchor_references",
+	"validator_attestations",
+	"batch_attestations",
+	"consensus_entry",
+}
+
+// BatchBundle is the certen_batch_v1 document
+type BatchBundle struct {
+	Schema        string    `json:"$schema"`
+	BundleVersion string    `json:"bundle_version"`
+	BundleFormat  string    `json:"bundle_format"`
+	BundleID      uuid.UUID `json:"bundle_id"`
+	GeneratedAt   time.Time `json:"generated_at"`
+
+	BatchHeader           BatchHeader             `json:"batch_header"`
+	Leaves                []BatchLeaf             `json:"leaves"`
+	Tree                  BatchTree               `json:"tree"`
+	Anchor                *AnchorSection          `json:"anchor"`
+	AnchorReferences      []AnchorReference       `json:"anchor_references"`
+	ValidatorAttestations []ValidatorAttestation  `json:"validator_attestations"`
+	BatchAttestations     []BatchAttestationEntry `json:"batch_attestations"`
+	ConsensusEntry        *ConsensusSection       `json:"consensus_entry"`
+
+	Manifest Manifest `json:"manifest"`
+}
+
+// BatchHeader describes the batch
+type BatchHeader struct {
+	BatchID       uuid.UUID  `json:"batch_id"`
+	BatchType     string     `json:"batch_type"`
+	Status        string     `json:"status"`
+	MerkleRoot    string     `json:"merkle_root"`
+	TxCount       int        `json:"transaction_count"`
+	ValidatorID   string     `json:"validator_id"`
+	StartTime     time.Time  `json:"batch_start_time"`
+	EndTime       *time.Time `json:"batch_end_time,omitempty"`
+	AccumHeight   *int64     `json:"accumulate_block_height,omitempty"`
+	AccumHash     string     `json:"accumulate_block_hash,omitempty"`
+	ErrorMessage  string     `json:"error_message,omitempty"`
+	HashAlgorithm string     `json:"hash_algorithm"`
+}
+
+// BatchLeaf maps a leaf to its transaction and inclusion path
+type BatchLeaf struct {
+	Index       int         `json:"index"`
+	LeafHash    string      `json:"leaf_hash"`
+	AccumTxHash string      `json:"accum_tx_hash"`
+	AccountURL  string      `json:"account_url"`
+	ProofID     *uuid.UUID  `json:"proof_id,omitempty"`
+	MerklePath  []PathEntry `json:"merkle_path"`
+}
+
+// BatchTree holds every level of the tree, leaves first and root last
+type BatchTree struct {
+	LeafCount int        `json:"leaf_count"`
+	Depth     int        `json:"depth"`
+	Root      string     `json:"root"`
+	Levels    [][]string `json:"levels"`
+}
+
+// AnchorSection is the anchor record for the batch
+type AnchorSection struct {
+	AnchorID              uuid.UUID  `json:"anchor_id"`
+	TargetChain           string     `json:"target_chain"`
+	ChainID               string     `json:"chain_id,omitempty"`
+	NetworkName           string     `json:"network_name,omitempty"`
+	ContractAddress       string     `json:"contract_address,omitempty"`
+	AnchorTxHash          string     `json:"anchor_tx_hash"`
+	AnchorBlockNumber     int64      `json:"anchor_block_number"`
+	AnchorBlockHash       string     `json:"anchor_block_hash,omitempty"`
+	AnchorTimestamp       *time.Time `json:"anchor_timestamp,omitempty"`
+	MerkleRoot            string     `json:"merkle_root"`
+	Confirmations         int        `json:"confirmations"`
+	RequiredConfirmations int        `json:"required_confirmations"`
+	IsFinal               bool       `json:"is_final"`
+	ConfirmedAt           *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// AnchorReference is a proof's recorded anchor within the batch
+type AnchorReference struct {
+	ProofID           uuid.UUID `json:"proof_id"`
+	AccumTxHash       string    `json:"accum_tx_hash"`
+	AnchorTxHash      string    `json:"anchor_tx_hash,omitempty"`
+	AnchorBlockNumber *int64    `json:"anchor_block_number,omitempty"`
+	AnchorChain       string    `json:"anchor_chain,omitempty"`
+}
+
+// ValidatorAttestation is an Ed25519 attestation from validator_attestations
+type ValidatorAttestation struct {
+	AttestationID   uuid.UUID  `json:"attestation_id"`
+	ProofID         *uuid.UUID `json:"proof_id,omitempty"`
+	ValidatorID     string     `json:"validator_id"`
+	ValidatorPubkey string     `json:"validator_pubkey"`
+	AttestedHash    string     `json:"attested_hash"`
+	Signature       string     `json:"signature"`
+	AnchorTxHash    string     `json:"anchor_tx_hash,omitempty"`
+	MerkleRoot      string     `json:"merkle_root,omitempty"`
+	BlockNumber     *int64     `json:"block_number,omitempty"`
+	SignatureValid  bool       `json:"signature_valid"`
+	AttestedAt      time.Time  `json:"attested_at"`
+}
+
+// BatchAttestationEntry is a BLS attestation from batch_attestations
+type BatchAttestationEntry struct {
+	AttestationID   uuid.UUID `json:"attestation_id"`
+	ValidatorID     string    `json:"validator_id"`
+	MerkleRoot      string    `json:"merkle_root"`
+	BLSSignature    string    `json:"bls_signature"`
+	BLSPublicKey    string    `json:"bls_public_key"`
+	TxCount         int       `json:"tx_count"`
+	BlockHeight     int64     `json:"block_height"`
+	AttestationTime time.Time `json:"attestation_time"`
+	SignatureValid  *bool     `json:"signature_valid,omitempty"`
+}
+
+// ConsensusSection is the batch's consensus_entries row
+type ConsensusSection struct {
+	EntryID            uuid.UUID  `json:"entry_id"`
+	State              string     `json:"state"`
+	MerkleRoot         string     `json:"merkle_root"`
+	AnchorTxHash       string     `json:"anchor_tx_hash,omitempty"`
+	BlockNumber        *int64     `json:"block_number,omitempty"`
+	TxCount            int        `json:"tx_count"`
+	AttestationCount   int        `json:"attestation_count"`
+	RequiredCount      int        `json:"required_count"`
+	QuorumFraction     float64    `json:"quorum_fraction"`
+	AggregateSignature string     `json:"aggregate_signature,omitempty"`
+	AggregatePubkey    string     `json:"aggregate_pubkey,omitempty"`
+	StartTime          time.Time  `json:"start_time"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+}
+
+// Manifest lists the hash of every bundle section
+type Manifest struct {
+	Algorithm    string            `json:"algorithm"`
+	Sections     map[string]string `json:"sections"`
+	ManifestHash string            `json:"manifest_hash"`
+}
+
+// BatchBundleInput is the stored data a batch bundle is built from
+type BatchBundleInput struct {
+	Batch                 *database.AnchorBatch
+	Transactions          []*database.BatchTransaction // Ordered by tree index
+	Anchors               []*database.AnchorRecord
+	Proofs                []database.ProofArtifact
+	ValidatorAttestations []database.ProofAttestation
+	BatchAttestations     []*database.BatchAttestation
+	ConsensusEntry        *database.ConsensusEntry // nil if none
+}
+
+// LoadBatchBundleInput reads everything a batch bundle needs
+func LoadBatchBundleInput(ctx context.Context, repos *database.Repositories, batchID uuid.UUID) (*BatchBundleInput, error) {
+	batch, err := repos.Batches.GetBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	input := &BatchBundleInput{Batch: batch}
+
+	if input.Transactions, err = repos.Batches.GetTransactionsInBatch(ctx, batchID); err != nil {
+		return nil, err
+	}
+	if input.Anchors, err = repos.Anchors.GetAnchorsByBatchID(ctx, batchID); err != nil {
+		return nil, err
+	}
+	if input.Proofs, err = repos.ProofArtifacts.GetProofsByBatch(ctx, batchID); err != nil {
+		return nil, err
+	}
+	if input.ValidatorAttestations, err = repos.ProofArtifacts.GetProofAttestationsByBatch(ctx, batchID); err != nil {
+		return nil, err
+	}
+	if input.BatchAttestations, err = repos.Consensus.GetBatchAttestations(ctx, batchID); err != nil {
+		return nil, err
+	}
+
+	entry, err := repos.Consensus.GetConsensusEntry(ctx, batchID)
+	switch {
+	case err == nil:
+		input.ConsensusEntry = entry
+	case !errors.Is(err, database.ErrConsensusEntryNotFound):
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// NewBatchBundle assembles a batch bundle. Leaf paths are recomputed from the
+// transaction hashes, so the bundle is consistent even if stored paths are not.
+func NewBatchBundle(input *BatchBundleInput) (*BatchBundle, error) {
+	batch := input.Batch
+	if batch.Status == database.BatchStatusPending {
+		return nil, ErrBatchOpen
+	}
+
+	tree, err := merkle.TreeFromTransactions(input.Transactions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild batch tree: %w", err)
+	}
+
+	b := &BatchBundle{
+		Schema:        BatchBundleSchema,
+		BundleVersion: BatchBundleVersion,
+		BundleFormat:  BatchBundleFormat,
+		BundleID:      uuid.New(),
+		GeneratedAt:   time.Now().UTC(),
+		BatchHeader: BatchHeader{
+			BatchID:       batch.BatchID,
+			BatchType:     string(batch.BatchType),
+			Status:        string(batch.Status),
+			MerkleRoot:    hex.EncodeToString(batch.MerkleRoot),
+			TxCount:       batch.TxCount,
+			ValidatorID:   batch.ValidatorID,
+			StartTime:     batch.StartTime.UTC(),
+			AccumHash:     batch.AccumHash.String,
+			ErrorMessage:  batch.ErrorMessage.String,
+			HashAlgorithm: "sha256",
+		},
+		Leaves:                make([]BatchLeaf, 0, len(input.Transactions)),
+		AnchorReferences:      []AnchorReference{},
+		ValidatorAttestations: make([]ValidatorAttestation, 0, len(input.ValidatorAttestations)),
+		BatchAttestations:     make([]BatchAttestationEntry, 0, len(input.BatchAttestations)),
+	}
+	if batch.EndTime.Valid {
+		end := batch.EndTime.Time.UTC()
+		b.BatchHeader.EndTime = &end
+	}
+	if batch.AccumHeight.Valid {
+		height := batch.AccumHeight.Int64
+		b.BatchHeader.AccumHeight = &height
+	}
+
+	proofIDs := make(map[string]uuid.UUID, len(input.Proofs))
+	for _, p := range input.Proofs {
+		proofIDs[p.AccumTxHash] = p.ProofID
+		if p.AnchorTxHash == nil {
+			continue
+		}
+		ref := AnchorReference{
+			ProofID:           p.ProofID,
+			AccumTxHash:       p.AccumTxHash,
+			AnchorTxHash:      *p.AnchorTxHash,
+			AnchorBlockNumber: p.AnchorBlockNumber,
+		}
+		if p.AnchorChain != nil {
+			ref.AnchorChain = *p.AnchorChain
+		}
+		b.AnchorReferences = append(b.AnchorReferences, ref)
+	}
+
+	for i, tx := range input.Transactions {
+		path, err := tree.Path(i)
+		if err != nil {
+			return nil, err
+		}
+		leaf := BatchLeaf{
+			Index:       i,
+			LeafHash:    hex.EncodeToString(tx.TxHash),
+			AccumTxHash: tx.AccumTxHash,
+			AccountURL:  tx.AccountURL,
+			MerklePath:  ToPathEntries(path),
+		}
+		if proofID, ok := proofIDs[tx.AccumTxHash]; ok {
+			leaf.ProofID = &proofID
+		}
+		b.Leaves = append(b.Leaves, leaf)
+	}
+
+	b.Tree = BatchTree{
+		LeafCount: tree.LeafCount(),
+		Depth:     tree.Depth(),
+		Root:      hex.EncodeToString(tree.Root()),
+		Levels:    make([][]string, 0, tree.Depth()+1),
+	}
+	for _, level := range tree.Levels() {
+		hashes := make([]string, len(level))
+		for i, node := range level {
+			hashes[i] = hex.EncodeToString(node)
+		}
+		b.Tree.Levels = append(b.Tree.Levels, hashes)
+	}
+
+	if anchor := primaryAnchor(input.Anchors); anchor != nil {
+		b.Anchor = &AnchorSection{
+			AnchorID:              anchor.AnchorID,
+			TargetChain:           string(anchor.TargetChain),
+			ChainID:               anchor.ChainID.String,
+			NetworkName:           anchor.NetworkName.String,
+			ContractAddress:       anchor.ContractAddress.String,
+			AnchorTxHash:          anchor.AnchorTxHash,
+			AnchorBlockNumber:     anchor.AnchorBlockNumber,
+			AnchorBlockHash:       anchor.AnchorBlockHash.String,
+			MerkleRoot:            hex.EncodeToString(anchor.MerkleRoot),
+			Confirmations:         anchor.Confirmations,
+			RequiredConfirmations: anchor.RequiredConfirms,
+			IsFinal:               anchor.IsFinal,
+		}
+		if anchor.AnchorTimestamp.Valid {
+			ts := anchor.AnchorTimestamp.Time.UTC()
+			b.Anchor.AnchorTimestamp = &ts
+		}
+		if anchor.ConfirmedAt.Valid {
+			ts := anchor.ConfirmedAt.Time.UTC()
+			b.Anchor.ConfirmedAt = &ts
+		}
+	}
+
+	for _, a := range input.ValidatorAttestations {
+		b.ValidatorAttestations = append(b.ValidatorAttestations, toValidatorAttestation(a))
+	}
+
+	for _, a := range input.BatchAttestations {
+		b.BatchAttestations = append(b.BatchAttestations, BatchAttestationEntry{
+			AttestationID:   a.AttestationID,
+			ValidatorID:     a.ValidatorID,
+			MerkleRoot:      hex.EncodeToString(a.MerkleRoot),
+			BLSSignature:    hex.EncodeToString(a.BLSSignature),
+			BLSPublicKey:    hex.EncodeToString(a.BLSPublicKey),
+			TxCount:         a.TxCount,
+			BlockHeight:     a.BlockHeight,
+			AttestationTime: a.AttestationTime.UTC(),
+			SignatureValid:  a.SignatureValid,
+		})
+	}
+
+	if e := input.ConsensusEntry; e != nil {
+		b.ConsensusEntry = &ConsensusSection{
+			EntryID:            e.EntryID,
+			State:              string(e.State),
+			MerkleRoot:         hex.EncodeToString(e.MerkleRoot),
+			BlockNumber:        e.BlockNumber,
+			TxCount:            e.TxCount,
+			AttestationCount:   e.AttestationCount,
+			RequiredCount:      e.RequiredCount,
+			QuorumFraction:     e.QuorumFraction,
+			AggregateSignature: hex.EncodeToString(e.AggregateSignature),
+			AggregatePubkey:    hex.EncodeToString(e.AggregatePubkey),
+			StartTime:          e.StartTime.UTC(),
+			CompletedAt:        e.CompletedAt,
+		}
+		if e.AnchorTxHash != nil {
+			b.ConsensusEntry.AnchorTxHash = *e.AnchorTxHash
+		}
+	}
+
+	if err := b.seal(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// primaryAnchor picks the final anchor if there is one, otherwise the latest
+func primaryAnchor(anchors []*database.AnchorRecord) *database.AnchorRecord {
+	var latest *database.AnchorRecord
+	for _, a := range anchors {
+		if a.IsFinal {
+			return a
+		}
+		latest = a
+	}
+	return latest
+}
+
+// toValidatorAttestation converts a stored attestation to bundle format
+func toValidatorAttestation(a database.ProofAttestation) ValidatorAttestation {
+	entry := ValidatorAttestation{
+		AttestationID:   a.AttestationID,
+		ProofID:         a.ProofArtifactID,
+		ValidatorID:     a.ValidatorID,
+		ValidatorPubkey: hex.EncodeToString(a.ValidatorPubkey),
+		AttestedHash:    hex.EncodeToString(a.AttestedHash),
+		Signature:       hex.EncodeToString(a.Signature),
+		MerkleRoot:      hex.EncodeToString(a.MerkleRoot),
+		BlockNumber:     a.BlockNumber,
+		SignatureValid:  a.SignatureValid,
+		AttestedAt:      a.AttestedAt.UTC(),
+	}
+	if a.AnchorTxHash != nil {
+		entry.AnchorTxHash = *a.AnchorTxHash
+	}
+	return entry
+}
+
+// seal computes the manifest over the bundle's sections
+func (b *BatchBundle) seal() error {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to serialize batch bundle: %w", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("failed to serialize batch bundle: %w", err)
+	}
+
+	manifest, err := computeManifest(doc, batchSections)
+	if err != nil {
+		return err
+	}
+	b.Manifest = *manifest
+	return nil
+}
+
+// Marshal returns the bundle's compact JSON
+func (b *BatchBundle) Marshal() ([]byte, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize batch bundle: %w", err)
+	}
+	return data, nil
+}
+
+// computeManifest hashes the compact JSON of each named section
+func computeManifest(doc map[string]json.RawMessage, names []string) (*Manifest, error) {
+	sections := make(map[string]string, len(names))
+	for _, name := range names {
+		raw, ok := doc[name]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing section %q", name)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON in section %q: %w", name, err)
+		}
+		sections[name] = hex.EncodeToString(Hash(compact.Bytes()))
+	}
+
+	// encoding/json writes map keys in sorted order
+	sectionsJSON, err := json.Marshal(sections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize manifest: %w", err)
+	}
+
+	return &Manifest{
+		Algorithm:    "sha256",
+		Sections:     sections,
+		ManifestHash: hex.EncodeToString(Hash(sectionsJSON)),
+	}, nil
+}
+
+// =============================================================================
+// VERIFICATION
+// =============================================================================
+
+// BatchVerification is the result of verifying a batch bundle
+type BatchVerification struct {
+	BundleValid   bool            `json:"bundle_valid"`
+	ManifestValid bool            `json:"manifest_valid"`
+	RootValid     bool            `json:"root_valid"`
+	PathsValid    bool            `json:"paths_valid"`
+	AnchorValid   bool            `json:"anchor_valid"`
+	Components    map[string]bool `json:"components"`
+	Attestations  QuorumStatus    `json:"attestations"`
+	ManifestHash  string          `json:"manifest_hash"`
+	Errors        []string        `json:"errors"`
+}
+
+// QuorumStatus summarises attestation validity against RequiredQuorum
+type QuorumStatus struct {
+	Total     int  `json:"total"`
+	Valid     int  `json:"valid"`
+	QuorumMet bool `json:"quorum_met"`
+	Required  int  `json:"required"`
+}
+
+// VerifyBatchBundle checks a decompressed batch bundle: its manifest, its
+// tree against the leaves and header, every leaf path, the anchored root and
+// attestation quorum
+func VerifyBatchBundle(jsonData []byte) (*BatchVerification, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("invalid bundle JSON: %w", err)
+	}
+	var b BatchBundle
+	if err := json.Unmarshal(jsonData, &b); err != nil {
+		return nil, fmt.Errorf("invalid batch bundle: %w", err)
+	}
+	if b.BundleFormat != BatchBundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format: %q", b.BundleFormat)
+	}
+
+	result := &BatchVerification{
+		Components: map[string]bool{
+			"batch_header":    b.BatchHeader.MerkleRoot != "",
+			"leaves":          len(b.Leaves) > 0,
+			"tree":            len(b.Tree.Levels) > 0,
+			"anchor":          b.Anchor != nil,
+			"consensus_entry": b.ConsensusEntry != nil,
+		},
+		Errors: []string{},
+	}
+	fail := func(format string, args ...interface{}) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	// Manifest
+	manifest, err := computeManifest(doc, batchSections)
+	if err != nil {
+		fail("%v", err)
+	} else {
+		result.ManifestHash = manifest.ManifestHash
+		result.ManifestValid = manifest.ManifestHash == b.Manifest.ManifestHash
+		for name, hash := range manifest.Sections {
+			if b.Manifest.Sections[name] != hash {
+				result.ManifestValid = false
+				fail("section %s does not match manifest", name)
+			}
+		}
+		if manifest.ManifestHash != b.Manifest.ManifestHash {
+			fail("manifest hash mismatch")
+		}
+	}
+
+	// Tree and root
+	leaves := make([][]byte, len(b.Leaves))
+	for i, leaf := range b.Leaves {
+		hash, err := hex.DecodeString(leaf.LeafHash)
+		if err != nil || leaf.Index != i {
+			fail("leaf %d is malformed", i)
+			leaves = nil
+			break
+		}
+		leaves[i] = hash
+	}
+
+	var root []byte
+	if leaves != nil {
+		tree, err := merkle.NewTree(leaves)
+		if err != nil {
+			fail("cannot rebuild tree: %v", err)
+		} else {
+			root = tree.Root()
+			rootHex := hex.EncodeToString(root)
+			result.RootValid = rootHex == b.BatchHeader.MerkleRoot && rootHex == b.Tree.Root &&
+				levelsEqual(tree.Levels(), b.Tree.Levels)
+			if !result.RootValid {
+				fail("recomputed root %s does not match header or tree", rootHex)
+			}
+		}
+	}
+
+	// Leaf paths
+	if root != nil {
+		result.PathsValid = true
+		for i, leaf := range b.Leaves {
+			if !merkle.VerifyPath(leaves[i], FromPathEntries(leaf.MerklePath), root) {
+				result.PathsValid = false
+				fail("path for leaf %d does not verify", i)
+			}
+		}
+	}
+
+	// Anchor
+	if b.Anchor == nil {
+		result.AnchorValid = true
+	} else if root != nil {
+		result.AnchorValid = b.Anchor.MerkleRoot == hex.EncodeToString(root)
+		if !result.AnchorValid {
+			fail("anchored root does not match batch root")
+		}
+	}
+
+	// Attestations: same majority rule as single-proof bundles
+	total := len(b.ValidatorAttestations) + len(b.BatchAttestations)
+	valid := 0
+	for _, a := range b.ValidatorAttestations {
+		if a.SignatureValid {
+			valid++
+		}
+	}
+	for _, a := range b.BatchAttestations {
+		if a.SignatureValid != nil && *a.SignatureValid {
+			valid++
+		}
+	}
+	result.Attestations = QuorumStatus{
+		Total:     total,
+		Valid:     valid,
+		Required:  RequiredQuorum(total),
+		QuorumMet: valid >= RequiredQuorum(total),
+	}
+
+	result.BundleValid = result.ManifestValid && result.RootValid && result.PathsValid &&
+		result.AnchorValid && result.Attestations.QuorumMet
+
+	return result, nil
+}
+
+func levelsEqual(levels [][][]byte, encoded [][]string) bool {
+	if len(levels) != len(encoded) {
+		return false
+	}
+	for i, level := range levels {
+		if len(level) != len(encoded[i]) {
+			return false
+		}
+		for j, node := range level {
+			if hex.EncodeToString(node) != encoded[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
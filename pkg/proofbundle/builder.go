@@ -0,0 +1,200 @@
+// Copyright 2025 Certen Protocol
+//
+// Proof Bundle Builder
+// Builds certen_v1 (or signed certen_v2) bundles on demand and regenerates stale ones
+//
+// A stored bundle is served as long as it is current. It is rebuilt from
+// GetProofWithDetails when none exists, when attestations were added, when
+// the anchor was recorded or confirmed after the bundle was built, or when
+// the bundle is past its ExpiresAt.
+
+package proofbundle
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// BuilderConfig contains configuration for the bundle builder
+type BuilderConfig struct {
+	TTL    time.Duration // Lifetime of a built bundle; 0 means bundles do not expire
+	Signer *Signer       // Builds signed certen_v2 bundles if set, certen_v1 otherwise
+}
+
+// Builder builds and stores proof bundles
+type Builder struct {
+	repos  *database.Repositories
+	ttl    time.Duration
+	signer *Signer
+	logger *log.Logger
+
+	mu sync.Mutex // Serializes rebuilds so concurrent requests store one bundle
+}
+
+// NewBuilder creates a new bundle builder
+func NewBuilder(repos *database.Repositories, cfg *BuilderConfig, logger *log.Logger) *Builder {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[BundleBuilder] ", log.LstdFlags)
+	}
+	if cfg == nil {
+		cfg = &BuilderConfig{}
+	}
+
+	return &Builder{
+		repos:  repos,
+		ttl:    cfg.TTL,
+		signer: cfg.Signer,
+		logger: logger,
+	}
+}
+
+// GetOrBuild returns the current bundle for a proof, building it if the
+// stored one is missing or stale. Returns database.ErrProofNotFound if the
+// proof does not exist.
+func (b *Builder) GetOrBuild(ctx context.Context, proofID uuid.UUID) (*database.ProofBundle, error) {
+	details, stored, err := b.load(ctx, proofID)
+	if err != nil {
+		return nil, err
+	}
+	if RebuildReason(stored, details, b.Format(), time.Now()) == "" {
+		return stored, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Another request may have rebuilt it while we waited
+	details, stored, err = b.load(ctx, proofID)
+	if err != nil {
+		return nil, err
+	}
+	reason := RebuildReason(stored, details, b.Format(), time.Now())
+	if reason == "" {
+		return stored, nil
+	}
+
+	bundle, err := b.Build(ctx, details)
+	if err != nil {
+		return nil, err
+	}
+	b.logger.Printf("Built bundle %s for proof %s (%s)", bundle.BundleID, proofID, reason)
+	return bundle, nil
+}
+
+// Format returns the bundle format the builder produces
+func (b *Builder) Format() string {
+	if b.signer != nil {
+		return SignedBundleFormat
+	}
+	return ProofBundleFormat
+}
+
+// Build assembles a bundle from the proof's stored records and saves it
+func (b *Builder) Build(ctx context.Context, details *database.ProofArtifactWithDetails) (*database.ProofBundle, error) {
+	doc, err := b.Assemble(ctx, details)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := doc.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := Compress(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	includesMerkle, includesAnchor, includesChained, includesGovernance := doc.ProofComponents.Includes()
+	newBundle := &database.NewProofBundle{
+		BundleID:           &doc.BundleID,
+		ProofID:            details.ProofID,
+		BundleFormat:       doc.Format(),
+		BundleVersion:      doc.BundleVersion,
+		BundleData:         compressed,
+		BundleHash:         Hash(jsonData),
+		BundleSizeBytes:    len(compressed),
+		IncludesChained:    includesChained,
+		IncludesGovernance: includesGovernance,
+		IncludesMerkle:     includesMerkle,
+		IncludesAnchor:     includesAnchor,
+		AttestationCount:   len(details.Attestations),
+	}
+	if b.ttl > 0 {
+		expiresAt := doc.GeneratedAt.Add(b.ttl)
+		newBundle.ExpiresAt = &expiresAt
+	}
+
+	return b.repos.ProofArtifacts.CreateProofBundle(ctx, newBundle)
+}
+
+// Assemble builds the bundle document from the proof's stored records
+// without saving it
+func (b *Builder) Assemble(ctx context.Context, details *database.ProofArtifactWithDetails) (*ProofBundle, error) {
+	input := &ProofBundleInput{Details: details}
+
+	path, err := b.loadMerklePath(ctx, &details.ProofArtifact)
+	if err != nil {
+		return nil, err
+	}
+	input.MerklePath = path
+
+	if input.CustodyHash, err = b.repos.ProofArtifacts.GetLatestCustodyHash(ctx, details.ProofID); err != nil {
+		return nil, err
+	}
+
+	doc, err := NewProofBundle(input)
+	if err != nil {
+		return nil, err
+	}
+	if b.signer != nil {
+		if err := doc.Sign(b.signer); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// load reads the proof with its related records and its latest stored bundle
+func (b *Builder) load(ctx context.Context, proofID uuid.UUID) (*database.ProofArtifactWithDetails, *database.ProofBundle, error) {
+	details, err := b.repos.ProofArtifacts.GetProofWithDetails(ctx, proofID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if details == nil {
+		return nil, nil, database.ErrProofNotFound
+	}
+
+	stored, err := b.repos.ProofArtifacts.GetProofBundleByProofID(ctx, proofID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return details, stored, nil
+}
+
+// loadMerklePath reads the proof's inclusion path from its batch transaction
+func (b *Builder) loadMerklePath(ctx context.Context, proof *database.ProofArtifact) ([]database.MerklePathNode, error) {
+	if proof.BatchID == nil {
+		return nil, nil
+	}
+
+	tx, err := b.repos.Batches.GetTransactionByAccumHash(ctx, proof.AccumTxHash)
+	if errors.Is(err, database.ErrTransactionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tx.BatchID != *proof.BatchID {
+		return nil, nil
+	}
+
+	return merkle.ParseStoredPath(tx.MerklePath)
+}
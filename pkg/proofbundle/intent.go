@@ -0,0 +1,586 @@
+// Copyright 2025 Certen Protocol
+//
+// Intent Bundles
+// One aggregate bundle per multi-leg intent (format certen_intent_v1)
+//
+// An intent bundle packages the certen_intents record, every leg with its
+// current proof bundle embedded verbatim, the leg dependency graph, the
+// per-chain anchor groups and the intent timeline. Its manifest covers those
+// sections the same way as a batch bundle, and each embedded leg bundle is
+// pinned by its SHA-256, so one manifest hash commits to the whole cross-chain
+// operation. When the service has a signing key the bundle is signed like a
+// certen_v2 bundle.
+
+package proofbundle
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// Intent bundle identifiers
+const (
+	IntentBundleSchema  = "https://certen.io/schemas/intent-bundle/v1.0"
+	IntentBundleVersion = "1.0"
+	IntentBundleFormat  = "certen_intent_v1"
+)
+
+// Manifest section names, in bundle order
+var intentSections = []string{
+	"intent",
+	"legs",
+	"leg_dependencies",
+	"chain_groups",
+	"timeline",
+}
+
+// IntentBundle is the certen_intent_v1 document
+type IntentBundle struct {
+	Schema        string    `json:"$schema"`
+	BundleVersion string    `json:"bundle_version"`
+	BundleFormat  string    `json:"bundle_format"`
+	BundleID      uuid.UUID `json:"bundle_id"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	IntentID      string    `json:"intent_id"`
+
+	Intent          *IntentSection       `json:"intent"`
+	Legs            []IntentLegEntry     `json:"legs"`
+	LegDependencies []LegDependencyEntry `json:"leg_dependencies"`
+	ChainGroups     []ChainGroupEntry    `json:"chain_groups"`
+	Timeline        []TimelineEntry      `json:"timeline"`
+
+	Manifest        Manifest         `json:"manifest"`
+	BundleSignature *BundleSignature `json:"bundle_signature,omitempty"`
+}
+
+// IntentSection is the intent's certen_intents record; null for intents
+// recorded only through their batch transactions
+type IntentSection struct {
+	IntentID         string          `json:"intent_id"`
+	OperationID      string          `json:"operation_id"`
+	UserID           string          `json:"user_id,omitempty"`
+	OrganizationADI  string          `json:"organization_adi,omitempty"`
+	AccumulateTxHash string          `json:"accumulate_tx_hash"`
+	AccountURL       string          `json:"account_url,omitempty"`
+	Partition        string          `json:"partition,omitempty"`
+	LegCount         int             `json:"leg_count"`
+	ExecutionMode    string          `json:"execution_mode"`
+	ProofClass       string          `json:"proof_class"`
+	Status           string          `json:"status"`
+	LegsCompleted    int             `json:"legs_completed"`
+	LegsFailed       int             `json:"legs_failed"`
+	LegsPending      int             `json:"legs_pending"`
+	IntentData       json.RawMessage `json:"intent_data,omitempty"`
+	CrossChainData   json.RawMessage `json:"cross_chain_data,omitempty"`
+	GovernanceData   json.RawMessage `json:"governance_data,omitempty"`
+	ReplayData       json.RawMessage `json:"replay_data,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	CompletedAt      *time.Time      `json:"completed_at,omitempty"`
+	ExpiresAt        *time.Time      `json:"expires_at,omitempty"`
+	ErrorMessage     string          `json:"error_message,omitempty"`
+}
+
+// IntentLegEntry is one leg with its proof bundle; Bundle is null until the
+// leg has a proof
+type IntentLegEntry struct {
+	LegID           uuid.UUID       `json:"leg_id"`
+	LegIndex        int             `json:"leg_index"`
+	TargetChain     string          `json:"target_chain"`
+	Role            string          `json:"role"`
+	Status          string          `json:"status"`
+	ProofID         *uuid.UUID      `json:"proof_id,omitempty"`
+	AnchorTxHash    string          `json:"anchor_tx_hash,omitempty"`
+	ExecutionTxHash string          `json:"execution_tx_hash,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+	BundleHash      string          `json:"bundle_hash,omitempty"`
+	Bundle          json.RawMessage `json:"bundle"`
+}
+
+// LegDependencyEntry is an edge of the leg dependency graph: LegID waits on
+// DependsOnLegID
+type LegDependencyEntry struct {
+	DependencyID   uuid.UUID  `json:"dependency_id"`
+	LegID          uuid.UUID  `json:"leg_id"`
+	DependsOnLegID uuid.UUID  `json:"depends_on_leg_id"`
+	ConditionType  string     `json:"condition_type"`
+	IsSatisfied    bool       `json:"is_satisfied"`
+	SatisfiedAt    *time.Time `json:"satisfied_at,omitempty"`
+}
+
+// ChainGroupEntry is the set of legs anchored together on one chain
+type ChainGroupEntry struct {
+	GroupID      uuid.UUID   `json:"group_id"`
+	TargetChain  string      `json:"target_chain"`
+	ChainKey     string      `json:"chain_key"`
+	ChainID      *int64      `json:"chain_id,omitempty"`
+	LegIDs       []uuid.UUID `json:"leg_ids"`
+	Status       string      `json:"status"`
+	BatchID      *uuid.UUID  `json:"batch_id,omitempty"`
+	AnchorID     *uuid.UUID  `json:"anchor_id,omitempty"`
+	AnchorTxHash string      `json:"anchor_tx_hash,omitempty"`
+	AnchorBlock  *int64      `json:"anchor_block,omitempty"`
+	AnchoredAt   *time.Time  `json:"anchored_at,omitempty"`
+}
+
+// TimelineEntry is one event of the intent timeline
+type TimelineEntry struct {
+	ProofID     *uuid.UUID `json:"proof_id,omitempty"`
+	EventType   string     `json:"event_type"`
+	Phase       string     `json:"phase"`
+	Action      string     `json:"action"`
+	Message     string     `json:"message"`
+	ActorType   string     `json:"actor_type"`
+	ActorID     string     `json:"actor_id,omitempty"`
+	CurrentHash string     `json:"current_hash,omitempty"`
+	Timestamp   time.Time  `json:"timestamp"`
+}
+
+// IntentBundleInput is the stored data an intent bundle is built from
+type IntentBundleInput struct {
+	IntentID     string
+	Intent       *database.CertenIntent // nil if the intent has no certen_intents record
+	Legs         []database.LegProofDetail
+	LegBundles   map[uuid.UUID][]byte // Decompressed bundle JSON by proof ID
+	Dependencies []*database.LegDependency
+	ChainGroups  []*database.IntentChainGroup
+	Timeline     []database.IntentTimelineEvent
+}
+
+// BuildIntentBundle loads an intent with its legs' current proof bundles,
+// building stale leg bundles as needed, and assembles the intent bundle.
+// Returns database.ErrIntentNotFound if the intent has neither a record nor legs.
+func (b *Builder) BuildIntentBundle(ctx context.Context, intentID string) (*IntentBundle, error) {
+	return b.intentBundle(ctx, intentID, func(proofID uuid.UUID) ([]byte, error) {
+		bundle, err := b.GetOrBuild(ctx, proofID)
+		if err != nil {
+			return nil, err
+		}
+		return Decompress(bundle.BundleData)
+	})
+}
+
+// AssembleIntentBundle is BuildIntentBundle with leg bundles assembled from
+// the stored records without saving them
+func (b *Builder) AssembleIntentBundle(ctx context.Context, intentID string) (*IntentBundle, error) {
+	return b.intentBundle(ctx, intentID, func(proofID uuid.UUID) ([]byte, error) {
+		details, err := b.repos.ProofArtifacts.GetProofWithDetails(ctx, proofID)
+		if err != nil {
+			return nil, err
+		}
+		if details == nil {
+			return nil, database.ErrProofNotFound
+		}
+		doc, err := b.Assemble(ctx, details)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Marshal()
+	})
+}
+
+// intentBundle loads an intent and assembles its bundle, reading each leg's
+// proof bundle JSON with legBundle
+func (b *Builder) intentBundle(ctx context.Context, intentID string, legBundle func(proofID uuid.UUID) ([]byte, error)) (*IntentBundle, error) {
+	input := &IntentBundleInput{IntentID: intentID, LegBundles: make(map[uuid.UUID][]byte)}
+
+	intent, err := b.repos.MultiLeg.GetIntent(ctx, intentID)
+	switch {
+	case err == nil:
+		input.Intent = intent
+	case !errors.Is(err, database.ErrIntentNotFound):
+		return nil, err
+	}
+
+	if input.Legs, err = b.repos.ProofArtifacts.GetLegsByIntentID(ctx, intentID); err != nil {
+		return nil, err
+	}
+	if input.Intent == nil && len(input.Legs) == 0 {
+		return nil, database.ErrIntentNotFound
+	}
+
+	for _, leg := range input.Legs {
+		if leg.ProofID == nil {
+			continue
+		}
+		jsonData, err := legBundle(*leg.ProofID)
+		if errors.Is(err, database.ErrProofNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to build bundle for leg %d: %w", leg.LegIndex, err)
+		}
+		input.LegBundles[*leg.ProofID] = jsonData
+	}
+
+	if input.Dependencies, err = b.repos.MultiLeg.GetLegDependencies(ctx, intentID); err != nil {
+		return nil, err
+	}
+	if input.ChainGroups, err = b.repos.MultiLeg.GetChainGroups(ctx, intentID); err != nil {
+		return nil, err
+	}
+	if input.Timeline, err = b.repos.ProofArtifacts.GetTimelineByIntentID(ctx, intentID); err != nil {
+		return nil, err
+	}
+
+	doc, err := NewIntentBundle(input)
+	if err != nil {
+		return nil, err
+	}
+	if b.signer != nil {
+		if err := doc.Sign(b.signer); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// NewIntentBundle assembles and seals an intent bundle
+func NewIntentBundle(input *IntentBundleInput) (*IntentBundle, error) {
+	b := &IntentBundle{
+		Schema:          IntentBundleSchema,
+		BundleVersion:   IntentBundleVersion,
+		BundleFormat:    IntentBundleFormat,
+		BundleID:        uuid.New(),
+		GeneratedAt:     time.Now().UTC(),
+		IntentID:        input.IntentID,
+		Legs:            make([]IntentLegEntry, 0, len(input.Legs)),
+		LegDependencies: make([]LegDependencyEntry, 0, len(input.Dependencies)),
+		ChainGroups:     make([]ChainGroupEntry, 0, len(input.ChainGroups)),
+		Timeline:        make([]TimelineEntry, 0, len(input.Timeline)),
+	}
+
+	if i := input.Intent; i != nil {
+		b.Intent = &IntentSection{
+			IntentID:         i.IntentID,
+			OperationID:      i.OperationID,
+			UserID:           i.UserID.String,
+			OrganizationADI:  i.OrganizationADI.String,
+			AccumulateTxHash: i.AccumulateTxHash,
+			AccountURL:       i.AccountURL.String,
+			Partition:        i.Partition.String,
+			LegCount:         i.LegCount,
+			ExecutionMode:    string(i.ExecutionMode),
+			ProofClass:       i.ProofClass,
+			Status:           string(i.Status),
+			LegsCompleted:    i.LegsCompleted,
+			LegsFailed:       i.LegsFailed,
+			LegsPending:      i.LegsPending,
+			IntentData:       rawOrNil(i.IntentData),
+			CrossChainData:   rawOrNil(i.CrossChainData),
+			GovernanceData:   rawOrNil(i.GovernanceData),
+			ReplayData:       rawOrNil(i.ReplayData),
+			CreatedAt:        i.CreatedAt.UTC(),
+			CompletedAt:      nullTimePtr(i.CompletedAt),
+			ExpiresAt:        nullTimePtr(i.ExpiresAt),
+			ErrorMessage:     i.ErrorMessage.String,
+		}
+	}
+
+	for _, leg := range input.Legs {
+		entry := IntentLegEntry{
+			LegID:           leg.LegID,
+			LegIndex:        leg.LegIndex,
+			TargetChain:     leg.TargetChain,
+			Role:            leg.Role,
+			Status:          leg.Status,
+			ProofID:         leg.ProofID,
+			AnchorTxHash:    derefString(leg.AnchorTxHash),
+			ExecutionTxHash: derefString(leg.ExecutionTxHash),
+			CreatedAt:       leg.CreatedAt.UTC(),
+			CompletedAt:     utcPtr(leg.CompletedAt),
+		}
+		if leg.ProofID != nil {
+			if jsonData, ok := input.LegBundles[*leg.ProofID]; ok {
+				if !json.Valid(jsonData) {
+					return nil, fmt.Errorf("bundle for leg %d is not valid JSON", leg.LegIndex)
+				}
+				entry.Bundle = jsonData
+				entry.BundleHash = hex.EncodeToString(Hash(jsonData))
+			}
+		}
+		b.Legs = append(b.Legs, entry)
+	}
+
+	for _, d := range input.Dependencies {
+		b.LegDependencies = append(b.LegDependencies, LegDependencyEntry{
+			DependencyID:   d.DependencyID,
+			LegID:          d.LegID,
+			DependsOnLegID: d.DependsOnLegID,
+			ConditionType:  string(d.ConditionType),
+			IsSatisfied:    d.IsSatisfied,
+			SatisfiedAt:    nullTimePtr(d.SatisfiedAt),
+		})
+	}
+
+	for _, g := range input.ChainGroups {
+		entry := ChainGroupEntry{
+			GroupID:      g.GroupID,
+			TargetChain:  g.TargetChain,
+			ChainKey:     g.ChainKey,
+			LegIDs:       append([]uuid.UUID{}, g.LegIDs...),
+			Status:       string(g.Status),
+			AnchorTxHash: g.AnchorTxHash.String,
+			AnchoredAt:   nullTimePtr(g.AnchoredAt),
+		}
+		if g.ChainID.Valid {
+			chainID := g.ChainID.Int64
+			entry.ChainID = &chainID
+		}
+		if g.BatchID.Valid {
+			batchID := g.BatchID.UUID
+			entry.BatchID = &batchID
+		}
+		if g.AnchorID.Valid {
+			anchorID := g.AnchorID.UUID
+			entry.AnchorID = &anchorID
+		}
+		if g.AnchorBlock.Valid {
+			block := g.AnchorBlock.Int64
+			entry.AnchorBlock = &block
+		}
+		b.ChainGroups = append(b.ChainGroups, entry)
+	}
+
+	for _, e := range input.Timeline {
+		b.Timeline = append(b.Timeline, TimelineEntry{
+			ProofID:     e.ProofID,
+			EventType:   e.EventType,
+			Phase:       e.Phase,
+			Action:      e.Action,
+			Message:     e.Message,
+			ActorType:   e.ActorType,
+			ActorID:     derefString(e.ActorID),
+			CurrentHash: hex.EncodeToString(e.CurrentHash),
+			Timestamp:   e.Timestamp.UTC(),
+		})
+	}
+
+	if err := b.seal(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// seal computes the manifest over the bundle's sections
+func (b *IntentBundle) seal() error {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to serialize intent bundle: %w", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("failed to serialize intent bundle: %w", err)
+	}
+
+	manifest, err := computeManifest(doc, intentSections)
+	if err != nil {
+		return err
+	}
+	b.Manifest = *manifest
+	return nil
+}
+
+// Sign embeds the service signature; the bundle must already be sealed
+func (b *IntentBundle) Sign(signer *Signer) error {
+	b.BundleSignature = nil
+
+	unsigned, err := b.Marshal()
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(unsigned, b.GeneratedAt)
+	if err != nil {
+		return err
+	}
+	b.BundleSignature = sig
+	return nil
+}
+
+// Marshal returns the bundle's compact JSON
+func (b *IntentBundle) Marshal() ([]byte, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize intent bundle: %w", err)
+	}
+	return data, nil
+}
+
+// AttestationCount returns the number of validator attestations across the
+// embedded leg bundles
+func (b *IntentBundle) AttestationCount() int {
+	count := 0
+	for _, leg := range b.Legs {
+		var doc struct {
+			ValidatorAttestations []json.RawMessage `json:"validator_attestations"`
+		}
+		if leg.Bundle != nil && json.Unmarshal(leg.Bundle, &doc) == nil {
+			count += len(doc.ValidatorAttestations)
+		}
+	}
+	return count
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// =============================================================================
+// VERIFICATION
+// =============================================================================
+
+// IntentVerification is the result of verifying an intent bundle
+type IntentVerification struct {
+	BundleValid   bool                   `json:"bundle_valid"`
+	ManifestValid bool                   `json:"manifest_valid"`
+	LegsValid     bool                   `json:"legs_valid"`
+	GraphValid    bool                   `json:"graph_valid"`
+	Legs          []LegVerification      `json:"legs"`
+	Signature     *SignatureVerification `json:"signature,omitempty"`
+	ManifestHash  string                 `json:"manifest_hash"`
+	Errors        []string               `json:"errors"`
+}
+
+// LegVerification is the check of one embedded leg bundle
+type LegVerification struct {
+	LegID     uuid.UUID              `json:"leg_id"`
+	LegIndex  int                    `json:"leg_index"`
+	ProofID   *uuid.UUID             `json:"proof_id,omitempty"`
+	HasBundle bool                   `json:"has_bundle"`
+	HashValid bool                   `json:"hash_valid"`
+	Signature *SignatureVerification `json:"signature,omitempty"`
+}
+
+// VerifyIntentBundle checks a decompressed intent bundle: its manifest, the
+// hash (and signature, for certen_v2) of every embedded leg bundle, that the
+// dependency graph and chain groups only reference the bundle's legs, and the
+// bundle signature if present. keys may be nil when no keys are trusted.
+func VerifyIntentBundle(jsonData []byte, keys *KeySet) (*IntentVerification, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("invalid bundle JSON: %w", err)
+	}
+	var b IntentBundle
+	if err := json.Unmarshal(jsonData, &b); err != nil {
+		return nil, fmt.Errorf("invalid intent bundle: %w", err)
+	}
+	if b.BundleFormat != IntentBundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format: %q", b.BundleFormat)
+	}
+	if keys == nil {
+		keys = NewKeySet(nil)
+	}
+
+	result := &IntentVerification{
+		Legs:   make([]LegVerification, 0, len(b.Legs)),
+		Errors: []string{},
+	}
+	fail := func(format string, args ...interface{}) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	// Manifest
+	manifest, err := computeManifest(doc, intentSections)
+	if err != nil {
+		fail("%v", err)
+	} else {
+		result.ManifestHash = manifest.ManifestHash
+		result.ManifestValid = manifest.ManifestHash == b.Manifest.ManifestHash
+		for name, hash := range manifest.Sections {
+			if b.Manifest.Sections[name] != hash {
+				result.ManifestValid = false
+				fail("section %s does not match manifest", name)
+			}
+		}
+		if manifest.ManifestHash != b.Manifest.ManifestHash {
+			fail("manifest hash mismatch")
+		}
+	}
+
+	// Leg bundles
+	result.LegsValid = true
+	legIDs := make(map[uuid.UUID]bool, len(b.Legs))
+	for _, leg := range b.Legs {
+		legIDs[leg.LegID] = true
+		lv := LegVerification{
+			LegID:     leg.LegID,
+			LegIndex:  leg.LegIndex,
+			ProofID:   leg.ProofID,
+			HasBundle: len(leg.Bundle) > 0 && string(leg.Bundle) != "null",
+		}
+		if !lv.HasBundle {
+			lv.HashValid = leg.BundleHash == ""
+		} else if expected, err := hex.DecodeString(leg.BundleHash); err == nil {
+			lv.HashValid = HashMatches(leg.Bundle, expected)
+		}
+		if !lv.HashValid {
+			result.LegsValid = false
+			fail("bundle for leg %d does not match its hash", leg.LegIndex)
+		}
+
+		if lv.HasBundle {
+			var legDoc struct {
+				BundleFormat string `json:"bundle_format"`
+			}
+			if json.Unmarshal(leg.Bundle, &legDoc) == nil && legDoc.BundleFormat == SignedBundleFormat {
+				sig, err := keys.VerifySignature(leg.Bundle)
+				if err != nil {
+					sig = &SignatureVerification{Error: err.Error()}
+				}
+				lv.Signature = sig
+				if !sig.Valid {
+					result.LegsValid = false
+					fail("signature on leg %d bundle is invalid: %s", leg.LegIndex, sig.Error)
+				}
+			}
+		}
+		result.Legs = append(result.Legs, lv)
+	}
+
+	// Dependency graph and chain groups
+	result.GraphValid = true
+	for _, d := range b.LegDependencies {
+		if !legIDs[d.LegID] || !legIDs[d.DependsOnLegID] {
+			result.GraphValid = false
+			fail("dependency %s references a leg not in the bundle", d.DependencyID)
+		}
+	}
+	for _, g := range b.ChainGroups {
+		for _, legID := range g.LegIDs {
+			if !legIDs[legID] {
+				result.GraphValid = false
+				fail("chain group %s references leg %s not in the bundle", g.ChainKey, legID)
+			}
+		}
+	}
+
+	// Bundle signature
+	if b.BundleSignature != nil {
+		sig, err := keys.VerifySignature(jsonData)
+		if err != nil {
+			return nil, err
+		}
+		result.Signature = sig
+		if !sig.Valid {
+			fail("bundle signature is invalid: %s", sig.Error)
+		}
+	}
+
+	result.BundleValid = result.ManifestValid && result.LegsValid && result.GraphValid &&
+		(result.Signature == nil || result.Signature.Valid)
+
+	return result, nil
+}
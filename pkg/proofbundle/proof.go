@@ -0,0 +1,527 @@
+// Copyright 2025 Certen Protocol
+//
+// Proof Bundles
+// Self-contained verification bundle for a single proof (format certen_v1)
+//
+// The four proof_components are assembled from stored records only and in a
+// fixed order (layers by number, governance by level, attestations by
+// validator), so the same records always produce the same components.
+
+package proofbundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// Proof bundle identifiers
+const (
+	ProofBundleSchema  = "https://certen.io/schemas/proof-bundle/v1.0"
+	ProofBundleVersion = "1.0"
+	ProofBundleFormat  = "certen_v1"
+)
+
+// ProofBundle is the certen_v1 document, or certen_v2 once signed
+type ProofBundle struct {
+	Schema        string    `json:"$schema"`
+	BundleVersion string    `json:"bundle_version"`
+	BundleFormat  string    `json:"bundle_format,omitempty"` // Set for certen_v2
+	BundleID      uuid.UUID `json:"bundle_id"`
+	GeneratedAt   time.Time `json:"generated_at"`
+
+	TransactionReference  TransactionReference   `json:"transaction_reference"`
+	ProofComponents       ProofComponents        `json:"proof_components"`
+	ValidatorAttestations []ValidatorAttestation `json:"validator_attestations"`
+	BundleIntegrity       BundleIntegrity        `json:"bundle_integrity"`
+
+	BundleSignature *BundleSignature `json:"bundle_signature,omitempty"` // certen_v2 only
+}
+
+// TransactionReference identifies the proven transaction
+type TransactionReference struct {
+	ProofID         uuid.UUID `json:"proof_id"`
+	AccumTxHash     string    `json:"accum_tx_hash"`
+	AccountURL      string    `json:"account_url"`
+	TransactionType string    `json:"transaction_type,omitempty"`
+}
+
+// ProofComponents holds the four proof layers; absent components are null
+type ProofComponents struct {
+	MerkleInclusion *MerkleInclusion `json:"1_merkle_inclusion"`
+	AnchorReference *AnchorDetails   `json:"2_anchor_reference"`
+	ChainedProof    *ChainedProof    `json:"3_chained_proof"`
+	GovernanceProof *GovernanceProof `json:"4_governance_proof"`
+}
+
+// MerkleInclusion proves the transaction is a leaf of the batch tree
+type MerkleInclusion struct {
+	BatchID    *uuid.UUID  `json:"batch_id,omitempty"`
+	MerkleRoot string      `json:"merkle_root"`
+	LeafHash   string      `json:"leaf_hash"`
+	LeafIndex  *int        `json:"leaf_index,omitempty"`
+	MerklePath []PathEntry `json:"merkle_path"`
+}
+
+// AnchorDetails is the external chain anchor for the proof
+type AnchorDetails struct {
+	TargetChain           string     `json:"target_chain"`
+	ChainID               string     `json:"chain_id,omitempty"`
+	NetworkName           string     `json:"network_name,omitempty"`
+	ContractAddress       string     `json:"contract_address,omitempty"`
+	AnchorTxHash          string     `json:"anchor_tx_hash"`
+	AnchorBlockNumber     int64      `json:"anchor_block_number"`
+	AnchorBlockHash       string     `json:"anchor_block_hash,omitempty"`
+	AnchorTimestamp       *time.Time `json:"anchor_timestamp,omitempty"`
+	Confirmations         int        `json:"confirmations"`
+	RequiredConfirmations *int       `json:"required_confirmations,omitempty"`
+	IsConfirmed           bool       `json:"is_confirmed"`
+	ConfirmedAt           *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// ChainedProof holds the L1/L2/L3 layers
+type ChainedProof struct {
+	Layer1 *ProofLayer `json:"layer1,omitempty"`
+	Layer2 *ProofLayer `json:"layer2,omitempty"`
+	Layer3 *ProofLayer `json:"layer3,omitempty"`
+}
+
+// ProofLayer is one chained proof layer
+type ProofLayer struct {
+	LayerName      string          `json:"layer_name"`
+	SourceHash     string          `json:"source_hash"`
+	TargetHash     string          `json:"target_hash"`
+	ReceiptEntries json.RawMessage `json:"receipt_entries,omitempty"`
+	Layer          json.RawMessage `json:"layer,omitempty"`
+	Verified       bool            `json:"verified"`
+}
+
+// GovernanceProof holds the G0/G1/G2 levels
+type GovernanceProof struct {
+	Level string           `json:"level"`
+	G0    *GovernanceLevel `json:"g0,omitempty"`
+	G1    *GovernanceLevel `json:"g1,omitempty"`
+	G2    *GovernanceLevel `json:"g2,omitempty"`
+}
+
+// GovernanceLevel is one governance proof level
+type GovernanceLevel struct {
+	LevelName string          `json:"level_name"`
+	Level     json.RawMessage `json:"level,omitempty"`
+	Verified  bool            `json:"verified"`
+}
+
+// BundleIntegrity binds the bundle to the stored artifact and custody chain
+type BundleIntegrity struct {
+	ArtifactHash     string `json:"artifact_hash"`
+	CustodyChainHash string `json:"custody_chain_hash,omitempty"`
+	ComponentsHash   string `json:"components_hash"`
+}
+
+// ProofBundleInput is the stored data a proof bundle is built from
+type ProofBundleInput struct {
+	Details     *database.ProofArtifactWithDetails
+	MerklePath  []database.MerklePathNode // From the batch transaction; nil if unbatched
+	CustodyHash []byte                    // Latest custody event hash; nil if none
+}
+
+// NewProofBundle assembles a certen_v1 bundle
+func NewProofBundle(input *ProofBundleInput) (*ProofBundle, error) {
+	proof := input.Details.ProofArtifact
+
+	b := &ProofBundle{
+		Schema:        ProofBundleSchema,
+		BundleVersion: ProofBundleVersion,
+		BundleID:      uuid.New(),
+		GeneratedAt:   time.Now().UTC(),
+		TransactionReference: TransactionReference{
+			ProofID:     proof.ProofID,
+			AccumTxHash: proof.AccumTxHash,
+			AccountURL:  proof.AccountURL,
+		},
+		ProofComponents:       BuildProofComponents(input),
+		ValidatorAttestations: proofAttestations(input.Details.Attestations),
+		BundleIntegrity: BundleIntegrity{
+			ArtifactHash: "sha256:" + hex.EncodeToString(proof.ArtifactHash),
+		},
+	}
+	if meta := input.Details.TransactionMetadata; meta != nil {
+		b.TransactionReference.TransactionType = meta.IntentType
+	}
+	if len(input.CustodyHash) > 0 {
+		b.BundleIntegrity.CustodyChainHash = "sha256:" + hex.EncodeToString(input.CustodyHash)
+	}
+
+	components, err := json.Marshal(b.ProofComponents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize proof components: %w", err)
+	}
+	b.BundleIntegrity.ComponentsHash = "sha256:" + hex.EncodeToString(Hash(components))
+
+	return b, nil
+}
+
+// Sign turns the bundle into a certen_v2 bundle signed by signer
+func (b *ProofBundle) Sign(signer *Signer) error {
+	b.Schema = SignedBundleSchema
+	b.BundleVersion = SignedBundleVersion
+	b.BundleFormat = SignedBundleFormat
+	b.BundleSignature = nil
+
+	unsigned, err := b.Marshal()
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(unsigned, b.GeneratedAt)
+	if err != nil {
+		return err
+	}
+	b.BundleSignature = sig
+	return nil
+}
+
+// Format returns the bundle's format name
+func (b *ProofBundle) Format() string {
+	if b.BundleFormat != "" {
+		return b.BundleFormat
+	}
+	return ProofBundleFormat
+}
+
+// Marshal returns the bundle's compact JSON
+func (b *ProofBundle) Marshal() ([]byte, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize proof bundle: %w", err)
+	}
+	return data, nil
+}
+
+// BuildProofComponents assembles the four proof components from stored records
+func BuildProofComponents(input *ProofBundleInput) ProofComponents {
+	details := input.Details
+	proof := details.ProofArtifact
+	var c ProofComponents
+
+	if len(proof.MerkleRoot) > 0 && len(proof.LeafHash) > 0 {
+		c.MerkleInclusion = &MerkleInclusion{
+			BatchID:    proof.BatchID,
+			MerkleRoot: hex.EncodeToString(proof.MerkleRoot),
+			LeafHash:   hex.EncodeToString(proof.LeafHash),
+			LeafIndex:  proof.LeafIndex,
+			MerklePath: ToPathEntries(input.MerklePath),
+		}
+	}
+
+	if ref := details.AnchorReference; ref != nil {
+		c.AnchorReference = &AnchorDetails{
+			TargetChain:           ref.TargetChain,
+			ChainID:               ref.ChainID,
+			NetworkName:           ref.NetworkName,
+			ContractAddress:       derefString(ref.ContractAddress),
+			AnchorTxHash:          ref.AnchorTxHash,
+			AnchorBlockNumber:     ref.AnchorBlockNumber,
+			AnchorBlockHash:       derefString(ref.AnchorBlockHash),
+			AnchorTimestamp:       utcPtr(ref.AnchorTimestamp),
+			Confirmations:         ref.Confirmations,
+			RequiredConfirmations: ref.RequiredConfirmations,
+			IsConfirmed:           ref.IsConfirmed,
+			ConfirmedAt:           utcPtr(ref.ConfirmedAt),
+		}
+	} else if proof.AnchorTxHash != nil {
+		// Anchored before anchor_references were recorded
+		c.AnchorReference = &AnchorDetails{
+			TargetChain:  derefString(proof.AnchorChain),
+			AnchorTxHash: *proof.AnchorTxHash,
+		}
+		if proof.AnchorBlockNumber != nil {
+			c.AnchorReference.AnchorBlockNumber = *proof.AnchorBlockNumber
+		}
+	}
+
+	if len(details.ChainedLayers) > 0 {
+		layers := append([]database.ChainedProofLayer(nil), details.ChainedLayers...)
+		sort.SliceStable(layers, func(i, j int) bool { return layers[i].LayerNumber < layers[j].LayerNumber })
+
+		chained := &ChainedProof{}
+		for _, l := range layers {
+			layer := &ProofLayer{
+				LayerName:      l.LayerName,
+				SourceHash:     hex.EncodeToString(l.SourceHash),
+				TargetHash:     hex.EncodeToString(l.TargetHash),
+				ReceiptEntries: rawOrNil(l.ReceiptEntries),
+				Layer:          rawOrNil(l.LayerJSON),
+				Verified:       l.Verified,
+			}
+			switch l.LayerNumber {
+			case 1:
+				chained.Layer1 = layer
+			case 2:
+				chained.Layer2 = layer
+			case 3:
+				chained.Layer3 = layer
+			}
+		}
+		c.ChainedProof = chained
+	}
+
+	if len(details.GovernanceLevels) > 0 {
+		levels := append([]database.GovernanceProofLevel(nil), details.GovernanceLevels...)
+		sort.SliceStable(levels, func(i, j int) bool { return levels[i].GovLevel < levels[j].GovLevel })
+
+		gov := &GovernanceProof{}
+		for _, l := range levels {
+			level := &GovernanceLevel{
+				LevelName: l.LevelName,
+				Level:     rawOrNil(l.LevelJSON),
+				Verified:  l.Verified,
+			}
+			switch l.GovLevel {
+			case database.GovLevelG0:
+				gov.G0 = level
+			case database.GovLevelG1:
+				gov.G1 = level
+			case database.GovLevelG2:
+				gov.G2 = level
+			}
+			// Highest level present, unless the proof records its own
+			gov.Level = string(l.GovLevel)
+		}
+		if proof.GovLevel != nil {
+			gov.Level = string(*proof.GovLevel)
+		}
+		c.GovernanceProof = gov
+	}
+
+	return c
+}
+
+// Includes reports which components a bundle carries, for the proof_bundles flags
+func (c ProofComponents) Includes() (merkle, anchor, chained, governance bool) {
+	return c.MerkleInclusion != nil, c.AnchorReference != nil, c.ChainedProof != nil, c.GovernanceProof != nil
+}
+
+// proofAttestations converts a proof's attestations, ordered by validator
+func proofAttestations(attestations []database.ProofAttestation) []ValidatorAttestation {
+	sorted := append([]database.ProofAttestation(nil), attestations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ValidatorID != sorted[j].ValidatorID {
+			return sorted[i].ValidatorID < sorted[j].ValidatorID
+		}
+		return sorted[i].AttestedAt.Before(sorted[j].AttestedAt)
+	})
+
+	entries := make([]ValidatorAttestation, len(sorted))
+	for i, a := range sorted {
+		entries[i] = toValidatorAttestation(a)
+	}
+	return entries
+}
+
+// =============================================================================
+// REGENERATION
+// =============================================================================
+
+// RebuildReason explains why a stored bundle must be regenerated ("" if
+// current). format is the format the builder currently produces.
+func RebuildReason(stored *database.ProofBundle, details *database.ProofArtifactWithDetails, format string, now time.Time) string {
+	if stored == nil {
+		return "no stored bundle"
+	}
+	if stored.ExpiresAt != nil && !now.Before(*stored.ExpiresAt) {
+		return "bundle expired"
+	}
+	if stored.BundleFormat != format {
+		return fmt.Sprintf("stored format %s", stored.BundleFormat)
+	}
+	if stored.AttestationCount != len(details.Attestations) {
+		return fmt.Sprintf("attestations changed (%d -> %d)", stored.AttestationCount, len(details.Attestations))
+	}
+
+	hasAnchor := details.AnchorReference != nil || details.AnchorTxHash != nil
+	if !stored.IncludesAnchor && hasAnchor {
+		return "proof anchored"
+	}
+	if ref := details.AnchorReference; ref != nil && ref.IsConfirmed && ref.ConfirmedAt != nil {
+		if ref.ConfirmedAt.After(stored.CreatedAt) {
+			return "anchor confirmed"
+		}
+	}
+	return ""
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// rawOrNil drops empty raw JSON, which cannot be marshaled
+func rawOrNil(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
+// =============================================================================
+// VERIFICATION
+// =============================================================================
+
+// ProofVerification is the result of verifying a certen_v1/v2 bundle offline
+type ProofVerification struct {
+	BundleValid         bool                   `json:"bundle_valid"`
+	ComponentsHashValid bool                   `json:"components_hash_valid"`
+	MerkleValid         bool                   `json:"merkle_valid"`
+	Components          map[string]bool        `json:"components"`
+	Attestations        QuorumStatus           `json:"attestations"`
+	InvalidAttestations []string               `json:"invalid_attestations,omitempty"`
+	Signature           *SignatureVerification `json:"signature,omitempty"`
+	ProofID             uuid.UUID              `json:"proof_id"`
+	Errors              []string               `json:"errors"`
+}
+
+// VerifyProofBundle checks a decompressed proof bundle using only its own
+// contents: the components hash, the Merkle inclusion path against the root,
+// each validator's Ed25519 signature over its attested hash, attestation
+// quorum, and the service signature of certen_v2 bundles. Stored
+// signature_valid flags are not trusted. keys may be nil when no keys are
+// trusted.
+func VerifyProofBundle(jsonData []byte, keys *KeySet) (*ProofVerification, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("invalid bundle JSON: %w", err)
+	}
+	var b ProofBundle
+	if err := json.Unmarshal(jsonData, &b); err != nil {
+		return nil, fmt.Errorf("invalid proof bundle: %w", err)
+	}
+	if format := b.Format(); format != ProofBundleFormat && format != SignedBundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format: %q", format)
+	}
+	if keys == nil {
+		keys = NewKeySet(nil)
+	}
+
+	merkleIncluded, anchorIncluded, chainedIncluded, governanceIncluded := b.ProofComponents.Includes()
+	result := &ProofVerification{
+		ProofID: b.TransactionReference.ProofID,
+		Components: map[string]bool{
+			"merkle_inclusion": merkleIncluded,
+			"anchor_reference": anchorIncluded,
+			"chained_proof":    chainedIncluded,
+			"governance_proof": governanceIncluded,
+		},
+		Errors: []string{},
+	}
+	fail := func(format string, args ...interface{}) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	// Components hash
+	var components bytes.Buffer
+	if err := json.Compact(&components, doc["proof_components"]); err != nil {
+		fail("proof_components is missing or malformed")
+	} else {
+		result.ComponentsHashValid = "sha256:"+hex.EncodeToString(Hash(components.Bytes())) == b.BundleIntegrity.ComponentsHash
+		if !result.ComponentsHashValid {
+			fail("components hash mismatch")
+		}
+	}
+
+	// Merkle inclusion
+	var root []byte
+	if inc := b.ProofComponents.MerkleInclusion; inc == nil {
+		fail("bundle has no Merkle inclusion proof")
+	} else {
+		leaf, leafErr := hex.DecodeString(inc.LeafHash)
+		decodedRoot, rootErr := hex.DecodeString(inc.MerkleRoot)
+		if leafErr != nil || rootErr != nil {
+			fail("Merkle inclusion hashes are malformed")
+		} else {
+			root = decodedRoot
+			result.MerkleValid = merkle.VerifyPath(leaf, FromPathEntries(inc.MerklePath), root)
+			if !result.MerkleValid {
+				fail("Merkle path does not lead to root %s", inc.MerkleRoot)
+			}
+		}
+	}
+
+	// Validator attestations
+	valid := 0
+	for _, a := range b.ValidatorAttestations {
+		if err := verifyAttestation(a, root); err != nil {
+			result.InvalidAttestations = append(result.InvalidAttestations, a.ValidatorID)
+			fail("attestation by %s: %v", a.ValidatorID, err)
+			continue
+		}
+		valid++
+	}
+	total := len(b.ValidatorAttestations)
+	result.Attestations = QuorumStatus{
+		Total:     total,
+		Valid:     valid,
+		Required:  RequiredQuorum(total),
+		QuorumMet: valid >= RequiredQuorum(total),
+	}
+
+	// Service signature
+	if b.Format() == SignedBundleFormat {
+		sig, err := keys.VerifySignature(jsonData)
+		if err != nil {
+			sig = &SignatureVerification{Error: err.Error()}
+		}
+		result.Signature = sig
+		if !sig.Valid {
+			fail("bundle signature is invalid: %s", sig.Error)
+		}
+	}
+
+	result.BundleValid = result.ComponentsHashValid && result.MerkleValid && result.Attestations.QuorumMet &&
+		(result.Signature == nil || result.Signature.Valid)
+
+	return result, nil
+}
+
+// verifyAttestation checks a validator's Ed25519 signature over its attested
+// hash, and that it attests to the bundle's Merkle root
+func verifyAttestation(a ValidatorAttestation, root []byte) error {
+	pub, err := hex.DecodeString(a.ValidatorPubkey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("malformed validator public key")
+	}
+	signature, err := hex.DecodeString(a.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return errors.New("malformed signature")
+	}
+	attested, err := hex.DecodeString(a.AttestedHash)
+	if err != nil || len(attested) == 0 {
+		return errors.New("malformed attested hash")
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), attested, signature) {
+		return errors.New("signature does not match attested hash")
+	}
+	if a.MerkleRoot != "" && root != nil && a.MerkleRoot != hex.EncodeToString(root) {
+		return errors.New("attests to a different Merkle root")
+	}
+	return nil
+}
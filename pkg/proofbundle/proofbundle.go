@@ -0,0 +1,111 @@
+// Copyright 2025 Certen Protocol
+//
+// Package proofbundle builds and verifies Certen proof bundles.
+//
+// Bundles are compact JSON documents stored and served gzip-compressed.
+// Every bundle type is checked with the same rules:
+// - The SHA-256 of the decompressed JSON matches the recorded bundle hash
+// - Its proof components are present
+// - A majority of its validator attestations carry valid signatures
+
+package proofbundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// Compress gzips bundle JSON for storage or download
+func Compress(jsonData []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(jsonData); err != nil {
+		return nil, fmt.Errorf("failed to compress bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the JSON inside a gzipped bundle
+func Decompress(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress bundle: %w", err)
+	}
+	defer gz.Close()
+
+	jsonData, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	return jsonData, nil
+}
+
+// Hash returns the SHA-256 of bundle JSON
+func Hash(jsonData []byte) []byte {
+	sum := sha256.Sum256(jsonData)
+	return sum[:]
+}
+
+// HashMatches reports whether jsonData hashes to expected. Older bundles
+// recorded the hash of pretty-printed JSON while storing compact JSON, so the
+// indented form is accepted as a fallback.
+func HashMatches(jsonData, expected []byte) bool {
+	if bytes.Equal(Hash(jsonData), expected) {
+		return true
+	}
+
+	var prettyBuf bytes.Buffer
+	if json.Indent(&prettyBuf, jsonData, "", "  ") == nil {
+		return bytes.Equal(Hash(prettyBuf.Bytes()), expected)
+	}
+	return false
+}
+
+// RequiredQuorum returns the number of valid attestations needed out of total:
+// a majority of attestors, and at least one
+func RequiredQuorum(total int) int {
+	required := total/2 + 1
+	if required < 1 {
+		required = 1
+	}
+	return required
+}
+
+// PathEntry is a Merkle path step in bundle format.
+// Right means the sibling sits on the right: hash(current || sibling).
+type PathEntry struct {
+	Hash  string `json:"hash"`
+	Right bool   `json:"right"`
+}
+
+// ToPathEntries converts stored path nodes to bundle format
+func ToPathEntries(path []database.MerklePathNode) []PathEntry {
+	entries := make([]PathEntry, len(path))
+	for i, node := range path {
+		entries[i] = PathEntry{Hash: node.Hash, Right: node.Position == merkle.PositionRight}
+	}
+	return entries
+}
+
+// FromPathEntries converts bundle format path steps to path nodes
+func FromPathEntries(entries []PathEntry) []database.MerklePathNode {
+	path := make([]database.MerklePathNode, len(entries))
+	for i, entry := range entries {
+		position := merkle.PositionLeft
+		if entry.Right {
+			position = merkle.PositionRight
+		}
+		path[i] = database.MerklePathNode{Hash: entry.Hash, Position: position}
+	}
+	return path
+}
@@ -0,0 +1,669 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for proof bundle building and verification
+
+package proofbundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+func testBatchInput(t *testing.T, n int) *BatchBundleInput {
+	t.Helper()
+
+	batchID := uuid.New()
+	txs := make([]*database.BatchTransaction, n)
+	for i := range txs {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("bundle-tx-%d", i)))
+		txs[i] = &database.BatchTransaction{
+			BatchID:     batchID,
+			TreeIndex:   i,
+			TxHash:      sum[:],
+			AccumTxHash: fmt.Sprintf("accum-%d", i),
+		}
+	}
+	tree, err := merkle.TreeFromTransactions(txs)
+	if err != nil {
+		t.Fatalf("TreeFromTransactions failed: %v", err)
+	}
+
+	return &BatchBundleInput{
+		Batch: &database.AnchorBatch{
+			BatchID:    batchID,
+			BatchType:  database.BatchTypeOnCadence,
+			MerkleRoot: tree.Root(),
+			TxCount:    n,
+			Status:     database.BatchStatusConfirmed,
+			StartTime:  time.Now(),
+		},
+		Transactions: txs,
+		Anchors: []*database.AnchorRecord{{
+			AnchorID:     uuid.New(),
+			BatchID:      batchID,
+			TargetChain:  database.TargetChainEthereum,
+			AnchorTxHash: "0xabc",
+			MerkleRoot:   tree.Root(),
+			IsFinal:      true,
+		}},
+		ValidatorAttestations: []database.ProofAttestation{
+			{AttestationID: uuid.New(), ValidatorID: "v1", SignatureValid: true},
+			{AttestationID: uuid.New(), ValidatorID: "v2", SignatureValid: true},
+			{AttestationID: uuid.New(), ValidatorID: "v3", SignatureValid: false},
+		},
+	}
+}
+
+// ============================================================================
+// Shared Rule Tests
+// ============================================================================
+
+func TestRequiredQuorum(t *testing.T) {
+	cases := map[int]int{0: 1, 1: 1, 2: 2, 3: 2, 4: 3, 7: 4}
+	for total, expected := range cases {
+		if got := RequiredQuorum(total); got != expected {
+			t.Errorf("RequiredQuorum(%d): expected %d, got %d", total, expected, got)
+		}
+	}
+}
+
+func TestHashMatches_LegacyPretty(t *testing.T) {
+	compact := []byte(`{"a":1,"b":[1,2]}`)
+	var pretty bytes.Buffer
+	json.Indent(&pretty, compact, "", "  ")
+
+	if !HashMatches(compact, Hash(compact)) {
+		t.Error("Expected compact hash to match")
+	}
+	if !HashMatches(compact, Hash(pretty.Bytes())) {
+		t.Error("Expected legacy pretty-printed hash to match")
+	}
+	if HashMatches(compact, Hash([]byte("other"))) {
+		t.Error("Expected unrelated hash not to match")
+	}
+}
+
+func TestCompress_RoundTrip(t *testing.T) {
+	data := []byte(`{"bundle":"data"}`)
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	out, err := Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress failed: %v", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Errorf("Expected %s, got %s", data, out)
+	}
+}
+
+// ============================================================================
+// Batch Bundle Tests
+// ============================================================================
+
+func TestBatchBundle_BuildAndVerify(t *testing.T) {
+	b, err := NewBatchBundle(testBatchInput(t, 5))
+	if err != nil {
+		t.Fatalf("NewBatchBundle failed: %v", err)
+	}
+	if b.Manifest.ManifestHash == "" || len(b.Manifest.Sections) != len(batchSections) {
+		t.Fatalf("Expected manifest over %d sections, got %+v", len(batchSections), b.Manifest)
+	}
+
+	data, _ := b.Marshal()
+	result, err := VerifyBatchBundle(data)
+	if err != nil {
+		t.Fatalf("VerifyBatchBundle failed: %v", err)
+	}
+	if !result.BundleValid {
+		t.Errorf("Expected valid bundle, got errors: %v", result.Errors)
+	}
+	if result.Attestations.Valid != 2 || !result.Attestations.QuorumMet {
+		t.Errorf("Expected 2 of 3 valid attestations meeting quorum, got %+v", result.Attestations)
+	}
+}
+
+func TestBatchBundle_PrettyPrintedStillVerifies(t *testing.T) {
+	b, _ := NewBatchBundle(testBatchInput(t, 3))
+	pretty, _ := json.MarshalIndent(b, "", "  ")
+
+	result, err := VerifyBatchBundle(pretty)
+	if err != nil {
+		t.Fatalf("VerifyBatchBundle failed: %v", err)
+	}
+	if !result.ManifestValid {
+		t.Errorf("Expected manifest to survive re-indentation, got errors: %v", result.Errors)
+	}
+}
+
+func TestBatchBundle_TamperedLeaf(t *testing.T) {
+	b, _ := NewBatchBundle(testBatchInput(t, 4))
+	b.Leaves[2].AccumTxHash = "forged"
+	data, _ := b.Marshal()
+
+	result, _ := VerifyBatchBundle(data)
+	if result.ManifestValid || result.BundleValid {
+		t.Error("Expected tampered leaf to invalidate the manifest")
+	}
+}
+
+func TestBatchBundle_AnchorMismatch(t *testing.T) {
+	input := testBatchInput(t, 4)
+	wrong := sha256.Sum256([]byte("wrong"))
+	input.Anchors[0].MerkleRoot = wrong[:]
+
+	b, _ := NewBatchBundle(input)
+	data, _ := b.Marshal()
+
+	result, _ := VerifyBatchBundle(data)
+	if result.AnchorValid || result.BundleValid {
+		t.Error("Expected anchored root mismatch to fail verification")
+	}
+	if !result.ManifestValid {
+		t.Error("Expected manifest to be valid")
+	}
+}
+
+func TestBatchBundle_NoQuorum(t *testing.T) {
+	input := testBatchInput(t, 2)
+	input.ValidatorAttestations = nil
+
+	b, _ := NewBatchBundle(input)
+	data, _ := b.Marshal()
+
+	result, _ := VerifyBatchBundle(data)
+	if result.Attestations.QuorumMet || result.BundleValid {
+		t.Error("Expected bundle without attestations to fail quorum")
+	}
+}
+
+func TestBatchBundle_OpenBatch(t *testing.T) {
+	input := testBatchInput(t, 2)
+	input.Batch.Status = database.BatchStatusPending
+
+	if _, err := NewBatchBundle(input); err != ErrBatchOpen {
+		t.Errorf("Expected ErrBatchOpen, got %v", err)
+	}
+}
+
+func TestVerifyBatchBundle_WrongFormat(t *testing.T) {
+	_, err := VerifyBatchBundle([]byte(`{"bundle_format":"certen_v1"}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+}
+
+// ============================================================================
+// Proof Bundle Tests
+// ============================================================================
+
+func testProofDetails() *database.ProofArtifactWithDetails {
+	batchID := uuid.New()
+	index := 1
+	anchorTx := "0xanchor"
+	g1 := database.GovLevelG1
+	confirmedAt := time.Now().Add(-time.Hour)
+
+	return &database.ProofArtifactWithDetails{
+		ProofArtifact: database.ProofArtifact{
+			ProofID:      uuid.New(),
+			AccumTxHash:  "accum-1",
+			AccountURL:   "acc://example.acme",
+			BatchID:      &batchID,
+			MerkleRoot:   []byte{0x01},
+			LeafHash:     []byte{0x02},
+			LeafIndex:    &index,
+			AnchorTxHash: &anchorTx,
+			GovLevel:     &g1,
+			ArtifactHash: []byte{0x03},
+		},
+		ChainedLayers: []database.ChainedProofLayer{
+			{LayerNumber: 2, LayerName: "bvn_to_dn", LayerJSON: json.RawMessage(`{"b":2}`)},
+			{LayerNumber: 1, LayerName: "tx_to_bvn"},
+		},
+		GovernanceLevels: []database.GovernanceProofLevel{
+			{GovLevel: database.GovLevelG1, LevelName: "authority"},
+			{GovLevel: database.GovLevelG0, LevelName: "inclusion"},
+		},
+		Attestations: []database.ProofAttestation{
+			{ValidatorID: "v2", SignatureValid: true},
+			{ValidatorID: "v1", SignatureValid: true},
+		},
+		AnchorReference: &database.AnchorReferenceRecord{
+			TargetChain:  "ethereum",
+			AnchorTxHash: anchorTx,
+			IsConfirmed:  true,
+			ConfirmedAt:  &confirmedAt,
+		},
+	}
+}
+
+func TestNewProofBundle_DeterministicComponents(t *testing.T) {
+	details := testProofDetails()
+	first, err := NewProofBundle(&ProofBundleInput{Details: details})
+	if err != nil {
+		t.Fatalf("NewProofBundle failed: %v", err)
+	}
+
+	// Same records in a different order
+	details.ChainedLayers[0], details.ChainedLayers[1] = details.ChainedLayers[1], details.ChainedLayers[0]
+	details.GovernanceLevels[0], details.GovernanceLevels[1] = details.GovernanceLevels[1], details.GovernanceLevels[0]
+	details.Attestations[0], details.Attestations[1] = details.Attestations[1], details.Attestations[0]
+	second, _ := NewProofBundle(&ProofBundleInput{Details: details})
+
+	if first.BundleIntegrity.ComponentsHash != second.BundleIntegrity.ComponentsHash {
+		t.Error("Expected identical components hash for the same records")
+	}
+	if second.ValidatorAttestations[0].ValidatorID != "v1" {
+		t.Errorf("Expected attestations ordered by validator, got '%s' first", second.ValidatorAttestations[0].ValidatorID)
+	}
+	if first.ProofComponents.GovernanceProof.Level != "G1" {
+		t.Errorf("Expected governance level G1, got '%s'", first.ProofComponents.GovernanceProof.Level)
+	}
+}
+
+func TestProofComponents_Includes(t *testing.T) {
+	details := testProofDetails()
+	details.ChainedLayers = nil
+	details.AnchorReference = nil
+	details.AnchorTxHash = nil
+
+	merkleOK, anchor, chained, governance := BuildProofComponents(&ProofBundleInput{Details: details}).Includes()
+	if !merkleOK || anchor || chained || !governance {
+		t.Errorf("Expected merkle and governance only, got merkle=%v anchor=%v chained=%v governance=%v",
+			merkleOK, anchor, chained, governance)
+	}
+}
+
+func TestRebuildReason(t *testing.T) {
+	now := time.Now()
+	details := testProofDetails()
+	current := &database.ProofBundle{
+		BundleFormat:     ProofBundleFormat,
+		IncludesAnchor:   true,
+		AttestationCount: 2,
+		CreatedAt:        now.Add(-time.Minute),
+	}
+
+	if reason := RebuildReason(current, details, ProofBundleFormat, now); reason != "" {
+		t.Errorf("Expected current bundle, got '%s'", reason)
+	}
+	if RebuildReason(nil, details, ProofBundleFormat, now) == "" {
+		t.Error("Expected missing bundle to be built")
+	}
+
+	expired := *current
+	past := now.Add(-time.Second)
+	expired.ExpiresAt = &past
+	if RebuildReason(&expired, details, ProofBundleFormat, now) == "" {
+		t.Error("Expected expired bundle to be rebuilt")
+	}
+
+	attested := *current
+	attested.AttestationCount = 1
+	if RebuildReason(&attested, details, ProofBundleFormat, now) == "" {
+		t.Error("Expected new attestations to trigger a rebuild")
+	}
+
+	unanchored := *current
+	unanchored.IncludesAnchor = false
+	if RebuildReason(&unanchored, details, ProofBundleFormat, now) == "" {
+		t.Error("Expected new anchor to trigger a rebuild")
+	}
+
+	confirmedLater := *current
+	confirmedLater.CreatedAt = details.AnchorReference.ConfirmedAt.Add(-time.Minute)
+	if RebuildReason(&confirmedLater, details, ProofBundleFormat, now) == "" {
+		t.Error("Expected anchor confirmation after build to trigger a rebuild")
+	}
+}
+
+// ============================================================================
+// Signing Tests
+// ============================================================================
+
+func testSigner(t *testing.T, seed byte) *Signer {
+	t.Helper()
+	signer, err := ParseSigningKey(strings.Repeat(fmt.Sprintf("%02x", seed), 32))
+	if err != nil {
+		t.Fatalf("ParseSigningKey failed: %v", err)
+	}
+	return signer
+}
+
+func signedBundle(t *testing.T, signer *Signer) []byte {
+	t.Helper()
+	b, err := NewProofBundle(&ProofBundleInput{Details: testProofDetails()})
+	if err != nil {
+		t.Fatalf("NewProofBundle failed: %v", err)
+	}
+	if err := b.Sign(signer); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if b.Format() != SignedBundleFormat {
+		t.Errorf("Expected format %s, got %s", SignedBundleFormat, b.Format())
+	}
+	data, _ := b.Marshal()
+	return data
+}
+
+func TestSignedBundle_Verifies(t *testing.T) {
+	signer := testSigner(t, 1)
+	data := signedBundle(t, signer)
+
+	result, err := NewKeySet(signer).VerifySignature(data)
+	if err != nil {
+		t.Fatalf("VerifySignature failed: %v", err)
+	}
+	if !result.Valid || result.KeyID != signer.KeyID() || result.KeyStatus != KeyStatusActive {
+		t.Errorf("Expected valid signature by active key %s, got %+v", signer.KeyID(), result)
+	}
+
+	// Re-indenting does not change the signed payload
+	var pretty bytes.Buffer
+	json.Indent(&pretty, data, "", "  ")
+	if result, _ := NewKeySet(signer).VerifySignature(pretty.Bytes()); !result.Valid {
+		t.Errorf("Expected re-indented bundle to verify, got %+v", result)
+	}
+}
+
+func TestSignedBundle_Tampered(t *testing.T) {
+	signer := testSigner(t, 1)
+	data := bytes.Replace(signedBundle(t, signer), []byte("acc://example.acme"), []byte("acc://forged.acme"), 1)
+
+	result, err := NewKeySet(signer).VerifySignature(data)
+	if err != nil {
+		t.Fatalf("VerifySignature failed: %v", err)
+	}
+	if result.Valid {
+		t.Error("Expected tampered bundle to fail signature verification")
+	}
+}
+
+func TestSignedBundle_RetiredAndUnknownKeys(t *testing.T) {
+	oldSigner := testSigner(t, 1)
+	data := signedBundle(t, oldSigner)
+
+	keys := NewKeySet(testSigner(t, 2))
+	if result, _ := keys.VerifySignature(data); result.Valid || result.Error != ErrUnknownKey.Error() {
+		t.Errorf("Expected unknown key, got %+v", result)
+	}
+
+	if err := keys.AddRetired(hex.EncodeToString(oldSigner.PublicKey())); err != nil {
+		t.Fatalf("AddRetired failed: %v", err)
+	}
+	result, _ := keys.VerifySignature(data)
+	if !result.Valid || result.KeyStatus != KeyStatusRetired {
+		t.Errorf("Expected valid signature by retired key, got %+v", result)
+	}
+
+	jwks := keys.JWKs()
+	if len(jwks) != 2 || jwks[0].Status != KeyStatusActive {
+		t.Errorf("Expected active key listed first of 2, got %+v", jwks)
+	}
+}
+
+func TestVerifySignature_Unsigned(t *testing.T) {
+	b, _ := NewProofBundle(&ProofBundleInput{Details: testProofDetails()})
+	data, _ := b.Marshal()
+
+	if _, err := NewKeySet(nil).VerifySignature(data); err == nil {
+		t.Error("Expected error for unsigned bundle")
+	}
+}
+
+func TestParseSigningKey_InvalidLength(t *testing.T) {
+	if _, err := ParseSigningKey("abcd"); err == nil {
+		t.Error("Expected error for short key")
+	}
+}
+
+// ============================================================================
+// Intent Bundle Tests
+// ============================================================================
+
+func testIntentInput(t *testing.T, signer *Signer) *IntentBundleInput {
+	t.Helper()
+
+	var legBundle []byte
+	details := testProofDetails()
+	if signer != nil {
+		legBundle = signedBundle(t, signer)
+	} else {
+		b, err := NewProofBundle(&ProofBundleInput{Details: details})
+		if err != nil {
+			t.Fatalf("NewProofBundle failed: %v", err)
+		}
+		legBundle, _ = b.Marshal()
+	}
+
+	proofID := details.ProofID
+	source, dest := uuid.New(), uuid.New()
+	return &IntentBundleInput{
+		IntentID: "intent-1",
+		Legs: []database.LegProofDetail{
+			{LegID: source, LegIndex: 0, TargetChain: "ethereum", Role: "source", Status: "completed", ProofID: &proofID},
+			{LegID: dest, LegIndex: 1, TargetChain: "arbitrum", Role: "destination", Status: "pending"},
+		},
+		LegBundles: map[uuid.UUID][]byte{proofID: legBundle},
+		Dependencies: []*database.LegDependency{
+			{DependencyID: uuid.New(), IntentID: "intent-1", LegID: dest, DependsOnLegID: source, ConditionType: "completion"},
+		},
+		ChainGroups: []*database.IntentChainGroup{
+			{GroupID: uuid.New(), IntentID: "intent-1", TargetChain: "ethereum", ChainKey: "ethereum:1", LegIDs: []uuid.UUID{source}},
+		},
+	}
+}
+
+func TestIntentBundle_BuildAndVerify(t *testing.T) {
+	b, err := NewIntentBundle(testIntentInput(t, nil))
+	if err != nil {
+		t.Fatalf("NewIntentBundle failed: %v", err)
+	}
+	if b.Legs[1].Bundle != nil || b.Legs[0].BundleHash == "" {
+		t.Errorf("Expected bundle on the proven leg only, got %+v", b.Legs)
+	}
+
+	data, _ := b.Marshal()
+	result, err := VerifyIntentBundle(data, nil)
+	if err != nil {
+		t.Fatalf("VerifyIntentBundle failed: %v", err)
+	}
+	if !result.BundleValid || result.ManifestHash != b.Manifest.ManifestHash {
+		t.Errorf("Expected valid bundle, got %+v", result)
+	}
+	if len(result.Legs) != 2 || !result.Legs[0].HasBundle || result.Legs[1].HasBundle {
+		t.Errorf("Expected 2 legs with one bundle, got %+v", result.Legs)
+	}
+}
+
+func TestIntentBundle_TamperedLegBundle(t *testing.T) {
+	b, _ := NewIntentBundle(testIntentInput(t, nil))
+	data, _ := b.Marshal()
+	data = bytes.Replace(data, []byte("acc://example.acme"), []byte("acc://forged.acme"), 1)
+
+	result, err := VerifyIntentBundle(data, nil)
+	if err != nil {
+		t.Fatalf("VerifyIntentBundle failed: %v", err)
+	}
+	if result.BundleValid || result.ManifestValid || result.LegsValid {
+		t.Errorf("Expected tampered leg bundle to fail manifest and leg checks, got %+v", result)
+	}
+}
+
+func TestIntentBundle_DanglingDependency(t *testing.T) {
+	input := testIntentInput(t, nil)
+	input.Dependencies[0].DependsOnLegID = uuid.New()
+	b, _ := NewIntentBundle(input)
+	data, _ := b.Marshal()
+
+	result, _ := VerifyIntentBundle(data, nil)
+	if result.GraphValid || result.BundleValid {
+		t.Errorf("Expected dependency on unknown leg to fail, got %+v", result)
+	}
+}
+
+func TestIntentBundle_Signed(t *testing.T) {
+	signer := testSigner(t, 1)
+	b, _ := NewIntentBundle(testIntentInput(t, signer))
+	if err := b.Sign(signer); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	data, _ := b.Marshal()
+
+	result, err := VerifyIntentBundle(data, NewKeySet(signer))
+	if err != nil {
+		t.Fatalf("VerifyIntentBundle failed: %v", err)
+	}
+	if !result.BundleValid || result.Signature == nil || result.Legs[0].Signature == nil {
+		t.Errorf("Expected valid signed bundle and leg, got %+v", result)
+	}
+
+	// Without the signing key neither signature can be checked
+	if result, _ := VerifyIntentBundle(data, nil); result.BundleValid || result.LegsValid {
+		t.Errorf("Expected untrusted signatures to fail, got %+v", result)
+	}
+}
+
+// ============================================================================
+// Uploaded Bundle Tests
+// ============================================================================
+
+// verifiableBundle builds a bundle with a real inclusion path and validator signatures
+func verifiableBundle(t *testing.T) []byte {
+	t.Helper()
+
+	leaves := [][]byte{Hash([]byte("leaf-0")), Hash([]byte("leaf-1")), Hash([]byte("leaf-2"))}
+	tree, err := merkle.NewTree(leaves)
+	if err != nil {
+		t.Fatalf("NewTree failed: %v", err)
+	}
+	path, _ := tree.Path(1)
+
+	details := testProofDetails()
+	details.MerkleRoot = tree.Root()
+	details.LeafHash = leaves[1]
+	details.Attestations = nil
+	attested := Hash([]byte("attested"))
+	for i := 1; i <= 2; i++ {
+		key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{byte(i)}, ed25519.SeedSize))
+		details.Attestations = append(details.Attestations, database.ProofAttestation{
+			ValidatorID:     fmt.Sprintf("v%d", i),
+			ValidatorPubkey: key.Public().(ed25519.PublicKey),
+			AttestedHash:    attested,
+			Signature:       ed25519.Sign(key, attested),
+			MerkleRoot:      tree.Root(),
+		})
+	}
+
+	b, err := NewProofBundle(&ProofBundleInput{Details: details, MerklePath: path})
+	if err != nil {
+		t.Fatalf("NewProofBundle failed: %v", err)
+	}
+	data, _ := b.Marshal()
+	return data
+}
+
+func TestVerifyProofBundle_Valid(t *testing.T) {
+	result, err := VerifyProofBundle(verifiableBundle(t), nil)
+	if err != nil {
+		t.Fatalf("VerifyProofBundle failed: %v", err)
+	}
+	if !result.BundleValid || result.Attestations.Valid != 2 {
+		t.Errorf("Expected valid bundle with 2 valid attestations, got %+v", result)
+	}
+}
+
+func TestVerifyProofBundle_ForgedAttestation(t *testing.T) {
+	var doc map[string]interface{}
+	json.Unmarshal(verifiableBundle(t), &doc)
+	attestations := doc["validator_attestations"].([]interface{})
+	attestations[0].(map[string]interface{})["attested_hash"] = hex.EncodeToString(Hash([]byte("forged")))
+	data, _ := json.Marshal(doc)
+
+	result, err := VerifyProofBundle(data, nil)
+	if err != nil {
+		t.Fatalf("VerifyProofBundle failed: %v", err)
+	}
+	if result.Attestations.Valid != 1 || len(result.InvalidAttestations) != 1 {
+		t.Errorf("Expected one forged attestation, got %+v", result)
+	}
+	if result.BundleValid {
+		t.Error("Expected bundle without quorum to be invalid")
+	}
+}
+
+func TestVerifyProofBundle_TamperedComponents(t *testing.T) {
+	data := bytes.Replace(verifiableBundle(t), []byte(`"tx_to_bvn"`), []byte(`"forged"`), 1)
+
+	result, err := VerifyProofBundle(data, nil)
+	if err != nil {
+		t.Fatalf("VerifyProofBundle failed: %v", err)
+	}
+	if result.ComponentsHashValid || result.BundleValid {
+		t.Errorf("Expected components hash mismatch, got %+v", result)
+	}
+}
+
+func TestReadBundle_Gzip(t *testing.T) {
+	data := verifiableBundle(t)
+	compressed, _ := Compress(data)
+
+	jsonData, err := ReadBundle(compressed)
+	if err != nil {
+		t.Fatalf("ReadBundle failed: %v", err)
+	}
+	if !bytes.Equal(jsonData, data) {
+		t.Error("Expected decompressed bundle to round-trip")
+	}
+	if _, err := ReadBundle([]byte("not json")); err == nil {
+		t.Error("Expected error for non-JSON upload")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	if format, _ := DetectFormat(verifiableBundle(t)); format != ProofBundleFormat {
+		t.Errorf("Expected %s, got %s", ProofBundleFormat, format)
+	}
+	if _, err := DetectFormat([]byte(`{"bundle_format":"other"}`)); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	stored := verifiableBundle(t)
+
+	// The same records built again get a new bundle_id and generated_at
+	var rebuilt ProofBundle
+	json.Unmarshal(stored, &rebuilt)
+	rebuilt.BundleID = uuid.New()
+	rebuilt.GeneratedAt = rebuilt.GeneratedAt.Add(time.Minute)
+	uploaded, _ := rebuilt.Marshal()
+
+	diffs, err := Diff(uploaded, stored)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected rebuilt bundle to match, got %+v", diffs)
+	}
+
+	uploaded = bytes.Replace(uploaded, []byte("acc://example.acme"), []byte("acc://forged.acme"), 1)
+	diffs, _ = Diff(uploaded, stored)
+	if len(diffs) != 1 || diffs[0].Path != "transaction_reference.account_url" {
+		t.Errorf("Expected account_url difference, got %+v", diffs)
+	}
+}
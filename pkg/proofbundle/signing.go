@@ -0,0 +1,283 @@
+// Copyright 2025 Certen Protocol
+//
+// Bundle Signing
+// Ed25519 service signatures for certen_v2 bundles
+//
+// A certen_v2 bundle embeds a detached signature in bundle_signature. The
+// signed payload is the bundle without that field, as compact JSON with
+// top-level keys sorted, so re-indenting a bundle does not break it. The
+// message signed is:
+//
+//	certen-bundle-v2\n<key_id>\n<signed_at RFC3339>\n<hex sha256(payload)>
+
+package proofbundle
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Signed bundle identifiers
+const (
+	SignedBundleSchema  = "https://certen.io/schemas/proof-bundle/v2.0"
+	SignedBundleVersion = "2.0"
+	SignedBundleFormat  = "certen_v2"
+
+	SignatureAlgorithm = "Ed25519"
+	signatureDomain    = "certen-bundle-v2"
+)
+
+// Key statuses published at the well-known endpoint
+const (
+	KeyStatusActive  = "active"
+	KeyStatusRetired = "retired"
+)
+
+// ErrUnknownKey is returned when a bundle is signed by a key not in the key set
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// BundleSignature is the detached signature embedded in a certen_v2 bundle
+type BundleSignature struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"key_id"`
+	SignedAt  time.Time `json:"signed_at"`
+	Signature string    `json:"signature"`
+}
+
+// Signer signs bundles with the service instance key
+type Signer struct {
+	keyID string
+	key   ed25519.PrivateKey
+}
+
+// NewSigner creates a signer from an Ed25519 private key
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{
+		keyID: KeyID(key.Public().(ed25519.PublicKey)),
+		key:   key,
+	}
+}
+
+// ParseSigningKey parses a hex or base64 Ed25519 key: a 32-byte seed or a
+// 64-byte private key
+func ParseSigningKey(encoded string) (*Signer, error) {
+	raw, err := decodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return NewSigner(ed25519.NewKeyFromSeed(raw)), nil
+	case ed25519.PrivateKeySize:
+		return NewSigner(ed25519.PrivateKey(raw)), nil
+	default:
+		return nil, fmt.Errorf("signing key must be %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+	}
+}
+
+// KeyID returns the service key ID for a public key: the first 8 bytes of
+// its SHA-256, hex encoded
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeyID returns the signer's key ID
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the signer's public key
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Sign returns the detached signature for a bundle's JSON
+func (s *Signer) Sign(jsonData []byte, signedAt time.Time) (*BundleSignature, error) {
+	signedAt = signedAt.UTC().Truncate(time.Second)
+	message, err := signatureMessage(jsonData, s.keyID, signedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BundleSignature{
+		Algorithm: SignatureAlgorithm,
+		KeyID:     s.keyID,
+		SignedAt:  signedAt,
+		Signature: hex.EncodeToString(ed25519.Sign(s.key, message)),
+	}, nil
+}
+
+// =============================================================================
+// KEY SET
+// =============================================================================
+
+// KeySet holds the public keys bundles are verified against: the active key
+// and any retired keys that signed bundles still in circulation
+type KeySet struct {
+	keys   map[string]ed25519.PublicKey
+	status map[string]string
+	order  []string
+}
+
+// NewKeySet creates a key set with the signer's key (if any) as the active key
+func NewKeySet(signer *Signer) *KeySet {
+	ks := &KeySet{
+		keys:   make(map[string]ed25519.PublicKey),
+		status: make(map[string]string),
+	}
+	if signer != nil {
+		ks.add(signer.PublicKey(), KeyStatusActive)
+	}
+	return ks
+}
+
+// AddRetired adds a retired public key (hex or base64)
+func (ks *KeySet) AddRetired(encoded string) error {
+	raw, err := decodeKey(encoded)
+	if err != nil {
+		return err
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	ks.add(ed25519.PublicKey(raw), KeyStatusRetired)
+	return nil
+}
+
+func (ks *KeySet) add(pub ed25519.PublicKey, status string) {
+	keyID := KeyID(pub)
+	if _, exists := ks.keys[keyID]; exists {
+		return
+	}
+	ks.keys[keyID] = pub
+	ks.status[keyID] = status
+	ks.order = append(ks.order, keyID)
+}
+
+// PublicKeyJWK is a service public key in JWK form (RFC 8037)
+type PublicKeyJWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Status    string `json:"status"`
+}
+
+// JWKs returns the key set's public keys, active key first
+func (ks *KeySet) JWKs() []PublicKeyJWK {
+	ids := append([]string(nil), ks.order...)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return ks.status[ids[i]] == KeyStatusActive && ks.status[ids[j]] != KeyStatusActive
+	})
+
+	jwks := make([]PublicKeyJWK, 0, len(ids))
+	for _, id := range ids {
+		jwks = append(jwks, PublicKeyJWK{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(ks.keys[id]),
+			KeyID:     id,
+			Use:       "sig",
+			Algorithm: "EdDSA",
+			Status:    ks.status[id],
+		})
+	}
+	return jwks
+}
+
+// SignatureVerification is the result of checking a bundle signature
+type SignatureVerification struct {
+	Valid     bool      `json:"valid"`
+	KeyID     string    `json:"key_id"`
+	KeyStatus string    `json:"key_status,omitempty"`
+	SignedAt  time.Time `json:"signed_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// VerifySignature checks the embedded signature of a certen_v2 bundle.
+// An error means the bundle has no readable signature.
+func (ks *KeySet) VerifySignature(jsonData []byte) (*SignatureVerification, error) {
+	var doc struct {
+		Signature *BundleSignature `json:"bundle_signature"`
+	}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	sig := doc.Signature
+	if sig == nil {
+		return nil, errors.New("bundle is not signed")
+	}
+
+	result := &SignatureVerification{KeyID: sig.KeyID, SignedAt: sig.SignedAt}
+	pub, ok := ks.keys[sig.KeyID]
+	if !ok {
+		result.Error = ErrUnknownKey.Error()
+		return result, nil
+	}
+	result.KeyStatus = ks.status[sig.KeyID]
+
+	if sig.Algorithm != SignatureAlgorithm {
+		result.Error = fmt.Sprintf("unsupported algorithm %s", sig.Algorithm)
+		return result, nil
+	}
+	signature, err := hex.DecodeString(sig.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		result.Error = "malformed signature"
+		return result, nil
+	}
+
+	message, err := signatureMessage(jsonData, sig.KeyID, sig.SignedAt)
+	if err != nil {
+		return nil, err
+	}
+	result.Valid = ed25519.Verify(pub, message, signature)
+	if !result.Valid {
+		result.Error = "signature does not match bundle"
+	}
+	return result, nil
+}
+
+// signatureMessage builds the signed message for a bundle
+func signatureMessage(jsonData []byte, keyID string, signedAt time.Time) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	delete(doc, "bundle_signature")
+
+	// Map keys marshal sorted and raw values are compacted
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize bundle payload: %w", err)
+	}
+
+	message := fmt.Sprintf("%s\n%s\n%s\n%s",
+		signatureDomain, keyID, signedAt.UTC().Format(time.RFC3339), hex.EncodeToString(Hash(payload)))
+	return []byte(message), nil
+}
+
+// decodeKey accepts hex or standard/URL base64 key encodings
+func decodeKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if raw, err := hex.DecodeString(encoded); err == nil {
+		return raw, nil
+	}
+	if raw, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+		return raw, nil
+	}
+	if raw, err := base64.RawURLEncoding.DecodeString(encoded); err == nil {
+		return raw, nil
+	}
+	return nil, errors.New("key is neither hex nor base64")
+}
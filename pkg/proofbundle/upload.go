@@ -0,0 +1,295 @@
+// Copyright 2025 Certen Protocol
+//
+// Uploaded Bundles
+// Verifies bundles held outside the service and compares them to our records
+//
+// An uploaded bundle is verified from its own contents only, so the result
+// holds for a counterparty's copy. It is then compared field by field with a
+// bundle assembled from the current stored records. Fields that differ on
+// every build (bundle IDs, generation and signing times) and hashes derived
+// from other fields are left out of the comparison.
+
+package proofbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// ErrUnsupportedFormat is returned for documents that are not a known bundle format
+var ErrUnsupportedFormat = errors.New("unsupported bundle format")
+
+// ReadBundle returns the JSON of an uploaded bundle, decompressing it if it
+// is gzipped
+func ReadBundle(data []byte) ([]byte, error) {
+	jsonData := data
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		var err error
+		if jsonData, err = Decompress(data); err != nil {
+			return nil, err
+		}
+	}
+	if !json.Valid(jsonData) {
+		return nil, errors.New("bundle is not valid JSON")
+	}
+	return jsonData, nil
+}
+
+// DetectFormat returns a bundle's format. certen_v1 bundles carry no
+// bundle_format and are recognised by their proof components.
+func DetectFormat(jsonData []byte) (string, error) {
+	var doc struct {
+		BundleFormat    string          `json:"bundle_format"`
+		ProofComponents json.RawMessage `json:"proof_components"`
+	}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return "", fmt.Errorf("invalid bundle JSON: %w", err)
+	}
+
+	switch doc.BundleFormat {
+	case ProofBundleFormat, SignedBundleFormat, BatchBundleFormat, IntentBundleFormat:
+		return doc.BundleFormat, nil
+	case "":
+		if doc.ProofComponents != nil {
+			return ProofBundleFormat, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, doc.BundleFormat)
+}
+
+// Verification is the result of verifying a bundle of any format; exactly
+// one of Proof, Batch and Intent is set
+type Verification struct {
+	BundleFormat string              `json:"bundle_format"`
+	BundleValid  bool                `json:"bundle_valid"`
+	Proof        *ProofVerification  `json:"proof,omitempty"`
+	Batch        *BatchVerification  `json:"batch,omitempty"`
+	Intent       *IntentVerification `json:"intent,omitempty"`
+
+	// Full verification of each leg bundle embedded in an intent bundle,
+	// keyed by leg index
+	LegProofs map[int]*ProofVerification `json:"leg_proofs,omitempty"`
+}
+
+// Verify checks a decompressed bundle of any supported format
+func Verify(jsonData []byte, keys *KeySet) (*Verification, error) {
+	format, err := DetectFormat(jsonData)
+	if err != nil {
+		return nil, err
+	}
+	result := &Verification{BundleFormat: format}
+
+	switch format {
+	case ProofBundleFormat, SignedBundleFormat:
+		if result.Proof, err = VerifyProofBundle(jsonData, keys); err != nil {
+			return nil, err
+		}
+		result.BundleValid = result.Proof.BundleValid
+
+	case BatchBundleFormat:
+		if result.Batch, err = VerifyBatchBundle(jsonData); err != nil {
+			return nil, err
+		}
+		result.BundleValid = result.Batch.BundleValid
+
+	case IntentBundleFormat:
+		if result.Intent, err = VerifyIntentBundle(jsonData, keys); err != nil {
+			return nil, err
+		}
+		result.BundleValid = result.Intent.BundleValid
+
+		var b IntentBundle
+		if err := json.Unmarshal(jsonData, &b); err != nil {
+			return nil, fmt.Errorf("invalid intent bundle: %w", err)
+		}
+		for _, leg := range b.Legs {
+			if len(leg.Bundle) == 0 || string(leg.Bundle) == "null" {
+				continue
+			}
+			legResult, err := VerifyProofBundle(leg.Bundle, keys)
+			if err != nil {
+				return nil, fmt.Errorf("leg %d: %w", leg.LegIndex, err)
+			}
+			if result.LegProofs == nil {
+				result.LegProofs = make(map[int]*ProofVerification)
+			}
+			result.LegProofs[leg.LegIndex] = legResult
+			result.BundleValid = result.BundleValid && legResult.BundleValid
+		}
+	}
+
+	return result, nil
+}
+
+// =============================================================================
+// STORED RECORD COMPARISON
+// =============================================================================
+
+// Expected assembles, without saving, the bundle our stored records produce
+// for the same proof, batch or intent as an uploaded bundle. Returns the
+// repository's not-found error if we hold no such record.
+func (b *Builder) Expected(ctx context.Context, jsonData []byte) ([]byte, error) {
+	format, err := DetectFormat(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case ProofBundleFormat, SignedBundleFormat:
+		var doc struct {
+			TransactionReference TransactionReference `json:"transaction_reference"`
+		}
+		if err := json.Unmarshal(jsonData, &doc); err != nil {
+			return nil, fmt.Errorf("invalid proof bundle: %w", err)
+		}
+		details, err := b.repos.ProofArtifacts.GetProofWithDetails(ctx, doc.TransactionReference.ProofID)
+		if err != nil {
+			return nil, err
+		}
+		if details == nil {
+			return nil, database.ErrProofNotFound
+		}
+		expected, err := b.Assemble(ctx, details)
+		if err != nil {
+			return nil, err
+		}
+		return expected.Marshal()
+
+	case BatchBundleFormat:
+		var doc struct {
+			BatchHeader BatchHeader `json:"batch_header"`
+		}
+		if err := json.Unmarshal(jsonData, &doc); err != nil {
+			return nil, fmt.Errorf("invalid batch bundle: %w", err)
+		}
+		input, err := LoadBatchBundleInput(ctx, b.repos, doc.BatchHeader.BatchID)
+		if err != nil {
+			return nil, err
+		}
+		expected, err := NewBatchBundle(input)
+		if err != nil {
+			return nil, err
+		}
+		return expected.Marshal()
+
+	default:
+		var doc struct {
+			IntentID string `json:"intent_id"`
+		}
+		if err := json.Unmarshal(jsonData, &doc); err != nil {
+			return nil, fmt.Errorf("invalid intent bundle: %w", err)
+		}
+		if doc.IntentID == "" {
+			return nil, database.ErrIntentNotFound
+		}
+		expected, err := b.AssembleIntentBundle(ctx, doc.IntentID)
+		if err != nil {
+			return nil, err
+		}
+		return expected.Marshal()
+	}
+}
+
+// FieldDifference is a field whose uploaded value differs from our records.
+// A missing value is null.
+type FieldDifference struct {
+	Path     string          `json:"path"`
+	Uploaded json.RawMessage `json:"uploaded"`
+	Stored   json.RawMessage `json:"stored"`
+}
+
+// Fields excluded from comparison at any depth
+var volatileFields = map[string]bool{
+	"$schema":          true,
+	"bundle_version":   true,
+	"bundle_format":    true,
+	"bundle_id":        true,
+	"generated_at":     true,
+	"bundle_signature": true,
+	"manifest":         true,
+	"bundle_hash":      true,
+	"components_hash":  true,
+}
+
+// Diff reports every field where an uploaded bundle differs from the bundle
+// assembled from our records, in path order
+func Diff(uploaded, stored []byte) ([]FieldDifference, error) {
+	var u, s interface{}
+	if err := unmarshalNumbers(uploaded, &u); err != nil {
+		return nil, fmt.Errorf("invalid uploaded bundle: %w", err)
+	}
+	if err := unmarshalNumbers(stored, &s); err != nil {
+		return nil, fmt.Errorf("invalid stored bundle: %w", err)
+	}
+
+	diffs := []FieldDifference{}
+	diffValues("", u, s, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+func unmarshalNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func diffValues(path string, u, s interface{}, diffs *[]FieldDifference) {
+	uObj, uIsObj := u.(map[string]interface{})
+	sObj, sIsObj := s.(map[string]interface{})
+	if uIsObj && sIsObj {
+		keys := make(map[string]bool, len(uObj)+len(sObj))
+		for k := range uObj {
+			keys[k] = true
+		}
+		for k := range sObj {
+			keys[k] = true
+		}
+		for k := range keys {
+			if volatileFields[k] {
+				continue
+			}
+			diffValues(joinPath(path, k), uObj[k], sObj[k], diffs)
+		}
+		return
+	}
+
+	uArr, uIsArr := u.([]interface{})
+	sArr, sIsArr := s.([]interface{})
+	if uIsArr && sIsArr {
+		n := len(uArr)
+		if len(sArr) > n {
+			n = len(sArr)
+		}
+		for i := 0; i < n; i++ {
+			var uv, sv interface{}
+			if i < len(uArr) {
+				uv = uArr[i]
+			}
+			if i < len(sArr) {
+				sv = sArr[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), uv, sv, diffs)
+		}
+		return
+	}
+
+	uRaw, _ := json.Marshal(u)
+	sRaw, _ := json.Marshal(s)
+	if !bytes.Equal(uRaw, sRaw) {
+		*diffs = append(*diffs, FieldDifference{Path: path, Uploaded: uRaw, Stored: sRaw})
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
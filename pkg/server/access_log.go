@@ -0,0 +1,311 @@
+// Copyright 2025 Certen Protocol
+//
+// Access Log Middleware
+// Records who called which route for which proof, intent, account or user
+//
+// Every request (except SkipPaths) is written to the append-only
+// api_access_log with its principal, client IP, registered route, the
+// resource IDs found in its path and query, and the response status, size and
+// latency. Entries are written by a background writer so logging does not
+// delay responses; when its queue is full the entry is written inline rather
+// than dropped. A failed write is logged and does not affect the response.
+//
+// The middleware runs outside the authentication middleware, which reports
+// the principal it resolved back to it, so requests whose credentials are
+// rejected are recorded too, with the rejected principal type. Requests
+// refused by the per-IP rate limit in front of it are not recorded.
+
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// accessLogStore is implemented by database.AccessLogRepository
+type accessLogStore interface {
+	RecordAccess(ctx context.Context, entry *database.AccessLogEntry) error
+}
+
+// routeMatcher returns the registered pattern that serves a request;
+// *http.ServeMux implements it
+type routeMatcher interface {
+	Handler(r *http.Request) (http.Handler, string)
+}
+
+// AccessLogConfig contains configuration for the access log middleware
+type AccessLogConfig struct {
+	Router    routeMatcher // Resolves route patterns; the path is used if nil
+	SkipPaths []string     // Exact paths that are not recorded
+	QueueSize int          // Entries buffered for the background writer
+}
+
+// AccessLogMiddleware records API requests in the access log
+type AccessLogMiddleware struct {
+	store  accessLogStore
+	config *AccessLogConfig
+	logger *log.Logger
+	skip   map[string]bool
+
+	queue  chan *database.AccessLogEntry
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewAccessLogMiddleware creates a new access log middleware. Without
+// repositories nothing is recorded.
+func NewAccessLogMiddleware(
+	repos *database.Repositories,
+	config *AccessLogConfig,
+	logger *log.Logger,
+) *AccessLogMiddleware {
+	var store accessLogStore
+	if repos != nil {
+		store = repos.AccessLog
+	}
+	return newAccessLogMiddleware(store, config, logger)
+}
+
+func newAccessLogMiddleware(store accessLogStore, config *AccessLogConfig, logger *log.Logger) *AccessLogMiddleware {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[AccessLog] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &AccessLogConfig{}
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = true
+	}
+	return &AccessLogMiddleware{
+		store:  store,
+		config: config,
+		logger: logger,
+		skip:   skip,
+		queue:  make(chan *database.AccessLogEntry, config.QueueSize),
+	}
+}
+
+// Start launches the background writer
+func (m *AccessLogMiddleware) Start() {
+	m.stopCh = make(chan struct{})
+	m.wg.Add(1)
+	go m.run()
+}
+
+// Stop writes the queued entries and waits for the background writer to exit
+func (m *AccessLogMiddleware) Stop() {
+	if m.stopCh != nil {
+		close(m.stopCh)
+	}
+	m.wg.Wait()
+}
+
+func (m *AccessLogMiddleware) run() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case entry := <-m.queue:
+			m.write(entry)
+		case <-m.stopCh:
+			for {
+				select {
+				case entry := <-m.queue:
+					m.write(entry)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// accessLogContextKey is the context key of a request's accessLogRecord
+type accessLogContextKey struct{}
+
+// accessLogRecord collects what the authentication middleware decided about
+// a request, for the access log wrapped around it
+type accessLogRecord struct {
+	principal *Principal
+	rejected  bool
+}
+
+// recordAuthentication notes the principal a request authenticated as, or
+// that its credentials were rejected, for the access log
+func recordAuthentication(ctx context.Context, principal *Principal, rejected bool) {
+	if record, ok := ctx.Value(accessLogContextKey{}).(*accessLogRecord); ok {
+		record.principal = principal
+		record.rejected = rejected
+	}
+}
+
+// Wrap returns next with its requests recorded. It must run outside the
+// authentication middleware and inside the client IP middleware.
+func (m *AccessLogMiddleware) Wrap(next http.Handler) http.Handler {
+	if m.store == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.skip[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		aw := &accessLogWriter{ResponseWriter: w}
+		record := &accessLogRecord{principal: PrincipalFromContext(r.Context())}
+		next.ServeHTTP(aw, r.WithContext(context.WithValue(r.Context(), accessLogContextKey{}, record)))
+
+		entry := m.entry(r, record, start)
+		entry.StatusCode = aw.statusCode()
+		entry.ResponseBytes = aw.bytes
+		entry.LatencyMS = int(time.Since(start).Milliseconds())
+
+		select {
+		case m.queue <- entry:
+		default:
+			m.write(entry)
+		}
+	})
+}
+
+// entry describes the request without its outcome
+func (m *AccessLogMiddleware) entry(r *http.Request, record *accessLogRecord, start time.Time) *database.AccessLogEntry {
+	route := r.URL.Path
+	if m.config.Router != nil {
+		if _, pattern := m.config.Router.Handler(r); pattern != "" {
+			route = pattern
+		}
+	}
+
+	clientIP := getClientIP(r)
+	entry := &database.AccessLogEntry{
+		OccurredAt:    start.UTC(),
+		PrincipalType: database.AccessPrincipalAnonymous,
+		ClientIP:      nilIfEmpty(clientIP),
+		Method:        r.Method,
+		Route:         route,
+		Path:          r.URL.Path,
+	}
+	switch principal := record.principal; {
+	case record.rejected:
+		entry.PrincipalType = database.AccessPrincipalRejected
+	case principal != nil:
+		entry.PrincipalType = principal.Type
+		entry.PrincipalName = nilIfEmpty(principal.Name())
+		entry.KeyID = principal.KeyID()
+	}
+	setAccessResources(entry, route, r.URL.Path, r.URL.Query())
+	return entry
+}
+
+func (m *AccessLogMiddleware) write(entry *database.AccessLogEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := m.store.RecordAccess(ctx, entry); err != nil {
+		m.logger.Printf("Error recording access to %s by %s: %v", entry.Path, entry.PrincipalType, err)
+	}
+}
+
+// accessResourceRoutes maps route patterns to the resource named by the
+// first path segment after the pattern
+var accessResourceRoutes = map[string]string{
+	"/api/v1/proofs/":         "proof",
+	"/api/v1/proofs/account/": "account",
+	"/api/v1/intent/":         "intent",
+	"/api/v1/intents/":        "intent",
+	"/api/v1/intent/user/":    "user",
+	"/api/v1/user/":           "user",
+}
+
+// setAccessResources fills in the proof, intent, account and user a request
+// concerns, from its path and its proof_id, intent_id, account_url and
+// user_id query parameters
+func setAccessResources(entry *database.AccessLogEntry, route, path string, query url.Values) {
+	var proofID, intentID, accountURL, userID string
+
+	if kind, ok := accessResourceRoutes[route]; ok {
+		rest := strings.TrimPrefix(path, route)
+		segment := strings.SplitN(rest, "/", 2)[0]
+		switch kind {
+		case "proof":
+			proofID = segment
+		case "account":
+			accountURL = strings.TrimSuffix(rest, "/")
+		case "intent":
+			intentID = segment
+		case "user":
+			userID = segment
+		}
+	}
+	if v := query.Get("proof_id"); v != "" {
+		proofID = v
+	}
+	if v := query.Get("intent_id"); v != "" {
+		intentID = v
+	}
+	if v := query.Get("account_url"); v != "" {
+		accountURL = v
+	}
+	if v := query.Get("user_id"); v != "" {
+		userID = v
+	}
+
+	if id, err := uuid.Parse(proofID); err == nil {
+		entry.ProofID = &id
+	}
+	entry.IntentID = nilIfEmpty(intentID)
+	entry.AccountURL = nilIfEmpty(accountURL)
+	entry.UserID = nilIfEmpty(userID)
+}
+
+// accessLogWriter passes a response through while counting its status and size
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (aw *accessLogWriter) WriteHeader(status int) {
+	if aw.status == 0 {
+		aw.status = status
+	}
+	aw.ResponseWriter.WriteHeader(status)
+}
+
+func (aw *accessLogWriter) Write(p []byte) (int, error) {
+	if aw.status == 0 {
+		aw.status = http.StatusOK
+	}
+	n, err := aw.ResponseWriter.Write(p)
+	aw.bytes += int64(n)
+	return n, err
+}
+
+// Flush supports streaming responses such as bulk downloads
+func (aw *accessLogWriter) Flush() {
+	if f, ok := aw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (aw *accessLogWriter) statusCode() int {
+	if aw.status == 0 {
+		return http.StatusOK
+	}
+	return aw.status
+}
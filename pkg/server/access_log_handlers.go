@@ -0,0 +1,166 @@
+// Copyright 2025 Certen Protocol
+//
+// Access Log Handlers
+// Query endpoint for the API access log
+//
+// Endpoints:
+// - GET /api/v1/audit/access - List access log entries (auditor API keys only)
+
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// AccessLogHandlers provides HTTP handlers for the access log
+type AccessLogHandlers struct {
+	repos  *database.Repositories
+	logger *log.Logger
+}
+
+// NewAccessLogHandlers creates new access log handlers
+func NewAccessLogHandlers(repos *database.Repositories, logger *log.Logger) *AccessLogHandlers {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[AccessLogAPI] ", log.LstdFlags)
+	}
+	return &AccessLogHandlers{
+		repos:  repos,
+		logger: logger,
+	}
+}
+
+// =============================================================================
+// ACCESS LOG QUERY ENDPOINT
+// =============================================================================
+
+// HandleListAccessLog handles GET /api/v1/audit/access
+// Query params: principal, key_id, proof_id, intent_id, account_url, user_id,
+// since, until (RFC3339), limit, offset
+func (h *AccessLogHandlers) HandleListAccessLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "An auditor API key is required")
+		return
+	}
+	if !principal.IsAuditor() {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "The access log requires an auditor API key")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &database.AccessLogFilter{
+		Limit:  h.parseIntParam(r, "limit", 100),
+		Offset: h.parseIntParam(r, "offset", 0),
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+
+	if v := query.Get("principal"); v != "" {
+		filter.PrincipalName = &v
+	}
+	if v := query.Get("key_id"); v != "" {
+		keyID, err := uuid.Parse(v)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "INVALID_KEY_ID", "Invalid key ID format")
+			return
+		}
+		filter.KeyID = &keyID
+	}
+	if v := query.Get("proof_id"); v != "" {
+		proofID, err := uuid.Parse(v)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "INVALID_PROOF_ID", "Invalid proof ID format")
+			return
+		}
+		filter.ProofID = &proofID
+	}
+	if v := query.Get("intent_id"); v != "" {
+		filter.IntentID = &v
+	}
+	if v := query.Get("account_url"); v != "" {
+		filter.AccountURL = &v
+	}
+	if v := query.Get("user_id"); v != "" {
+		filter.UserID = &v
+	}
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "INVALID_TIMESTAMP", "Invalid since timestamp format (use RFC3339)")
+			return
+		}
+		filter.Since = &since
+	}
+	if v := query.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "INVALID_TIMESTAMP", "Invalid until timestamp format (use RFC3339)")
+			return
+		}
+		filter.Until = &until
+	}
+
+	entries, err := h.repos.AccessLog.ListAccess(r.Context(), filter)
+	if err != nil {
+		h.logger.Printf("Error listing access log: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve access log")
+		return
+	}
+	if entries == nil {
+		entries = []*database.AccessLogEntry{}
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	})
+}
+
+// =============================================================================
+// HELPER METHODS
+// =============================================================================
+
+func (h *AccessLogHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AccessLogHandlers) writeError(w http.ResponseWriter, status int, code, message string) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+func (h *AccessLogHandlers) parseIntParam(r *http.Request, name string, defaultVal int) int {
+	valStr := r.URL.Query().Get(name)
+	if valStr == "" {
+		return defaultVal
+	}
+	val, err := strconv.Atoi(valStr)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
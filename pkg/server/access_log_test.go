@@ -0,0 +1,182 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the Access Log Middleware
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+type memoryAccessLogStore struct {
+	mu      sync.Mutex
+	entries []*database.AccessLogEntry
+}
+
+func (s *memoryAccessLogStore) RecordAccess(ctx context.Context, entry *database.AccessLogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func testAccessLogMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/api/v1/proofs/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"proof":"ok"}`))
+	})
+	mux.HandleFunc("/api/v1/proofs/account/", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/api/v1/user/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+	return mux
+}
+
+func accessLogRequest(handler http.Handler, path string, principal *Principal) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "203.0.113.5:4000"
+	if principal != nil {
+		req = req.WithContext(WithPrincipal(req.Context(), principal))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+// ============================================================================
+// Recording Tests
+// ============================================================================
+
+func TestAccessLog_RecordsRequests(t *testing.T) {
+	store := &memoryAccessLogStore{}
+	mux := testAccessLogMux()
+	m := newAccessLogMiddleware(store, &AccessLogConfig{Router: mux, SkipPaths: []string{"/health"}}, nil)
+	handler := m.Wrap(mux)
+
+	key := keyPrincipal("developer")
+	proofID := uuid.New()
+	accessLogRequest(handler, "/api/v1/proofs/"+proofID.String()+"/bundle", key)
+	accessLogRequest(handler, "/api/v1/proofs/account/acc://acme.acme/tokens?user_id=user-9", nil)
+	accessLogRequest(handler, "/api/v1/user/user-1/intents", tokenPrincipal("user-2", ""))
+	accessLogRequest(handler, "/health", nil)
+
+	if len(store.entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(store.entries))
+	}
+
+	proof := store.entries[0]
+	if proof.PrincipalType != PrincipalTypeAPIKey || proof.KeyID == nil || *proof.KeyID != key.APIKey.KeyID {
+		t.Errorf("Unexpected principal: %s %v", proof.PrincipalType, proof.KeyID)
+	}
+	if proof.Route != "/api/v1/proofs/" || proof.ProofID == nil || *proof.ProofID != proofID {
+		t.Errorf("Unexpected route or proof: %s %v", proof.Route, proof.ProofID)
+	}
+	if proof.StatusCode != http.StatusOK || proof.ResponseBytes != int64(len(`{"proof":"ok"}`)) {
+		t.Errorf("Unexpected outcome: status %d, %d bytes", proof.StatusCode, proof.ResponseBytes)
+	}
+	if proof.ClientIP == nil || *proof.ClientIP != "203.0.113.5" {
+		t.Errorf("Unexpected client IP: %v", proof.ClientIP)
+	}
+
+	account := store.entries[1]
+	if account.PrincipalType != database.AccessPrincipalAnonymous || account.PrincipalName != nil {
+		t.Errorf("Expected an anonymous entry, got %s", account.PrincipalType)
+	}
+	if account.AccountURL == nil || *account.AccountURL != "acc://acme.acme/tokens" || account.UserID == nil || *account.UserID != "user-9" {
+		t.Errorf("Unexpected resources: account %v, user %v", account.AccountURL, account.UserID)
+	}
+
+	user := store.entries[2]
+	if user.StatusCode != http.StatusForbidden || user.UserID == nil || *user.UserID != "user-1" {
+		t.Errorf("Unexpected user entry: status %d, user %v", user.StatusCode, user.UserID)
+	}
+	if user.PrincipalName == nil || *user.PrincipalName != "jwt:user-2" || user.KeyID != nil {
+		t.Errorf("Unexpected token principal: %v", user.PrincipalName)
+	}
+}
+
+func TestAccessLog_RecordsRejectedCredentials(t *testing.T) {
+	store := &memoryAccessLogStore{}
+	mux := testAccessLogMux()
+	m := newAccessLogMiddleware(store, &AccessLogConfig{Router: mux}, nil)
+	handler := m.Wrap(testAuthMiddleware(t, false).Wrap(mux))
+
+	for _, token := range []string{testBearerToken(t, "proofs:read", time.Now().Add(time.Hour)), "not-a-token"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/"+uuid.NewString(), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(store.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(store.entries))
+	}
+	if valid := store.entries[0]; valid.PrincipalType != PrincipalTypeToken || valid.PrincipalName == nil || *valid.PrincipalName != "jwt:user-1" {
+		t.Errorf("Unexpected principal for a valid token: %s %v", valid.PrincipalType, valid.PrincipalName)
+	}
+	rejected := store.entries[1]
+	if rejected.PrincipalType != database.AccessPrincipalRejected || rejected.PrincipalName != nil || rejected.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Unexpected entry for a rejected token: %s %v, status %d", rejected.PrincipalType, rejected.PrincipalName, rejected.StatusCode)
+	}
+	if rejected.ProofID == nil {
+		t.Error("Expected the rejected request's proof to be recorded")
+	}
+}
+
+func TestAccessLog_BackgroundWriter(t *testing.T) {
+	store := &memoryAccessLogStore{}
+	mux := testAccessLogMux()
+	m := newAccessLogMiddleware(store, &AccessLogConfig{Router: mux, QueueSize: 10}, nil)
+	m.Start()
+
+	handler := m.Wrap(mux)
+	for i := 0; i < 5; i++ {
+		accessLogRequest(handler, "/api/v1/proofs/"+uuid.NewString(), nil)
+	}
+
+	// Stop writes everything still queued
+	m.Stop()
+	if len(store.entries) != 5 {
+		t.Errorf("Expected 5 entries after stopping, got %d", len(store.entries))
+	}
+}
+
+func TestAccessLogHandlers_RequireAuditor(t *testing.T) {
+	h := NewAccessLogHandlers(nil, nil)
+
+	for name, tt := range map[string]struct {
+		principal *Principal
+		want      int
+	}{
+		"anonymous":     {nil, http.StatusUnauthorized},
+		"developer key": {keyPrincipal("developer"), http.StatusForbidden},
+		"token":         {tokenPrincipal("user-1", "acc://acme.acme", "org:admin"), http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/audit/access", nil)
+		if tt.principal != nil {
+			req = req.WithContext(WithPrincipal(req.Context(), tt.principal))
+		}
+		w := httptest.NewRecorder()
+		h.HandleListAccessLog(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", name, tt.want, w.Code)
+		}
+	}
+
+	// Filters are validated before the log is read
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit/access?proof_id=not-a-uuid", nil)
+	req = req.WithContext(WithPrincipal(req.Context(), keyPrincipal("auditor")))
+	w := httptest.NewRecorder()
+	h.HandleListAccessLog(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid proof_id, got %d", w.Code)
+	}
+}
@@ -0,0 +1,714 @@
+// Copyright 2025 Certen Protocol
+//
+// Admin API Handlers
+// Creates, rotates, deactivates and edits API keys, and registers and revokes
+// validator attestation keys
+//
+// Endpoints:
+// - POST /api/v1/admin/api-keys - Create a key; the plaintext is returned once
+// - GET /api/v1/admin/api-keys - List keys (include_inactive=true for all)
+// - GET /api/v1/admin/api-keys/{key_id} - Describe a key with its audit log
+// - POST /api/v1/admin/api-keys/{key_id}/rotate - Replace a key, keeping the old one for an overlap window
+// - POST /api/v1/admin/api-keys/{key_id}/deactivate - Deactivate a key immediately
+// - PUT /api/v1/admin/api-keys/{key_id}/expiry - Set or clear a key's expiry
+// - PUT /api/v1/admin/api-keys/{key_id}/permissions - Edit read, request and bulk download permissions
+// - POST /api/v1/admin/validator-keys - Register a validator's Ed25519 attestation key
+// - GET /api/v1/admin/validator-keys - List keys (validator_id, include_revoked=true)
+// - GET /api/v1/admin/validator-keys/{key_id} - Describe a validator key with its audit log
+// - POST /api/v1/admin/validator-keys/{key_id}/revoke - Revoke a validator key
+//
+// All endpoints require an X-API-Key with can_admin, or a bearer token with
+// the admin scope. Every change is recorded in the API key audit log with the
+// acting key or token subject and client IP.
+
+package server
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+const (
+	adminAPIKeysPath       = "/api/v1/admin/api-keys"
+	adminValidatorKeysPath = "/api/v1/admin/validator-keys"
+)
+
+// validClientTypes are the client types allowed by api_keys.valid_client_type
+var validClientTypes = map[string]bool{
+	"auditor":     true,
+	"service":     true,
+	"institution": true,
+	"developer":   true,
+	"internal":    true,
+	"validator":   true,
+}
+
+// AdminHandlers provides HTTP handlers for API key administration
+type AdminHandlers struct {
+	repos           *database.Repositories
+	logger          *log.Logger
+	apiKeyValidator *APIKeyValidator
+	rotationOverlap time.Duration
+}
+
+// AdminHandlersConfig contains configuration for admin handlers
+type AdminHandlersConfig struct {
+	RotationOverlap time.Duration    // Default time a rotated key keeps working
+	APIKeyValidator *APIKeyValidator // Validator whose cache key changes invalidate
+}
+
+// NewAdminHandlers creates new admin handlers
+func NewAdminHandlers(
+	repos *database.Repositories,
+	config *AdminHandlersConfig,
+	logger *log.Logger,
+) *AdminHandlers {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[AdminAPI] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &AdminHandlersConfig{}
+	}
+	rotationOverlap := config.RotationOverlap
+	if rotationOverlap <= 0 {
+		rotationOverlap = 24 * time.Hour
+	}
+
+	validator := config.APIKeyValidator
+	if validator == nil {
+		validator = NewAPIKeyValidator(repos)
+	}
+
+	return &AdminHandlers{
+		repos:           repos,
+		logger:          logger,
+		apiKeyValidator: validator,
+		rotationOverlap: rotationOverlap,
+	}
+}
+
+// =============================================================================
+// ADMIN TYPES
+// =============================================================================
+
+// APIKeyView is an API key as returned by the admin API, without its hash
+type APIKeyView struct {
+	KeyID            uuid.UUID  `json:"key_id"`
+	KeyPrefix        *string    `json:"key_prefix,omitempty"`
+	ClientName       string     `json:"client_name"`
+	ClientType       string     `json:"client_type"`
+	CanReadProofs    bool       `json:"can_read_proofs"`
+	CanRequestProofs bool       `json:"can_request_proofs"`
+	CanBulkDownload  bool       `json:"can_bulk_download"`
+	CanSubmitProofs  bool       `json:"can_submit_proofs"`
+	CanAdmin         bool       `json:"can_admin"`
+	ValidatorID      *string    `json:"validator_id,omitempty"`
+	RateLimitPerMin  int        `json:"rate_limit_per_min"`
+	IsActive         bool       `json:"is_active"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RotatedFrom      *uuid.UUID `json:"rotated_from,omitempty"`
+	DeactivatedAt    *time.Time `json:"deactivated_at,omitempty"`
+	Description      *string    `json:"description,omitempty"`
+	ContactEmail     *string    `json:"contact_email,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+}
+
+func newAPIKeyView(key *database.APIKey) *APIKeyView {
+	return &APIKeyView{
+		KeyID:            key.KeyID,
+		KeyPrefix:        key.KeyPrefix,
+		ClientName:       key.ClientName,
+		ClientType:       key.ClientType,
+		CanReadProofs:    key.CanReadProofs,
+		CanRequestProofs: key.CanRequestProofs,
+		CanBulkDownload:  key.CanBulkDownload,
+		CanSubmitProofs:  key.CanSubmitProofs,
+		CanAdmin:         key.CanAdmin,
+		ValidatorID:      key.ValidatorID,
+		RateLimitPerMin:  key.RateLimitPerMin,
+		IsActive:         key.IsActive,
+		ExpiresAt:        key.ExpiresAt,
+		RotatedFrom:      key.RotatedFrom,
+		DeactivatedAt:    key.DeactivatedAt,
+		Description:      key.Description,
+		ContactEmail:     key.ContactEmail,
+		CreatedAt:        key.CreatedAt,
+		LastUsedAt:       key.LastUsedAt,
+	}
+}
+
+// CreateAPIKeyRequest is the body of POST /api/v1/admin/api-keys
+type CreateAPIKeyRequest struct {
+	ClientName       string     `json:"client_name"`
+	ClientType       string     `json:"client_type"`
+	CanReadProofs    bool       `json:"can_read_proofs"`
+	CanRequestProofs bool       `json:"can_request_proofs"`
+	CanBulkDownload  bool       `json:"can_bulk_download"`
+	CanSubmitProofs  bool       `json:"can_submit_proofs"`
+	CanAdmin         bool       `json:"can_admin"`
+	ValidatorID      *string    `json:"validator_id,omitempty"`
+	RateLimitPerMin  int        `json:"rate_limit_per_min,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Description      *string    `json:"description,omitempty"`
+	ContactEmail     *string    `json:"contact_email,omitempty"`
+}
+
+// Validate checks the request and returns the key to create
+func (req *CreateAPIKeyRequest) Validate(now time.Time) (*database.NewAPIKey, error) {
+	if strings.TrimSpace(req.ClientName) == "" {
+		return nil, fmt.Errorf("client_name is required")
+	}
+	if !validClientTypes[req.ClientType] {
+		return nil, fmt.Errorf("client_type must be one of auditor, service, institution, developer, internal, validator")
+	}
+	if req.CanSubmitProofs && (req.ValidatorID == nil || *req.ValidatorID == "") {
+		return nil, fmt.Errorf("validator_id is required with can_submit_proofs")
+	}
+	if req.RateLimitPerMin < 0 {
+		return nil, fmt.Errorf("rate_limit_per_min must not be negative")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	return &database.NewAPIKey{
+		ClientName:       req.ClientName,
+		ClientType:       req.ClientType,
+		CanReadProofs:    req.CanReadProofs,
+		CanRequestProofs: req.CanRequestProofs,
+		CanBulkDownload:  req.CanBulkDownload,
+		CanSubmitProofs:  req.CanSubmitProofs,
+		CanAdmin:         req.CanAdmin,
+		ValidatorID:      req.ValidatorID,
+		RateLimitPerMin:  req.RateLimitPerMin,
+		ExpiresAt:        req.ExpiresAt,
+		Description:      req.Description,
+		ContactEmail:     req.ContactEmail,
+	}, nil
+}
+
+// RotateAPIKeyRequest is the optional body of POST .../{key_id}/rotate
+type RotateAPIKeyRequest struct {
+	OverlapSeconds *int64 `json:"overlap_seconds,omitempty"`
+}
+
+// SetAPIKeyExpiryRequest is the body of PUT .../{key_id}/expiry; a null
+// expires_at removes the expiry
+type SetAPIKeyExpiryRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ValidatorKeyView is a validator attestation key as returned by the admin API
+type ValidatorKeyView struct {
+	KeyID        uuid.UUID  `json:"key_id"`
+	ValidatorID  string     `json:"validator_id"`
+	PublicKey    string     `json:"public_key"` // Hex
+	IsActive     bool       `json:"is_active"`
+	Description  *string    `json:"description,omitempty"`
+	RegisteredAt time.Time  `json:"registered_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+func newValidatorKeyView(key *database.ValidatorKey) *ValidatorKeyView {
+	return &ValidatorKeyView{
+		KeyID:        key.KeyID,
+		ValidatorID:  key.ValidatorID,
+		PublicKey:    hex.EncodeToString(key.PublicKey),
+		IsActive:     key.IsActive,
+		Description:  key.Description,
+		RegisteredAt: key.RegisteredAt,
+		RevokedAt:    key.RevokedAt,
+	}
+}
+
+// RegisterValidatorKeyRequest is the body of POST /api/v1/admin/validator-keys
+type RegisterValidatorKeyRequest struct {
+	ValidatorID string  `json:"validator_id"`
+	PublicKey   string  `json:"public_key"` // Hex Ed25519 public key
+	Description *string `json:"description,omitempty"`
+}
+
+// Validate checks the request and returns the decoded public key
+func (req *RegisterValidatorKeyRequest) Validate() ([]byte, error) {
+	if strings.TrimSpace(req.ValidatorID) == "" {
+		return nil, fmt.Errorf("validator_id is required")
+	}
+	publicKey, err := hex.DecodeString(req.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public_key must be a hex Ed25519 public key")
+	}
+	return publicKey, nil
+}
+
+// CreatedAPIKeyResponse carries a new key's plaintext, shown only once
+type CreatedAPIKeyResponse struct {
+	APIKey   string      `json:"api_key"`
+	Key      *APIKeyView `json:"key"`
+	Previous *APIKeyView `json:"previous,omitempty"` // Rotated key and its new expiry
+}
+
+// =============================================================================
+// HANDLERS
+// =============================================================================
+
+// HandleAPIKeys handles GET and POST /api/v1/admin/api-keys
+func (h *AdminHandlers) HandleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListAPIKeys(w, r)
+	case http.MethodPost:
+		h.handleCreateAPIKey(w, r)
+	default:
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET and POST are allowed")
+	}
+}
+
+// HandleAPIKey handles /api/v1/admin/api-keys/{key_id}[/action]
+func (h *AdminHandlers) HandleAPIKey(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, adminAPIKeysPath), "/")
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 {
+		h.writeError(w, http.StatusNotFound, "NOT_FOUND", "Unknown admin endpoint")
+		return
+	}
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+
+	var handle func(http.ResponseWriter, *http.Request, *database.APIKey, uuid.UUID)
+	method := http.MethodPost
+	switch action {
+	case "":
+		handle, method = h.handleDescribeAPIKey, http.MethodGet
+	case "rotate":
+		handle = h.handleRotateAPIKey
+	case "deactivate":
+		handle = h.handleDeactivateAPIKey
+	case "expiry":
+		handle, method = h.handleSetAPIKeyExpiry, http.MethodPut
+	case "permissions":
+		handle, method = h.handleUpdateAPIKeyPermissions, http.MethodPut
+	default:
+		h.writeError(w, http.StatusNotFound, "NOT_FOUND", "Unknown admin endpoint")
+		return
+	}
+	if r.Method != method {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", fmt.Sprintf("Only %s is allowed", method))
+		return
+	}
+
+	admin, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(parts[0])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid key ID format")
+		return
+	}
+
+	handle(w, r, admin, keyID)
+}
+
+func (h *AdminHandlers) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r); !ok {
+		return
+	}
+	includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("include_inactive"))
+
+	keys, err := h.repos.APIKeys.ListKeys(r.Context(), includeInactive)
+	if err != nil {
+		h.logger.Printf("Error listing API keys: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list API keys")
+		return
+	}
+
+	views := make([]*APIKeyView, 0, len(keys))
+	for _, key := range keys {
+		views = append(views, newAPIKeyView(key))
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys":  views,
+		"count": len(views),
+	})
+}
+
+func (h *AdminHandlers) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	admin, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+	input, err := req.Validate(time.Now())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	created, err := h.repos.APIKeys.CreateKey(r.Context(), input, adminActor(admin, r))
+	if err != nil {
+		h.logger.Printf("Error creating API key for %s: %v", req.ClientName, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create API key")
+		return
+	}
+
+	h.logger.Printf("API key %s created for %s by %s", created.Key.KeyID, created.Key.ClientName, admin.ClientName)
+	h.writeJSON(w, http.StatusCreated, &CreatedAPIKeyResponse{
+		APIKey: created.Plaintext,
+		Key:    newAPIKeyView(created.Key),
+	})
+}
+
+func (h *AdminHandlers) handleDescribeAPIKey(w http.ResponseWriter, r *http.Request, _ *database.APIKey, keyID uuid.UUID) {
+	key, err := h.repos.APIKeys.GetKey(r.Context(), keyID)
+	if err != nil {
+		h.logger.Printf("Error getting API key %s: %v", keyID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get API key")
+		return
+	}
+	if key == nil {
+		h.writeError(w, http.StatusNotFound, "NOT_FOUND", "API key not found")
+		return
+	}
+
+	events, err := h.repos.APIKeys.ListAuditEvents(r.Context(), keyID, 100)
+	if err != nil {
+		h.logger.Printf("Error getting audit log for API key %s: %v", keyID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get API key audit log")
+		return
+	}
+	if events == nil {
+		events = []*database.APIKeyAuditEvent{}
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"key":       newAPIKeyView(key),
+		"audit_log": events,
+	})
+}
+
+func (h *AdminHandlers) handleRotateAPIKey(w http.ResponseWriter, r *http.Request, admin *database.APIKey, keyID uuid.UUID) {
+	overlap := h.rotationOverlap
+	var req RotateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+	if req.OverlapSeconds != nil {
+		if *req.OverlapSeconds < 0 {
+			h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "overlap_seconds must not be negative")
+			return
+		}
+		overlap = time.Duration(*req.OverlapSeconds) * time.Second
+	}
+
+	created, previous, err := h.repos.APIKeys.RotateKey(r.Context(), keyID, overlap, adminActor(admin, r))
+	if !h.writeKeyUpdateError(w, keyID, "rotate", err) {
+		return
+	}
+
+	h.logger.Printf("API key %s rotated to %s by %s", keyID, created.Key.KeyID, admin.ClientName)
+	h.writeJSON(w, http.StatusCreated, &CreatedAPIKeyResponse{
+		APIKey:   created.Plaintext,
+		Key:      newAPIKeyView(created.Key),
+		Previous: newAPIKeyView(previous),
+	})
+}
+
+func (h *AdminHandlers) handleDeactivateAPIKey(w http.ResponseWriter, r *http.Request, admin *database.APIKey, keyID uuid.UUID) {
+	key, err := h.repos.APIKeys.DeactivateKey(r.Context(), keyID, adminActor(admin, r))
+	if !h.writeKeyUpdateError(w, keyID, "deactivate", err) {
+		return
+	}
+	h.apiKeyValidator.Forget(keyID)
+
+	h.logger.Printf("API key %s deactivated by %s", keyID, admin.ClientName)
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"key": newAPIKeyView(key)})
+}
+
+func (h *AdminHandlers) handleSetAPIKeyExpiry(w http.ResponseWriter, r *http.Request, admin *database.APIKey, keyID uuid.UUID) {
+	var req SetAPIKeyExpiryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	key, err := h.repos.APIKeys.SetKeyExpiry(r.Context(), keyID, req.ExpiresAt, adminActor(admin, r))
+	if !h.writeKeyUpdateError(w, keyID, "set expiry of", err) {
+		return
+	}
+	h.apiKeyValidator.Forget(keyID)
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"key": newAPIKeyView(key)})
+}
+
+func (h *AdminHandlers) handleUpdateAPIKeyPermissions(w http.ResponseWriter, r *http.Request, admin *database.APIKey, keyID uuid.UUID) {
+	var perms database.APIKeyPermissions
+	if err := json.NewDecoder(r.Body).Decode(&perms); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+	if perms.CanReadProofs == nil && perms.CanRequestProofs == nil && perms.CanBulkDownload == nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST",
+			"Set at least one of can_read_proofs, can_request_proofs, can_bulk_download")
+		return
+	}
+
+	key, err := h.repos.APIKeys.UpdateKeyPermissions(r.Context(), keyID, &perms, adminActor(admin, r))
+	if !h.writeKeyUpdateError(w, keyID, "update permissions of", err) {
+		return
+	}
+	h.apiKeyValidator.Forget(keyID)
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"key": newAPIKeyView(key)})
+}
+
+// =============================================================================
+// VALIDATOR KEY HANDLERS
+// =============================================================================
+
+// HandleValidatorKeys handles GET and POST /api/v1/admin/validator-keys
+func (h *AdminHandlers) HandleValidatorKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListValidatorKeys(w, r)
+	case http.MethodPost:
+		h.handleRegisterValidatorKey(w, r)
+	default:
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET and POST are allowed")
+	}
+}
+
+// HandleValidatorKey handles /api/v1/admin/validator-keys/{key_id}[/revoke]
+func (h *AdminHandlers) HandleValidatorKey(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, adminValidatorKeysPath), "/")
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "revoke") {
+		h.writeError(w, http.StatusNotFound, "NOT_FOUND", "Unknown admin endpoint")
+		return
+	}
+	revoke := len(parts) == 2
+
+	method := http.MethodGet
+	if revoke {
+		method = http.MethodPost
+	}
+	if r.Method != method {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", fmt.Sprintf("Only %s is allowed", method))
+		return
+	}
+
+	admin, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(parts[0])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid key ID format")
+		return
+	}
+
+	if revoke {
+		h.handleRevokeValidatorKey(w, r, admin, keyID)
+		return
+	}
+	h.handleDescribeValidatorKey(w, r, keyID)
+}
+
+func (h *AdminHandlers) handleListValidatorKeys(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r); !ok {
+		return
+	}
+	query := r.URL.Query()
+	includeRevoked, _ := strconv.ParseBool(query.Get("include_revoked"))
+
+	keys, err := h.repos.Ingestion.ListValidatorKeys(r.Context(), query.Get("validator_id"), includeRevoked)
+	if err != nil {
+		h.logger.Printf("Error listing validator keys: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list validator keys")
+		return
+	}
+
+	views := make([]*ValidatorKeyView, 0, len(keys))
+	for _, key := range keys {
+		views = append(views, newValidatorKeyView(key))
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys":  views,
+		"count": len(views),
+	})
+}
+
+func (h *AdminHandlers) handleRegisterValidatorKey(w http.ResponseWriter, r *http.Request) {
+	admin, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	var req RegisterValidatorKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+	publicKey, err := req.Validate()
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	key, err := h.repos.Ingestion.RegisterValidatorKey(r.Context(), req.ValidatorID, publicKey, req.Description, adminActor(admin, r))
+	if err != nil {
+		h.logger.Printf("Error registering validator key for %s: %v", req.ValidatorID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to register validator key")
+		return
+	}
+
+	h.logger.Printf("Validator key %s registered for %s by %s", key.KeyID, key.ValidatorID, admin.ClientName)
+	h.writeJSON(w, http.StatusCreated, map[string]interface{}{"key": newValidatorKeyView(key)})
+}
+
+func (h *AdminHandlers) handleDescribeValidatorKey(w http.ResponseWriter, r *http.Request, keyID uuid.UUID) {
+	key, err := h.repos.Ingestion.GetValidatorKey(r.Context(), keyID)
+	if err != nil {
+		h.logger.Printf("Error getting validator key %s: %v", keyID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validator key")
+		return
+	}
+	if key == nil {
+		h.writeError(w, http.StatusNotFound, "NOT_FOUND", "Validator key not found")
+		return
+	}
+
+	events, err := h.repos.APIKeys.ListValidatorKeyAuditEvents(r.Context(), keyID, 100)
+	if err != nil {
+		h.logger.Printf("Error getting audit log for validator key %s: %v", keyID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validator key audit log")
+		return
+	}
+	if events == nil {
+		events = []*database.APIKeyAuditEvent{}
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"key":       newValidatorKeyView(key),
+		"audit_log": events,
+	})
+}
+
+func (h *AdminHandlers) handleRevokeValidatorKey(w http.ResponseWriter, r *http.Request, admin *database.APIKey, keyID uuid.UUID) {
+	key, err := h.repos.Ingestion.RevokeValidatorKey(r.Context(), keyID, adminActor(admin, r))
+	switch {
+	case errors.Is(err, database.ErrValidatorKeyNotFound):
+		h.writeError(w, http.StatusNotFound, "NOT_FOUND", "Validator key not found")
+		return
+	case errors.Is(err, database.ErrValidatorKeyRevoked):
+		h.writeError(w, http.StatusConflict, "VALIDATOR_KEY_REVOKED", "Validator key is already revoked")
+		return
+	case err != nil:
+		h.logger.Printf("Failed to revoke validator key %s: %v", keyID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke validator key")
+		return
+	}
+
+	h.logger.Printf("Validator key %s of %s revoked by %s", keyID, key.ValidatorID, admin.ClientName)
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"key": newValidatorKeyView(key)})
+}
+
+// =============================================================================
+// HELPER METHODS
+// =============================================================================
+
+// authorize requires an X-API-Key with can_admin or a bearer token with the
+// admin scope
+func (h *AdminHandlers) authorize(w http.ResponseWriter, r *http.Request) (*database.APIKey, bool) {
+	var key *database.APIKey
+	if principal := PrincipalFromContext(r.Context()); principal != nil {
+		key = principal.APIKey
+	} else if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		var err error
+		if key, err = h.apiKeyValidator.Validate(r.Context(), apiKey); err != nil {
+			h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+			return nil, false
+		}
+	}
+	if key == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "API key or bearer token is required for administration")
+		return nil, false
+	}
+	if !key.CanAdmin {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "API key does not have admin permission")
+		return nil, false
+	}
+	return key, true
+}
+
+// writeKeyUpdateError writes the response for a failed key update and
+// reports whether the update succeeded
+func (h *AdminHandlers) writeKeyUpdateError(w http.ResponseWriter, keyID uuid.UUID, action string, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, database.ErrAPIKeyNotFound):
+		h.writeError(w, http.StatusNotFound, "NOT_FOUND", "API key not found")
+	case errors.Is(err, database.ErrAPIKeyInactive):
+		h.writeError(w, http.StatusConflict, "API_KEY_INACTIVE", "API key is inactive or expired")
+	default:
+		h.logger.Printf("Failed to %s API key %s: %v", action, keyID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", fmt.Sprintf("Failed to %s API key", action))
+	}
+	return false
+}
+
+func adminActor(admin *database.APIKey, r *http.Request) *database.APIKeyActor {
+	clientIP := getClientIP(r)
+	if principal := PrincipalFromContext(r.Context()); principal != nil && principal.Type == PrincipalTypeToken {
+		return &database.APIKeyActor{
+			Type:     PrincipalTypeToken,
+			Name:     principal.Claims.Subject,
+			ClientIP: &clientIP,
+		}
+	}
+	return &database.APIKeyActor{
+		Type:     "api_key",
+		KeyID:    &admin.KeyID,
+		Name:     admin.ClientName,
+		ClientIP: &clientIP,
+	}
+}
+
+func (h *AdminHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AdminHandlers) writeError(w http.ResponseWriter, status int, code, message string) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for Admin Handlers
+// Tests routing, authentication and request validation without requiring database connection
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// ============================================================================
+// Routing Tests
+// ============================================================================
+
+func TestHandleAPIKeys_MethodNotAllowed(t *testing.T) {
+	handlers := NewAdminHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleAPIKeys(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestHandleAPIKeys_MissingAPIKey(t *testing.T) {
+	handlers := NewAdminHandlers(nil, nil, nil)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req := httptest.NewRequest(method, "/api/v1/admin/api-keys", strings.NewReader("{}"))
+		w := httptest.NewRecorder()
+		handlers.HandleAPIKeys(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status 401, got %d", method, w.Code)
+		}
+	}
+}
+
+func TestHandleAPIKey_Routing(t *testing.T) {
+	handlers := NewAdminHandlers(nil, nil, nil)
+	keyPath := "/api/v1/admin/api-keys/" + uuid.New().String()
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, keyPath, http.StatusUnauthorized},
+		{http.MethodPost, keyPath + "/rotate", http.StatusUnauthorized},
+		{http.MethodPost, keyPath + "/deactivate", http.StatusUnauthorized},
+		{http.MethodPut, keyPath + "/expiry", http.StatusUnauthorized},
+		{http.MethodPut, keyPath + "/permissions", http.StatusUnauthorized},
+		{http.MethodDelete, keyPath, http.StatusMethodNotAllowed},
+		{http.MethodGet, keyPath + "/rotate", http.StatusMethodNotAllowed},
+		{http.MethodPost, keyPath + "/expiry", http.StatusMethodNotAllowed},
+		{http.MethodPost, keyPath + "/unknown", http.StatusNotFound},
+		{http.MethodPost, keyPath + "/rotate/extra", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		handlers.HandleAPIKey(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
+
+func TestHandleValidatorKey_Routing(t *testing.T) {
+	handlers := NewAdminHandlers(nil, nil, nil)
+	keyPath := "/api/v1/admin/validator-keys/" + uuid.New().String()
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, keyPath, http.StatusUnauthorized},
+		{http.MethodPost, keyPath + "/revoke", http.StatusUnauthorized},
+		{http.MethodDelete, keyPath, http.StatusMethodNotAllowed},
+		{http.MethodGet, keyPath + "/revoke", http.StatusMethodNotAllowed},
+		{http.MethodPost, keyPath + "/rotate", http.StatusNotFound},
+		{http.MethodPost, keyPath + "/revoke/extra", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		handlers.HandleValidatorKey(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req := httptest.NewRequest(method, "/api/v1/admin/validator-keys", strings.NewReader("{}"))
+		w := httptest.NewRecorder()
+		handlers.HandleValidatorKeys(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status 401, got %d", method, w.Code)
+		}
+	}
+}
+
+// ============================================================================
+// Request Validation Tests
+// ============================================================================
+
+func TestCreateAPIKeyRequest_Validate(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	validator := "validator-1"
+
+	tests := []struct {
+		name string
+		req  CreateAPIKeyRequest
+		want string
+	}{
+		{"valid", CreateAPIKeyRequest{ClientName: "auditor-1", ClientType: "auditor", CanReadProofs: true}, ""},
+		{"submitter", CreateAPIKeyRequest{ClientName: "v1", ClientType: "validator", CanSubmitProofs: true, ValidatorID: &validator}, ""},
+		{"missing name", CreateAPIKeyRequest{ClientType: "auditor"}, "client_name"},
+		{"bad type", CreateAPIKeyRequest{ClientName: "x", ClientType: "robot"}, "client_type"},
+		{"submitter without validator", CreateAPIKeyRequest{ClientName: "x", ClientType: "validator", CanSubmitProofs: true}, "validator_id"},
+		{"negative rate", CreateAPIKeyRequest{ClientName: "x", ClientType: "service", RateLimitPerMin: -1}, "rate_limit_per_min"},
+		{"expired", CreateAPIKeyRequest{ClientName: "x", ClientType: "service", ExpiresAt: &past}, "expires_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := tt.req.Validate(now)
+			if tt.want == "" {
+				if err != nil || input.ClientName != tt.req.ClientName {
+					t.Errorf("Expected valid request, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRegisterValidatorKeyRequest_Validate(t *testing.T) {
+	pub := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name string
+		req  RegisterValidatorKeyRequest
+		want string
+	}{
+		{"valid", RegisterValidatorKeyRequest{ValidatorID: "validator-1", PublicKey: pub}, ""},
+		{"missing validator", RegisterValidatorKeyRequest{PublicKey: pub}, "validator_id"},
+		{"not hex", RegisterValidatorKeyRequest{ValidatorID: "validator-1", PublicKey: "zz"}, "public_key"},
+		{"short key", RegisterValidatorKeyRequest{ValidatorID: "validator-1", PublicKey: "abcd"}, "public_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publicKey, err := tt.req.Validate()
+			if tt.want == "" {
+				if err != nil || len(publicKey) != 32 {
+					t.Errorf("Expected valid request, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// ============================================================================
+// API Key Cache Tests
+// ============================================================================
+
+func TestAPIKeyValidator_CacheRechecksExpiry(t *testing.T) {
+	v := NewAPIKeyValidator(nil)
+	expires := time.Now().Add(-time.Second)
+	key := &database.APIKey{KeyID: uuid.New(), IsActive: true, ExpiresAt: &expires}
+	v.cache["cpk_test"] = &cachedAPIKey{key: key, cachedAt: time.Now()}
+
+	if _, err := v.Validate(context.Background(), "cpk_test"); err == nil {
+		t.Fatal("Expected cached key past its expiry to be rejected")
+	}
+	if _, ok := v.cache["cpk_test"]; ok {
+		t.Error("Expected expired key to be dropped from the cache")
+	}
+}
+
+func TestAPIKeyValidator_Forget(t *testing.T) {
+	v := NewAPIKeyValidator(nil)
+	key := &database.APIKey{KeyID: uuid.New(), IsActive: true}
+	v.cache["cpk_test"] = &cachedAPIKey{key: key, cachedAt: time.Now()}
+
+	if cached, err := v.Validate(context.Background(), "cpk_test"); err != nil || cached != key {
+		t.Fatalf("Expected cached key, got %v", err)
+	}
+	v.Forget(key.KeyID)
+	if _, ok := v.cache["cpk_test"]; ok {
+		t.Error("Expected key to be dropped from the cache")
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2025 Certen Protocol
+//
+// Authentication Middleware
+// Resolves the caller of every request once and enforces per-route permissions
+//
+// Wrap identifies the caller from an X-API-Key header (or api_key query
+// parameter), a verified TLS client certificate on the validator listener,
+// or an Authorization: Bearer JWT, in that order, and stores it in the
+// request context as a Principal. Invalid credentials are
+// rejected with 401 before any handler runs.
+//
+// Require guards a route with a permission. Public routes accept anyone.
+// Read and request routes accept anonymous callers unless API_KEY_REQUIRED is
+// set; bulk, submission and admin routes always need credentials. A caller
+// that lacks the route's permission gets 403.
+//
+// Tokens are given API key permissions from their scopes:
+//
+//	proofs:read     can_read_proofs
+//	proofs:request  can_request_proofs
+//	bulk:export     can_bulk_download
+//	admin           can_admin
+//
+// Tokens cannot submit proofs. Validators use API keys or, on the mTLS
+// validator listener, a client certificate whose subject common name is their
+// validator ID; a certificate may submit proofs and attestations for that
+// validator only.
+//
+// A user's intents can only be listed by that user's token, by a token with
+// org:admin for the user's intents in the token's organisation (org_adi), or
+// by an auditor or internal API key.
+
+package server
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/auth"
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// Principal types
+const (
+	PrincipalTypeAPIKey      = "api_key"
+	PrincipalTypeToken       = "jwt"
+	PrincipalTypeCertificate = "certificate"
+)
+
+// Client types of API keys derived from tokens and client certificates
+const (
+	tokenClientType       = "user"
+	certificateClientType = "validator"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Type        string            // PrincipalTypeAPIKey, PrincipalTypeToken or PrincipalTypeCertificate
+	APIKey      *database.APIKey  // Permissions; derived for tokens and certificates
+	Claims      *auth.Claims      // Set for tokens
+	Certificate *x509.Certificate // Set for client certificates
+}
+
+// Name identifies the principal in logs and audit records
+func (p *Principal) Name() string {
+	return p.APIKey.ClientName
+}
+
+// KeyID returns the stored API key of the principal, or nil for tokens and
+// certificates
+func (p *Principal) KeyID() *uuid.UUID {
+	if p.Type != PrincipalTypeAPIKey {
+		return nil
+	}
+	return &p.APIKey.KeyID
+}
+
+// auditorClientTypes are the API key client types with unrestricted read
+// access to user data
+var auditorClientTypes = map[string]bool{
+	"auditor":  true,
+	"internal": true,
+}
+
+// IsAuditor reports whether the principal is an auditor or internal API key
+func (p *Principal) IsAuditor() bool {
+	return p.Type == PrincipalTypeAPIKey && auditorClientTypes[p.APIKey.ClientType]
+}
+
+// OrganizationAdmin returns the organisation ADI whose intents a token with
+// the org:admin scope may read, or "" if the principal administers none
+func (p *Principal) OrganizationAdmin() string {
+	if p.Claims == nil || !p.Claims.HasScope(auth.ScopeOrgAdmin) {
+		return ""
+	}
+	return p.Claims.OrgADI
+}
+
+// userIntentScope decides whether a principal may read a user's intents.
+// Auditors and the user themselves may read all of them; organisation admins
+// only those of their organisation, which is returned.
+func userIntentScope(p *Principal, userID string) (organizationADI string, ok bool) {
+	switch {
+	case p == nil:
+		return "", false
+	case p.IsAuditor():
+		return "", true
+	case p.Claims != nil && p.Claims.Subject == userID:
+		return "", true
+	}
+	org := p.OrganizationAdmin()
+	return org, org != ""
+}
+
+// intentListScope decides which intents a principal may list across users.
+// Auditors see all of them; tokens only their subject's and, for
+// organisation admins, their organisation's. Other keys may list none.
+func intentListScope(p *Principal) (*database.IntentScope, bool) {
+	switch {
+	case p == nil:
+		return nil, false
+	case p.IsAuditor():
+		return nil, true
+	case p.Claims != nil:
+		return &database.IntentScope{UserID: p.Claims.Subject, OrganizationADI: p.OrganizationAdmin()}, true
+	}
+	return nil, false
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a context carrying the request's principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext returns the principal of a request, or nil for
+// anonymous requests
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return p
+}
+
+// Permission is what a route requires of its caller
+type Permission string
+
+// Route permissions
+const (
+	PermissionPublic        Permission = "public"
+	PermissionAuthenticated Permission = "authenticated" // Any valid credentials
+	PermissionReadProofs    Permission = "can_read_proofs"
+	PermissionRequestProofs Permission = "can_request_proofs"
+	PermissionBulkDownload  Permission = "can_bulk_download"
+	PermissionSubmitProofs  Permission = "can_submit_proofs"
+	PermissionAdmin         Permission = "can_admin"
+)
+
+// grantedBy reports whether a key has the permission
+func (p Permission) grantedBy(key *database.APIKey) bool {
+	switch p {
+	case PermissionPublic, PermissionAuthenticated:
+		return true
+	case PermissionReadProofs:
+		return key.CanReadProofs
+	case PermissionRequestProofs:
+		return key.CanRequestProofs
+	case PermissionBulkDownload:
+		return key.CanBulkDownload
+	case PermissionSubmitProofs:
+		return key.CanSubmitProofs
+	case PermissionAdmin:
+		return key.CanAdmin
+	}
+	return false
+}
+
+// alwaysAuthenticated reports whether the permission needs credentials even
+// when API keys are optional
+func (p Permission) alwaysAuthenticated() bool {
+	switch p {
+	case PermissionReadProofs, PermissionRequestProofs, PermissionPublic:
+		return false
+	}
+	return true
+}
+
+// AuthConfig contains configuration for the authentication middleware
+type AuthConfig struct {
+	APIKeyRequired  bool             // Require credentials on read and request routes
+	TokenVerifier   *auth.Verifier   // Enables bearer tokens; nil disables them
+	APIKeyValidator *APIKeyValidator // Shared with the admin handlers; created if nil
+}
+
+// AuthMiddleware authenticates requests and enforces route permissions
+type AuthMiddleware struct {
+	validator      *APIKeyValidator
+	verifier       *auth.Verifier
+	apiKeyRequired bool
+	logger         *log.Logger
+}
+
+// NewAuthMiddleware creates a new authentication middleware
+func NewAuthMiddleware(repos *database.Repositories, config *AuthConfig, logger *log.Logger) *AuthMiddleware {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[Auth] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &AuthConfig{}
+	}
+	validator := config.APIKeyValidator
+	if validator == nil {
+		validator = NewAPIKeyValidator(repos)
+	}
+	return &AuthMiddleware{
+		validator:      validator,
+		verifier:       config.TokenVerifier,
+		apiKeyRequired: config.APIKeyRequired,
+		logger:         logger,
+	}
+}
+
+// Wrap returns next with the request's principal resolved
+func (m *AuthMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := m.authenticate(r)
+		if err != nil {
+			recordAuthentication(r.Context(), nil, true)
+			m.writeAuthError(w, err)
+			return
+		}
+		recordAuthentication(r.Context(), principal, false)
+		if principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require returns handler guarded by a route permission
+func (m *AuthMiddleware) Require(perm Permission, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := PrincipalFromContext(r.Context())
+		switch {
+		case principal == nil && perm != PermissionPublic && (m.apiKeyRequired || perm.alwaysAuthenticated()):
+			if m.verifier != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			m.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "An API key or bearer token is required")
+			return
+		case principal != nil && !perm.grantedBy(principal.APIKey):
+			m.writeError(w, http.StatusForbidden, "FORBIDDEN",
+				fmt.Sprintf("Credentials do not have the %s permission", perm))
+			return
+		}
+		handler(w, r)
+	})
+}
+
+// errInvalidToken wraps token verification failures
+type errInvalidToken struct{ err error }
+
+func (e *errInvalidToken) Error() string { return e.err.Error() }
+func (e *errInvalidToken) Unwrap() error { return e.err }
+
+func (m *AuthMiddleware) authenticate(r *http.Request) (*Principal, error) {
+	apiKey := r.Header.Get("X-API-Key")
+	if apiKey == "" {
+		apiKey = r.URL.Query().Get("api_key")
+	}
+	if apiKey != "" {
+		key, err := m.validator.Validate(r.Context(), apiKey)
+		if err != nil {
+			return nil, err
+		}
+		return &Principal{Type: PrincipalTypeAPIKey, APIKey: key}, nil
+	}
+
+	if cert := verifiedClientCertificate(r); cert != nil {
+		validatorID := cert.Subject.CommonName
+		if validatorID == "" {
+			return nil, errors.New("client certificate has no common name")
+		}
+		return &Principal{Type: PrincipalTypeCertificate, APIKey: certificateAPIKey(validatorID), Certificate: cert}, nil
+	}
+
+	token, ok := bearerToken(r)
+	if !ok || m.verifier == nil {
+		return nil, nil
+	}
+	claims, err := m.verifier.Verify(token)
+	if err != nil {
+		return nil, &errInvalidToken{err}
+	}
+	return &Principal{Type: PrincipalTypeToken, APIKey: tokenAPIKey(claims), Claims: claims}, nil
+}
+
+func (m *AuthMiddleware) writeAuthError(w http.ResponseWriter, err error) {
+	var tokenErr *errInvalidToken
+	if !errors.As(err, &tokenErr) {
+		m.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	code, message := "INVALID_TOKEN", "Bearer token is invalid"
+	switch {
+	case errors.Is(err, auth.ErrTokenExpired):
+		code, message = "TOKEN_EXPIRED", "Bearer token has expired or is not yet valid"
+	case errors.Is(err, auth.ErrUnsupportedAlgorithm):
+		message = "Bearer token algorithm is not accepted"
+	}
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, message))
+	m.writeError(w, http.StatusUnauthorized, code, message)
+}
+
+func (m *AuthMiddleware) writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	}); err != nil {
+		m.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(header[7:])
+	return token, token != ""
+}
+
+// tokenAPIKey maps a token's scopes onto API key permissions so handlers can
+// check a token like a key. The key is not stored and has no KeyID.
+func tokenAPIKey(claims *auth.Claims) *database.APIKey {
+	expiresAt := claims.ExpiresAt
+	return &database.APIKey{
+		ClientName:       "jwt:" + claims.Subject,
+		ClientType:       tokenClientType,
+		CanReadProofs:    claims.HasScope(auth.ScopeProofsRead),
+		CanRequestProofs: claims.HasScope(auth.ScopeProofsRequest),
+		CanBulkDownload:  claims.HasScope(auth.ScopeBulkExport),
+		CanAdmin:         claims.HasScope(auth.ScopeAdmin),
+		RateLimitPerMin:  100,
+		IsActive:         true,
+		ExpiresAt:        &expiresAt,
+	}
+}
+
+// verifiedClientCertificate returns the client certificate of a TLS
+// connection whose chain was verified, or nil
+func verifiedClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// certificateAPIKey gives a validator's client certificate the permissions of
+// a validator API key. The key is not stored and has no KeyID.
+func certificateAPIKey(validatorID string) *database.APIKey {
+	return &database.APIKey{
+		ClientName:      "cert:" + validatorID,
+		ClientType:      certificateClientType,
+		CanSubmitProofs: true,
+		ValidatorID:     &validatorID,
+		IsActive:        true,
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the Authentication Middleware
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/certen/proofs-service/pkg/auth"
+)
+
+var testJWTSecret = []byte("bearer-test-secret-32-bytes-long")
+
+func testBearerToken(t *testing.T, scope string, exp time.Time) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	input := encode(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." +
+		encode(map[string]interface{}{"sub": "user-1", "exp": exp.Unix(), "scope": scope})
+	mac := hmac.New(sha256.New, testJWTSecret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// principalHandler reports the principal resolved for the request
+func principalHandler(got **Principal) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*got = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func testAuthMiddleware(t *testing.T, required bool) *AuthMiddleware {
+	t.Helper()
+
+	verifier, err := auth.NewVerifier(&auth.VerifierConfig{HMACSecret: testJWTSecret})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	return NewAuthMiddleware(nil, &AuthConfig{APIKeyRequired: required, TokenVerifier: verifier}, nil)
+}
+
+// ============================================================================
+// Authentication Tests
+// ============================================================================
+
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	var principal *Principal
+	handler := testAuthMiddleware(t, false).Wrap(principalHandler(&principal))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/query", nil)
+	req.Header.Set("Authorization", "Bearer "+testBearerToken(t, "proofs:read bulk:export", time.Now().Add(time.Hour)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent || principal == nil {
+		t.Fatalf("Expected token to be accepted, got status %d", w.Code)
+	}
+	if principal.Type != PrincipalTypeToken || principal.KeyID() != nil || principal.Claims.Subject != "user-1" {
+		t.Errorf("Unexpected principal: %+v", principal)
+	}
+	key := principal.APIKey
+	if key.ClientName != "jwt:user-1" || !key.CanReadProofs || !key.CanBulkDownload || key.CanRequestProofs || key.CanAdmin || key.CanSubmitProofs {
+		t.Errorf("Unexpected permissions: %+v", key)
+	}
+}
+
+func TestAuthMiddleware_InvalidToken(t *testing.T) {
+	var principal *Principal
+	handler := testAuthMiddleware(t, false).Wrap(principalHandler(&principal))
+
+	for name, token := range map[string]string{
+		"expired":   testBearerToken(t, "proofs:read", time.Now().Add(-time.Hour)),
+		"malformed": "abc",
+		"tampered":  strings.Replace(testBearerToken(t, "proofs:read", time.Now().Add(time.Hour)), ".", ".x", 1),
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/query", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status 401, got %d", name, w.Code)
+		}
+		if !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Bearer ") {
+			t.Errorf("%s: expected WWW-Authenticate header", name)
+		}
+	}
+}
+
+func TestAuthMiddleware_Anonymous(t *testing.T) {
+	var principal *Principal
+
+	// Without a token, or without a configured verifier, requests are anonymous
+	for name, handler := range map[string]http.Handler{
+		"no token":    testAuthMiddleware(t, false).Wrap(principalHandler(&principal)),
+		"no verifier": NewAuthMiddleware(nil, nil, nil).Wrap(principalHandler(&principal)),
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/query", nil)
+		if name == "no verifier" {
+			req.Header.Set("Authorization", "Bearer "+testBearerToken(t, "admin", time.Now().Add(time.Hour)))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent || principal != nil {
+			t.Errorf("%s: expected an anonymous request, got status %d", name, w.Code)
+		}
+	}
+}
+
+// ============================================================================
+// Permission Tests
+// ============================================================================
+
+func TestAuthMiddleware_Require(t *testing.T) {
+	readToken := testBearerToken(t, "proofs:read", time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name     string
+		required bool
+		perm     Permission
+		token    string
+		want     int
+	}{
+		{"public anonymous, keys required", true, PermissionPublic, "", http.StatusNoContent},
+		{"read anonymous, keys optional", false, PermissionReadProofs, "", http.StatusNoContent},
+		{"read anonymous, keys required", true, PermissionReadProofs, "", http.StatusUnauthorized},
+		{"request anonymous, keys required", true, PermissionRequestProofs, "", http.StatusUnauthorized},
+		{"bulk anonymous, keys optional", false, PermissionBulkDownload, "", http.StatusUnauthorized},
+		{"admin anonymous, keys optional", false, PermissionAdmin, "", http.StatusUnauthorized},
+		{"authenticated anonymous, keys optional", false, PermissionAuthenticated, "", http.StatusUnauthorized},
+		{"read with scope", true, PermissionReadProofs, readToken, http.StatusNoContent},
+		{"request without scope", false, PermissionRequestProofs, readToken, http.StatusForbidden},
+		{"bulk without scope", false, PermissionBulkDownload, readToken, http.StatusForbidden},
+		{"submit with token", false, PermissionSubmitProofs, testBearerToken(t, "proofs:read proofs:request bulk:export admin", time.Now().Add(time.Hour)), http.StatusForbidden},
+		{"authenticated with token", true, PermissionAuthenticated, readToken, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testAuthMiddleware(t, tt.required)
+			var principal *Principal
+			handler := m.Wrap(m.Require(tt.perm, principalHandler(&principal)))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/query", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_AdminRequiresScope(t *testing.T) {
+	handler := testAuthMiddleware(t, false).Wrap(http.HandlerFunc(NewAdminHandlers(nil, nil, nil).HandleAPIKeys))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/api-keys", nil)
+	req.Header.Set("Authorization", "Bearer "+testBearerToken(t, "proofs:read", time.Now().Add(time.Hour)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 without the admin scope, got %d", w.Code)
+	}
+}
+
+func TestBulkExport_AcceptsTokenPrincipal(t *testing.T) {
+	// A token without bulk:export reaches the handler's own permission check
+	handler := testAuthMiddleware(t, false).Wrap(http.HandlerFunc(NewBulkHandlers(nil, nil, nil).HandleBulkExport))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/proofs/bulk/export", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer "+testBearerToken(t, "proofs:read", time.Now().Add(time.Hour)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
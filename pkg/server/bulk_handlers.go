@@ -7,6 +7,7 @@
 // - POST /api/v1/proofs/bulk/export - Export proofs in bulk
 // - GET /api/v1/proofs/bulk/export/{job_id} - Get export job status
 // - GET /api/v1/proofs/bulk/download/{job_id} - Download export file
+// - POST /api/v1/proofs/bulk/import - Import a JSON lines export
 // - POST /api/v1/proofs/bulk/verify - Bulk verification
 // - GET /api/v1/stats/proofs - Get proof statistics
 // - GET /api/v1/stats/system - Get system health
@@ -19,6 +20,7 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -31,6 +33,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/proofarchive"
 )
 
 // BulkHandlers provides HTTP handlers for bulk operations
@@ -38,19 +41,19 @@ type BulkHandlers struct {
 	repos           *database.Repositories
 	validatorID     string
 	logger          *log.Logger
-	rateLimiter     *RateLimiter
 	apiKeyValidator *APIKeyValidator
 	exportJobs      map[uuid.UUID]*ExportJob
 	exportMu        sync.RWMutex
 	maxExportSize   int
+	maxImportBytes  int64
 	startTime       time.Time
 }
 
 // BulkHandlersConfig contains configuration for bulk handlers
 type BulkHandlersConfig struct {
-	ValidatorID        string
-	RateLimitPerMinute int
-	MaxExportSize      int // Maximum number of proofs in single export
+	ValidatorID    string
+	MaxExportSize  int   // Maximum number of proofs in single export
+	MaxImportBytes int64 // Maximum size of an uploaded import file
 }
 
 // NewBulkHandlers creates new bulk handlers
@@ -64,20 +67,23 @@ func NewBulkHandlers(
 	}
 	if config == nil {
 		config = &BulkHandlersConfig{
-			ValidatorID:        "default-validator",
-			RateLimitPerMinute: 10, // Lower rate limit for bulk operations
-			MaxExportSize:      10000,
+			ValidatorID:   "default-validator",
+			MaxExportSize: 10000,
 		}
 	}
+	maxImportBytes := config.MaxImportBytes
+	if maxImportBytes <= 0 {
+		maxImportBytes = 256 << 20
+	}
 
 	return &BulkHandlers{
 		repos:           repos,
 		validatorID:     config.ValidatorID,
 		logger:          logger,
-		rateLimiter:     NewRateLimiter(config.RateLimitPerMinute),
 		apiKeyValidator: NewAPIKeyValidator(repos),
 		exportJobs:      make(map[uuid.UUID]*ExportJob),
 		maxExportSize:   config.MaxExportSize,
+		maxImportBytes:  maxImportBytes,
 		startTime:       time.Now(),
 	}
 }
@@ -146,6 +152,10 @@ func (h *BulkHandlers) HandleBulkExport(w http.ResponseWriter, r *http.Request)
 		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
 		return
 	}
+	if apiKey == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "API key is required for bulk operations")
+		return
+	}
 
 	// Check permissions
 	if !apiKey.CanBulkDownload {
@@ -153,12 +163,6 @@ func (h *BulkHandlers) HandleBulkExport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check rate limit
-	if !h.rateLimiter.Allow(apiKey.ClientName) {
-		h.writeError(w, http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded for bulk operations")
-		return
-	}
-
 	// Parse request
 	var req BulkExportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -280,6 +284,18 @@ func (h *BulkHandlers) HandleDownloadExport(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Redeem the signed URL, if the request came in with one
+	signedURLID, err := redeemSignedURL(r, h.repos)
+	if errors.Is(err, database.ErrSignedURLNotRedeemable) {
+		h.writeError(w, http.StatusGone, "URL_REDEEMED", "Signed URL has expired or has already been used")
+		return
+	}
+	if err != nil {
+		h.logger.Printf("Error redeeming signed URL: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to redeem signed URL")
+		return
+	}
+
 	// Set headers based on format
 	var contentType, extension string
 	switch job.Format {
@@ -298,6 +314,90 @@ func (h *BulkHandlers) HandleDownloadExport(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("X-Processed-Count", strconv.Itoa(job.ProcessedCount))
 	w.WriteHeader(http.StatusOK)
 	w.Write(job.FileData)
+
+	if h.repos != nil {
+		download := &database.NewBundleDownload{
+			ExportJobID:  &jobID,
+			SignedURLID:  signedURLID,
+			ClientIP:     getClientIP(r),
+			ResponseCode: http.StatusOK,
+			BytesSent:    len(job.FileData),
+		}
+		if principal := PrincipalFromContext(r.Context()); principal != nil {
+			download.APIKeyID = principal.KeyID()
+		}
+		if userAgent := r.UserAgent(); userAgent != "" {
+			download.UserAgent = &userAgent
+		}
+		if err := h.repos.ProofArtifacts.RecordBundleDownload(r.Context(), download); err != nil {
+			h.logger.Printf("Error recording export download %s: %v", jobID, err)
+		}
+	}
+}
+
+// CompletedExportExpiry returns when a completed export job's file expires.
+// It reports false for unknown jobs and jobs that are not completed.
+func (h *BulkHandlers) CompletedExportExpiry(jobID uuid.UUID) (time.Time, bool) {
+	h.exportMu.RLock()
+	defer h.exportMu.RUnlock()
+
+	job, ok := h.exportJobs[jobID]
+	if !ok || job.Status != "completed" || len(job.FileData) == 0 {
+		return time.Time{}, false
+	}
+	return job.ExpiresAt, true
+}
+
+// =============================================================================
+// BULK IMPORT ENDPOINTS
+// =============================================================================
+
+// HandleBulkImport handles POST /api/v1/proofs/bulk/import
+//
+// The body is a JSON lines export made with include_artifacts, gzipped or
+// not. Each record is verified and imported on its own; the response counts
+// imported, skipped (already stored) and rejected records.
+func (h *BulkHandlers) HandleBulkImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+		return
+	}
+
+	apiKey, err := h.validateAPIKey(r)
+	if err != nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Importing writes proofs, so it needs both bulk and submit permissions
+	if !apiKey.CanBulkDownload || !apiKey.CanSubmitProofs {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "API key does not have bulk import permission")
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.maxImportBytes)
+	report, err := proofarchive.NewImporter(h.repos.Ingestion).ImportJSONLines(r.Context(), body)
+	if err != nil {
+		h.logger.Printf("Bulk import stopped after %d imported, %d skipped, %d rejected: %v",
+			report.Imported, report.Skipped, report.Rejected, err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, "IMPORT_TOO_LARGE",
+				fmt.Sprintf("Import file exceeds %d bytes", h.maxImportBytes))
+			return
+		}
+		if errors.Is(err, proofarchive.ErrUnreadable) {
+			h.writeError(w, http.StatusBadRequest, "INVALID_IMPORT", err.Error())
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, "IMPORT_FAILED",
+			fmt.Sprintf("Import stopped after %d imported, %d skipped, %d rejected records", report.Imported, report.Skipped, report.Rejected))
+		return
+	}
+
+	h.logger.Printf("Bulk import by %s: %d imported, %d skipped, %d rejected",
+		apiKey.ClientName, report.Imported, report.Skipped, report.Rejected)
+	h.writeJSON(w, http.StatusOK, report)
 }
 
 // =============================================================================
@@ -739,29 +839,15 @@ func (h *BulkHandlers) processExportJob(job *ExportJob) {
 func (h *BulkHandlers) writeJSONLinesExport(w io.Writer, proofs []database.ProofArtifact, job *ExportJob) {
 	ctx := context.Background()
 	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false) // Keep artifact_json as stored so artifact_hash still matches
 
-	for _, proof := range proofs {
-		record := map[string]interface{}{
-			"proof_id":           proof.ProofID,
-			"proof_type":         string(proof.ProofType),
-			"accum_tx_hash":      proof.AccumTxHash,
-			"account_url":        proof.AccountURL,
-			"gov_level":          govLevelToString(proof.GovLevel),
-			"status":             string(proof.Status),
-			"created_at":         proof.CreatedAt,
-			"anchored_at":        proof.AnchoredAt,
-			"verified_at":        proof.VerifiedAt,
-			"anchor_chain":       stringPtrOrEmpty(proof.AnchorChain),
-			"anchor_tx_hash":     stringPtrOrEmpty(proof.AnchorTxHash),
-			"anchor_block_number": int64PtrOrZero(proof.AnchorBlockNumber),
-		}
-
+	for i := range proofs {
+		var attestations []database.ProofAttestation
 		if job.Request.IncludeAttestations {
-			attestations, _ := h.repos.ProofArtifacts.GetProofAttestationsByProof(ctx, proof.ProofID)
-			record["attestations"] = attestations
+			attestations, _ = h.repos.ProofArtifacts.GetProofAttestationsByProof(ctx, proofs[i].ProofID)
 		}
 
-		encoder.Encode(record)
+		encoder.Encode(proofarchive.NewRecord(&proofs[i], attestations, job.Request.IncludeArtifacts))
 	}
 }
 
@@ -868,6 +954,9 @@ func (h *BulkHandlers) getTimeWindowStats(ctx context.Context, start, end time.T
 }
 
 func (h *BulkHandlers) validateAPIKey(r *http.Request) (*database.APIKey, error) {
+	if principal := PrincipalFromContext(r.Context()); principal != nil {
+		return principal.APIKey, nil
+	}
 	apiKey := r.Header.Get("X-API-Key")
 	if apiKey == "" {
 		apiKey = r.URL.Query().Get("api_key")
@@ -0,0 +1,96 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for Bulk Handlers
+// Tests export formatting and import validation without requiring database connection
+
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/proofarchive"
+)
+
+// ============================================================================
+// Bulk Import Tests
+// ============================================================================
+
+func TestHandleBulkImport_MethodNotAllowed(t *testing.T) {
+	handlers := NewBulkHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/bulk/import", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleBulkImport(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestHandleBulkImport_MissingAPIKey(t *testing.T) {
+	handlers := NewBulkHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/proofs/bulk/import", strings.NewReader("{}\n"))
+	w := httptest.NewRecorder()
+	handlers.HandleBulkImport(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// JSON Lines Export Tests
+// ============================================================================
+
+func TestWriteJSONLinesExport_ImportableWithArtifacts(t *testing.T) {
+	handlers := NewBulkHandlers(nil, nil, nil)
+
+	// HTML-sensitive characters must survive so artifact_hash still matches
+	artifact := json.RawMessage(`{"memo":"<a&b>"}`)
+	sum := sha256.Sum256(artifact)
+	proof := database.ProofArtifact{
+		ProofID:      uuid.New(),
+		ProofType:    database.ProofTypeChained,
+		ProofVersion: "1.0",
+		AccumTxHash:  "abc123",
+		AccountURL:   "acc://export.acme",
+		ProofClass:   database.ProofClassOnCadence,
+		ValidatorID:  "validator-1",
+		Status:       database.ProofStatusPending,
+		CreatedAt:    time.Now().UTC(),
+		ArtifactJSON: artifact,
+		ArtifactHash: sum[:],
+	}
+
+	for _, include := range []bool{false, true} {
+		var buf bytes.Buffer
+		job := &ExportJob{Request: &BulkExportRequest{IncludeArtifacts: include}}
+		handlers.writeJSONLinesExport(&buf, []database.ProofArtifact{proof}, job)
+
+		var rec proofarchive.Record
+		if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+			t.Fatalf("Invalid export line: %v", err)
+		}
+		if rec.ProofID != proof.ProofID || rec.AccumTxHash != proof.AccumTxHash {
+			t.Errorf("Export summary mismatch: %+v", rec)
+		}
+		err := rec.Verify()
+		if include && err != nil {
+			t.Errorf("Expected export with artifacts to verify, got %v", err)
+		}
+		if !include && err != proofarchive.ErrNoArtifact {
+			t.Errorf("Expected ErrNoArtifact without artifacts, got %v", err)
+		}
+	}
+}
@@ -8,6 +8,11 @@
 // - GET /api/v1/proofs/{proof_id}/bundle - Download proof bundle
 // - GET /api/v1/proofs/{proof_id}/bundle/verify - Verify bundle integrity
 // - GET /api/v1/proofs/{proof_id}/custody - Get custody chain
+// - GET /.well-known/certen-bundle-keys - Public keys that sign certen_v2 bundles
+// - GET /api/v1/batches/{batch_id}/bundle - Download batch audit bundle
+// - GET /api/v1/batches/{batch_id}/bundle/verify - Verify batch audit bundle
+// - GET /api/v1/intents/{intent_id}/bundle - Download multi-leg intent bundle
+// - POST /api/v1/bundles/verify - Verify an uploaded bundle against our records
 // - POST /api/v1/proofs/verify/merkle - Verify merkle proof
 // - POST /api/v1/proofs/verify/governance - Verify governance proof
 
@@ -20,6 +25,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -31,6 +37,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/proofbundle"
 )
 
 // BundleHandlers provides HTTP handlers for bundle operations
@@ -38,16 +45,27 @@ type BundleHandlers struct {
 	repos           *database.Repositories
 	validatorID     string
 	logger          *log.Logger
-	rateLimiter     *RateLimiter
 	apiKeyValidator *APIKeyValidator
+	queueEstimator  QueueEstimator
+	builder         *proofbundle.Builder
+	keys            *proofbundle.KeySet
+	maxBundleSize   int64
+}
+
+// QueueEstimator estimates how long a newly queued proof request will wait
+type QueueEstimator interface {
+	EstimateWait(ctx context.Context, requestType database.RequestType) (time.Duration, error)
 }
 
 // BundleHandlersConfig contains configuration for bundle handlers
 type BundleHandlersConfig struct {
 	ValidatorID            string
-	RateLimitPerMinute     int
 	MaxBundleSizeBytes     int64
 	EnableAPIKeyValidation bool
+	QueueEstimator         QueueEstimator
+	BundleTTL              time.Duration       // 0 means built bundles do not expire
+	Signer                 *proofbundle.Signer // Signs certen_v2 bundles; nil builds certen_v1
+	KeySet                 *proofbundle.KeySet // Keys certen_v2 bundles verify against
 }
 
 // NewBundleHandlers creates new bundle handlers
@@ -62,17 +80,31 @@ func NewBundleHandlers(
 	if config == nil {
 		config = &BundleHandlersConfig{
 			ValidatorID:        "default-validator",
-			RateLimitPerMinute: 100,
 			MaxBundleSizeBytes: 10 * 1024 * 1024, // 10MB
 		}
 	}
 
+	keys := config.KeySet
+	if keys == nil {
+		keys = proofbundle.NewKeySet(config.Signer)
+	}
+	maxBundleSize := config.MaxBundleSizeBytes
+	if maxBundleSize <= 0 {
+		maxBundleSize = 10 * 1024 * 1024
+	}
+
 	return &BundleHandlers{
 		repos:           repos,
 		validatorID:     config.ValidatorID,
 		logger:          logger,
-		rateLimiter:     NewRateLimiter(config.RateLimitPerMinute),
 		apiKeyValidator: NewAPIKeyValidator(repos),
+		queueEstimator:  config.QueueEstimator,
+		builder: proofbundle.NewBuilder(repos, &proofbundle.BuilderConfig{
+			TTL:    config.BundleTTL,
+			Signer: config.Signer,
+		}, logger),
+		keys:          keys,
+		maxBundleSize: maxBundleSize,
 	}
 }
 
@@ -113,12 +145,6 @@ func (h *BundleHandlers) HandleRequestProof(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Check rate limit
-	if apiKey != nil && !h.rateLimiter.Allow(apiKey.ClientName) {
-		h.writeError(w, http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded")
-		return
-	}
-
 	// Check permissions
 	if apiKey != nil && !apiKey.CanRequestProofs {
 		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "API key does not have proof request permission")
@@ -167,7 +193,7 @@ func (h *BundleHandlers) HandleRequestProof(w http.ResponseWriter, r *http.Reque
 
 	// Create proof request
 	var apiKeyID *uuid.UUID
-	if apiKey != nil {
+	if apiKey != nil && apiKey.KeyID != uuid.Nil {
 		apiKeyID = &apiKey.KeyID
 	}
 
@@ -188,12 +214,15 @@ func (h *BundleHandlers) HandleRequestProof(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Estimate time based on proof class
+	// Estimate time from the current queue depth (omitted if no estimator is available)
 	var estimatedTimeMs int64
-	if input.ProofClass == "on_demand" {
-		estimatedTimeMs = 5000 // 5 seconds for on-demand
-	} else {
-		estimatedTimeMs = 900000 // 15 minutes for on-cadence
+	if h.queueEstimator != nil {
+		wait, err := h.queueEstimator.EstimateWait(ctx, database.RequestType(input.ProofClass))
+		if err != nil {
+			h.logger.Printf("Error estimating queue wait: %v", err)
+		} else {
+			estimatedTimeMs = wait.Milliseconds()
+		}
 	}
 
 	h.writeJSON(w, http.StatusAccepted, ProofRequestResponse{
@@ -268,25 +297,30 @@ func (h *BundleHandlers) HandleDownloadBundle(w http.ResponseWriter, r *http.Req
 
 	ctx := r.Context()
 
-	// Get bundle from database
-	bundle, err := h.repos.ProofArtifacts.GetBundleByProofID(ctx, proofID)
-	if err != nil {
-		h.logger.Printf("Error getting bundle: %v", err)
-		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve bundle")
+	// Get the current bundle, building it if missing or stale
+	bundle, ok := h.currentBundle(w, r, proofID)
+	if !ok {
 		return
 	}
 
-	if bundle == nil {
-		h.writeError(w, http.StatusNotFound, "BUNDLE_NOT_FOUND", fmt.Sprintf("No bundle found for proof: %s", proofID))
+	// Redeem the signed URL, if the request came in with one
+	signedURLID, err := redeemSignedURL(r, h.repos)
+	if errors.Is(err, database.ErrSignedURLNotRedeemable) {
+		h.writeError(w, http.StatusGone, "URL_REDEEMED", "Signed URL has expired or has already been used")
+		return
+	}
+	if err != nil {
+		h.logger.Printf("Error redeeming signed URL: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to redeem signed URL")
 		return
 	}
 
 	// Record download
 	var apiKeyID *uuid.UUID
-	if apiKey != nil {
+	if apiKey != nil && apiKey.KeyID != uuid.Nil {
 		apiKeyID = &apiKey.KeyID
 	}
-	h.recordBundleDownload(ctx, bundle.BundleID, apiKeyID, clientIP, r.UserAgent(), http.StatusOK, len(bundle.BundleData))
+	h.recordBundleDownload(ctx, bundle.BundleID, apiKeyID, signedURLID, clientIP, r.UserAgent(), http.StatusOK, len(bundle.BundleData))
 
 	// Check if client wants decompressed JSON
 	acceptEncoding := r.Header.Get("Accept-Encoding")
@@ -330,6 +364,229 @@ func (h *BundleHandlers) HandleDownloadBundle(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// currentBundle returns the proof's current bundle, writing the error response on failure
+func (h *BundleHandlers) currentBundle(w http.ResponseWriter, r *http.Request, proofID uuid.UUID) (*database.ProofBundle, bool) {
+	bundle, err := h.builder.GetOrBuild(r.Context(), proofID)
+	if errors.Is(err, database.ErrProofNotFound) {
+		h.writeError(w, http.StatusNotFound, "PROOF_NOT_FOUND", fmt.Sprintf("No proof found with ID: %s", proofID))
+		return nil, false
+	}
+	if err != nil {
+		h.logger.Printf("Error building bundle for proof %s: %v", proofID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve bundle")
+		return nil, false
+	}
+	return bundle, true
+}
+
+// =============================================================================
+// BATCH BUNDLE ENDPOINTS
+// =============================================================================
+
+// HandleDownloadBatchBundle handles GET /api/v1/batches/{batch_id}/bundle
+func (h *BundleHandlers) HandleDownloadBatchBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	// Extract batch ID from path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/batches/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "bundle" {
+		h.writeError(w, http.StatusBadRequest, "INVALID_PATH", "Invalid endpoint path")
+		return
+	}
+
+	batchID, err := uuid.Parse(parts[0])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_BATCH_ID", "Invalid batch ID format")
+		return
+	}
+
+	batchBundle, ok := h.buildBatchBundle(w, r, batchID)
+	if !ok {
+		return
+	}
+
+	jsonData, err := batchBundle.Marshal()
+	if err != nil {
+		h.logger.Printf("Error serializing batch bundle: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to serialize batch bundle")
+		return
+	}
+
+	attestationCount := len(batchBundle.ValidatorAttestations) + len(batchBundle.BatchAttestations)
+	w.Header().Set("X-Bundle-ID", batchBundle.BundleID.String())
+	w.Header().Set("X-Bundle-Hash", "sha256:"+hex.EncodeToString(proofbundle.Hash(jsonData)))
+	w.Header().Set("X-Bundle-Format", proofbundle.BatchBundleFormat)
+	w.Header().Set("X-Bundle-Version", proofbundle.BatchBundleVersion)
+	w.Header().Set("X-Manifest-Hash", "sha256:"+batchBundle.Manifest.ManifestHash)
+	w.Header().Set("X-Attestation-Count", fmt.Sprintf("%d", attestationCount))
+
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		compressed, err := proofbundle.Compress(jsonData)
+		if err != nil {
+			h.logger.Printf("Error compressing batch bundle: %v", err)
+			h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to compress batch bundle")
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"batch_%s.bundle.gz\"", batchID.String()))
+		w.WriteHeader(http.StatusOK)
+		w.Write(compressed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"batch_%s.bundle.json\"", batchID.String()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
+
+// HandleVerifyBatchBundle handles GET /api/v1/batches/{batch_id}/bundle/verify
+// It builds the batch bundle from stored records and verifies it
+func (h *BundleHandlers) HandleVerifyBatchBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	// Extract batch ID from path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/batches/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 || parts[1] != "bundle" || parts[2] != "verify" {
+		h.writeError(w, http.StatusBadRequest, "INVALID_PATH", "Invalid endpoint path")
+		return
+	}
+
+	batchID, err := uuid.Parse(parts[0])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_BATCH_ID", "Invalid batch ID format")
+		return
+	}
+
+	batchBundle, ok := h.buildBatchBundle(w, r, batchID)
+	if !ok {
+		return
+	}
+
+	jsonData, err := batchBundle.Marshal()
+	if err != nil {
+		h.logger.Printf("Error serializing batch bundle: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to serialize batch bundle")
+		return
+	}
+
+	result, err := proofbundle.VerifyBatchBundle(jsonData)
+	if err != nil {
+		h.logger.Printf("Error verifying batch bundle: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "VERIFICATION_ERROR", "Failed to verify batch bundle")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"batch_id":     batchID,
+		"verification": result,
+		"verified_at":  time.Now().UTC(),
+	})
+}
+
+// buildBatchBundle loads a batch and assembles its bundle, writing the error response on failure
+func (h *BundleHandlers) buildBatchBundle(w http.ResponseWriter, r *http.Request, batchID uuid.UUID) (*proofbundle.BatchBundle, bool) {
+	input, err := proofbundle.LoadBatchBundleInput(r.Context(), h.repos, batchID)
+	if errors.Is(err, database.ErrBatchNotFound) {
+		h.writeError(w, http.StatusNotFound, "BATCH_NOT_FOUND", fmt.Sprintf("No batch found with ID: %s", batchID))
+		return nil, false
+	}
+	if err != nil {
+		h.logger.Printf("Error loading batch %s: %v", batchID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve batch")
+		return nil, false
+	}
+
+	batchBundle, err := proofbundle.NewBatchBundle(input)
+	if errors.Is(err, proofbundle.ErrBatchOpen) {
+		h.writeError(w, http.StatusConflict, "BATCH_OPEN", "Batch is still open; its Merkle root is not final")
+		return nil, false
+	}
+	if err != nil {
+		h.logger.Printf("Error building batch bundle %s: %v", batchID, err)
+		h.writeError(w, http.StatusUnprocessableEntity, "BUNDLE_BUILD_FAILED", fmt.Sprintf("Cannot build batch bundle: %v", err))
+		return nil, false
+	}
+
+	return batchBundle, true
+}
+
+// =============================================================================
+// INTENT BUNDLE ENDPOINTS
+// =============================================================================
+
+// HandleDownloadIntentBundle handles GET /api/v1/intents/{intent_id}/bundle
+func (h *BundleHandlers) HandleDownloadIntentBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	// Extract intent ID from path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/intents/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[1] != "bundle" || parts[0] == "" {
+		h.writeError(w, http.StatusBadRequest, "INVALID_PATH", "Invalid endpoint path")
+		return
+	}
+	intentID := parts[0]
+
+	intentBundle, err := h.builder.BuildIntentBundle(r.Context(), intentID)
+	if errors.Is(err, database.ErrIntentNotFound) {
+		h.writeError(w, http.StatusNotFound, "INTENT_NOT_FOUND", fmt.Sprintf("No intent found with ID: %s", intentID))
+		return
+	}
+	if err != nil {
+		h.logger.Printf("Error building intent bundle %s: %v", intentID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to build intent bundle")
+		return
+	}
+
+	jsonData, err := intentBundle.Marshal()
+	if err != nil {
+		h.logger.Printf("Error serializing intent bundle: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to serialize intent bundle")
+		return
+	}
+
+	w.Header().Set("X-Bundle-ID", intentBundle.BundleID.String())
+	w.Header().Set("X-Bundle-Hash", "sha256:"+hex.EncodeToString(proofbundle.Hash(jsonData)))
+	w.Header().Set("X-Bundle-Format", proofbundle.IntentBundleFormat)
+	w.Header().Set("X-Bundle-Version", proofbundle.IntentBundleVersion)
+	w.Header().Set("X-Manifest-Hash", "sha256:"+intentBundle.Manifest.ManifestHash)
+	w.Header().Set("X-Leg-Count", fmt.Sprintf("%d", len(intentBundle.Legs)))
+	w.Header().Set("X-Attestation-Count", fmt.Sprintf("%d", intentBundle.AttestationCount()))
+
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		compressed, err := proofbundle.Compress(jsonData)
+		if err != nil {
+			h.logger.Printf("Error compressing intent bundle: %v", err)
+			h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to compress intent bundle")
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"intent_%s.bundle.gz\"", intentID))
+		w.WriteHeader(http.StatusOK)
+		w.Write(compressed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"intent_%s.bundle.json\"", intentID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
+
 // =============================================================================
 // BUNDLE VERIFICATION ENDPOINTS
 // =============================================================================
@@ -342,6 +599,9 @@ type BundleVerificationResponse struct {
 	Attestations BundleAttestationStatus `json:"attestations"`
 	VerifiedAt   time.Time               `json:"verified_at"`
 	Details      map[string]interface{}  `json:"details,omitempty"`
+
+	// Service signature check, certen_v2 bundles only
+	Signature *proofbundle.SignatureVerification `json:"signature,omitempty"`
 }
 
 // BundleAttestationStatus represents attestation verification status
@@ -375,10 +635,9 @@ func (h *BundleHandlers) HandleVerifyBundle(w http.ResponseWriter, r *http.Reque
 
 	ctx := r.Context()
 
-	// Get bundle
-	bundle, err := h.repos.ProofArtifacts.GetBundleByProofID(ctx, proofID)
-	if err != nil || bundle == nil {
-		h.writeError(w, http.StatusNotFound, "BUNDLE_NOT_FOUND", fmt.Sprintf("No bundle found for proof: %s", proofID))
+	// Get the current bundle, building it if missing or stale
+	bundle, ok := h.currentBundle(w, r, proofID)
+	if !ok {
 		return
 	}
 
@@ -396,21 +655,8 @@ func (h *BundleHandlers) HandleVerifyBundle(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	computedHash := sha256.Sum256(jsonData)
-	hashValid := bytes.Equal(computedHash[:], bundle.BundleHash)
-
-	// Handle legacy hash mismatch: older bundles stored hash of pretty-printed
-	// JSON while bundle_data contains compact JSON. If compact hash doesn't match,
-	// try re-hashing with indented format as a fallback.
-	if !hashValid {
-		var prettyBuf bytes.Buffer
-		if json.Indent(&prettyBuf, jsonData, "", "  ") == nil {
-			prettyHash := sha256.Sum256(prettyBuf.Bytes())
-			if bytes.Equal(prettyHash[:], bundle.BundleHash) {
-				hashValid = true
-			}
-		}
-	}
+	// Accepts the legacy pretty-printed hash as well as the compact one
+	hashValid := proofbundle.HashMatches(jsonData, bundle.BundleHash)
 
 	// Parse bundle to verify components
 	var bundleContent map[string]interface{}
@@ -434,11 +680,7 @@ func (h *BundleHandlers) HandleVerifyBundle(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Dynamic quorum: require majority of attestors (at least 1)
-	totalAttestations := len(attestations)
-	requiredQuorum := totalAttestations/2 + 1
-	if requiredQuorum < 1 {
-		requiredQuorum = 1
-	}
+	requiredQuorum := proofbundle.RequiredQuorum(len(attestations))
 	quorumMet := validCount >= requiredQuorum
 
 	// Count how many components are present (not all are required)
@@ -452,6 +694,16 @@ func (h *BundleHandlers) HandleVerifyBundle(w http.ResponseWriter, r *http.Reque
 
 	bundleValid := hashValid && hasComponents && quorumMet
 
+	// certen_v2 bundles must also carry a valid service signature
+	var signature *proofbundle.SignatureVerification
+	if bundle.BundleFormat == proofbundle.SignedBundleFormat {
+		signature, err = h.keys.VerifySignature(jsonData)
+		if err != nil {
+			signature = &proofbundle.SignatureVerification{Error: err.Error()}
+		}
+		bundleValid = bundleValid && signature.Valid
+	}
+
 	response := BundleVerificationResponse{
 		BundleValid: bundleValid,
 		HashValid:   hashValid,
@@ -469,11 +721,136 @@ func (h *BundleHandlers) HandleVerifyBundle(w http.ResponseWriter, r *http.Reque
 			"bundle_version": bundle.BundleVersion,
 			"bundle_size":    bundle.BundleSizeBytes,
 		},
+		Signature: signature,
 	}
 
 	h.writeJSON(w, http.StatusOK, response)
 }
 
+// UploadedBundleVerificationResponse is the result of verifying an uploaded bundle
+type UploadedBundleVerificationResponse struct {
+	BundleValid  bool                      `json:"bundle_valid"`
+	BundleFormat string                    `json:"bundle_format"`
+	BundleHash   string                    `json:"bundle_hash"`
+	Verification *proofbundle.Verification `json:"verification"`
+	StoredRecord *StoredRecordComparison   `json:"stored_record,omitempty"`
+	VerifiedAt   time.Time                 `json:"verified_at"`
+}
+
+// StoredRecordComparison compares an uploaded bundle with our current records
+type StoredRecordComparison struct {
+	Found       bool                          `json:"found"`
+	Matches     bool                          `json:"matches"`
+	Differences []proofbundle.FieldDifference `json:"differences,omitempty"`
+	Error       string                        `json:"error,omitempty"`
+}
+
+// HandleVerifyUploadedBundle handles POST /api/v1/bundles/verify
+// The body is a bundle of any format, gzipped or plain JSON. Nothing is stored.
+func (h *BundleHandlers) HandleVerifyUploadedBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBundleSize+1))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_BUNDLE", "Failed to read request body")
+		return
+	}
+	if int64(len(body)) > h.maxBundleSize {
+		h.writeError(w, http.StatusRequestEntityTooLarge, "BUNDLE_TOO_LARGE",
+			fmt.Sprintf("Bundle exceeds %d bytes", h.maxBundleSize))
+		return
+	}
+
+	jsonData, err := proofbundle.ReadBundle(body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_BUNDLE", err.Error())
+		return
+	}
+
+	verification, err := proofbundle.Verify(jsonData, h.keys)
+	if errors.Is(err, proofbundle.ErrUnsupportedFormat) {
+		h.writeError(w, http.StatusBadRequest, "UNSUPPORTED_FORMAT", err.Error())
+		return
+	}
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_BUNDLE", err.Error())
+		return
+	}
+
+	response := UploadedBundleVerificationResponse{
+		BundleValid:  verification.BundleValid,
+		BundleFormat: verification.BundleFormat,
+		BundleHash:   "sha256:" + hex.EncodeToString(proofbundle.Hash(jsonData)),
+		Verification: verification,
+		VerifiedAt:   time.Now().UTC(),
+	}
+	if h.repos != nil {
+		apiKey, _ := h.validateAPIKey(r)
+		if canCompareWithStored(apiKey, verification) {
+			response.StoredRecord = h.compareWithStored(r.Context(), jsonData)
+		}
+	}
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// canCompareWithStored reports whether an upload may be compared with our
+// records. The differences list stored values, so the comparison needs
+// can_read_proofs and a bundle that verified on its own.
+func canCompareWithStored(apiKey *database.APIKey, verification *proofbundle.Verification) bool {
+	if apiKey == nil || !apiKey.CanReadProofs {
+		return false
+	}
+	return verification.BundleValid
+}
+
+// compareWithStored diffs an uploaded bundle against the bundle our records
+// currently produce for it
+func (h *BundleHandlers) compareWithStored(ctx context.Context, jsonData []byte) *StoredRecordComparison {
+	expected, err := h.builder.Expected(ctx, jsonData)
+	switch {
+	case errors.Is(err, database.ErrProofNotFound),
+		errors.Is(err, database.ErrBatchNotFound),
+		errors.Is(err, database.ErrIntentNotFound):
+		return &StoredRecordComparison{Found: false}
+	case errors.Is(err, proofbundle.ErrBatchOpen):
+		return &StoredRecordComparison{Found: true, Error: "batch is still open"}
+	case err != nil:
+		h.logger.Printf("Error assembling stored bundle for comparison: %v", err)
+		return &StoredRecordComparison{Found: true, Error: "failed to load stored record"}
+	}
+
+	diffs, err := proofbundle.Diff(jsonData, expected)
+	if err != nil {
+		h.logger.Printf("Error comparing uploaded bundle: %v", err)
+		return &StoredRecordComparison{Found: true, Error: "failed to compare with stored record"}
+	}
+	return &StoredRecordComparison{
+		Found:       true,
+		Matches:     len(diffs) == 0,
+		Differences: diffs,
+	}
+}
+
+// BundleKeysResponse lists the service's bundle signing keys
+type BundleKeysResponse struct {
+	Keys []proofbundle.PublicKeyJWK `json:"keys"`
+}
+
+// HandleGetBundleKeys handles GET /.well-known/certen-bundle-keys
+func (h *BundleHandlers) HandleGetBundleKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	h.writeJSON(w, http.StatusOK, BundleKeysResponse{Keys: h.keys.JWKs()})
+}
+
 // =============================================================================
 // CUSTODY CHAIN ENDPOINTS
 // =============================================================================
@@ -714,61 +1091,6 @@ func (h *BundleHandlers) HandleVerifyGovernance(w http.ResponseWriter, r *http.R
 	})
 }
 
-// =============================================================================
-// RATE LIMITER
-// =============================================================================
-
-// RateLimiter implements a simple token bucket rate limiter
-type RateLimiter struct {
-	buckets    map[string]*tokenBucket
-	mu         sync.RWMutex
-	ratePerMin int
-}
-
-type tokenBucket struct {
-	tokens    int
-	lastFill  time.Time
-	maxTokens int
-}
-
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(ratePerMinute int) *RateLimiter {
-	return &RateLimiter{
-		buckets:    make(map[string]*tokenBucket),
-		ratePerMin: ratePerMinute,
-	}
-}
-
-// Allow checks if a request is allowed for the given client
-func (rl *RateLimiter) Allow(clientID string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	bucket, ok := rl.buckets[clientID]
-	if !ok {
-		bucket = &tokenBucket{
-			tokens:    rl.ratePerMin,
-			lastFill:  time.Now(),
-			maxTokens: rl.ratePerMin,
-		}
-		rl.buckets[clientID] = bucket
-	}
-
-	// Refill tokens based on time elapsed
-	elapsed := time.Since(bucket.lastFill)
-	tokensToAdd := int(elapsed.Minutes() * float64(rl.ratePerMin))
-	if tokensToAdd > 0 {
-		bucket.tokens = minInt(bucket.tokens+tokensToAdd, bucket.maxTokens)
-		bucket.lastFill = time.Now()
-	}
-
-	if bucket.tokens > 0 {
-		bucket.tokens--
-		return true
-	}
-	return false
-}
-
 // =============================================================================
 // API KEY VALIDATOR
 // =============================================================================
@@ -776,16 +1098,22 @@ func (rl *RateLimiter) Allow(clientID string) bool {
 // APIKeyValidator validates API keys
 type APIKeyValidator struct {
 	repos    *database.Repositories
-	cache    map[string]*database.APIKey
+	cache    map[string]*cachedAPIKey
 	cacheMu  sync.RWMutex
 	cacheTTL time.Duration
 }
 
+// cachedAPIKey is a validated key and when it was looked up
+type cachedAPIKey struct {
+	key      *database.APIKey
+	cachedAt time.Time
+}
+
 // NewAPIKeyValidator creates a new API key validator
 func NewAPIKeyValidator(repos *database.Repositories) *APIKeyValidator {
 	return &APIKeyValidator{
 		repos:    repos,
-		cache:    make(map[string]*database.APIKey),
+		cache:    make(map[string]*cachedAPIKey),
 		cacheTTL: 5 * time.Minute,
 	}
 }
@@ -796,12 +1124,18 @@ func (v *APIKeyValidator) Validate(ctx context.Context, apiKeyHeader string) (*d
 		return nil, fmt.Errorf("API key is required")
 	}
 
-	// Check cache first
+	// Check cache first; expiry is rechecked since it can pass while cached
 	v.cacheMu.RLock()
 	cached, ok := v.cache[apiKeyHeader]
 	v.cacheMu.RUnlock()
-	if ok && cached != nil {
-		return cached, nil
+	if ok && time.Since(cached.cachedAt) < v.cacheTTL {
+		if cached.key.ExpiresAt == nil || cached.key.ExpiresAt.After(time.Now()) {
+			return cached.key, nil
+		}
+		v.cacheMu.Lock()
+		delete(v.cache, apiKeyHeader)
+		v.cacheMu.Unlock()
+		return nil, fmt.Errorf("API key has expired")
 	}
 
 	// Hash the API key for lookup
@@ -822,7 +1156,7 @@ func (v *APIKeyValidator) Validate(ctx context.Context, apiKeyHeader string) (*d
 
 	// Cache the valid key
 	v.cacheMu.Lock()
-	v.cache[apiKeyHeader] = keyRecord
+	v.cache[apiKeyHeader] = &cachedAPIKey{key: keyRecord, cachedAt: time.Now()}
 	v.cacheMu.Unlock()
 
 	// Update last used timestamp
@@ -831,11 +1165,25 @@ func (v *APIKeyValidator) Validate(ctx context.Context, apiKeyHeader string) (*d
 	return keyRecord, nil
 }
 
+// Forget drops a key from the cache so changes to it take effect on its next use
+func (v *APIKeyValidator) Forget(keyID uuid.UUID) {
+	v.cacheMu.Lock()
+	defer v.cacheMu.Unlock()
+	for plaintext, cached := range v.cache {
+		if cached.key.KeyID == keyID {
+			delete(v.cache, plaintext)
+		}
+	}
+}
+
 // =============================================================================
 // HELPER METHODS
 // =============================================================================
 
 func (h *BundleHandlers) validateAPIKey(r *http.Request) (*database.APIKey, error) {
+	if principal := PrincipalFromContext(r.Context()); principal != nil {
+		return principal.APIKey, nil
+	}
 	apiKey := r.Header.Get("X-API-Key")
 	if apiKey == "" {
 		apiKey = r.URL.Query().Get("api_key")
@@ -847,9 +1195,10 @@ func (h *BundleHandlers) validateAPIKey(r *http.Request) (*database.APIKey, erro
 	return h.apiKeyValidator.Validate(r.Context(), apiKey)
 }
 
-func (h *BundleHandlers) recordBundleDownload(ctx context.Context, bundleID uuid.UUID, apiKeyID *uuid.UUID, clientIP, userAgent string, responseCode, bytesSent int) {
+func (h *BundleHandlers) recordBundleDownload(ctx context.Context, bundleID uuid.UUID, apiKeyID, signedURLID *uuid.UUID, clientIP, userAgent string, responseCode, bytesSent int) {
 	download := &database.NewBundleDownload{
 		BundleID:     bundleID,
+		SignedURLID:  signedURLID,
 		APIKeyID:     apiKeyID,
 		ClientIP:     clientIP,
 		UserAgent:    &userAgent,
@@ -876,36 +1225,9 @@ func (h *BundleHandlers) writeError(w http.ResponseWriter, status int, code, mes
 	})
 }
 
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header first
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		parts := strings.Split(forwarded, ",")
-		return strings.TrimSpace(parts[0])
-	}
-	// Fall back to X-Real-IP
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
-	}
-	// Fall back to remote address
-	addr := r.RemoteAddr
-	if colonIdx := strings.LastIndex(addr, ":"); colonIdx != -1 {
-		return addr[:colonIdx]
-	}
-	return addr
-}
-
 func nilIfEmpty(s string) *string {
 	if s == "" {
 		return nil
 	}
 	return &s
 }
-
-func minInt(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,179 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for Bundle Handlers
+// Tests request validation and signing keys without requiring database connection
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/proofbundle"
+)
+
+// ============================================================================
+// Request Validation Tests
+// ============================================================================
+
+func TestHandleDownloadBundle_InvalidProofID(t *testing.T) {
+	handlers := NewBundleHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/not-a-uuid/bundle", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleDownloadBundle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleVerifyBatchBundle_InvalidBatchID(t *testing.T) {
+	handlers := NewBundleHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/batches/not-a-uuid/bundle/verify", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleVerifyBatchBundle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleDownloadIntentBundle_InvalidPath(t *testing.T) {
+	handlers := NewBundleHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/intents//bundle", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleDownloadIntentBundle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleVerifyUploadedBundle_InvalidBody(t *testing.T) {
+	handlers := NewBundleHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bundles/verify", strings.NewReader("not a bundle"))
+	w := httptest.NewRecorder()
+	handlers.HandleVerifyUploadedBundle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleVerifyUploadedBundle_UnsupportedFormat(t *testing.T) {
+	handlers := NewBundleHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bundles/verify", strings.NewReader(`{"bundle_format":"other"}`))
+	w := httptest.NewRecorder()
+	handlers.HandleVerifyUploadedBundle(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "UNSUPPORTED_FORMAT") {
+		t.Errorf("Expected UNSUPPORTED_FORMAT, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleVerifyUploadedBundle_TooLarge(t *testing.T) {
+	handlers := NewBundleHandlers(nil, &BundleHandlersConfig{MaxBundleSizeBytes: 16}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bundles/verify", strings.NewReader(strings.Repeat(" ", 17)))
+	w := httptest.NewRecorder()
+	handlers.HandleVerifyUploadedBundle(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+}
+
+func TestHandleVerifyUploadedBundle_Gzip(t *testing.T) {
+	handlers := NewBundleHandlers(nil, nil, nil)
+
+	b, err := proofbundle.NewIntentBundle(&proofbundle.IntentBundleInput{IntentID: "intent-1"})
+	if err != nil {
+		t.Fatalf("NewIntentBundle failed: %v", err)
+	}
+	data, _ := b.Marshal()
+	compressed, _ := proofbundle.Compress(data)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bundles/verify", bytes.NewReader(compressed))
+	w := httptest.NewRecorder()
+	handlers.HandleVerifyUploadedBundle(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp UploadedBundleVerificationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if !resp.BundleValid || resp.BundleFormat != proofbundle.IntentBundleFormat {
+		t.Errorf("Expected valid %s bundle, got %+v", proofbundle.IntentBundleFormat, resp)
+	}
+}
+
+func TestCanCompareWithStored(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey *database.APIKey
+		valid  bool
+		want   bool
+	}{
+		{"anonymous", nil, true, false},
+		{"without can_read_proofs", &database.APIKey{}, true, false},
+		{"invalid bundle", &database.APIKey{CanReadProofs: true}, false, false},
+		{"reader with valid bundle", &database.APIKey{CanReadProofs: true}, true, true},
+	}
+	for _, tt := range tests {
+		if got := canCompareWithStored(tt.apiKey, &proofbundle.Verification{BundleValid: tt.valid}); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+// ============================================================================
+// Signing Key Tests
+// ============================================================================
+
+func TestHandleGetBundleKeys(t *testing.T) {
+	signer, err := proofbundle.ParseSigningKey(strings.Repeat("01", 32))
+	if err != nil {
+		t.Fatalf("ParseSigningKey failed: %v", err)
+	}
+	handlers := NewBundleHandlers(nil, &BundleHandlersConfig{Signer: signer}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/certen-bundle-keys", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleGetBundleKeys(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var resp BundleKeysResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(resp.Keys) != 1 || resp.Keys[0].KeyID != signer.KeyID() {
+		t.Errorf("Expected key %s, got %+v", signer.KeyID(), resp.Keys)
+	}
+}
+
+func TestHandleGetBundleKeys_NoSigner(t *testing.T) {
+	handlers := NewBundleHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/certen-bundle-keys", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleGetBundleKeys(w, req)
+
+	if !strings.Contains(w.Body.String(), `"keys":[]`) {
+		t.Errorf("Expected empty key list, got %s", w.Body.String())
+	}
+}
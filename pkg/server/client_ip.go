@@ -0,0 +1,197 @@
+// Copyright 2025 Certen Protocol
+//
+// Client IP Middleware
+// Resolves the client IP of every request once, trusting only known proxies
+//
+// The client IP is the address of the connection's peer unless that peer is
+// a trusted proxy (TRUSTED_PROXIES). Then the forwarding chain in the one
+// header those proxies set (TRUSTED_PROXY_HEADER) is read from right to left:
+// each hop appended by a trusted proxy is accepted until the first address
+// that is not a trusted proxy, which is the client. Left of that point the
+// chain is written by the client and is ignored, so a caller cannot choose
+// its own IP by sending the header. Other forwarding headers are never read,
+// since the proxies pass them through from the client unchanged.
+//
+// The resolved IP is stored in the request context and used for download
+// logs, admin audit records and rate limiting.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrustedProxies parses trusted proxy CIDRs. A bare IP address is
+// trusted on its own.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// DefaultTrustedProxyHeader is the forwarding header read when none is configured
+const DefaultTrustedProxyHeader = "X-Forwarded-For"
+
+// ClientIPConfig contains configuration for the client IP middleware
+type ClientIPConfig struct {
+	TrustedProxies []netip.Prefix // Proxies whose forwarding header is believed
+	Header         string         // Header the proxies set; Forwarded is parsed per RFC 7239, others as address lists
+}
+
+// ClientIPMiddleware resolves the client IP of each request
+type ClientIPMiddleware struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+// NewClientIPMiddleware creates a new client IP middleware
+func NewClientIPMiddleware(config *ClientIPConfig) *ClientIPMiddleware {
+	if config == nil {
+		config = &ClientIPConfig{}
+	}
+	header := http.CanonicalHeaderKey(strings.TrimSpace(config.Header))
+	if header == "" {
+		header = DefaultTrustedProxyHeader
+	}
+	return &ClientIPMiddleware{trusted: config.TrustedProxies, header: header}
+}
+
+// Wrap returns next with the request's client IP resolved
+func (m *ClientIPMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithClientIP(r.Context(), m.resolve(r))))
+	})
+}
+
+type clientIPContextKey struct{}
+
+// WithClientIP returns a context carrying the request's client IP
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP resolved for a request
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPContextKey{}).(string)
+	return ip, ok
+}
+
+// getClientIP returns the resolved client IP of a request, or the
+// connection's peer address if the middleware did not run
+func getClientIP(r *http.Request) string {
+	if ip, ok := ClientIPFromContext(r.Context()); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func (m *ClientIPMiddleware) resolve(r *http.Request) string {
+	peer, err := netip.ParseAddr(remoteIP(r))
+	if err != nil {
+		return remoteIP(r)
+	}
+	peer = peer.Unmap()
+	if !m.isTrusted(peer) {
+		return peer.String()
+	}
+
+	values := r.Header.Values(m.header)
+	hops := splitHeaderList(values)
+	if m.header == "Forwarded" {
+		hops = forwardedFor(values)
+	}
+
+	// Walk back through the chain while the hop that reported it is trusted
+	client := peer
+	for i := len(hops) - 1; i >= 0 && m.isTrusted(client); i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			break
+		}
+		client = addr
+	}
+	return client.String()
+}
+
+func (m *ClientIPMiddleware) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range m.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the host part of the request's remote address
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// splitHeaderList splits comma-separated header values into trimmed items
+func splitHeaderList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+	return items
+}
+
+// forwardedFor returns the for= node of each element of RFC 7239 Forwarded
+// headers. Elements without one yield "" so the chain keeps its length.
+func forwardedFor(values []string) []string {
+	elements := splitHeaderList(values)
+	nodes := make([]string, len(elements))
+	for i, element := range elements {
+		for _, pair := range strings.Split(element, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(name), "for") {
+				nodes[i] = strings.Trim(strings.TrimSpace(value), `"`)
+				break
+			}
+		}
+	}
+	return nodes
+}
+
+// parseHop parses an address from a forwarding header, which may carry a
+// port and, for IPv6, brackets. Obfuscated and "unknown" nodes do not parse.
+func parseHop(hop string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(hop); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
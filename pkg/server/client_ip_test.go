@@ -0,0 +1,91 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the Client IP Middleware
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testClientIP(t *testing.T, header, remoteAddr string, headers map[string][]string) string {
+	t.Helper()
+
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies failed: %v", err)
+	}
+
+	var got string
+	handler := NewClientIPMiddleware(&ClientIPConfig{TrustedProxies: trusted, Header: header}).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = getClientIP(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/query", nil)
+	req.RemoteAddr = remoteAddr
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	return got
+}
+
+// ============================================================================
+// Resolution Tests
+// ============================================================================
+
+func TestClientIP_Resolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		remoteAddr string
+		headers    map[string][]string
+		want       string
+	}{
+		{"direct", "", "203.0.113.5:4000", nil, "203.0.113.5"},
+		{"untrusted peer spoofing", "", "203.0.113.5:4000", map[string][]string{"X-Forwarded-For": {"1.2.3.4"}}, "203.0.113.5"},
+		{"trusted proxy", "", "10.0.0.1:4000", map[string][]string{"X-Forwarded-For": {"198.51.100.7"}}, "198.51.100.7"},
+		{"spoofed left entry ignored", "", "10.0.0.1:4000", map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.7, 10.0.0.2"}}, "198.51.100.7"},
+		{"multiple header lines", "", "10.0.0.1:4000", map[string][]string{"X-Forwarded-For": {"1.2.3.4", "198.51.100.7"}}, "198.51.100.7"},
+		{"all hops trusted", "", "10.0.0.1:4000", map[string][]string{"X-Forwarded-For": {"192.168.1.10, 10.0.0.2"}}, "192.168.1.10"},
+		{"invalid hop", "", "10.0.0.1:4000", map[string][]string{"X-Forwarded-For": {"198.51.100.7, garbage"}}, "10.0.0.1"},
+		{"other headers ignored", "", "10.0.0.1:4000", map[string][]string{"Forwarded": {"for=1.2.3.4"}, "X-Real-IP": {"1.2.3.4"}}, "10.0.0.1"},
+		{"forwarded", "Forwarded", "10.0.0.1:4000", map[string][]string{"Forwarded": {`for=1.2.3.4, for=198.51.100.7;proto=https;by=10.0.0.1`}}, "198.51.100.7"},
+		{"forwarded ipv6 with port", "forwarded", "[2001:db8::1]:4000", map[string][]string{"Forwarded": {`For="[2001:db9::17]:4711"`}}, "2001:db9::17"},
+		{"forwarded ignores x-forwarded-for", "Forwarded", "10.0.0.1:4000", map[string][]string{"X-Forwarded-For": {"1.2.3.4"}}, "10.0.0.1"},
+		{"forwarded unknown", "Forwarded", "10.0.0.1:4000", map[string][]string{"Forwarded": {"for=unknown"}}, "10.0.0.1"},
+		{"real ip from trusted proxy", "X-Real-IP", "192.168.1.10:4000", map[string][]string{"X-Real-IP": {"198.51.100.7"}}, "198.51.100.7"},
+		{"real ip from untrusted peer", "X-Real-IP", "192.168.1.11:4000", map[string][]string{"X-Real-IP": {"198.51.100.7"}}, "192.168.1.11"},
+		{"ipv4-mapped peer", "", "[::ffff:10.0.0.1]:4000", map[string][]string{"X-Forwarded-For": {"198.51.100.7"}}, "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testClientIP(t, tt.header, tt.remoteAddr, tt.headers); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClientIP_WithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/query", nil)
+	req.RemoteAddr = "203.0.113.5:4000"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	if got := getClientIP(req); got != "203.0.113.5" {
+		t.Errorf("Expected the peer address, got %s", got)
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	for _, value := range []string{"10.0.0.0/33", "not-an-ip", "10.0.0"} {
+		if _, err := ParseTrustedProxies([]string{value}); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2025 Certen Protocol
+//
+// Idempotency Middleware
+// Replays stored responses for mutating requests retried with an Idempotency-Key
+//
+// A POST, PUT, PATCH or DELETE request with an Idempotency-Key header claims
+// the key for its caller before it runs. Keys are scoped to the principal
+// resolved by the authentication middleware, by API key ID, bearer token
+// subject or client certificate subject, so two clients cannot see each
+// other's responses; anonymous callers cannot send a key. A repeat of the
+// request with the same key gets the stored response with
+// Idempotent-Replayed: true instead of running again.
+//
+// A claim is held for a short lease that is renewed while the request runs,
+// so a claim left by a crashed instance frees up quickly. Only successful
+// (below 400) responses are stored, for the TTL. When the request fails the
+// claim is released, so the client can retry with the same key, with a
+// corrected body if needed.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// Idempotency headers
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// idempotencyStore is implemented by database.IdempotencyRepository
+type idempotencyStore interface {
+	ClaimKey(ctx context.Context, scope, key, method, path string, fingerprint []byte, now, expiresAt time.Time) (*database.IdempotencyRecord, bool, error)
+	ExtendKey(ctx context.Context, scope, key string, expiresAt time.Time) error
+	CompleteKey(ctx context.Context, scope, key string, status int, headers json.RawMessage, body []byte, expiresAt time.Time) error
+	ReleaseKey(ctx context.Context, scope, key string) error
+}
+
+// IdempotencyConfig contains configuration for the idempotency middleware
+type IdempotencyConfig struct {
+	TTL              time.Duration // How long a stored response is replayed
+	Lease            time.Duration // How long an unfinished request holds its key between renewals
+	MaxBodyBytes     int64         // Largest request body accepted with a key
+	MaxResponseBytes int           // Largest response stored; larger responses are not replayed
+}
+
+// IdempotencyMiddleware stores and replays responses for Idempotency-Key requests
+type IdempotencyMiddleware struct {
+	store  idempotencyStore
+	config *IdempotencyConfig
+	logger *log.Logger
+}
+
+// NewIdempotencyMiddleware creates a new idempotency middleware. Without
+// repositories requests pass through unchanged.
+func NewIdempotencyMiddleware(
+	repos *database.Repositories,
+	config *IdempotencyConfig,
+	logger *log.Logger,
+) *IdempotencyMiddleware {
+	var store idempotencyStore
+	if repos != nil {
+		store = repos.Idempotency
+	}
+	return newIdempotencyMiddleware(store, config, logger)
+}
+
+func newIdempotencyMiddleware(store idempotencyStore, config *IdempotencyConfig, logger *log.Logger) *IdempotencyMiddleware {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[Idempotency] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &IdempotencyConfig{}
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.Lease <= 0 {
+		config.Lease = time.Minute
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 10 << 20
+	}
+	if config.MaxResponseBytes <= 0 {
+		config.MaxResponseBytes = 1 << 20
+	}
+
+	return &IdempotencyMiddleware{
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Wrap returns next with idempotency key handling
+func (m *IdempotencyMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || m.store == nil || !isMutatingMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			m.writeError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY",
+				fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		scope, ok := idempotencyScope(r)
+		if !ok {
+			m.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Idempotency-Key requires an API key or bearer token")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, m.config.MaxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				m.writeError(w, http.StatusRequestEntityTooLarge, "IDEMPOTENCY_BODY_TOO_LARGE",
+					fmt.Sprintf("Requests with an Idempotency-Key are limited to %d bytes", m.config.MaxBodyBytes))
+				return
+			}
+			m.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		path := r.URL.RequestURI()
+		fingerprint := requestFingerprint(r.Method, path, body)
+		now := time.Now().UTC()
+
+		rec, claimed, err := m.store.ClaimKey(r.Context(), scope, key, r.Method, path, fingerprint, now, now.Add(m.config.Lease))
+		if err != nil {
+			m.logger.Printf("Error claiming idempotency key: %v", err)
+			m.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to check idempotency key")
+			return
+		}
+
+		if !claimed {
+			switch {
+			case !bytes.Equal(rec.Fingerprint, fingerprint):
+				m.writeError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+					"Idempotency-Key was already used for a different request")
+			case rec.ResponseStatus == nil:
+				w.Header().Set("Retry-After", "1")
+				m.writeError(w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS",
+					"A request with this Idempotency-Key is still in progress")
+			default:
+				m.replay(w, rec)
+			}
+			return
+		}
+
+		m.serveAndStore(w, r, next, scope, key)
+	})
+}
+
+// serveAndStore runs a request that claimed a key and stores its response,
+// or releases the claim if the response is not stored
+func (m *IdempotencyMiddleware) serveAndStore(w http.ResponseWriter, r *http.Request, next http.Handler, scope, key string) {
+	// Store even if the client goes away before the response is written
+	ctx := context.WithoutCancel(r.Context())
+
+	rec := &responseRecorder{ResponseWriter: w, limit: m.config.MaxResponseBytes}
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := m.store.ReleaseKey(ctx, scope, key); err != nil {
+			m.logger.Printf("Error releasing idempotency key: %v", err)
+		}
+	}()
+
+	stop := m.renewLease(ctx, scope, key)
+	next.ServeHTTP(rec, r)
+	stop()
+
+	status := rec.statusCode()
+	if status >= 400 || rec.overflow {
+		return
+	}
+
+	headers, err := json.Marshal(replayableHeaders(w.Header()))
+	if err != nil {
+		m.logger.Printf("Error encoding response headers: %v", err)
+		return
+	}
+	if err := m.store.CompleteKey(ctx, scope, key, status, headers, rec.body.Bytes(), time.Now().UTC().Add(m.config.TTL)); err != nil {
+		m.logger.Printf("Error storing idempotent response: %v", err)
+		return
+	}
+	completed = true
+}
+
+// renewLease extends a claim every half lease until the returned function is
+// called, which waits for any renewal in flight
+func (m *IdempotencyMiddleware) renewLease(ctx context.Context, scope, key string) func() {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(m.config.Lease / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := m.store.ExtendKey(ctx, scope, key, time.Now().UTC().Add(m.config.Lease)); err != nil {
+					m.logger.Printf("Error renewing idempotency key: %v", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+func (m *IdempotencyMiddleware) replay(w http.ResponseWriter, rec *database.IdempotencyRecord) {
+	var headers http.Header
+	if len(rec.ResponseHeaders) > 0 {
+		if err := json.Unmarshal(rec.ResponseHeaders, &headers); err != nil {
+			m.logger.Printf("Error decoding stored response headers: %v", err)
+		}
+	}
+	for name, values := range headers {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(*rec.ResponseStatus)
+	w.Write(rec.ResponseBody)
+}
+
+func (m *IdempotencyMiddleware) writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	}); err != nil {
+		m.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+// responseRecorder passes a response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	if !rr.overflow {
+		if rr.body.Len()+len(p) > rr.limit {
+			rr.overflow = true
+			rr.body.Reset()
+		} else {
+			rr.body.Write(p)
+		}
+	}
+	return rr.ResponseWriter.Write(p)
+}
+
+func (rr *responseRecorder) statusCode() int {
+	if rr.status == 0 {
+		return http.StatusOK
+	}
+	return rr.status
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyScope identifies the caller by its API key ID, bearer token
+// subject or client certificate subject. ok is false for anonymous callers.
+func idempotencyScope(r *http.Request) (scope string, ok bool) {
+	principal := PrincipalFromContext(r.Context())
+	switch {
+	case principal == nil:
+		return "", false
+	case principal.Type == PrincipalTypeToken:
+		return "jwt:" + principal.Claims.Subject, true
+	case principal.Type == PrincipalTypeCertificate:
+		return "cert:" + *principal.APIKey.ValidatorID, true
+	}
+	return "key:" + principal.APIKey.KeyID.String(), true
+}
+
+// requestFingerprint is the SHA-256 of the method, path with query, and body
+func requestFingerprint(method, path string, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// replayableHeaders drops headers that outer middleware sets per request:
+// CORS headers and the rate limiter's counters, which a replay would
+// otherwise roll back to the original request's values
+func replayableHeaders(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		canonical := http.CanonicalHeaderKey(name)
+		if strings.HasPrefix(canonical, "Access-Control-") || canonical == "Vary" ||
+			strings.HasPrefix(canonical, "Ratelimit-") || canonical == "Retry-After" {
+			continue
+		}
+		out[name] = values
+	}
+	return out
+}
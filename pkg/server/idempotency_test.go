@@ -0,0 +1,321 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the Idempotency Middleware
+// Uses an in-memory key store in place of the database
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/auth"
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// memoryIdempotencyStore is an in-memory idempotencyStore
+type memoryIdempotencyStore struct {
+	mu       sync.Mutex
+	keys     map[string]*database.IdempotencyRecord
+	extended int
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]*database.IdempotencyRecord)}
+}
+
+func (s *memoryIdempotencyStore) ClaimKey(ctx context.Context, scope, key, method, path string, fingerprint []byte, now, expiresAt time.Time) (*database.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.keys[scope+"/"+key]; ok && rec.ExpiresAt.After(now) {
+		return rec, false, nil
+	}
+	rec := &database.IdempotencyRecord{
+		Scope: scope, Key: key, Method: method, Path: path,
+		Fingerprint: fingerprint, CreatedAt: now, ExpiresAt: expiresAt,
+	}
+	s.keys[scope+"/"+key] = rec
+	return rec, true, nil
+}
+
+func (s *memoryIdempotencyStore) ExtendKey(ctx context.Context, scope, key string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.keys[scope+"/"+key]; ok && rec.ResponseStatus == nil {
+		rec.ExpiresAt = expiresAt
+		s.extended++
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) CompleteKey(ctx context.Context, scope, key string, status int, headers json.RawMessage, body []byte, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.keys[scope+"/"+key]
+	rec.ResponseStatus = &status
+	rec.ResponseHeaders = headers
+	rec.ResponseBody = append([]byte(nil), body...)
+	rec.ExpiresAt = expiresAt
+	return nil
+}
+
+func (s *memoryIdempotencyStore) ReleaseKey(ctx context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.keys[scope+"/"+key]; ok && rec.ResponseStatus == nil {
+		delete(s.keys, scope+"/"+key)
+	}
+	return nil
+}
+
+// testAPIKeys gives each test client a fixed API key ID
+var testAPIKeys = map[string]uuid.UUID{"client-a": uuid.New(), "client-b": uuid.New()}
+
+func newTestIdempotency(store idempotencyStore, config *IdempotencyConfig) *IdempotencyMiddleware {
+	return newIdempotencyMiddleware(store, config, nil)
+}
+
+// countingHandler creates a new job per call and fails on {"fail":true}
+func countingHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Fail bool `json:"fail"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		if body.Fail {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"bad"}`)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, `{"job":%d}`, *calls)
+	})
+}
+
+func doIdempotent(h http.Handler, method, key, apiKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/proofs/bulk/export", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if apiKey != "" {
+		req = req.WithContext(WithPrincipal(req.Context(), &Principal{
+			Type:   PrincipalTypeAPIKey,
+			APIKey: &database.APIKey{KeyID: testAPIKeys[apiKey], ClientName: apiKey},
+		}))
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// ============================================================================
+// Idempotency Tests
+// ============================================================================
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), nil).Wrap(countingHandler(&calls))
+
+	first := doIdempotent(h, http.MethodPost, "key-1", "client-a", `{"format":"csv"}`)
+	again := doIdempotent(h, http.MethodPost, "key-1", "client-a", `{"format":"csv"}`)
+
+	if calls != 1 {
+		t.Fatalf("Expected handler to run once, ran %d times", calls)
+	}
+	if again.Code != http.StatusAccepted || again.Body.String() != first.Body.String() {
+		t.Errorf("Expected replay of %d %s, got %d %s", first.Code, first.Body, again.Code, again.Body)
+	}
+	if again.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("Expected only the replay to be marked Idempotent-Replayed")
+	}
+	if again.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected stored Content-Type, got %q", again.Header().Get("Content-Type"))
+	}
+}
+
+func TestIdempotency_KeyReusedWithDifferentBody(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), nil).Wrap(countingHandler(&calls))
+
+	doIdempotent(h, http.MethodPost, "key-1", "client-a", `{"format":"csv"}`)
+	w := doIdempotent(h, http.MethodPost, "key-1", "client-a", `{"format":"json_lines"}`)
+
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+		t.Errorf("Expected 422 IDEMPOTENCY_KEY_REUSED, got %d %s", w.Code, w.Body)
+	}
+	if calls != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_ScopedPerCaller(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), nil).Wrap(countingHandler(&calls))
+
+	doIdempotent(h, http.MethodPost, "key-1", "client-a", `{}`)
+	w := doIdempotent(h, http.MethodPost, "key-1", "client-b", `{}`)
+
+	if calls != 2 || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("Expected a different caller's key to run the handler, calls=%d", calls)
+	}
+}
+
+func TestIdempotency_ScopedPerTokenSubject(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), nil).Wrap(countingHandler(&calls))
+
+	send := func(subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/proofs/request", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		req = req.WithContext(WithPrincipal(req.Context(), &Principal{
+			Type:   PrincipalTypeToken,
+			APIKey: &database.APIKey{ClientName: subject},
+			Claims: &auth.Claims{Subject: subject},
+		}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	send("user-1")
+	again := send("user-1")
+	send("user-2")
+
+	if calls != 2 || again.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected one run per token subject and a replay, calls=%d", calls)
+	}
+}
+
+func TestIdempotency_FailedResponseReleasesKey(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), nil).Wrap(countingHandler(&calls))
+
+	if w := doIdempotent(h, http.MethodPost, "key-1", "client-a", `{"fail":true}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d", w.Code)
+	}
+	// A corrected retry with the same key runs
+	if w := doIdempotent(h, http.MethodPost, "key-1", "client-a", `{}`); w.Code != http.StatusAccepted {
+		t.Errorf("Expected retry to run, got %d %s", w.Code, w.Body)
+	}
+	if calls != 2 {
+		t.Errorf("Expected handler to run twice, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	now := time.Now()
+	store.ClaimKey(context.Background(), "key:"+testAPIKeys["client-a"].String(), "key-1", http.MethodPost,
+		"/api/v1/proofs/bulk/export", requestFingerprint(http.MethodPost, "/api/v1/proofs/bulk/export", []byte(`{}`)),
+		now, now.Add(time.Minute))
+
+	calls := 0
+	w := doIdempotent(newTestIdempotency(store, nil).Wrap(countingHandler(&calls)), http.MethodPost, "key-1", "client-a", `{}`)
+
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 409 with Retry-After, got %d", w.Code)
+	}
+	if calls != 0 {
+		t.Errorf("Expected handler not to run, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_PassThrough(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), nil).Wrap(countingHandler(&calls))
+
+	doIdempotent(h, http.MethodPost, "", "", `{}`)
+	doIdempotent(h, http.MethodPost, "", "", `{}`)
+	doIdempotent(h, http.MethodGet, "key-1", "client-a", "")
+	doIdempotent(h, http.MethodGet, "key-1", "client-a", "")
+
+	if calls != 4 {
+		t.Errorf("Expected requests without a key and GETs to pass through, ran %d times", calls)
+	}
+
+	// Without a database the middleware does nothing
+	calls = 0
+	h = NewIdempotencyMiddleware(nil, nil, nil).Wrap(countingHandler(&calls))
+	doIdempotent(h, http.MethodPost, "key-1", "client-a", `{}`)
+	doIdempotent(h, http.MethodPost, "key-1", "client-a", `{}`)
+	if calls != 2 {
+		t.Errorf("Expected pass-through without repositories, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_InvalidRequests(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), &IdempotencyConfig{MaxBodyBytes: 8}).Wrap(countingHandler(&calls))
+
+	if w := doIdempotent(h, http.MethodPost, strings.Repeat("k", 256), "client-a", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an overlong key, got %d", w.Code)
+	}
+	if w := doIdempotent(h, http.MethodPost, "key-1", "client-a", `{"format":"csv"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized body, got %d", w.Code)
+	}
+	if calls != 0 {
+		t.Errorf("Expected handler not to run, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_RequiresPrincipal(t *testing.T) {
+	calls := 0
+	h := newTestIdempotency(newMemoryIdempotencyStore(), nil).Wrap(countingHandler(&calls))
+
+	if w := doIdempotent(h, http.MethodPost, "key-1", "", `{}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an anonymous caller, got %d", w.Code)
+	}
+	if calls != 0 {
+		t.Errorf("Expected handler not to run, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_LeaseRenewedWhileRunning(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusCreated)
+	})
+	h := newTestIdempotency(store, &IdempotencyConfig{TTL: time.Hour, Lease: 20 * time.Millisecond}).Wrap(slow)
+
+	start := time.Now()
+	if w := doIdempotent(h, http.MethodPost, "key-1", "client-a", `{}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", w.Code)
+	}
+	if store.extended == 0 {
+		t.Error("Expected the lease to be renewed while the request ran")
+	}
+	rec := store.keys["key:"+testAPIKeys["client-a"].String()+"/key-1"]
+	if rec == nil || rec.ExpiresAt.Before(start.Add(time.Hour)) {
+		t.Errorf("Expected the completed key to be kept for the TTL, got %+v", rec)
+	}
+}
+
+func TestReplayableHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("Location", "/api/v1/proofs/request/1")
+	h.Set("Access-Control-Allow-Origin", "*")
+	h.Set("Vary", "Origin")
+	h.Set(RateLimitLimitHeader, "60")
+	h.Set(RateLimitRemainingHeader, "59")
+	h.Set(RateLimitResetHeader, "30")
+	h.Set(RateLimitPolicyHeader, "60;w=60")
+	h.Set("Retry-After", "30")
+
+	out := replayableHeaders(h)
+	if len(out) != 2 || out.Get("Content-Type") == "" || out.Get("Location") == "" {
+		t.Errorf("Expected only Content-Type and Location to be stored, got %v", out)
+	}
+}
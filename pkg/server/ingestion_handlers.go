@@ -0,0 +1,910 @@
+// Copyright 2025 Certen Protocol
+//
+// Ingestion API Handlers
+// Accepts complete proofs and attestations from validators and stores them atomically
+//
+// Endpoints:
+// - POST /api/v1/proofs/ingest - Submit a proof with its layers, governance levels and anchor
+// - POST /api/v1/attestations - Submit an Ed25519 attestation for a proof or batch
+// - POST /api/v1/attestations/bls - Submit a BLS attestation for an external chain result
+// - GET /api/v1/attestations/bls/{result_id} - Consensus state for an external chain result
+//
+// Submissions require an API key with can_submit_proofs that was issued to a
+// validator. The artifact hash is checked before anything is written, and a
+// second submission for the same accum_tx_hash returns the stored proof.
+// Attestations must be signed by a key registered to the validator, and a
+// validator attests to each proof or batch once.
+//
+// BLS attestations are collected per external chain result until the
+// validator set's threshold weight is reached, then aggregated. Each signature
+// is pairing-verified against the validator's key and the result hash before
+// it counts toward the threshold, and the aggregate is verified before it is
+// stored.
+
+package server
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/bls"
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/proofbundle"
+)
+
+// IngestionHandlers provides HTTP handlers for validator proof submission
+type IngestionHandlers struct {
+	repos            *database.Repositories
+	logger           *log.Logger
+	apiKeyValidator  *APIKeyValidator
+	maxBodySize      int64
+	consensusTimeout time.Duration
+}
+
+// IngestionHandlersConfig contains configuration for ingestion handlers
+type IngestionHandlersConfig struct {
+	MaxBodySizeBytes int64
+	ConsensusTimeout time.Duration // Deadline for a result's BLS attestations to reach threshold
+}
+
+// NewIngestionHandlers creates new ingestion handlers
+func NewIngestionHandlers(
+	repos *database.Repositories,
+	config *IngestionHandlersConfig,
+	logger *log.Logger,
+) *IngestionHandlers {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[IngestAPI] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &IngestionHandlersConfig{}
+	}
+	maxBodySize := config.MaxBodySizeBytes
+	if maxBodySize <= 0 {
+		maxBodySize = 10 * 1024 * 1024
+	}
+	consensusTimeout := config.ConsensusTimeout
+	if consensusTimeout <= 0 {
+		consensusTimeout = 10 * time.Minute
+	}
+
+	return &IngestionHandlers{
+		repos:            repos,
+		logger:           logger,
+		apiKeyValidator:  NewAPIKeyValidator(repos),
+		maxBodySize:      maxBodySize,
+		consensusTimeout: consensusTimeout,
+	}
+}
+
+// =============================================================================
+// INGESTION TYPES
+// =============================================================================
+
+// ProofIngestionRequest is a complete proof submitted by a validator.
+// Binary fields are hex encoded.
+type ProofIngestionRequest struct {
+	ArtifactHash     string                       `json:"artifact_hash"` // SHA256 of artifact.artifact_json
+	Artifact         IngestArtifact               `json:"artifact"`
+	Layers           []IngestLayer                `json:"layers,omitempty"`
+	GovernanceLevels []IngestGovernanceLevel      `json:"governance_levels,omitempty"`
+	AnchorReference  *database.NewAnchorReference `json:"anchor_reference,omitempty"`
+}
+
+// IngestArtifact is the proof artifact of a submission
+type IngestArtifact struct {
+	ProofType    database.ProofType        `json:"proof_type"`
+	AccumTxHash  string                    `json:"accum_tx_hash"`
+	AccountURL   string                    `json:"account_url"`
+	BatchID      *uuid.UUID                `json:"batch_id,omitempty"`
+	MerkleRoot   string                    `json:"merkle_root,omitempty"`
+	LeafHash     string                    `json:"leaf_hash,omitempty"`
+	LeafIndex    *int                      `json:"leaf_index,omitempty"`
+	GovLevel     *database.GovernanceLevel `json:"gov_level,omitempty"`
+	ProofClass   database.ProofClass       `json:"proof_class"`
+	ValidatorID  string                    `json:"validator_id,omitempty"` // Defaults to the key's validator
+	ArtifactJSON json.RawMessage           `json:"artifact_json"`
+}
+
+// IngestLayer is one chained proof layer (L1/L2/L3) of a submission
+type IngestLayer struct {
+	LayerNumber        int             `json:"layer_number"`
+	LayerName          string          `json:"layer_name"`
+	BVNPartition       *string         `json:"bvn_partition,omitempty"`
+	ReceiptAnchor      string          `json:"receipt_anchor,omitempty"`
+	BVNRoot            string          `json:"bvn_root,omitempty"`
+	DNRoot             string          `json:"dn_root,omitempty"`
+	AnchorSequence     *int64          `json:"anchor_sequence,omitempty"`
+	BVNPartitionID     *string         `json:"bvn_partition_id,omitempty"`
+	DNBlockHash        string          `json:"dn_block_hash,omitempty"`
+	DNBlockHeight      *int64          `json:"dn_block_height,omitempty"`
+	ConsensusTimestamp *time.Time      `json:"consensus_timestamp,omitempty"`
+	LayerJSON          json.RawMessage `json:"layer_json"`
+}
+
+// IngestGovernanceLevel is one governance proof level (G0/G1/G2) of a submission
+type IngestGovernanceLevel struct {
+	GovLevel          database.GovernanceLevel `json:"gov_level"`
+	LevelName         string                   `json:"level_name"`
+	BlockHeight       *int64                   `json:"block_height,omitempty"`
+	FinalityTimestamp *time.Time               `json:"finality_timestamp,omitempty"`
+	AnchorHeight      *int64                   `json:"anchor_height,omitempty"`
+	IsAnchored        *bool                    `json:"is_anchored,omitempty"`
+	AuthorityURL      *string                  `json:"authority_url,omitempty"`
+	KeyPageCount      *int                     `json:"key_page_count,omitempty"`
+	ThresholdM        *int                     `json:"threshold_m,omitempty"`
+	ThresholdN        *int                     `json:"threshold_n,omitempty"`
+	SignatureCount    *int                     `json:"signature_count,omitempty"`
+	OutcomeType       *string                  `json:"outcome_type,omitempty"`
+	OutcomeHash       string                   `json:"outcome_hash,omitempty"`
+	BindingEnforced   *bool                    `json:"binding_enforced,omitempty"`
+	LevelJSON         json.RawMessage          `json:"level_json"`
+}
+
+// ProofIngestionResponse reports the stored proof
+type ProofIngestionResponse struct {
+	ProofID          uuid.UUID `json:"proof_id"`
+	AccumTxHash      string    `json:"accum_tx_hash"`
+	ArtifactHash     string    `json:"artifact_hash"`
+	Created          bool      `json:"created"` // false when the proof was already stored
+	LayerCount       int       `json:"layer_count"`
+	GovernanceLevels int       `json:"governance_levels"`
+	Anchored         bool      `json:"anchored"`
+	CustodyHash      string    `json:"custody_hash,omitempty"`
+	IngestedAt       time.Time `json:"ingested_at"`
+}
+
+// errArtifactHashMismatch is returned when artifact_hash is not the hash of artifact_json
+var errArtifactHashMismatch = errors.New("artifact_hash does not match SHA256 of artifact_json")
+
+// =============================================================================
+// INGESTION ENDPOINT
+// =============================================================================
+
+// HandleIngestProof handles POST /api/v1/proofs/ingest
+func (h *IngestionHandlers) HandleIngestProof(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+		return
+	}
+
+	apiKey, err := h.validateAPIKey(r)
+	if err != nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		return
+	}
+	if !apiKey.CanSubmitProofs || apiKey.ValidatorID == nil || *apiKey.ValidatorID == "" {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "API key does not have proof submission permission")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBodySize+1))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+		return
+	}
+	if int64(len(body)) > h.maxBodySize {
+		h.writeError(w, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE",
+			fmt.Sprintf("Request exceeds %d bytes", h.maxBodySize))
+		return
+	}
+
+	var req ProofIngestionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	validatorID := *apiKey.ValidatorID
+	if req.Artifact.ValidatorID == "" {
+		req.Artifact.ValidatorID = validatorID
+	}
+	if req.Artifact.ValidatorID != validatorID {
+		h.writeError(w, http.StatusForbidden, "VALIDATOR_MISMATCH",
+			fmt.Sprintf("API key is not authorized to submit proofs for validator %s", req.Artifact.ValidatorID))
+		return
+	}
+
+	sub, err := req.toSubmission()
+	if errors.Is(err, errArtifactHashMismatch) {
+		h.writeError(w, http.StatusUnprocessableEntity, "ARTIFACT_HASH_MISMATCH", err.Error())
+		return
+	}
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	result, created, err := h.repos.Ingestion.IngestProof(r.Context(), sub)
+	if err != nil {
+		h.logger.Printf("Error ingesting proof for %s from %s: %v", sub.Artifact.AccumTxHash, validatorID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to store proof")
+		return
+	}
+
+	response := ProofIngestionResponse{
+		ProofID:          result.Proof.ProofID,
+		AccumTxHash:      result.Proof.AccumTxHash,
+		ArtifactHash:     hex.EncodeToString(result.Proof.ArtifactHash),
+		Created:          created,
+		LayerCount:       len(result.Layers),
+		GovernanceLevels: len(result.GovernanceLevels),
+		Anchored:         result.AnchorReference != nil,
+		IngestedAt:       time.Now().UTC(),
+	}
+	if result.CustodyEvent != nil {
+		response.CustodyHash = hex.EncodeToString(result.CustodyEvent.CurrentHash)
+	}
+
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+		submitted := sha256.Sum256(sub.Artifact.ArtifactJSON)
+		if !bytes.Equal(result.Proof.ArtifactHash, submitted[:]) {
+			h.logger.Printf("Validator %s resubmitted %s with a different artifact (stored proof %s kept)",
+				validatorID, sub.Artifact.AccumTxHash, result.Proof.ProofID)
+		}
+	} else {
+		h.logger.Printf("Ingested proof %s for %s from validator %s", result.Proof.ProofID, sub.Artifact.AccumTxHash, validatorID)
+	}
+	h.writeJSON(w, status, response)
+}
+
+// toSubmission validates the request and decodes its hex fields
+func (req *ProofIngestionRequest) toSubmission() (*database.ProofSubmission, error) {
+	a := req.Artifact
+	if a.AccumTxHash == "" {
+		return nil, errors.New("artifact.accum_tx_hash is required")
+	}
+	if a.AccountURL == "" {
+		return nil, errors.New("artifact.account_url is required")
+	}
+	switch a.ProofType {
+	case database.ProofTypeCertenAnchor, database.ProofTypeChained, database.ProofTypeGovernance, database.ProofTypeMerkle:
+	default:
+		return nil, fmt.Errorf("artifact.proof_type %q is not supported", a.ProofType)
+	}
+	switch a.ProofClass {
+	case database.ProofClassOnCadence, database.ProofClassOnDemand:
+	default:
+		return nil, fmt.Errorf("artifact.proof_class %q is not supported", a.ProofClass)
+	}
+	if a.GovLevel != nil && !validGovLevel(*a.GovLevel) {
+		return nil, fmt.Errorf("artifact.gov_level %q is not supported", *a.GovLevel)
+	}
+	if len(a.ArtifactJSON) == 0 || !json.Valid(a.ArtifactJSON) {
+		return nil, errors.New("artifact.artifact_json must be a JSON document")
+	}
+
+	claimed, err := hex.DecodeString(req.ArtifactHash)
+	if err != nil || len(claimed) != sha256.Size {
+		return nil, errors.New("artifact_hash must be a hex SHA256 hash")
+	}
+	computed := sha256.Sum256(a.ArtifactJSON)
+	if !bytes.Equal(claimed, computed[:]) {
+		return nil, errArtifactHashMismatch
+	}
+
+	sub := &database.ProofSubmission{
+		Artifact: database.NewProofArtifact{
+			ProofType:    a.ProofType,
+			AccumTxHash:  a.AccumTxHash,
+			AccountURL:   a.AccountURL,
+			BatchID:      a.BatchID,
+			LeafIndex:    a.LeafIndex,
+			GovLevel:     a.GovLevel,
+			ProofClass:   a.ProofClass,
+			ValidatorID:  a.ValidatorID,
+			ArtifactJSON: a.ArtifactJSON,
+		},
+	}
+	if sub.Artifact.MerkleRoot, err = decodeHexField("artifact.merkle_root", a.MerkleRoot); err != nil {
+		return nil, err
+	}
+	if sub.Artifact.LeafHash, err = decodeHexField("artifact.leaf_hash", a.LeafHash); err != nil {
+		return nil, err
+	}
+
+	for i, l := range req.Layers {
+		if l.LayerNumber < 1 || l.LayerNumber > 3 {
+			return nil, fmt.Errorf("layers[%d].layer_number must be 1, 2 or 3", i)
+		}
+		layer := database.NewChainedProofLayer{
+			LayerNumber:        l.LayerNumber,
+			LayerName:          l.LayerName,
+			BVNPartition:       l.BVNPartition,
+			AnchorSequence:     l.AnchorSequence,
+			BVNPartitionID:     l.BVNPartitionID,
+			DNBlockHeight:      l.DNBlockHeight,
+			ConsensusTimestamp: l.ConsensusTimestamp,
+			LayerJSON:          l.LayerJSON,
+		}
+		if layer.ReceiptAnchor, err = decodeHexField(fmt.Sprintf("layers[%d].receipt_anchor", i), l.ReceiptAnchor); err != nil {
+			return nil, err
+		}
+		if layer.BVNRoot, err = decodeHexField(fmt.Sprintf("layers[%d].bvn_root", i), l.BVNRoot); err != nil {
+			return nil, err
+		}
+		if layer.DNRoot, err = decodeHexField(fmt.Sprintf("layers[%d].dn_root", i), l.DNRoot); err != nil {
+			return nil, err
+		}
+		if layer.DNBlockHash, err = decodeHexField(fmt.Sprintf("layers[%d].dn_block_hash", i), l.DNBlockHash); err != nil {
+			return nil, err
+		}
+		sub.Layers = append(sub.Layers, layer)
+	}
+
+	for i, g := range req.GovernanceLevels {
+		if !validGovLevel(g.GovLevel) {
+			return nil, fmt.Errorf("governance_levels[%d].gov_level %q is not supported", i, g.GovLevel)
+		}
+		level := database.NewGovernanceProofLevel{
+			GovLevel:          g.GovLevel,
+			LevelName:         g.LevelName,
+			BlockHeight:       g.BlockHeight,
+			FinalityTimestamp: g.FinalityTimestamp,
+			AnchorHeight:      g.AnchorHeight,
+			IsAnchored:        g.IsAnchored,
+			AuthorityURL:      g.AuthorityURL,
+			KeyPageCount:      g.KeyPageCount,
+			ThresholdM:        g.ThresholdM,
+			ThresholdN:        g.ThresholdN,
+			SignatureCount:    g.SignatureCount,
+			OutcomeType:       g.OutcomeType,
+			BindingEnforced:   g.BindingEnforced,
+			LevelJSON:         g.LevelJSON,
+		}
+		if level.OutcomeHash, err = decodeHexField(fmt.Sprintf("governance_levels[%d].outcome_hash", i), g.OutcomeHash); err != nil {
+			return nil, err
+		}
+		sub.GovernanceLevels = append(sub.GovernanceLevels, level)
+	}
+
+	if ref := req.AnchorReference; ref != nil {
+		if ref.TargetChain == "" || ref.ChainID == "" || ref.NetworkName == "" || ref.AnchorTxHash == "" {
+			return nil, errors.New("anchor_reference requires target_chain, chain_id, network_name and anchor_tx_hash")
+		}
+		sub.AnchorReference = ref
+	}
+
+	return sub, nil
+}
+
+func validGovLevel(level database.GovernanceLevel) bool {
+	switch level {
+	case database.GovLevelG0, database.GovLevelG1, database.GovLevelG2:
+		return true
+	}
+	return false
+}
+
+// decodeHexField decodes an optional hex field; empty decodes to nil
+func decodeHexField(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be hex encoded", name)
+	}
+	return b, nil
+}
+
+// =============================================================================
+// ATTESTATION ENDPOINT
+// =============================================================================
+
+// AttestationRequest is an Ed25519 attestation for a proof or a batch.
+// The signature covers SHA256(merkle_root || anchor_tx_hash). Binary fields
+// are hex encoded.
+type AttestationRequest struct {
+	ProofID         *uuid.UUID `json:"proof_id,omitempty"`
+	BatchID         *uuid.UUID `json:"batch_id,omitempty"`
+	ValidatorID     string     `json:"validator_id,omitempty"` // Defaults to the key's validator
+	ValidatorPubkey string     `json:"validator_pubkey"`
+	MerkleRoot      string     `json:"merkle_root"`
+	AnchorTxHash    *string    `json:"anchor_tx_hash,omitempty"`
+	BlockNumber     *int64     `json:"block_number,omitempty"`
+	Signature       string     `json:"signature"`
+	AttestedAt      *time.Time `json:"attested_at,omitempty"` // Defaults to receipt time
+}
+
+// AttestationResponse reports the stored attestation and quorum state
+type AttestationResponse struct {
+	AttestationID        uuid.UUID  `json:"attestation_id"`
+	ProofID              *uuid.UUID `json:"proof_id,omitempty"`
+	BatchID              *uuid.UUID `json:"batch_id,omitempty"`
+	ValidatorID          string     `json:"validator_id"`
+	AttestedHash         string     `json:"attested_hash"`
+	ValidAttestations    int        `json:"valid_attestations"`
+	RegisteredValidators int        `json:"registered_validators"`
+	RequiredQuorum       int        `json:"required_quorum"`
+	QuorumMet            bool       `json:"quorum_met"`
+	CustodyEvents        int        `json:"custody_events"`
+	AttestedAt           time.Time  `json:"attested_at"`
+}
+
+// errInvalidAttestationSignature is returned when the signature does not verify
+var errInvalidAttestationSignature = errors.New("signature does not verify against validator_pubkey")
+
+// HandleSubmitAttestation handles POST /api/v1/attestations
+func (h *IngestionHandlers) HandleSubmitAttestation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+		return
+	}
+
+	apiKey, err := h.validateAPIKey(r)
+	if err != nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		return
+	}
+	if !apiKey.CanSubmitProofs || apiKey.ValidatorID == nil || *apiKey.ValidatorID == "" {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "API key does not have attestation submission permission")
+		return
+	}
+
+	var req AttestationRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, h.maxBodySize)).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	validatorID := *apiKey.ValidatorID
+	if req.ValidatorID == "" {
+		req.ValidatorID = validatorID
+	}
+	if req.ValidatorID != validatorID {
+		h.writeError(w, http.StatusForbidden, "VALIDATOR_MISMATCH",
+			fmt.Sprintf("API key is not authorized to attest for validator %s", req.ValidatorID))
+		return
+	}
+
+	sub, err := req.toSubmission(time.Now().UTC())
+	if errors.Is(err, errInvalidAttestationSignature) {
+		h.writeError(w, http.StatusUnprocessableEntity, "INVALID_SIGNATURE", err.Error())
+		return
+	}
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	result, err := h.repos.Ingestion.SubmitAttestation(r.Context(), sub, proofbundle.RequiredQuorum)
+	switch {
+	case errors.Is(err, database.ErrValidatorKeyNotRegistered):
+		h.writeError(w, http.StatusForbidden, "KEY_NOT_REGISTERED",
+			fmt.Sprintf("validator_pubkey is not an active key registered to validator %s", sub.ValidatorID))
+		return
+	case errors.Is(err, database.ErrProofNotFound):
+		h.writeError(w, http.StatusNotFound, "PROOF_NOT_FOUND", "Proof not found")
+		return
+	case errors.Is(err, database.ErrBatchNotFound):
+		h.writeError(w, http.StatusNotFound, "BATCH_NOT_FOUND", "Batch not found")
+		return
+	case errors.Is(err, database.ErrAttestationMismatch):
+		h.writeError(w, http.StatusUnprocessableEntity, "ATTESTATION_MISMATCH", err.Error())
+		return
+	case errors.Is(err, database.ErrDuplicateAttestation):
+		h.writeAttestationRejection(w, "DUPLICATE_ATTESTATION",
+			"Validator has already submitted this attestation", result.Attestation)
+		return
+	case errors.Is(err, database.ErrConflictingAttestation):
+		existing := result.Attestation
+		h.logger.Printf("CONFLICTING ATTESTATION: validator %s attested %s to %s, previously attested %s (attestation %s)",
+			sub.ValidatorID, hex.EncodeToString(database.ComputeAttestedHash(sub.MerkleRoot, stringOrEmpty(sub.AnchorTxHash))),
+			attestationTarget(sub), hex.EncodeToString(existing.AttestedHash), existing.AttestationID)
+		h.writeAttestationRejection(w, "CONFLICTING_ATTESTATION",
+			"Validator has already attested different content for this target", existing)
+		return
+	case err != nil:
+		h.logger.Printf("Error storing attestation from %s for %s: %v", sub.ValidatorID, attestationTarget(sub), err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to store attestation")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, AttestationResponse{
+		AttestationID:        result.Attestation.AttestationID,
+		ProofID:              sub.ProofID,
+		BatchID:              sub.BatchID,
+		ValidatorID:          sub.ValidatorID,
+		AttestedHash:         hex.EncodeToString(result.Attestation.AttestedHash),
+		ValidAttestations:    result.ValidAttestations,
+		RegisteredValidators: result.RegisteredValidators,
+		RequiredQuorum:       result.RequiredQuorum,
+		QuorumMet:            result.QuorumMet,
+		CustodyEvents:        result.CustodyEvents,
+		AttestedAt:           result.Attestation.AttestedAt,
+	})
+}
+
+// toSubmission validates the request, decodes its hex fields and verifies
+// the signature against the submitted public key
+func (req *AttestationRequest) toSubmission(now time.Time) (*database.AttestationSubmission, error) {
+	if (req.ProofID == nil) == (req.BatchID == nil) {
+		return nil, errors.New("exactly one of proof_id and batch_id is required")
+	}
+
+	pub, err := hex.DecodeString(req.ValidatorPubkey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("validator_pubkey must be a hex Ed25519 public key")
+	}
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, errors.New("signature must be a hex Ed25519 signature")
+	}
+	root, err := hex.DecodeString(req.MerkleRoot)
+	if err != nil || len(root) != sha256.Size {
+		return nil, errors.New("merkle_root must be a hex SHA256 hash")
+	}
+
+	attested := database.ComputeAttestedHash(root, stringOrEmpty(req.AnchorTxHash))
+	if !ed25519.Verify(ed25519.PublicKey(pub), attested, signature) {
+		return nil, errInvalidAttestationSignature
+	}
+
+	attestedAt := now
+	if req.AttestedAt != nil {
+		attestedAt = *req.AttestedAt
+	}
+
+	return &database.AttestationSubmission{
+		ProofID:         req.ProofID,
+		BatchID:         req.BatchID,
+		ValidatorID:     req.ValidatorID,
+		ValidatorPubkey: pub,
+		MerkleRoot:      root,
+		AnchorTxHash:    req.AnchorTxHash,
+		BlockNumber:     req.BlockNumber,
+		Signature:       signature,
+		AttestedAt:      attestedAt,
+	}, nil
+}
+
+func attestationTarget(sub *database.AttestationSubmission) string {
+	if sub.ProofID != nil {
+		return "proof " + sub.ProofID.String()
+	}
+	return "batch " + sub.BatchID.String()
+}
+
+// writeAttestationRejection rejects a repeat attestation, identifying the one already stored
+func (h *IngestionHandlers) writeAttestationRejection(w http.ResponseWriter, code, message string, existing *database.ProofAttestation) {
+	h.writeJSON(w, http.StatusConflict, map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+		"existing_attestation": map[string]interface{}{
+			"attestation_id": existing.AttestationID,
+			"attested_hash":  hex.EncodeToString(existing.AttestedHash),
+			"attested_at":    existing.AttestedAt,
+		},
+	})
+}
+
+// =============================================================================
+// BLS ATTESTATION ENDPOINTS
+// =============================================================================
+
+// BLSAttestationRequest is a BLS attestation for an external chain result.
+// message_hash is the result hash; public_key is the validator's compressed
+// G2 key from the validator set and signature a compressed G1 point. Binary
+// fields are hex encoded.
+type BLSAttestationRequest struct {
+	ResultID    uuid.UUID  `json:"result_id"`
+	ValidatorID string     `json:"validator_id,omitempty"` // Defaults to the key's validator
+	PublicKey   string     `json:"public_key"`
+	MessageHash string     `json:"message_hash"`
+	Signature   string     `json:"signature"`
+	AttestedAt  *time.Time `json:"attested_at,omitempty"` // Defaults to receipt time
+}
+
+// BLSAttestationResponse reports the stored attestation and consensus state
+type BLSAttestationResponse struct {
+	AttestationID uuid.UUID                `json:"attestation_id"`
+	ResultID      uuid.UUID                `json:"result_id"`
+	ValidatorID   string                   `json:"validator_id"`
+	Weight        int64                    `json:"weight"`
+	AttestedAt    time.Time                `json:"attested_at"`
+	Consensus     *ResultConsensusResponse `json:"consensus"`
+}
+
+// ResultConsensusResponse is the consensus state of an external chain result
+type ResultConsensusResponse struct {
+	EntryID            uuid.UUID               `json:"entry_id"`
+	ResultID           uuid.UUID               `json:"result_id"`
+	SnapshotID         uuid.UUID               `json:"snapshot_id"`
+	State              database.ConsensusState `json:"state"`
+	AttestationCount   int                     `json:"attestation_count"`
+	RequiredCount      int                     `json:"required_count"`
+	TotalWeight        int64                   `json:"total_weight"`
+	ThresholdWeight    int64                   `json:"threshold_weight"`
+	AchievedWeight     int64                   `json:"achieved_weight"`
+	StartTime          time.Time               `json:"start_time"`
+	Deadline           time.Time               `json:"deadline"`
+	CompletedAt        *time.Time              `json:"completed_at,omitempty"`
+	AggregationID      *uuid.UUID              `json:"aggregation_id,omitempty"`
+	AggregateSignature string                  `json:"aggregate_signature,omitempty"`
+	AggregatePubkey    string                  `json:"aggregate_pubkey,omitempty"`
+	AggregateVerified  bool                    `json:"aggregate_verified"` // Aggregates are pairing-verified before they are stored
+}
+
+// errInvalidBLSPoint is returned when a public key or signature is not a valid subgroup point
+var errInvalidBLSPoint = errors.New("invalid BLS12-381 point")
+
+// errInvalidBLSSignature is returned when a BLS signature does not verify against its public key and message hash
+var errInvalidBLSSignature = errors.New("invalid BLS signature")
+
+// HandleSubmitBLSAttestation handles POST /api/v1/attestations/bls
+func (h *IngestionHandlers) HandleSubmitBLSAttestation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+		return
+	}
+
+	apiKey, err := h.validateAPIKey(r)
+	if err != nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		return
+	}
+	if !apiKey.CanSubmitProofs || apiKey.ValidatorID == nil || *apiKey.ValidatorID == "" {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "API key does not have attestation submission permission")
+		return
+	}
+
+	var req BLSAttestationRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, h.maxBodySize)).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	validatorID := *apiKey.ValidatorID
+	if req.ValidatorID == "" {
+		req.ValidatorID = validatorID
+	}
+	if req.ValidatorID != validatorID {
+		h.writeError(w, http.StatusForbidden, "VALIDATOR_MISMATCH",
+			fmt.Sprintf("API key is not authorized to attest for validator %s", req.ValidatorID))
+		return
+	}
+
+	sub, err := req.toSubmission(time.Now().UTC())
+	if errors.Is(err, errInvalidBLSPoint) {
+		h.writeError(w, http.StatusUnprocessableEntity, "INVALID_BLS_POINT", err.Error())
+		return
+	}
+	if errors.Is(err, errInvalidBLSSignature) {
+		h.writeError(w, http.StatusUnprocessableEntity, "INVALID_SIGNATURE", err.Error())
+		return
+	}
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	result, err := h.repos.Consensus.SubmitBLSAttestation(r.Context(), sub, h.consensusTimeout)
+	switch {
+	case errors.Is(err, database.ErrExternalResultNotFound):
+		h.writeError(w, http.StatusNotFound, "RESULT_NOT_FOUND", "External chain result not found")
+		return
+	case errors.Is(err, database.ErrValidatorSetNotFound):
+		h.writeError(w, http.StatusConflict, "NO_VALIDATOR_SET", "No validator set snapshot exists for the result's chain")
+		return
+	case errors.Is(err, database.ErrValidatorNotInSet):
+		h.writeError(w, http.StatusForbidden, "VALIDATOR_NOT_IN_SET",
+			fmt.Sprintf("Validator %s is not in the validator set for this result", sub.ValidatorID))
+		return
+	case errors.Is(err, database.ErrValidatorKeyNotRegistered):
+		h.writeError(w, http.StatusForbidden, "KEY_NOT_REGISTERED",
+			fmt.Sprintf("public_key does not match validator %s in the validator set", sub.ValidatorID))
+		return
+	case errors.Is(err, database.ErrAttestationMismatch):
+		h.writeError(w, http.StatusUnprocessableEntity, "ATTESTATION_MISMATCH", err.Error())
+		return
+	case errors.Is(err, database.ErrInvalidBLSSignature):
+		h.writeError(w, http.StatusUnprocessableEntity, "INVALID_SIGNATURE", err.Error())
+		return
+	case errors.Is(err, database.ErrConsensusClosed):
+		h.writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error": map[string]string{
+				"code":    "CONSENSUS_CLOSED",
+				"message": fmt.Sprintf("Consensus for this result is %s", result.Entry.State),
+			},
+			"consensus": newResultConsensusResponse(result.Entry),
+		})
+		return
+	case errors.Is(err, database.ErrDuplicateAttestation):
+		h.writeBLSAttestationRejection(w, "DUPLICATE_ATTESTATION",
+			"Validator has already submitted this attestation", result.Attestation)
+		return
+	case errors.Is(err, database.ErrConflictingAttestation):
+		existing := result.Attestation
+		h.logger.Printf("CONFLICTING ATTESTATION: validator %s submitted BLS signature %s for result %s, previously %s (attestation %s)",
+			sub.ValidatorID, hex.EncodeToString(sub.Signature), sub.ResultID,
+			hex.EncodeToString(existing.Signature), existing.AttestationID)
+		h.writeBLSAttestationRejection(w, "CONFLICTING_ATTESTATION",
+			"Validator has already attested this result with a different signature", existing)
+		return
+	case err != nil:
+		h.logger.Printf("Error storing BLS attestation from %s for result %s: %v", sub.ValidatorID, sub.ResultID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to store attestation")
+		return
+	}
+
+	if result.Aggregate != nil {
+		h.logger.Printf("BLS threshold met for result %s: %d attestations, weight %d/%d",
+			sub.ResultID, result.Aggregate.ParticipantCount, result.Aggregate.AchievedWeight, result.Aggregate.ThresholdWeight)
+	}
+
+	h.writeJSON(w, http.StatusCreated, BLSAttestationResponse{
+		AttestationID: result.Attestation.AttestationID,
+		ResultID:      sub.ResultID,
+		ValidatorID:   sub.ValidatorID,
+		Weight:        result.Attestation.Weight,
+		AttestedAt:    result.Attestation.AttestedAt,
+		Consensus:     newResultConsensusResponse(result.Entry),
+	})
+}
+
+// HandleGetResultConsensus handles GET /api/v1/attestations/bls/{result_id}
+func (h *IngestionHandlers) HandleGetResultConsensus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	if _, err := h.validateAPIKey(r); err != nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	resultID, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/api/v1/attestations/bls/"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid result ID format")
+		return
+	}
+
+	entry, err := h.repos.Consensus.GetResultConsensusEntry(r.Context(), resultID)
+	if errors.Is(err, database.ErrConsensusEntryNotFound) {
+		h.writeError(w, http.StatusNotFound, "CONSENSUS_NOT_FOUND", "No attestations have been submitted for this result")
+		return
+	}
+	if err != nil {
+		h.logger.Printf("Error getting consensus for result %s: %v", resultID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get consensus state")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, newResultConsensusResponse(entry))
+}
+
+// toSubmission validates the request, decodes its hex fields, checks that
+// the public key and signature are valid BLS12-381 subgroup points and
+// verifies the signature over message_hash. The repository verifies it again
+// against the validator set key and the stored result hash.
+func (req *BLSAttestationRequest) toSubmission(now time.Time) (*database.BLSAttestationSubmission, error) {
+	if req.ResultID == uuid.Nil {
+		return nil, errors.New("result_id is required")
+	}
+
+	pub, err := hex.DecodeString(req.PublicKey)
+	if err != nil || len(pub) != bls.G2Size {
+		return nil, errors.New("public_key must be a hex compressed BLS12-381 G2 point")
+	}
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil || len(signature) != bls.G1Size {
+		return nil, errors.New("signature must be a hex compressed BLS12-381 G1 point")
+	}
+	messageHash, err := hex.DecodeString(req.MessageHash)
+	if err != nil || len(messageHash) != sha256.Size {
+		return nil, errors.New("message_hash must be a hex SHA256 hash")
+	}
+
+	if err := bls.ValidatePublicKey(pub); err != nil {
+		return nil, fmt.Errorf("%w: public_key: %v", errInvalidBLSPoint, err)
+	}
+	if err := bls.ValidateSignature(signature); err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", errInvalidBLSPoint, err)
+	}
+	if err := bls.Verify(pub, messageHash, signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidBLSSignature, err)
+	}
+
+	attestedAt := now
+	if req.AttestedAt != nil {
+		attestedAt = *req.AttestedAt
+	}
+
+	return &database.BLSAttestationSubmission{
+		ResultID:    req.ResultID,
+		ValidatorID: req.ValidatorID,
+		PublicKey:   pub,
+		MessageHash: messageHash,
+		Signature:   signature,
+		AttestedAt:  attestedAt,
+	}, nil
+}
+
+func newResultConsensusResponse(e *database.ResultConsensusEntry) *ResultConsensusResponse {
+	resp := &ResultConsensusResponse{
+		EntryID:          e.EntryID,
+		ResultID:         e.ResultID,
+		SnapshotID:       e.SnapshotID,
+		State:            e.State,
+		AttestationCount: e.AttestationCount,
+		RequiredCount:    e.RequiredCount,
+		TotalWeight:      e.TotalWeight,
+		ThresholdWeight:  e.ThresholdWeight,
+		AchievedWeight:   e.AchievedWeight,
+		StartTime:        e.StartTime,
+		Deadline:         e.Deadline,
+		CompletedAt:      e.CompletedAt,
+		AggregationID:    e.AggregationID,
+	}
+	if len(e.AggregateSignature) > 0 {
+		resp.AggregateSignature = hex.EncodeToString(e.AggregateSignature)
+		resp.AggregatePubkey = hex.EncodeToString(e.AggregatePubkey)
+		resp.AggregateVerified = true
+	}
+	return resp
+}
+
+// writeBLSAttestationRejection rejects a repeat BLS attestation, identifying the one already stored
+func (h *IngestionHandlers) writeBLSAttestationRejection(w http.ResponseWriter, code, message string, existing *database.BLSAttestationRecord) {
+	h.writeJSON(w, http.StatusConflict, map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+		"existing_attestation": map[string]interface{}{
+			"attestation_id": existing.AttestationID,
+			"signature":      hex.EncodeToString(existing.Signature),
+			"attested_at":    existing.AttestedAt,
+		},
+	})
+}
+
+// =============================================================================
+// HELPER METHODS
+// =============================================================================
+
+func (h *IngestionHandlers) validateAPIKey(r *http.Request) (*database.APIKey, error) {
+	if principal := PrincipalFromContext(r.Context()); principal != nil {
+		return principal.APIKey, nil
+	}
+	apiKey := r.Header.Get("X-API-Key")
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required for proof submission")
+	}
+	return h.apiKeyValidator.Validate(r.Context(), apiKey)
+}
+
+func (h *IngestionHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *IngestionHandlers) writeError(w http.ResponseWriter, status int, code, message string) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,410 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for Ingestion Handlers
+// Tests submission validation without requiring database connection
+
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/bls"
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// ============================================================================
+// Request Validation Tests
+// ============================================================================
+
+func TestHandleIngestProof_MethodNotAllowed(t *testing.T) {
+	handlers := NewIngestionHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/ingest", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleIngestProof(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestHandleIngestProof_MissingAPIKey(t *testing.T) {
+	handlers := NewIngestionHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/proofs/ingest", strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+	handlers.HandleIngestProof(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// Submission Decoding Tests
+// ============================================================================
+
+func testIngestionRequest() *ProofIngestionRequest {
+	artifactJSON := json.RawMessage(`{"proof":"chained","tx":"abc123"}`)
+	hash := sha256.Sum256(artifactJSON)
+	govLevel := database.GovLevelG1
+
+	return &ProofIngestionRequest{
+		ArtifactHash: hex.EncodeToString(hash[:]),
+		Artifact: IngestArtifact{
+			ProofType:    database.ProofTypeChained,
+			AccumTxHash:  "abc123",
+			AccountURL:   "acc://test.acme/tokens",
+			MerkleRoot:   strings.Repeat("ab", 32),
+			GovLevel:     &govLevel,
+			ProofClass:   database.ProofClassOnDemand,
+			ValidatorID:  "validator-1",
+			ArtifactJSON: artifactJSON,
+		},
+		Layers: []IngestLayer{
+			{LayerNumber: 1, LayerName: "L1", BVNRoot: strings.Repeat("01", 32), LayerJSON: json.RawMessage(`{}`)},
+			{LayerNumber: 2, LayerName: "L2", DNRoot: strings.Repeat("02", 32), LayerJSON: json.RawMessage(`{}`)},
+		},
+		GovernanceLevels: []IngestGovernanceLevel{
+			{GovLevel: database.GovLevelG0, LevelName: "G0", LevelJSON: json.RawMessage(`{}`)},
+			{GovLevel: database.GovLevelG2, LevelName: "G2", OutcomeHash: strings.Repeat("03", 32), LevelJSON: json.RawMessage(`{}`)},
+		},
+		AnchorReference: &database.NewAnchorReference{
+			TargetChain:       "ethereum",
+			ChainID:           "11155111",
+			NetworkName:       "sepolia",
+			AnchorTxHash:      "0xdeadbeef",
+			AnchorBlockNumber: 100,
+		},
+	}
+}
+
+func TestToSubmission_Valid(t *testing.T) {
+	sub, err := testIngestionRequest().toSubmission()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if sub.Artifact.AccumTxHash != "abc123" {
+		t.Errorf("Expected accum_tx_hash abc123, got %s", sub.Artifact.AccumTxHash)
+	}
+	if len(sub.Artifact.MerkleRoot) != 32 {
+		t.Errorf("Expected 32-byte merkle root, got %d bytes", len(sub.Artifact.MerkleRoot))
+	}
+	if len(sub.Layers) != 2 || len(sub.Layers[0].BVNRoot) != 32 || len(sub.Layers[1].DNRoot) != 32 {
+		t.Errorf("Expected 2 decoded layers, got %+v", sub.Layers)
+	}
+	if len(sub.GovernanceLevels) != 2 || len(sub.GovernanceLevels[1].OutcomeHash) != 32 {
+		t.Errorf("Expected 2 decoded governance levels, got %+v", sub.GovernanceLevels)
+	}
+	if sub.AnchorReference == nil || sub.AnchorReference.AnchorTxHash != "0xdeadbeef" {
+		t.Errorf("Expected anchor reference to be carried over, got %+v", sub.AnchorReference)
+	}
+}
+
+func TestToSubmission_ArtifactHashMismatch(t *testing.T) {
+	req := testIngestionRequest()
+	req.Artifact.ArtifactJSON = json.RawMessage(`{"proof":"chained","tx":"tampered"}`)
+
+	_, err := req.toSubmission()
+	if !errors.Is(err, errArtifactHashMismatch) {
+		t.Errorf("Expected artifact hash mismatch, got %v", err)
+	}
+}
+
+func TestToSubmission_InvalidFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ProofIngestionRequest)
+	}{
+		{"missing tx hash", func(r *ProofIngestionRequest) { r.Artifact.AccumTxHash = "" }},
+		{"missing account", func(r *ProofIngestionRequest) { r.Artifact.AccountURL = "" }},
+		{"unknown proof type", func(r *ProofIngestionRequest) { r.Artifact.ProofType = "other" }},
+		{"unknown proof class", func(r *ProofIngestionRequest) { r.Artifact.ProofClass = "later" }},
+		{"malformed artifact hash", func(r *ProofIngestionRequest) { r.ArtifactHash = "xyz" }},
+		{"short artifact hash", func(r *ProofIngestionRequest) { r.ArtifactHash = "abcd" }},
+		{"bad merkle root", func(r *ProofIngestionRequest) { r.Artifact.MerkleRoot = "not-hex" }},
+		{"bad layer number", func(r *ProofIngestionRequest) { r.Layers[0].LayerNumber = 4 }},
+		{"bad governance level", func(r *ProofIngestionRequest) { r.GovernanceLevels[0].GovLevel = "G9" }},
+		{"incomplete anchor", func(r *ProofIngestionRequest) { r.AnchorReference.ChainID = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testIngestionRequest()
+			tt.modify(req)
+			_, err := req.toSubmission()
+			if err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if errors.Is(err, errArtifactHashMismatch) {
+				t.Errorf("Expected validation error, got hash mismatch")
+			}
+		})
+	}
+}
+
+// ============================================================================
+// Attestation Tests
+// ============================================================================
+
+func TestHandleSubmitAttestation_MethodNotAllowed(t *testing.T) {
+	handlers := NewIngestionHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/attestations", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleSubmitAttestation(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestHandleSubmitAttestation_MissingAPIKey(t *testing.T) {
+	handlers := NewIngestionHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/attestations", strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+	handlers.HandleSubmitAttestation(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func testAttestationRequest(t *testing.T) (*AttestationRequest, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	root := sha256.Sum256([]byte("batch root"))
+	anchor := "0xabc"
+	proofID := uuid.New()
+
+	return &AttestationRequest{
+		ProofID:         &proofID,
+		ValidatorID:     "validator-1",
+		ValidatorPubkey: hex.EncodeToString(pub),
+		MerkleRoot:      hex.EncodeToString(root[:]),
+		AnchorTxHash:    &anchor,
+		Signature:       hex.EncodeToString(ed25519.Sign(priv, database.ComputeAttestedHash(root[:], anchor))),
+	}, priv
+}
+
+func TestAttestationToSubmission_Valid(t *testing.T) {
+	req, _ := testAttestationRequest(t)
+	now := time.Now().UTC()
+
+	sub, err := req.toSubmission(now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sub.ProofID == nil || *sub.ProofID != *req.ProofID {
+		t.Errorf("Expected proof ID %s, got %v", req.ProofID, sub.ProofID)
+	}
+	if len(sub.ValidatorPubkey) != ed25519.PublicKeySize || len(sub.Signature) != ed25519.SignatureSize {
+		t.Error("Expected decoded public key and signature")
+	}
+	if !sub.AttestedAt.Equal(now) {
+		t.Errorf("Expected attested_at to default to %v, got %v", now, sub.AttestedAt)
+	}
+}
+
+func TestAttestationToSubmission_InvalidSignature(t *testing.T) {
+	req, priv := testAttestationRequest(t)
+
+	// Signed without the anchor the request claims to cover
+	root, _ := hex.DecodeString(req.MerkleRoot)
+	req.Signature = hex.EncodeToString(ed25519.Sign(priv, database.ComputeAttestedHash(root, "")))
+
+	_, err := req.toSubmission(time.Now())
+	if !errors.Is(err, errInvalidAttestationSignature) {
+		t.Errorf("Expected invalid signature, got %v", err)
+	}
+}
+
+func TestAttestationToSubmission_InvalidFields(t *testing.T) {
+	batchID := uuid.New()
+	tests := []struct {
+		name   string
+		modify func(*AttestationRequest)
+	}{
+		{"no target", func(r *AttestationRequest) { r.ProofID = nil }},
+		{"two targets", func(r *AttestationRequest) { r.BatchID = &batchID }},
+		{"short public key", func(r *AttestationRequest) { r.ValidatorPubkey = "abcd" }},
+		{"malformed signature", func(r *AttestationRequest) { r.Signature = "not-hex" }},
+		{"short merkle root", func(r *AttestationRequest) { r.MerkleRoot = "abcd" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := testAttestationRequest(t)
+			tt.modify(req)
+			_, err := req.toSubmission(time.Now())
+			if err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if errors.Is(err, errInvalidAttestationSignature) {
+				t.Errorf("Expected validation error, got invalid signature")
+			}
+		})
+	}
+}
+
+// ============================================================================
+// BLS Attestation Tests
+// ============================================================================
+
+// testG1Generator is the BLS12-381 G1 generator: a valid subgroup point that
+// is not a signature of anything the tests sign
+const testG1Generator = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+
+func TestHandleSubmitBLSAttestation_MethodNotAllowed(t *testing.T) {
+	handlers := NewIngestionHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/attestations/bls", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleSubmitBLSAttestation(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestHandleSubmitBLSAttestation_MissingAPIKey(t *testing.T) {
+	handlers := NewIngestionHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/attestations/bls", strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+	handlers.HandleSubmitBLSAttestation(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestHandleGetResultConsensus_MissingAPIKey(t *testing.T) {
+	handlers := NewIngestionHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/attestations/bls/"+uuid.New().String(), nil)
+	w := httptest.NewRecorder()
+	handlers.HandleGetResultConsensus(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func testBLSAttestationRequest(t *testing.T) *BLSAttestationRequest {
+	t.Helper()
+	seed := sha256.Sum256([]byte("validator-1"))
+	sk, err := bls.NewSecretKey(seed[:])
+	if err != nil {
+		t.Fatalf("Failed to derive BLS key: %v", err)
+	}
+	resultHash := sha256.Sum256([]byte("external chain result"))
+	sig, err := sk.Sign(resultHash[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	return &BLSAttestationRequest{
+		ResultID:    uuid.New(),
+		ValidatorID: "validator-1",
+		PublicKey:   hex.EncodeToString(sk.PublicKey()),
+		MessageHash: hex.EncodeToString(resultHash[:]),
+		Signature:   hex.EncodeToString(sig),
+	}
+}
+
+func TestBLSAttestationToSubmission_Valid(t *testing.T) {
+	req := testBLSAttestationRequest(t)
+	now := time.Now().UTC()
+
+	sub, err := req.toSubmission(now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sub.ResultID != req.ResultID {
+		t.Errorf("Expected result ID %s, got %s", req.ResultID, sub.ResultID)
+	}
+	if len(sub.PublicKey) != 96 || len(sub.Signature) != 48 || len(sub.MessageHash) != 32 {
+		t.Error("Expected decoded public key, signature and message hash")
+	}
+	if !sub.AttestedAt.Equal(now) {
+		t.Errorf("Expected attested_at to default to %v, got %v", now, sub.AttestedAt)
+	}
+}
+
+func TestBLSAttestationToSubmission_InvalidPoint(t *testing.T) {
+	// x = 0 decodes to (0, 2), which is on the curve but outside the subgroup
+	lowOrder := "80" + strings.Repeat("00", 47)
+	// The point at infinity is never a usable signature
+	infinity := "c0" + strings.Repeat("00", 47)
+
+	for _, sig := range []string{lowOrder, infinity} {
+		req := testBLSAttestationRequest(t)
+		req.Signature = sig
+		if _, err := req.toSubmission(time.Now()); !errors.Is(err, errInvalidBLSPoint) {
+			t.Errorf("Expected invalid BLS point for %s, got %v", sig[:2], err)
+		}
+	}
+}
+
+func TestBLSAttestationToSubmission_InvalidSignature(t *testing.T) {
+	otherHash := sha256.Sum256([]byte("another result"))
+
+	tests := []struct {
+		name   string
+		modify func(*BLSAttestationRequest)
+	}{
+		{"different message", func(r *BLSAttestationRequest) { r.MessageHash = hex.EncodeToString(otherHash[:]) }},
+		{"valid point, not a signature", func(r *BLSAttestationRequest) { r.Signature = testG1Generator }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testBLSAttestationRequest(t)
+			tt.modify(req)
+			if _, err := req.toSubmission(time.Now()); !errors.Is(err, errInvalidBLSSignature) {
+				t.Errorf("Expected invalid BLS signature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBLSAttestationToSubmission_InvalidFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*BLSAttestationRequest)
+	}{
+		{"missing result", func(r *BLSAttestationRequest) { r.ResultID = uuid.Nil }},
+		{"short public key", func(r *BLSAttestationRequest) { r.PublicKey = testG1Generator }},
+		{"malformed signature", func(r *BLSAttestationRequest) { r.Signature = "not-hex" }},
+		{"short message hash", func(r *BLSAttestationRequest) { r.MessageHash = "abcd" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testBLSAttestationRequest(t)
+			tt.modify(req)
+			_, err := req.toSubmission(time.Now())
+			if err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if errors.Is(err, errInvalidBLSPoint) {
+				t.Errorf("Expected validation error, got invalid point")
+			}
+		})
+	}
+}
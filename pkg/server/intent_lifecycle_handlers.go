@@ -102,10 +102,22 @@ func (h *IntentLifecycleHandlers) HandleListByUser(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// Users read their own intents; organisation admins their organisation's
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Credentials are required to read user intents")
+		return
+	}
+	organizationADI, ok := userIntentScope(principal, userID)
+	if !ok {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "Credentials may not read this user's intents")
+		return
+	}
+
 	limit := h.parseIntParam(r, "limit", 50)
 
 	ctx := r.Context()
-	items, err := h.repos.IntentLifecycle.ListByUserEnriched(ctx, userID, limit)
+	items, err := h.repos.IntentLifecycle.ListByUserEnriched(ctx, userID, organizationADI, limit)
 	if err != nil {
 		h.logger.Printf("Error listing lifecycles by user: %v", err)
 		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list lifecycles")
@@ -133,10 +145,22 @@ func (h *IntentLifecycleHandlers) HandleListByStatus(w http.ResponseWriter, r *h
 		return
 	}
 
+	// Users list their own intents; organisation admins their organisation's
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Credentials are required to list intents")
+		return
+	}
+	scope, ok := intentListScope(principal)
+	if !ok {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "Credentials may not list intents")
+		return
+	}
+
 	limit := h.parseIntParam(r, "limit", 50)
 
 	ctx := r.Context()
-	items, err := h.repos.IntentLifecycle.ListByStatus(ctx, database.IntentLifecycleStatus(status), limit)
+	items, err := h.repos.IntentLifecycle.ListByStatus(ctx, database.IntentLifecycleStatus(status), scope, limit)
 	if err != nil {
 		h.logger.Printf("Error listing lifecycles by status: %v", err)
 		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list lifecycles")
@@ -157,10 +181,22 @@ func (h *IntentLifecycleHandlers) HandleListRecent(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// Users list their own intents; organisation admins their organisation's
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Credentials are required to list intents")
+		return
+	}
+	scope, ok := intentListScope(principal)
+	if !ok {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "Credentials may not list intents")
+		return
+	}
+
 	limit := h.parseIntParam(r, "limit", 50)
 
 	ctx := r.Context()
-	items, err := h.repos.IntentLifecycle.ListRecentEnriched(ctx, limit)
+	items, err := h.repos.IntentLifecycle.ListRecentEnriched(ctx, scope, limit)
 	if err != nil {
 		h.logger.Printf("Error listing recent lifecycles: %v", err)
 		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list lifecycles")
@@ -0,0 +1,694 @@
+// Copyright 2025 Certen Protocol
+//
+// Merkle API Handlers
+// Server-side Merkle proof generation from stored batch transactions
+//
+// Endpoints:
+// - GET /api/v1/proofs/{proof_id}/merkle - Recompute a proof's inclusion path
+// - GET /api/v1/batches/{batch_id}/tree    - Batch tree levels, leaves and highlighted paths
+// - GET /api/v1/audit/merkle/findings     - Merkle consistency audit findings
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// MerkleHandlers provides HTTP handlers for Merkle tree operations
+type MerkleHandlers struct {
+	repos       *database.Repositories
+	validatorID string
+	logger      *log.Logger
+}
+
+// NewMerkleHandlers creates new Merkle handlers
+func NewMerkleHandlers(repos *database.Repositories, validatorID string, logger *log.Logger) *MerkleHandlers {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[MerkleAPI] ", log.LstdFlags)
+	}
+	return &MerkleHandlers{
+		repos:       repos,
+		validatorID: validatorID,
+		logger:      logger,
+	}
+}
+
+// =============================================================================
+// PROOF MERKLE PATH ENDPOINT
+// =============================================================================
+
+// ProofMerkleResponse is a freshly computed inclusion proof for one transaction.
+// merkle_root, leaf_hash and merkle_path can be posted to /api/v1/proofs/verify/merkle as-is.
+type ProofMerkleResponse struct {
+	ProofID     uuid.UUID         `json:"proof_id"`
+	BatchID     uuid.UUID         `json:"batch_id"`
+	BatchStatus string            `json:"batch_status"`
+	AccumTxHash string            `json:"accum_tx_hash"`
+	LeafIndex   int               `json:"leaf_index"`
+	LeafHash    string            `json:"leaf_hash"`
+	MerkleRoot  string            `json:"merkle_root"`
+	MerklePath  []MerklePathEntry `json:"merkle_path"`
+	TreeSize    int               `json:"tree_size"`
+	TreeDepth   int               `json:"tree_depth"`
+
+	// Comparison against stored values
+	StoredMerklePath  json.RawMessage `json:"stored_merkle_path,omitempty"`
+	StoredBatchRoot   string          `json:"stored_batch_root,omitempty"`
+	StoredProofRoot   string          `json:"stored_proof_root,omitempty"`
+	PathMatchesStored bool            `json:"path_matches_stored"`
+	RootMatchesBatch  bool            `json:"root_matches_batch"`
+	Consistent        bool            `json:"consistent"`
+	Mismatches        []string        `json:"mismatches"`
+	ComputedAt        time.Time       `json:"computed_at"`
+}
+
+// merkleTarget identifies the batch transaction behind a proof
+type merkleTarget struct {
+	proofID     uuid.UUID
+	batchID     *uuid.UUID
+	accumTxHash string
+	proofRoot   []byte
+}
+
+// HandleGetProofMerkle handles GET /api/v1/proofs/{proof_id}/merkle
+func (h *MerkleHandlers) HandleGetProofMerkle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	// Extract proof ID from path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/proofs/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "merkle" {
+		h.writeError(w, http.StatusBadRequest, "INVALID_PATH", "Invalid endpoint path")
+		return
+	}
+
+	proofID, err := uuid.Parse(parts[0])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_PROOF_ID", "Invalid proof ID format")
+		return
+	}
+
+	ctx := r.Context()
+	target, err := h.findTarget(ctx, proofID)
+	if err != nil {
+		h.logger.Printf("Error getting proof %s: %v", proofID, err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve proof")
+		return
+	}
+	if target == nil {
+		h.writeError(w, http.StatusNotFound, "PROOF_NOT_FOUND", fmt.Sprintf("No proof found with ID: %s", proofID))
+		return
+	}
+
+	// Locate the batch holding the proof's transaction
+	batchID := target.batchID
+	if batchID == nil {
+		tx, err := h.repos.Batches.GetTransactionByAccumHash(ctx, target.accumTxHash)
+		if errors.Is(err, database.ErrTransactionNotFound) {
+			h.writeError(w, http.StatusNotFound, "NOT_BATCHED", "Proof transaction is not in any batch")
+			return
+		}
+		if err != nil {
+			h.logger.Printf("Error getting batch transaction: %v", err)
+			h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve batch transaction")
+			return
+		}
+		batchID = &tx.BatchID
+	}
+
+	batch, err := h.repos.Batches.GetBatch(ctx, *batchID)
+	if errors.Is(err, database.ErrBatchNotFound) {
+		h.writeError(w, http.StatusNotFound, "BATCH_NOT_FOUND", fmt.Sprintf("No batch found with ID: %s", *batchID))
+		return
+	}
+	if err != nil {
+		h.logger.Printf("Error getting batch: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve batch")
+		return
+	}
+
+	txs, err := h.repos.Batches.GetTransactionsInBatch(ctx, batch.BatchID)
+	if err != nil {
+		h.logger.Printf("Error getting batch transactions: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve batch transactions")
+		return
+	}
+
+	var leafTx *database.BatchTransaction
+	for _, tx := range txs {
+		if tx.AccumTxHash == target.accumTxHash {
+			leafTx = tx
+			break
+		}
+	}
+	if leafTx == nil {
+		h.writeError(w, http.StatusNotFound, "NOT_BATCHED", fmt.Sprintf("Transaction %s not found in batch %s", target.accumTxHash, batch.BatchID))
+		return
+	}
+
+	tree, err := merkle.TreeFromTransactions(txs)
+	if err != nil {
+		// A gap or malformed leaf is itself an integrity finding, not a server fault
+		h.writeError(w, http.StatusUnprocessableEntity, "TREE_REBUILD_FAILED", fmt.Sprintf("Cannot rebuild batch tree: %v", err))
+		return
+	}
+
+	freshPath, err := tree.Path(leafTx.TreeIndex)
+	if err != nil {
+		h.logger.Printf("Error computing merkle path: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to compute merkle path")
+		return
+	}
+
+	response := ProofMerkleResponse{
+		ProofID:          target.proofID,
+		BatchID:          batch.BatchID,
+		BatchStatus:      string(batch.Status),
+		AccumTxHash:      leafTx.AccumTxHash,
+		LeafIndex:        leafTx.TreeIndex,
+		LeafHash:         hex.EncodeToString(leafTx.TxHash),
+		MerkleRoot:       hex.EncodeToString(tree.Root()),
+		MerklePath:       toMerklePathEntries(freshPath),
+		TreeSize:         tree.LeafCount(),
+		TreeDepth:        tree.Depth(),
+		StoredMerklePath: leafTx.MerklePath,
+		Mismatches:       []string{},
+		ComputedAt:       time.Now().UTC(),
+	}
+
+	storedPath, err := merkle.ParseStoredPath(leafTx.MerklePath)
+	switch {
+	case err != nil:
+		response.Mismatches = append(response.Mismatches, err.Error())
+	case merkle.PathsEqual(storedPath, freshPath):
+		response.PathMatchesStored = true
+	default:
+		response.Mismatches = append(response.Mismatches, "stored merkle_path differs from recomputed path")
+	}
+
+	// An open batch has no root yet; its tree is still growing
+	if batch.Status == database.BatchStatusPending {
+		response.Mismatches = append(response.Mismatches, "batch is still open; root is provisional")
+	} else {
+		response.StoredBatchRoot = hex.EncodeToString(batch.MerkleRoot)
+		if bytes.Equal(batch.MerkleRoot, tree.Root()) {
+			response.RootMatchesBatch = true
+		} else {
+			response.Mismatches = append(response.Mismatches, "anchor_batches.merkle_root differs from recomputed root")
+		}
+	}
+
+	if len(target.proofRoot) > 0 {
+		response.StoredProofRoot = hex.EncodeToString(target.proofRoot)
+		if !bytes.Equal(target.proofRoot, tree.Root()) {
+			response.Mismatches = append(response.Mismatches, "proof merkle_root differs from recomputed root")
+		}
+	}
+
+	response.Consistent = len(response.Mismatches) == 0
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// findTarget resolves a proof ID against proof_artifacts, then certen_anchor_proofs
+func (h *MerkleHandlers) findTarget(ctx context.Context, proofID uuid.UUID) (*merkleTarget, error) {
+	artifact, err := h.repos.ProofArtifacts.GetProofByID(ctx, proofID)
+	if err != nil {
+		return nil, err
+	}
+	if artifact != nil {
+		return &merkleTarget{
+			proofID:     artifact.ProofID,
+			batchID:     artifact.BatchID,
+			accumTxHash: artifact.AccumTxHash,
+			proofRoot:   artifact.MerkleRoot,
+		}, nil
+	}
+
+	legacy, err := h.repos.Proofs.GetProof(ctx, proofID)
+	if errors.Is(err, database.ErrProofNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &merkleTarget{
+		proofID:     legacy.ProofID,
+		batchID:     &legacy.BatchID,
+		accumTxHash: legacy.AccumTxHash,
+		proofRoot:   legacy.MerkleRoot,
+	}, nil
+}
+
+// toMerklePathEntries converts stored-format path nodes to the verify endpoint format
+func toMerklePathEntries(path []database.MerklePathNode) []MerklePathEntry {
+	entries := make([]MerklePathEntry, len(path))
+	for i, node := range path {
+		entries[i] = MerklePathEntry{Hash: node.Hash, Right: node.Position == merkle.PositionRight}
+	}
+	return entries
+}
+
+// =============================================================================
+// BATCH TREE ENDPOINT
+// =============================================================================
+
+// MaxTreeLeafRange is the largest leaf range returned by a single tree request
+const MaxTreeLeafRange = 1024
+
+// Node roles relative to the highlighted leaves
+const (
+	TreeNodeRolePath    = "path"    // Leaf or ancestor of a highlighted leaf
+	TreeNodeRoleSibling = "sibling" // Sibling hashed with a path node
+)
+
+// BatchTreeResponse describes a window of a batch's Merkle tree
+type BatchTreeResponse struct {
+	BatchID          uuid.UUID         `json:"batch_id"`
+	BatchStatus      string            `json:"batch_status"`
+	MerkleRoot       string            `json:"merkle_root"`
+	StoredBatchRoot  string            `json:"stored_batch_root,omitempty"`
+	RootMatchesBatch bool              `json:"root_matches_batch"`
+	Provisional      bool              `json:"provisional"` // Batch still open; tree may grow
+	LeafCount        int               `json:"leaf_count"`
+	TreeDepth        int               `json:"tree_depth"`
+	RangeFrom        int               `json:"range_from"`
+	RangeTo          int               `json:"range_to"` // Exclusive
+	MaxDepth         int               `json:"max_depth"`
+	Truncated        bool              `json:"truncated"` // Window does not cover the whole tree
+	Levels           []TreeLevel       `json:"levels"`
+	Leaves           []TreeLeaf        `json:"leaves"`
+	Paths            []HighlightedPath `json:"paths"`
+}
+
+// TreeLevel is one level of the tree window. Depth 0 is the root level.
+type TreeLevel struct {
+	Depth      int        `json:"depth"`
+	Height     int        `json:"height"`     // 0 for the leaf level
+	NodeCount  int        `json:"node_count"` // Nodes on the whole level, not just the window
+	FirstIndex int        `json:"first_index"`
+	Nodes      []TreeNode `json:"nodes"`
+}
+
+// TreeNode is a single node hash at a level
+type TreeNode struct {
+	Index int    `json:"index"`
+	Hash  string `json:"hash"`
+	Role  string `json:"role,omitempty"`
+}
+
+// TreeLeaf maps a leaf to its batch transaction
+type TreeLeaf struct {
+	Index       int        `json:"index"`
+	LeafHash    string     `json:"leaf_hash"`
+	AccumTxHash string     `json:"accum_tx_hash"`
+	AccountURL  string     `json:"account_url"`
+	ProofID     *uuid.UUID `json:"proof_id,omitempty"`
+	Highlighted bool       `json:"highlighted"`
+}
+
+// HighlightedPath is the full inclusion path of a selected leaf
+type HighlightedPath struct {
+	LeafIndex   int               `json:"leaf_index"`
+	LeafHash    string            `json:"leaf_hash"`
+	AccumTxHash string            `json:"accum_tx_hash"`
+	NodeIndices []int             `json:"node_indices"` // Ancestor index at each height, leaf to root
+	MerklePath  []MerklePathEntry `json:"merkle_path"`
+}
+
+// HandleGetBatchTree handles GET /api/v1/batches/{batch_id}/tree
+// Query parameters:
+//   - from, to: leaf range [from, to), at most MaxTreeLeafRange leaves
+//   - max_depth: deepest level returned, counted from the root (0 = root only)
+//   - highlight: comma-separated leaf indices whose paths are marked
+//   - tx: comma-separated Accumulate transaction hashes whose paths are marked
+func (h *MerkleHandlers) HandleGetBatchTree(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	// Extract batch ID from path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/batches/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "tree" {
+		h.writeError(w, http.StatusBadRequest, "INVALID_PATH", "Invalid endpoint path")
+		return
+	}
+
+	batchID, err := uuid.Parse(parts[0])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_BATCH_ID", "Invalid batch ID format")
+		return
+	}
+
+	query := r.URL.Query()
+	var highlightIndices []int
+	if raw := query.Get("highlight"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || index < 0 {
+				h.writeError(w, http.StatusBadRequest, "INVALID_HIGHLIGHT", fmt.Sprintf("Invalid leaf index: %s", part))
+				return
+			}
+			highlightIndices = append(highlightIndices, index)
+		}
+	}
+
+	ctx := r.Context()
+	batch, err := h.repos.Batches.GetBatch(ctx, batchID)
+	if errors.Is(err, database.ErrBatchNotFound) {
+		h.writeError(w, http.StatusNotFound, "BATCH_NOT_FOUND", fmt.Sprintf("No batch found with ID: %s", batchID))
+		return
+	}
+	if err != nil {
+		h.logger.Printf("Error getting batch: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve batch")
+		return
+	}
+
+	txs, err := h.repos.Batches.GetTransactionsInBatch(ctx, batchID)
+	if err != nil {
+		h.logger.Printf("Error getting batch transactions: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve batch transactions")
+		return
+	}
+	if len(txs) == 0 {
+		h.writeError(w, http.StatusNotFound, "EMPTY_BATCH", "Batch has no transactions")
+		return
+	}
+
+	tree, err := merkle.TreeFromTransactions(txs)
+	if err != nil {
+		h.writeError(w, http.StatusUnprocessableEntity, "TREE_REBUILD_FAILED", fmt.Sprintf("Cannot rebuild batch tree: %v", err))
+		return
+	}
+
+	if raw := query.Get("tx"); raw != "" {
+		byHash := make(map[string]int, len(txs))
+		for _, tx := range txs {
+			byHash[tx.AccumTxHash] = tx.TreeIndex
+		}
+		for _, hash := range strings.Split(raw, ",") {
+			index, ok := byHash[strings.TrimSpace(hash)]
+			if !ok {
+				h.writeError(w, http.StatusNotFound, "TRANSACTION_NOT_FOUND", fmt.Sprintf("Transaction %s not in batch", hash))
+				return
+			}
+			highlightIndices = append(highlightIndices, index)
+		}
+	}
+
+	leafCount := tree.LeafCount()
+	from := h.parseIntParam(r, "from", 0)
+	to := h.parseIntParam(r, "to", from+MaxTreeLeafRange)
+	if to > leafCount {
+		to = leafCount
+	}
+	if from < 0 || from >= to {
+		h.writeError(w, http.StatusBadRequest, "INVALID_RANGE", fmt.Sprintf("Leaf range must satisfy 0 <= from < to <= %d", leafCount))
+		return
+	}
+	if to-from > MaxTreeLeafRange {
+		h.writeError(w, http.StatusBadRequest, "RANGE_TOO_LARGE", fmt.Sprintf("Leaf range may span at most %d leaves", MaxTreeLeafRange))
+		return
+	}
+
+	maxDepth := h.parseIntParam(r, "max_depth", tree.Depth())
+	if maxDepth < 0 || maxDepth > tree.Depth() {
+		maxDepth = tree.Depth()
+	}
+
+	for _, index := range highlightIndices {
+		if index >= leafCount {
+			h.writeError(w, http.StatusBadRequest, "INVALID_HIGHLIGHT", fmt.Sprintf("Leaf index %d out of range [0, %d)", index, leafCount))
+			return
+		}
+	}
+
+	// Map leaves to proofs where proof artifacts exist
+	proofIDs := make(map[string]uuid.UUID)
+	proofs, err := h.repos.ProofArtifacts.GetProofsByBatch(ctx, batchID)
+	if err != nil {
+		h.logger.Printf("Error getting batch proofs: %v", err)
+	}
+	for _, p := range proofs {
+		proofIDs[p.AccumTxHash] = p.ProofID
+	}
+
+	roles := treeNodeRoles(tree, highlightIndices)
+
+	response := BatchTreeResponse{
+		BatchID:     batch.BatchID,
+		BatchStatus: string(batch.Status),
+		MerkleRoot:  hex.EncodeToString(tree.Root()),
+		Provisional: batch.Status == database.BatchStatusPending,
+		LeafCount:   leafCount,
+		TreeDepth:   tree.Depth(),
+		RangeFrom:   from,
+		RangeTo:     to,
+		MaxDepth:    maxDepth,
+		Truncated:   from > 0 || to < leafCount || maxDepth < tree.Depth(),
+		Levels:      buildTreeLevels(tree, from, to, maxDepth, roles),
+		Leaves:      []TreeLeaf{},
+		Paths:       []HighlightedPath{},
+	}
+	if !response.Provisional {
+		response.StoredBatchRoot = hex.EncodeToString(batch.MerkleRoot)
+		response.RootMatchesBatch = bytes.Equal(batch.MerkleRoot, tree.Root())
+	}
+
+	highlighted := make(map[int]bool, len(highlightIndices))
+	for _, index := range highlightIndices {
+		highlighted[index] = true
+	}
+
+	for _, tx := range txs[from:to] {
+		leaf := TreeLeaf{
+			Index:       tx.TreeIndex,
+			LeafHash:    hex.EncodeToString(tx.TxHash),
+			AccumTxHash: tx.AccumTxHash,
+			AccountURL:  tx.AccountURL,
+			Highlighted: highlighted[tx.TreeIndex],
+		}
+		if proofID, ok := proofIDs[tx.AccumTxHash]; ok {
+			leaf.ProofID = &proofID
+		}
+		response.Leaves = append(response.Leaves, leaf)
+	}
+
+	for index := range highlighted {
+		tx := txs[index]
+		merklePath, err := tree.Path(index)
+		if err != nil {
+			h.logger.Printf("Error computing merkle path: %v", err)
+			h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to compute merkle path")
+			return
+		}
+		nodeIndices := make([]int, tree.Depth()+1)
+		for height := range nodeIndices {
+			nodeIndices[height] = index >> uint(height)
+		}
+		response.Paths = append(response.Paths, HighlightedPath{
+			LeafIndex:   index,
+			LeafHash:    hex.EncodeToString(tx.TxHash),
+			AccumTxHash: tx.AccumTxHash,
+			NodeIndices: nodeIndices,
+			MerklePath:  toMerklePathEntries(merklePath),
+		})
+	}
+	sort.Slice(response.Paths, func(i, j int) bool {
+		return response.Paths[i].LeafIndex < response.Paths[j].LeafIndex
+	})
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// treeNodeRoles marks the path and sibling nodes of the highlighted leaves,
+// keyed by height then node index. A node on one path stays "path" even if
+// it is also the sibling of another.
+func treeNodeRoles(tree *merkle.Tree, highlights []int) []map[int]string {
+	levels := tree.Levels()
+	roles := make([]map[int]string, len(levels))
+	for height := range roles {
+		roles[height] = make(map[int]string)
+	}
+
+	for _, leaf := range highlights {
+		for height, level := range levels {
+			index := leaf >> uint(height)
+			roles[height][index] = TreeNodeRolePath
+			sibling := index ^ 1
+			if height < len(levels)-1 && sibling < len(level) && roles[height][sibling] == "" {
+				roles[height][sibling] = TreeNodeRoleSibling
+			}
+		}
+	}
+
+	return roles
+}
+
+// buildTreeLevels returns the levels from the root down to maxDepth, each
+// limited to the nodes covering leaves [from, to)
+func buildTreeLevels(tree *merkle.Tree, from, to, maxDepth int, roles []map[int]string) []TreeLevel {
+	levels := tree.Levels()
+	result := make([]TreeLevel, 0, maxDepth+1)
+
+	for depth := 0; depth <= maxDepth; depth++ {
+		height := tree.Depth() - depth
+		level := levels[height]
+		first := from >> uint(height)
+		last := (to - 1) >> uint(height)
+
+		nodes := make([]TreeNode, 0, last-first+1)
+		for index := first; index <= last; index++ {
+			node := TreeNode{Index: index, Hash: hex.EncodeToString(level[index])}
+			if roles != nil {
+				node.Role = roles[height][index]
+			}
+			nodes = append(nodes, node)
+		}
+
+		result = append(result, TreeLevel{
+			Depth:      depth,
+			Height:     height,
+			NodeCount:  len(level),
+			FirstIndex: first,
+			Nodes:      nodes,
+		})
+	}
+
+	return result
+}
+
+// =============================================================================
+// MERKLE AUDIT FINDINGS ENDPOINT
+// =============================================================================
+
+// HandleListAuditFindings handles GET /api/v1/audit/merkle/findings
+// Query parameters: batch_id, type, status (open|resolved|all), limit, offset
+func (h *MerkleHandlers) HandleListAuditFindings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &database.MerkleAuditFindingFilter{
+		Status: query.Get("status"),
+		Limit:  h.parseIntParam(r, "limit", 50),
+		Offset: h.parseIntParam(r, "offset", 0),
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+
+	switch filter.Status {
+	case "", "open", "resolved", "all":
+	default:
+		h.writeError(w, http.StatusBadRequest, "INVALID_STATUS", "status must be one of: open, resolved, all")
+		return
+	}
+
+	if batchIDStr := query.Get("batch_id"); batchIDStr != "" {
+		batchID, err := uuid.Parse(batchIDStr)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "INVALID_BATCH_ID", "Invalid batch ID format")
+			return
+		}
+		filter.BatchID = &batchID
+	}
+
+	if typeStr := query.Get("type"); typeStr != "" {
+		findingType := database.MerkleFindingType(typeStr)
+		switch findingType {
+		case database.MerkleFindingTreeRebuildFailed, database.MerkleFindingBatchRootMismatch,
+			database.MerkleFindingAnchorRootMismatch, database.MerkleFindingProofRootMismatch,
+			database.MerkleFindingProofLeafMismatch, database.MerkleFindingLeafPathMismatch:
+		default:
+			h.writeError(w, http.StatusBadRequest, "INVALID_FINDING_TYPE", fmt.Sprintf("Unknown finding type: %s", typeStr))
+			return
+		}
+		filter.FindingType = &findingType
+	}
+
+	ctx := r.Context()
+	findings, err := h.repos.MerkleAudit.ListFindings(ctx, filter)
+	if err != nil {
+		h.logger.Printf("Error listing audit findings: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve audit findings")
+		return
+	}
+	if findings == nil {
+		findings = []*database.MerkleAuditFinding{}
+	}
+
+	response := map[string]interface{}{
+		"findings": findings,
+		"count":    len(findings),
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+	}
+
+	latestRun, err := h.repos.MerkleAudit.GetLatestRun(ctx)
+	switch {
+	case err == nil:
+		response["latest_run"] = latestRun
+	case !errors.Is(err, database.ErrMerkleAuditRunNotFound):
+		h.logger.Printf("Error getting latest audit run: %v", err)
+	}
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// =============================================================================
+// HELPER METHODS
+// =============================================================================
+
+func (h *MerkleHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *MerkleHandlers) writeError(w http.ResponseWriter, status int, code, message string) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+func (h *MerkleHandlers) parseIntParam(r *http.Request, name string, defaultVal int) int {
+	valStr := r.URL.Query().Get(name)
+	if valStr == "" {
+		return defaultVal
+	}
+	val, err := strconv.Atoi(valStr)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
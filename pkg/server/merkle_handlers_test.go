@@ -0,0 +1,152 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for Merkle Handlers
+// Tests request validation and tree windowing without requiring database connection
+
+package server
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/certen/proofs-service/pkg/merkle"
+)
+
+// ============================================================================
+// Request Validation Tests
+// ============================================================================
+
+func TestHandleGetProofMerkle_MethodNotAllowed(t *testing.T) {
+	handlers := NewMerkleHandlers(nil, "test", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/proofs/00000000-0000-0000-0000-000000000000/merkle", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleGetProofMerkle(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestHandleGetProofMerkle_InvalidUUID(t *testing.T) {
+	handlers := NewMerkleHandlers(nil, "test", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/not-a-uuid/merkle", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleGetProofMerkle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleGetBatchTree_InvalidBatchID(t *testing.T) {
+	handlers := NewMerkleHandlers(nil, "test", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/batches/not-a-uuid/tree", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleGetBatchTree(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleGetBatchTree_InvalidHighlight(t *testing.T) {
+	handlers := NewMerkleHandlers(nil, "test", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/batches/00000000-0000-0000-0000-000000000000/tree?highlight=1,x", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleGetBatchTree(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleListAuditFindings_InvalidStatus(t *testing.T) {
+	handlers := NewMerkleHandlers(nil, "test", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit/merkle/findings?status=closed", nil)
+	w := httptest.NewRecorder()
+	handlers.HandleListAuditFindings(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+// ============================================================================
+// Tree Window Tests
+// ============================================================================
+
+func testTree(t *testing.T, n int) *merkle.Tree {
+	t.Helper()
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("leaf-%d", i)))
+		leaves[i] = sum[:]
+	}
+	tree, err := merkle.NewTree(leaves)
+	if err != nil {
+		t.Fatalf("NewTree failed: %v", err)
+	}
+	return tree
+}
+
+func TestBuildTreeLevels_FullTree(t *testing.T) {
+	tree := testTree(t, 5)
+	levels := buildTreeLevels(tree, 0, 5, tree.Depth(), nil)
+
+	if len(levels) != tree.Depth()+1 {
+		t.Fatalf("Expected %d levels, got %d", tree.Depth()+1, len(levels))
+	}
+	if len(levels[0].Nodes) != 1 || levels[0].Height != tree.Depth() {
+		t.Errorf("Expected root level first, got %+v", levels[0])
+	}
+	if last := levels[len(levels)-1]; len(last.Nodes) != 5 || last.Height != 0 {
+		t.Errorf("Expected 5 leaves on the last level, got %d", len(last.Nodes))
+	}
+}
+
+func TestBuildTreeLevels_RangeAndDepth(t *testing.T) {
+	tree := testTree(t, 16)
+
+	// Leaves 4..7 sit under node 1 at height 2
+	levels := buildTreeLevels(tree, 4, 8, tree.Depth(), nil)
+	for _, level := range levels {
+		span := 4 >> uint(level.Height)
+		if span == 0 {
+			span = 1
+		}
+		if len(level.Nodes) != span {
+			t.Errorf("Expected %d nodes at height %d, got %d", span, level.Height, len(level.Nodes))
+		}
+	}
+	if levels[2].FirstIndex != 1 {
+		t.Errorf("Expected first index 1 at height 2, got %d", levels[2].FirstIndex)
+	}
+
+	shallow := buildTreeLevels(tree, 0, 16, 1, nil)
+	if len(shallow) != 2 {
+		t.Errorf("Expected 2 levels for max_depth 1, got %d", len(shallow))
+	}
+}
+
+func TestTreeNodeRoles(t *testing.T) {
+	tree := testTree(t, 8)
+	roles := treeNodeRoles(tree, []int{2, 3})
+
+	if roles[0][2] != TreeNodeRolePath || roles[0][3] != TreeNodeRolePath {
+		t.Error("Expected highlighted leaves to be path nodes, not siblings")
+	}
+	if roles[1][0] != TreeNodeRoleSibling {
+		t.Errorf("Expected node 0 at height 1 to be a sibling, got '%s'", roles[1][0])
+	}
+	if roles[tree.Depth()][0] != TreeNodeRolePath {
+		t.Error("Expected root to be on the path")
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2025 Certen Protocol
+//
+// Rate Limit Middleware
+// Limits requests per API key, token subject or anonymous client IP
+//
+// Requests are counted in fixed windows of RATE_LIMIT_WINDOW seconds. An API
+// key may spend its rate_limit_per_min (scaled to the window) per window;
+// tokens get the limit of their derived key, validator client certificates
+// get RATE_LIMIT_VALIDATOR_REQUESTS, and anonymous callers share
+// RATE_LIMIT_REQUESTS per client IP. Each request costs the cost of its
+// route class, so one bulk export uses up as much of the limit as several
+// reads.
+//
+// The per-IP limit runs in front of authentication (WrapClientIP), so
+// requests that are rejected there still count: a request without
+// credentials is charged before it is authenticated, a request whose
+// credentials are rejected is charged afterwards, and an IP over its limit
+// cannot have credentials checked at all. Per-key and per-subject limits run
+// after authentication (Wrap).
+//
+// Responses carry the IETF RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers. A request over the limit gets
+// 429 RATE_LIMITED with Retry-After. Counters live in memory unless they are
+// shared through Postgres, in which case all replicas enforce one limit. If
+// the counter store fails the request is allowed.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// Rate limit headers
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// rateLimitStore is implemented by database.RateLimitRepository and
+// memoryRateLimitStore
+type rateLimitStore interface {
+	IncrementCounter(ctx context.Context, bucket string, windowStart time.Time, cost int, expiresAt time.Time) (int, error)
+	PurgeExpiredCounters(ctx context.Context, now time.Time) (int64, error)
+}
+
+// RateLimitConfig contains configuration for the rate limit middleware
+type RateLimitConfig struct {
+	Window           time.Duration  // Length of a counting window
+	AnonymousLimit   int            // Cost per window per client IP, and for keys without a limit
+	CertificateLimit int            // Cost per window per validator client certificate; AnonymousLimit if unset
+	RouteCosts       map[string]int // Cost by path prefix (longest match); 1 otherwise, 0 exempts
+	Shared           bool           // Count in Postgres so replicas share limits
+}
+
+// RateLimitMiddleware enforces per-caller request limits
+type RateLimitMiddleware struct {
+	store     rateLimitStore
+	config    *RateLimitConfig
+	logger    *log.Logger
+	now       func() time.Time
+	lastPurge atomic.Int64 // Unix nanoseconds of the last counter purge
+}
+
+// NewRateLimitMiddleware creates a new rate limit middleware. Counters are
+// kept in memory unless config.Shared is set and repositories are available.
+func NewRateLimitMiddleware(
+	repos *database.Repositories,
+	config *RateLimitConfig,
+	logger *log.Logger,
+) *RateLimitMiddleware {
+	var store rateLimitStore = newMemoryRateLimitStore()
+	if config != nil && config.Shared && repos != nil {
+		store = repos.RateLimits
+	}
+	return newRateLimitMiddleware(store, config, logger)
+}
+
+func newRateLimitMiddleware(store rateLimitStore, config *RateLimitConfig, logger *log.Logger) *RateLimitMiddleware {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[RateLimit] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &RateLimitConfig{}
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.AnonymousLimit <= 0 {
+		config.AnonymousLimit = 100
+	}
+
+	m := &RateLimitMiddleware{
+		store:  store,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+	m.lastPurge.Store(m.now().UnixNano())
+	return m
+}
+
+type ipChargedContextKey struct{}
+
+// WrapClientIP returns next with the per-IP limit for requests without valid
+// credentials. It must run outside the authentication middleware.
+func (m *RateLimitMiddleware) WrapClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost := m.routeCost(r.URL.Path)
+		if cost == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bucket, limit := "ip:"+getClientIP(r), m.config.AnonymousLimit
+		if !hasCredentials(r) {
+			if m.charge(w, r, bucket, limit, cost) {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ipChargedContextKey{}, true)))
+			}
+			return
+		}
+
+		// Refuse to check credentials for an IP that is already at its
+		// limit, and charge it for credentials that are rejected
+		if !m.peek(w, r, bucket, limit, cost) {
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == http.StatusUnauthorized {
+			m.count(r.Context(), bucket, cost)
+		}
+	})
+}
+
+// Wrap returns next with per-key and per-subject rate limiting. It must run
+// inside the authentication middleware so the request's principal is known.
+// Anonymous requests not already charged by WrapClientIP are charged to
+// their client IP.
+func (m *RateLimitMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost := m.routeCost(r.URL.Path)
+		if cost == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal := PrincipalFromContext(r.Context())
+		if principal == nil {
+			if charged, _ := r.Context().Value(ipChargedContextKey{}).(bool); charged {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if m.charge(w, r, "ip:"+getClientIP(r), m.config.AnonymousLimit, cost) {
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+
+		bucket, limit := m.bucket(principal)
+		if m.charge(w, r, bucket, limit, cost) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// charge adds cost to a bucket and sets the rate limit headers. It writes a
+// 429 and returns false when the bucket is over its limit.
+func (m *RateLimitMiddleware) charge(w http.ResponseWriter, r *http.Request, bucket string, limit, cost int) bool {
+	total, reset, ok := m.count(r.Context(), bucket, cost)
+	if !ok {
+		return true
+	}
+	return m.admit(w, limit, total, total, reset)
+}
+
+// peek is charge without the charge: it refuses a request that would take
+// the bucket over its limit
+func (m *RateLimitMiddleware) peek(w http.ResponseWriter, r *http.Request, bucket string, limit, cost int) bool {
+	total, reset, ok := m.count(r.Context(), bucket, 0)
+	if !ok {
+		return true
+	}
+	return m.admit(w, limit, total, total+cost, reset)
+}
+
+// count adds cost to a bucket's current window and returns its total and
+// the seconds until the window resets. ok is false if the store failed.
+func (m *RateLimitMiddleware) count(ctx context.Context, bucket string, cost int) (total, reset int, ok bool) {
+	now := m.now()
+	windowStart := now.Truncate(m.config.Window)
+	windowEnd := windowStart.Add(m.config.Window)
+
+	total, err := m.store.IncrementCounter(ctx, bucket, windowStart.UTC(), cost, windowEnd.UTC())
+	if err != nil {
+		m.logger.Printf("Rate limit check failed for %s: %v", bucket, err)
+		return 0, 0, false
+	}
+	m.maybePurge(now)
+	return total, int(math.Ceil(windowEnd.Sub(now).Seconds())), true
+}
+
+// admit sets the rate limit headers for a bucket at total, and writes a 429
+// and returns false if needed exceeds the limit
+func (m *RateLimitMiddleware) admit(w http.ResponseWriter, limit, total, needed, reset int) bool {
+	h := w.Header()
+	h.Set(RateLimitLimitHeader, strconv.Itoa(limit))
+	h.Set(RateLimitRemainingHeader, strconv.Itoa(maxInt(limit-total, 0)))
+	h.Set(RateLimitResetHeader, strconv.Itoa(reset))
+	h.Set(RateLimitPolicyHeader, fmt.Sprintf("%d;w=%d", limit, int(m.config.Window.Seconds())))
+
+	if needed > limit {
+		h.Set("Retry-After", strconv.Itoa(reset))
+		m.writeError(w, http.StatusTooManyRequests, "RATE_LIMITED",
+			fmt.Sprintf("Rate limit of %d per %s exceeded", limit, m.config.Window))
+		return false
+	}
+	return true
+}
+
+// bucket returns the counter and per-window limit for an authenticated caller
+func (m *RateLimitMiddleware) bucket(principal *Principal) (string, int) {
+	limit := m.config.AnonymousLimit
+	if perMin := principal.APIKey.RateLimitPerMin; perMin > 0 {
+		limit = maxInt(int(math.Ceil(float64(perMin)*m.config.Window.Minutes())), 1)
+	}
+	switch principal.Type {
+	case PrincipalTypeToken:
+		return "jwt:" + principal.Claims.Subject, limit
+	case PrincipalTypeCertificate:
+		if m.config.CertificateLimit > 0 {
+			limit = m.config.CertificateLimit
+		}
+		return "cert:" + *principal.APIKey.ValidatorID, limit
+	}
+	return "key:" + principal.APIKey.KeyID.String(), limit
+}
+
+// hasCredentials reports whether a request carries an API key, a verified
+// client certificate or a bearer token
+func hasCredentials(r *http.Request) bool {
+	if r.Header.Get("X-API-Key") != "" || r.URL.Query().Get("api_key") != "" {
+		return true
+	}
+	if verifiedClientCertificate(r) != nil {
+		return true
+	}
+	_, ok := bearerToken(r)
+	return ok
+}
+
+// routeCost returns the cost of the longest matching RouteCosts prefix
+func (m *RateLimitMiddleware) routeCost(path string) int {
+	cost, matched := 1, 0
+	for prefix, c := range m.config.RouteCosts {
+		if len(prefix) > matched && strings.HasPrefix(path, prefix) {
+			cost, matched = c, len(prefix)
+		}
+	}
+	return cost
+}
+
+// maybePurge deletes ended windows in the background, at most once per window
+func (m *RateLimitMiddleware) maybePurge(now time.Time) {
+	last := m.lastPurge.Load()
+	if now.UnixNano()-last < int64(m.config.Window) || !m.lastPurge.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.Window)
+		defer cancel()
+		if _, err := m.store.PurgeExpiredCounters(ctx, now.UTC()); err != nil {
+			m.logger.Printf("Rate limit counter purge failed: %v", err)
+		}
+	}()
+}
+
+func (m *RateLimitMiddleware) writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	}); err != nil {
+		m.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// =============================================================================
+// IN-MEMORY COUNTERS
+// =============================================================================
+
+type memoryCounterKey struct {
+	bucket      string
+	windowStart int64
+}
+
+type memoryCounter struct {
+	cost      int
+	expiresAt time.Time
+}
+
+// memoryRateLimitStore keeps counters for a single replica
+type memoryRateLimitStore struct {
+	mu       sync.Mutex
+	counters map[memoryCounterKey]*memoryCounter
+}
+
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{counters: make(map[memoryCounterKey]*memoryCounter)}
+}
+
+func (s *memoryRateLimitStore) IncrementCounter(ctx context.Context, bucket string, windowStart time.Time, cost int, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryCounterKey{bucket: bucket, windowStart: windowStart.UnixNano()}
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &memoryCounter{expiresAt: expiresAt}
+		s.counters[key] = counter
+	}
+	counter.cost += cost
+	return counter.cost, nil
+}
+
+func (s *memoryRateLimitStore) PurgeExpiredCounters(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for key, counter := range s.counters {
+		if !counter.expiresAt.After(now) {
+			delete(s.counters, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
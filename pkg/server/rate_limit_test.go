@@ -0,0 +1,226 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for the Rate Limit Middleware
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/auth"
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+var rateLimitNow = time.Date(2025, 6, 1, 12, 0, 10, 0, time.UTC)
+
+func testRateLimitMiddleware(store rateLimitStore, config *RateLimitConfig) (*RateLimitMiddleware, *time.Time) {
+	m := newRateLimitMiddleware(store, config, nil)
+	now := rateLimitNow
+	m.now = func() time.Time { return now }
+	m.lastPurge.Store(now.UnixNano())
+	return m, &now
+}
+
+func rateLimitRequest(m *RateLimitMiddleware, path, ip string, principal *Principal) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	if principal != nil {
+		req = req.WithContext(WithPrincipal(req.Context(), principal))
+	}
+	w := httptest.NewRecorder()
+	m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})).ServeHTTP(w, req)
+	return w
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) IncrementCounter(context.Context, string, time.Time, int, time.Time) (int, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func (failingRateLimitStore) PurgeExpiredCounters(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+// ============================================================================
+// Limit Tests
+// ============================================================================
+
+func TestRateLimit_AnonymousPerIP(t *testing.T) {
+	m, _ := testRateLimitMiddleware(newMemoryRateLimitStore(), &RateLimitConfig{Window: time.Minute, AnonymousLimit: 2})
+
+	for i := 0; i < 2; i++ {
+		if w := rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", nil); w.Code != http.StatusNoContent {
+			t.Fatalf("Request %d: expected status 204, got %d", i+1, w.Code)
+		}
+	}
+
+	w := rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "50" {
+		t.Errorf("Expected Retry-After 50, got %q", got)
+	}
+	if w.Header().Get(RateLimitRemainingHeader) != "0" || w.Header().Get(RateLimitPolicyHeader) != "2;w=60" {
+		t.Errorf("Unexpected headers: %v", w.Header())
+	}
+
+	// Another client IP has its own limit
+	w = rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.2", nil)
+	if w.Code != http.StatusNoContent || w.Header().Get(RateLimitRemainingHeader) != "1" {
+		t.Errorf("Expected a separate limit per IP, got status %d, remaining %q", w.Code, w.Header().Get(RateLimitRemainingHeader))
+	}
+}
+
+func TestRateLimit_PerKeyLimit(t *testing.T) {
+	m, _ := testRateLimitMiddleware(newMemoryRateLimitStore(), &RateLimitConfig{Window: 30 * time.Second, AnonymousLimit: 1})
+
+	// 4 per minute over a 30 second window allows 2 requests
+	key := &Principal{Type: PrincipalTypeAPIKey, APIKey: &database.APIKey{KeyID: uuid.New(), RateLimitPerMin: 4}}
+	for i, want := range []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests} {
+		w := rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", key)
+		if w.Code != want {
+			t.Errorf("Request %d: expected status %d, got %d", i+1, want, w.Code)
+		}
+		if w.Header().Get(RateLimitLimitHeader) != "2" {
+			t.Errorf("Request %d: expected RateLimit-Limit 2, got %q", i+1, w.Header().Get(RateLimitLimitHeader))
+		}
+	}
+
+	// A token is limited by subject, separately from the key and the IP
+	token := &Principal{Type: PrincipalTypeToken, APIKey: &database.APIKey{RateLimitPerMin: 100}, Claims: &auth.Claims{Subject: "user-1"}}
+	if w := rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", token); w.Code != http.StatusNoContent {
+		t.Errorf("Expected token request to be allowed, got %d", w.Code)
+	}
+}
+
+func TestRateLimit_CertificateLimit(t *testing.T) {
+	m, _ := testRateLimitMiddleware(newMemoryRateLimitStore(), &RateLimitConfig{Window: time.Minute, AnonymousLimit: 1, CertificateLimit: 2})
+
+	validatorID := "validator-1"
+	cert := &Principal{Type: PrincipalTypeCertificate, APIKey: certificateAPIKey(validatorID)}
+	for i, want := range []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests} {
+		w := rateLimitRequest(m, "/api/v1/proofs/ingest", "10.0.0.1", cert)
+		if w.Code != want {
+			t.Errorf("Request %d: expected status %d, got %d", i+1, want, w.Code)
+		}
+		if w.Header().Get(RateLimitLimitHeader) != "2" {
+			t.Errorf("Request %d: expected RateLimit-Limit 2, got %q", i+1, w.Header().Get(RateLimitLimitHeader))
+		}
+	}
+
+	// The same IP still has its own anonymous limit
+	if w := rateLimitRequest(m, "/api/v1/proofs/ingest", "10.0.0.1", nil); w.Code != http.StatusNoContent {
+		t.Errorf("Expected anonymous request to be allowed, got %d", w.Code)
+	}
+}
+
+func TestRateLimit_ClientIPBeforeAuthentication(t *testing.T) {
+	m, _ := testRateLimitMiddleware(newMemoryRateLimitStore(), &RateLimitConfig{Window: time.Minute, AnonymousLimit: 2})
+	handler := m.WrapClientIP(testAuthMiddleware(t, false).Wrap(m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))))
+	send := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/query", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	valid := testBearerToken(t, "proofs:read", time.Now().Add(time.Hour))
+
+	// Valid credentials are not charged to the IP
+	for i := 0; i < 3; i++ {
+		if code := send(valid); code != http.StatusNoContent {
+			t.Fatalf("Request %d: expected valid token to be allowed, got %d", i+1, code)
+		}
+	}
+
+	// Rejected credentials are, and once the IP is over its limit neither
+	// anonymous requests nor credentials are checked
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if code := send("not-a-token"); code != want {
+			t.Errorf("Rejected token %d: expected status %d, got %d", i+1, want, code)
+		}
+	}
+	if code := send(""); code != http.StatusTooManyRequests {
+		t.Errorf("Expected anonymous request to be limited, got %d", code)
+	}
+	if code := send(valid); code != http.StatusTooManyRequests {
+		t.Errorf("Expected credentials from a limited IP to be refused, got %d", code)
+	}
+}
+
+func TestRateLimit_RouteCosts(t *testing.T) {
+	m, _ := testRateLimitMiddleware(newMemoryRateLimitStore(), &RateLimitConfig{
+		Window:         time.Minute,
+		AnonymousLimit: 6,
+		RouteCosts: map[string]int{
+			"/health":                     0,
+			"/api/v1/proofs/bulk/":        5,
+			"/api/v1/proofs/bulk/export/": 1,
+		},
+	})
+
+	if w := rateLimitRequest(m, "/api/v1/proofs/bulk/export", "10.0.0.1", nil); w.Code != http.StatusNoContent || w.Header().Get(RateLimitRemainingHeader) != "1" {
+		t.Fatalf("Expected bulk request to cost 5, got status %d, remaining %q", w.Code, w.Header().Get(RateLimitRemainingHeader))
+	}
+	if w := rateLimitRequest(m, "/api/v1/proofs/bulk/export/"+uuid.NewString(), "10.0.0.1", nil); w.Code != http.StatusNoContent {
+		t.Errorf("Expected the longer prefix to cost 1, got status %d", w.Code)
+	}
+	if w := rateLimitRequest(m, "/api/v1/proofs/bulk/import", "10.0.0.1", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected second bulk request to be limited, got %d", w.Code)
+	}
+
+	// Exempt routes are neither counted nor limited
+	w := rateLimitRequest(m, "/health", "10.0.0.1", nil)
+	if w.Code != http.StatusNoContent || w.Header().Get(RateLimitLimitHeader) != "" {
+		t.Errorf("Expected /health to be exempt, got status %d, headers %v", w.Code, w.Header())
+	}
+}
+
+func TestRateLimit_WindowResetAndPurge(t *testing.T) {
+	store := newMemoryRateLimitStore()
+	m, now := testRateLimitMiddleware(store, &RateLimitConfig{Window: time.Minute, AnonymousLimit: 1})
+
+	rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", nil)
+	if w := rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", nil); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+
+	*now = now.Add(time.Minute)
+	m.lastPurge.Store(now.UnixNano()) // Purge explicitly below
+	if w := rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", nil); w.Code != http.StatusNoContent {
+		t.Errorf("Expected a new window to allow the request, got %d", w.Code)
+	}
+
+	if n, _ := store.PurgeExpiredCounters(context.Background(), *now); n != 1 {
+		t.Errorf("Expected the ended window to be purged, purged %d", n)
+	}
+	if len(store.counters) != 1 {
+		t.Errorf("Expected only the current window to remain, got %d counters", len(store.counters))
+	}
+}
+
+func TestRateLimit_StoreFailureAllows(t *testing.T) {
+	m, _ := testRateLimitMiddleware(failingRateLimitStore{}, &RateLimitConfig{AnonymousLimit: 1})
+
+	for i := 0; i < 3; i++ {
+		if w := rateLimitRequest(m, "/api/v1/proofs/query", "10.0.0.1", nil); w.Code != http.StatusNoContent {
+			t.Errorf("Request %d: expected status 204 when the store fails, got %d", i+1, w.Code)
+		}
+	}
+}
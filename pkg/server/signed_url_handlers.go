@@ -0,0 +1,230 @@
+// Copyright 2025 Certen Protocol
+//
+// Signed URL Handlers
+// Mints signed download URLs for one bundle or export job
+//
+// Endpoints:
+// - POST /api/v1/downloads/signed-urls - Mint a signed URL
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// proofLookup is implemented by database.ProofArtifactRepository
+type proofLookup interface {
+	GetProofByID(ctx context.Context, proofID uuid.UUID) (*database.ProofArtifact, error)
+}
+
+// signedURLStore is implemented by database.SignedURLRepository
+type signedURLStore interface {
+	CreateSignedURL(ctx context.Context, input *database.NewSignedDownloadURL) (*database.SignedDownloadURL, error)
+}
+
+// exportJobLookup is implemented by BulkHandlers
+type exportJobLookup interface {
+	CompletedExportExpiry(jobID uuid.UUID) (time.Time, bool)
+}
+
+// SignedURLHandlersConfig contains configuration for signed URL handlers
+type SignedURLHandlersConfig struct {
+	Signer     *SignedURLSigner // Nil disables minting
+	Exports    exportJobLookup  // Export jobs that URLs can be minted for
+	DefaultTTL time.Duration    // Lifetime when expires_in is omitted
+	MaxTTL     time.Duration    // Longest lifetime a URL may have
+}
+
+// SignedURLHandlers provides HTTP handlers for signed download URLs
+type SignedURLHandlers struct {
+	proofs proofLookup
+	urls   signedURLStore
+	config *SignedURLHandlersConfig
+	logger *log.Logger
+}
+
+// NewSignedURLHandlers creates new signed URL handlers
+func NewSignedURLHandlers(repos *database.Repositories, config *SignedURLHandlersConfig, logger *log.Logger) *SignedURLHandlers {
+	var proofs proofLookup
+	var urls signedURLStore
+	if repos != nil {
+		proofs = repos.ProofArtifacts
+		urls = repos.SignedURLs
+	}
+	return newSignedURLHandlers(proofs, urls, config, logger)
+}
+
+func newSignedURLHandlers(proofs proofLookup, urls signedURLStore, config *SignedURLHandlersConfig, logger *log.Logger) *SignedURLHandlers {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[SignedURLAPI] ", log.LstdFlags)
+	}
+	if config == nil {
+		config = &SignedURLHandlersConfig{}
+	}
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = time.Hour
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = 7 * 24 * time.Hour
+	}
+	return &SignedURLHandlers{
+		proofs: proofs,
+		urls:   urls,
+		config: config,
+		logger: logger,
+	}
+}
+
+// CreateSignedURLRequest is the body of POST /api/v1/downloads/signed-urls
+type CreateSignedURLRequest struct {
+	ResourceType string    `json:"resource_type"` // bundle or export
+	ResourceID   uuid.UUID `json:"resource_id"`   // Proof ID or export job ID
+	ExpiresIn    int       `json:"expires_in,omitempty"`
+	SingleUse    bool      `json:"single_use"`
+}
+
+// CreateSignedURLResponse describes a minted URL
+type CreateSignedURLResponse struct {
+	URLID        uuid.UUID `json:"url_id"`
+	URL          string    `json:"url"` // Path and query, relative to the API host
+	ResourceType string    `json:"resource_type"`
+	ResourceID   uuid.UUID `json:"resource_id"`
+	SingleUse    bool      `json:"single_use"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// =============================================================================
+// SIGNED URL ENDPOINT
+// =============================================================================
+
+// HandleCreateSignedURL handles POST /api/v1/downloads/signed-urls
+func (h *SignedURLHandlers) HandleCreateSignedURL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+		return
+	}
+
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "An API key or bearer token is required")
+		return
+	}
+	if h.config.Signer == nil {
+		h.writeError(w, http.StatusServiceUnavailable, "SIGNED_URLS_DISABLED", "Signed URLs are not configured")
+		return
+	}
+
+	var req CreateSignedURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON body")
+		return
+	}
+	if req.ResourceID == uuid.Nil {
+		h.writeError(w, http.StatusBadRequest, "INVALID_RESOURCE_ID", "resource_id is required")
+		return
+	}
+
+	ttl := h.config.DefaultTTL
+	if req.ExpiresIn != 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if ttl <= 0 || ttl > h.config.MaxTTL {
+		h.writeError(w, http.StatusBadRequest, "INVALID_EXPIRY",
+			fmt.Sprintf("expires_in must be between 1 and %d seconds", int(h.config.MaxTTL.Seconds())))
+		return
+	}
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Second)
+
+	ctx := r.Context()
+	switch req.ResourceType {
+	case database.SignedResourceBundle:
+		if !principal.APIKey.CanReadProofs {
+			h.writeError(w, http.StatusForbidden, "FORBIDDEN", "Credentials do not have the can_read_proofs permission")
+			return
+		}
+		proof, err := h.proofs.GetProofByID(ctx, req.ResourceID)
+		if err != nil {
+			h.logger.Printf("Error getting proof %s: %v", req.ResourceID, err)
+			h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve proof")
+			return
+		}
+		if proof == nil {
+			h.writeError(w, http.StatusNotFound, "PROOF_NOT_FOUND", fmt.Sprintf("No proof found with ID: %s", req.ResourceID))
+			return
+		}
+	case database.SignedResourceExport:
+		if !principal.APIKey.CanBulkDownload {
+			h.writeError(w, http.StatusForbidden, "FORBIDDEN", "Credentials do not have the can_bulk_download permission")
+			return
+		}
+		var fileExpiresAt time.Time
+		ok := false
+		if h.config.Exports != nil {
+			fileExpiresAt, ok = h.config.Exports.CompletedExportExpiry(req.ResourceID)
+		}
+		if !ok {
+			h.writeError(w, http.StatusNotFound, "JOB_NOT_FOUND", fmt.Sprintf("No completed export job found with ID: %s", req.ResourceID))
+			return
+		}
+		// The URL cannot outlive the export file
+		if fileExpiresAt.Before(expiresAt) {
+			expiresAt = fileExpiresAt.UTC().Truncate(time.Second)
+		}
+	default:
+		h.writeError(w, http.StatusBadRequest, "INVALID_RESOURCE_TYPE", "resource_type must be one of: bundle, export")
+		return
+	}
+
+	signedURL, err := h.urls.CreateSignedURL(ctx, &database.NewSignedDownloadURL{
+		ResourceType:   req.ResourceType,
+		ResourceID:     req.ResourceID,
+		SingleUse:      req.SingleUse,
+		ExpiresAt:      expiresAt,
+		CreatedByKeyID: principal.KeyID(),
+		CreatedBy:      principal.Name(),
+	})
+	if err != nil {
+		h.logger.Printf("Error creating signed URL: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create signed URL")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, &CreateSignedURLResponse{
+		URLID:        signedURL.URLID,
+		URL:          h.config.Signer.Sign(signedURL),
+		ResourceType: signedURL.ResourceType,
+		ResourceID:   signedURL.ResourceID,
+		SingleUse:    signedURL.SingleUse,
+		ExpiresAt:    signedURL.ExpiresAt,
+	})
+}
+
+// =============================================================================
+// HELPER METHODS
+// =============================================================================
+
+func (h *SignedURLHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *SignedURLHandlers) writeError(w http.ResponseWriter, status int, code, message string) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 Certen Protocol
+//
+// Signed Download URLs
+// Lets a third party download one bundle or export without an API key
+//
+// A signed URL is the download path of one proof bundle
+// (/api/v1/proofs/{proof_id}/bundle) or finished export
+// (/api/v1/proofs/bulk/download/{job_id}) with url_id, expires and signature
+// query parameters. The signature is an HMAC-SHA256, keyed with
+// DOWNLOAD_URL_SECRET, over the URL ID, resource and expiry, so none of them
+// can be changed.
+//
+// Allow serves a request carrying a valid signature for its exact path
+// without authentication and puts a SignedURLGrant in its context. The
+// download handler then redeems the URL in signed_download_urls, which
+// rejects expired and already used single-use URLs, and records the
+// download with the URL's ID.
+
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+// Signed URL query parameters
+const (
+	SignedURLIDParam        = "url_id"
+	SignedURLExpiresParam   = "expires"
+	SignedURLSignatureParam = "signature"
+)
+
+// SignedURLGrant is the access a verified signed URL gives its request
+type SignedURLGrant struct {
+	URLID        uuid.UUID
+	ResourceType string
+	ResourceID   uuid.UUID
+	ExpiresAt    time.Time
+}
+
+type signedURLGrantContextKey struct{}
+
+// WithSignedURLGrant returns a context carrying a signed URL grant
+func WithSignedURLGrant(ctx context.Context, grant *SignedURLGrant) context.Context {
+	return context.WithValue(ctx, signedURLGrantContextKey{}, grant)
+}
+
+// SignedURLGrantFromContext returns the signed URL grant of a request, or nil
+func SignedURLGrantFromContext(ctx context.Context) *SignedURLGrant {
+	grant, _ := ctx.Value(signedURLGrantContextKey{}).(*SignedURLGrant)
+	return grant
+}
+
+// errSignatureInvalid is returned for signatures that do not verify
+var errSignatureInvalid = errors.New("signature is invalid")
+
+// errSignedURLsUnavailable is returned when a signed URL cannot be redeemed
+// because there is no database
+var errSignedURLsUnavailable = errors.New("signed URLs require a database")
+
+// SignedURLSigner signs and verifies download URLs
+type SignedURLSigner struct {
+	secret []byte
+	logger *log.Logger
+	now    func() time.Time
+}
+
+// NewSignedURLSigner creates a signer keyed with secret. An empty secret
+// disables signed URLs and returns nil.
+func NewSignedURLSigner(secret []byte, logger *log.Logger) *SignedURLSigner {
+	if len(secret) == 0 {
+		return nil
+	}
+	if logger == nil {
+		logger = log.New(log.Writer(), "[SignedURL] ", log.LstdFlags)
+	}
+	return &SignedURLSigner{
+		secret: secret,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SignedURLPath returns the download path of a signed URL resource
+func SignedURLPath(resourceType string, resourceID uuid.UUID) string {
+	if resourceType == database.SignedResourceExport {
+		return "/api/v1/proofs/bulk/download/" + resourceID.String()
+	}
+	return "/api/v1/proofs/" + resourceID.String() + "/bundle"
+}
+
+// Sign returns the signed path and query of a minted URL
+func (s *SignedURLSigner) Sign(u *database.SignedDownloadURL) string {
+	query := url.Values{}
+	query.Set(SignedURLIDParam, u.URLID.String())
+	query.Set(SignedURLExpiresParam, strconv.FormatInt(u.ExpiresAt.Unix(), 10))
+	query.Set(SignedURLSignatureParam, s.signature(u.URLID, u.ResourceType, u.ResourceID, u.ExpiresAt.Unix()))
+	return SignedURLPath(u.ResourceType, u.ResourceID) + "?" + query.Encode()
+}
+
+func (s *SignedURLSigner) signature(urlID uuid.UUID, resourceType string, resourceID uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d", urlID, resourceType, resourceID, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Allow returns a handler that serves requests with a signature through
+// signed and all others through fallback. Without a signer every request
+// goes to fallback.
+func (s *SignedURLSigner) Allow(signed, fallback http.Handler) http.Handler {
+	if s == nil {
+		return fallback
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !r.URL.Query().Has(SignedURLSignatureParam) {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		grant, err := s.verify(r)
+		switch {
+		case errors.Is(err, database.ErrSignedURLNotRedeemable):
+			s.writeError(w, http.StatusGone, "URL_EXPIRED", "Signed URL has expired")
+			return
+		case err != nil:
+			s.writeError(w, http.StatusForbidden, "INVALID_SIGNATURE", "Signed URL is invalid")
+			return
+		}
+		signed.ServeHTTP(w, r.WithContext(WithSignedURLGrant(r.Context(), grant)))
+	})
+}
+
+// verify checks a request's signature against its path
+func (s *SignedURLSigner) verify(r *http.Request) (*SignedURLGrant, error) {
+	query := r.URL.Query()
+	urlID, err := uuid.Parse(query.Get(SignedURLIDParam))
+	if err != nil {
+		return nil, errSignatureInvalid
+	}
+	expires, err := strconv.ParseInt(query.Get(SignedURLExpiresParam), 10, 64)
+	if err != nil {
+		return nil, errSignatureInvalid
+	}
+
+	// The resource is the one the path names; the signature binds it
+	for _, resourceType := range []string{database.SignedResourceBundle, database.SignedResourceExport} {
+		resourceID, ok := signedURLResource(resourceType, r.URL.Path)
+		if !ok {
+			continue
+		}
+		expected := s.signature(urlID, resourceType, resourceID, expires)
+		if !hmac.Equal([]byte(expected), []byte(query.Get(SignedURLSignatureParam))) {
+			return nil, errSignatureInvalid
+		}
+		expiresAt := time.Unix(expires, 0).UTC()
+		if !s.now().Before(expiresAt) {
+			return nil, database.ErrSignedURLNotRedeemable
+		}
+		return &SignedURLGrant{URLID: urlID, ResourceType: resourceType, ResourceID: resourceID, ExpiresAt: expiresAt}, nil
+	}
+	return nil, errSignatureInvalid
+}
+
+// redeemSignedURL redeems the signed URL a request was let in by, if any,
+// and returns its ID for the download log. It returns
+// database.ErrSignedURLNotRedeemable when the URL has expired or was single
+// use and already redeemed, and errSignedURLsUnavailable without
+// repositories.
+func redeemSignedURL(r *http.Request, repos *database.Repositories) (*uuid.UUID, error) {
+	grant := SignedURLGrantFromContext(r.Context())
+	if grant == nil {
+		return nil, nil
+	}
+	if repos == nil || repos.SignedURLs == nil {
+		return nil, errSignedURLsUnavailable
+	}
+	redeemed, err := repos.SignedURLs.RedeemSignedURL(r.Context(), grant.URLID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &redeemed.URLID, nil
+}
+
+// signedURLResource returns the resource ID if path is the download path of
+// a resource of the given type
+func signedURLResource(resourceType, path string) (uuid.UUID, bool) {
+	id := strings.TrimPrefix(path, "/api/v1/proofs/")
+	if resourceType == database.SignedResourceExport {
+		id = strings.TrimPrefix(id, "bulk/download/")
+	}
+	resourceID, err := uuid.Parse(strings.TrimSuffix(id, "/bundle"))
+	if err != nil || SignedURLPath(resourceType, resourceID) != path {
+		return uuid.Nil, false
+	}
+	return resourceID, true
+}
+
+func (s *SignedURLSigner) writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	}); err != nil {
+		s.logger.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for Signed Download URLs
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+func testSignedURL(resourceType string, expiresAt time.Time) *database.SignedDownloadURL {
+	return &database.SignedDownloadURL{
+		URLID:        uuid.New(),
+		ResourceType: resourceType,
+		ResourceID:   uuid.New(),
+		ExpiresAt:    expiresAt,
+	}
+}
+
+// serveSigned serves target through Allow and returns the status and the
+// grant the signed handler saw
+func serveSigned(signer *SignedURLSigner, target string) (int, *SignedURLGrant, bool) {
+	var grant *SignedURLGrant
+	fellBack := false
+	signed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grant = SignedURLGrantFromContext(r.Context())
+	})
+	fallback := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fellBack = true
+	})
+	rec := httptest.NewRecorder()
+	signer.Allow(signed, fallback).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec.Code, grant, fellBack
+}
+
+// ============================================================================
+// Signing Tests
+// ============================================================================
+
+func TestSignedURL_Verifies(t *testing.T) {
+	signer := NewSignedURLSigner([]byte("secret"), nil)
+
+	for _, resourceType := range []string{database.SignedResourceBundle, database.SignedResourceExport} {
+		u := testSignedURL(resourceType, time.Now().Add(time.Hour))
+		target := signer.Sign(u)
+		if !strings.HasPrefix(target, SignedURLPath(resourceType, u.ResourceID)+"?") {
+			t.Fatalf("Unexpected signed URL %s", target)
+		}
+
+		status, grant, _ := serveSigned(signer, target)
+		if status != http.StatusOK || grant == nil {
+			t.Fatalf("Expected %s URL to verify, got %d", resourceType, status)
+		}
+		if grant.URLID != u.URLID || grant.ResourceType != resourceType || grant.ResourceID != u.ResourceID {
+			t.Errorf("Unexpected grant %+v", grant)
+		}
+	}
+}
+
+func TestSignedURL_RejectsTampering(t *testing.T) {
+	signer := NewSignedURLSigner([]byte("secret"), nil)
+	u := testSignedURL(database.SignedResourceBundle, time.Now().Add(time.Hour))
+	target, _ := url.Parse(signer.Sign(u))
+
+	tamper := func(edit func(path string, query url.Values) string) string {
+		query := target.Query()
+		path := edit(target.Path, query)
+		return path + "?" + query.Encode()
+	}
+	tests := map[string]string{
+		"other proof": tamper(func(path string, query url.Values) string {
+			return SignedURLPath(database.SignedResourceBundle, uuid.New())
+		}),
+		"other resource type": tamper(func(path string, query url.Values) string {
+			return SignedURLPath(database.SignedResourceExport, u.ResourceID)
+		}),
+		"later expiry": tamper(func(path string, query url.Values) string {
+			query.Set(SignedURLExpiresParam, "99999999999")
+			return path
+		}),
+		"other url id": tamper(func(path string, query url.Values) string {
+			query.Set(SignedURLIDParam, uuid.New().String())
+			return path
+		}),
+		"other endpoint": tamper(func(path string, query url.Values) string {
+			return "/api/v1/proofs/" + u.ResourceID.String() + "/custody"
+		}),
+	}
+	for name, target := range tests {
+		if status, grant, _ := serveSigned(signer, target); status != http.StatusForbidden || grant != nil {
+			t.Errorf("%s: expected 403, got %d", name, status)
+		}
+	}
+
+	other := NewSignedURLSigner([]byte("other secret"), nil)
+	if status, _, _ := serveSigned(other, signer.Sign(u)); status != http.StatusForbidden {
+		t.Errorf("Expected a URL signed with another secret to be rejected, got %d", status)
+	}
+}
+
+func TestSignedURL_Expired(t *testing.T) {
+	signer := NewSignedURLSigner([]byte("secret"), nil)
+	u := testSignedURL(database.SignedResourceExport, time.Now().Add(-time.Second))
+
+	if status, grant, _ := serveSigned(signer, signer.Sign(u)); status != http.StatusGone || grant != nil {
+		t.Errorf("Expected 410 for an expired URL, got %d", status)
+	}
+}
+
+func TestRedeemSignedURL_WithoutRepositories(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/proofs/"+uuid.New().String()+"/bundle", nil)
+	if urlID, err := redeemSignedURL(req, nil); urlID != nil || err != nil {
+		t.Errorf("Expected a request without a grant to pass, got %v, %v", urlID, err)
+	}
+
+	grant := &SignedURLGrant{URLID: uuid.New(), ResourceType: database.SignedResourceBundle, ResourceID: uuid.New()}
+	req = req.WithContext(WithSignedURLGrant(req.Context(), grant))
+	for name, repos := range map[string]*database.Repositories{"nil": nil, "empty": {}} {
+		if _, err := redeemSignedURL(req, repos); !errors.Is(err, errSignedURLsUnavailable) {
+			t.Errorf("%s repositories: expected errSignedURLsUnavailable, got %v", name, err)
+		}
+	}
+}
+
+func TestSignedURL_Fallback(t *testing.T) {
+	signer := NewSignedURLSigner([]byte("secret"), nil)
+	if _, grant, fellBack := serveSigned(signer, "/api/v1/proofs/"+uuid.New().String()+"/bundle"); !fellBack || grant != nil {
+		t.Error("Expected a request without a signature to use the fallback handler")
+	}
+
+	// Without a secret, signed URLs are ignored
+	disabled := NewSignedURLSigner(nil, nil)
+	if disabled != nil {
+		t.Fatal("Expected an empty secret to disable signing")
+	}
+	u := testSignedURL(database.SignedResourceBundle, time.Now().Add(time.Hour))
+	if _, _, fellBack := serveSigned(disabled, signer.Sign(u)); !fellBack {
+		t.Error("Expected a disabled signer to use the fallback handler")
+	}
+}
+
+// ============================================================================
+// Mint Handler Tests
+// ============================================================================
+
+type stubExportJobs map[uuid.UUID]time.Time
+
+func (s stubExportJobs) CompletedExportExpiry(jobID uuid.UUID) (time.Time, bool) {
+	expiresAt, ok := s[jobID]
+	return expiresAt, ok
+}
+
+type stubProofs map[uuid.UUID]*database.ProofArtifact
+
+func (s stubProofs) GetProofByID(ctx context.Context, proofID uuid.UUID) (*database.ProofArtifact, error) {
+	return s[proofID], nil
+}
+
+type memorySignedURLStore struct {
+	created []*database.SignedDownloadURL
+}
+
+func (s *memorySignedURLStore) CreateSignedURL(ctx context.Context, input *database.NewSignedDownloadURL) (*database.SignedDownloadURL, error) {
+	u := &database.SignedDownloadURL{
+		URLID:        uuid.New(),
+		ResourceType: input.ResourceType,
+		ResourceID:   input.ResourceID,
+		SingleUse:    input.SingleUse,
+		ExpiresAt:    input.ExpiresAt,
+		CreatedBy:    input.CreatedBy,
+	}
+	s.created = append(s.created, u)
+	return u, nil
+}
+
+func mintSignedURL(h *SignedURLHandlers, principal *Principal, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/downloads/signed-urls", strings.NewReader(body))
+	req = req.WithContext(WithPrincipal(req.Context(), principal))
+	rec := httptest.NewRecorder()
+	h.HandleCreateSignedURL(rec, req)
+	return rec
+}
+
+func TestCreateSignedURL_Bundle(t *testing.T) {
+	signer := NewSignedURLSigner([]byte("secret"), nil)
+	proofID := uuid.New()
+	store := &memorySignedURLStore{}
+	h := newSignedURLHandlers(stubProofs{proofID: {ProofID: proofID}}, store, &SignedURLHandlersConfig{Signer: signer}, nil)
+
+	rec := mintSignedURL(h, keyPrincipal("customer"), `{"resource_type":"bundle","resource_id":"`+proofID.String()+`","single_use":true}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp CreateSignedURLResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(store.created) != 1 || resp.URLID != store.created[0].URLID || !resp.SingleUse {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+	if status, grant, _ := serveSigned(signer, resp.URL); status != http.StatusOK || grant == nil || grant.ResourceID != proofID {
+		t.Errorf("Expected the minted URL to verify, got %d", status)
+	}
+}
+
+func TestCreateSignedURL_UnknownProof(t *testing.T) {
+	store := &memorySignedURLStore{}
+	h := newSignedURLHandlers(stubProofs{}, store, &SignedURLHandlersConfig{
+		Signer: NewSignedURLSigner([]byte("secret"), nil),
+	}, nil)
+
+	rec := mintSignedURL(h, keyPrincipal("customer"), `{"resource_type":"bundle","resource_id":"`+uuid.New().String()+`"}`)
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "PROOF_NOT_FOUND") {
+		t.Errorf("Expected 404 PROOF_NOT_FOUND, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(store.created) != 0 {
+		t.Error("Expected no signed URL to be created")
+	}
+}
+
+func TestCreateSignedURL_Rejects(t *testing.T) {
+	signer := NewSignedURLSigner([]byte("secret"), nil)
+	readOnly := keyPrincipal("customer")
+	bulk := keyPrincipal("customer")
+	bulk.APIKey.CanBulkDownload = true
+	noRead := keyPrincipal("customer")
+	noRead.APIKey.CanReadProofs = false
+
+	resourceID := uuid.New().String()
+	tests := []struct {
+		name      string
+		signer    *SignedURLSigner
+		principal *Principal
+		body      string
+		want      int
+	}{
+		{"no credentials", signer, nil, `{"resource_type":"bundle","resource_id":"` + resourceID + `"}`, http.StatusUnauthorized},
+		{"disabled", nil, readOnly, `{"resource_type":"bundle","resource_id":"` + resourceID + `"}`, http.StatusServiceUnavailable},
+		{"bundle without can_read_proofs", signer, noRead, `{"resource_type":"bundle","resource_id":"` + resourceID + `"}`, http.StatusForbidden},
+		{"export without can_bulk_download", signer, readOnly, `{"resource_type":"export","resource_id":"` + resourceID + `"}`, http.StatusForbidden},
+		{"unknown export", signer, bulk, `{"resource_type":"export","resource_id":"` + resourceID + `"}`, http.StatusNotFound},
+		{"unknown type", signer, readOnly, `{"resource_type":"batch","resource_id":"` + resourceID + `"}`, http.StatusBadRequest},
+		{"missing id", signer, readOnly, `{"resource_type":"bundle"}`, http.StatusBadRequest},
+		{"too long", signer, readOnly, `{"resource_type":"bundle","resource_id":"` + resourceID + `","expires_in":700000}`, http.StatusBadRequest},
+		{"negative expiry", signer, readOnly, `{"resource_type":"bundle","resource_id":"` + resourceID + `","expires_in":-5}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewSignedURLHandlers(nil, &SignedURLHandlersConfig{
+				Signer:  tt.signer,
+				Exports: stubExportJobs{},
+			}, nil)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/downloads/signed-urls", strings.NewReader(tt.body))
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), tt.principal))
+			}
+			rec := httptest.NewRecorder()
+			h.HandleCreateSignedURL(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Certen Protocol
+//
+// TLS Serving
+// Serves HTTPS with certificates that are reloaded when their files change
+//
+// The certificate and key are read from TLS_CERT_FILE and TLS_KEY_FILE and
+// checked for changes every TLS_RELOAD_INTERVAL, so a renewed certificate is
+// picked up without a restart. A pair that fails to load is logged and the
+// previous certificate is kept.
+//
+// The validator listener additionally requires a client certificate issued by
+// VALIDATOR_CLIENT_CA_FILE. The certificate's subject common name is the
+// validator ID; see the authentication middleware.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertReloaderConfig contains configuration for the certificate reloader
+type CertReloaderConfig struct {
+	CertFile string        // PEM certificate chain
+	KeyFile  string        // PEM private key
+	Interval time.Duration // Time between checks for changed files
+}
+
+// CertReloader holds the serving certificate and reloads it on file change
+type CertReloader struct {
+	config *CertReloaderConfig
+	logger *log.Logger
+
+	cert     atomic.Pointer[tls.Certificate]
+	certMod  time.Time
+	keyMod   time.Time
+	reloadMu sync.Mutex
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewCertReloader creates a certificate reloader and loads the certificate
+func NewCertReloader(config *CertReloaderConfig, logger *log.Logger) (*CertReloader, error) {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[TLS] ", log.LstdFlags)
+	}
+	if config == nil || config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("certificate and key files are required")
+	}
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+
+	c := &CertReloader{
+		config: config,
+		logger: logger,
+	}
+	if _, err := c.ReloadIfChanged(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate returns the current certificate; it is used as
+// tls.Config.GetCertificate
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load(), nil
+}
+
+// ReloadIfChanged loads the certificate and key if either file has been
+// modified since the last load, and reports whether it did
+func (c *CertReloader) ReloadIfChanged() (bool, error) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	certInfo, err := os.Stat(c.config.CertFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat certificate: %w", err)
+	}
+	keyInfo, err := os.Stat(c.config.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat key: %w", err)
+	}
+	if c.cert.Load() != nil && certInfo.ModTime().Equal(c.certMod) && keyInfo.ModTime().Equal(c.keyMod) {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.config.CertFile, c.config.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	c.cert.Store(&cert)
+	c.certMod, c.keyMod = certInfo.ModTime(), keyInfo.ModTime()
+	return true, nil
+}
+
+// Start launches the reload loop in the background
+func (c *CertReloader) Start() {
+	c.stopCh = make(chan struct{})
+	c.wg.Add(1)
+	go c.run()
+}
+
+// Stop signals the reload loop to exit and waits for it
+func (c *CertReloader) Stop() {
+	if c.stopCh != nil {
+		close(c.stopCh)
+	}
+	c.wg.Wait()
+}
+
+func (c *CertReloader) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			reloaded, err := c.ReloadIfChanged()
+			if err != nil {
+				c.logger.Printf("Certificate reload failed, keeping the current certificate: %v", err)
+			} else if reloaded {
+				c.logger.Printf("Reloaded TLS certificate from %s", c.config.CertFile)
+			}
+		}
+	}
+}
+
+// NewServerTLSConfig returns a TLS configuration serving the reloader's
+// certificate
+func NewServerTLSConfig(reloader *CertReloader) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+}
+
+// NewValidatorTLSConfig returns a TLS configuration that serves the
+// reloader's certificate and requires a client certificate issued by a CA
+// in caFile
+func NewValidatorTLSConfig(reloader *CertReloader, caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", caFile)
+	}
+
+	config := NewServerTLSConfig(reloader)
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.ClientCAs = pool
+	return config, nil
+}
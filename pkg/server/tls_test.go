@@ -0,0 +1,221 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for TLS serving and validator client certificates
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key signed by the CA
+func (ca *testCA) issue(t *testing.T, commonName string, serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey failed: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeTestFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+}
+
+func servingSerial(t *testing.T, reloader *CertReloader) int64 {
+	t.Helper()
+
+	cert, _ := reloader.GetCertificate(nil)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %v", err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+// ============================================================================
+// Certificate Reload Tests
+// ============================================================================
+
+func TestCertReloader_ReloadsChangedFiles(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	modTime := time.Now().Add(-time.Hour)
+
+	certPEM, keyPEM := ca.issue(t, "server", 10, x509.ExtKeyUsageServerAuth)
+	writeTestFile(t, certFile, certPEM, modTime)
+	writeTestFile(t, keyFile, keyPEM, modTime)
+
+	reloader, err := NewCertReloader(&CertReloaderConfig{CertFile: certFile, KeyFile: keyFile}, nil)
+	if err != nil {
+		t.Fatalf("NewCertReloader failed: %v", err)
+	}
+	if servingSerial(t, reloader) != 10 {
+		t.Fatalf("Expected the initial certificate to be served")
+	}
+	if reloaded, err := reloader.ReloadIfChanged(); reloaded || err != nil {
+		t.Errorf("Expected no reload for unchanged files, got %v, %v", reloaded, err)
+	}
+
+	// A broken certificate is reported and the previous one kept
+	writeTestFile(t, certFile, []byte("not a certificate"), modTime.Add(time.Minute))
+	if _, err := reloader.ReloadIfChanged(); err == nil {
+		t.Error("Expected an error for a broken certificate")
+	}
+	if servingSerial(t, reloader) != 10 {
+		t.Error("Expected the previous certificate to be kept")
+	}
+
+	certPEM, keyPEM = ca.issue(t, "server", 11, x509.ExtKeyUsageServerAuth)
+	writeTestFile(t, certFile, certPEM, modTime.Add(2*time.Minute))
+	writeTestFile(t, keyFile, keyPEM, modTime.Add(2*time.Minute))
+	if reloaded, err := reloader.ReloadIfChanged(); !reloaded || err != nil {
+		t.Fatalf("Expected a reload, got %v, %v", reloaded, err)
+	}
+	if servingSerial(t, reloader) != 11 {
+		t.Error("Expected the renewed certificate to be served")
+	}
+}
+
+func TestNewCertReloader_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewCertReloader(&CertReloaderConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: filepath.Join(dir, "missing.key")}, nil); err == nil {
+		t.Error("Expected an error for missing files")
+	}
+	if _, err := NewCertReloader(&CertReloaderConfig{}, nil); err == nil {
+		t.Error("Expected an error without files")
+	}
+}
+
+// ============================================================================
+// Validator Listener Tests
+// ============================================================================
+
+func TestValidatorListener_ClientCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt")
+	certPEM, keyPEM := ca.issue(t, "server", 10, x509.ExtKeyUsageServerAuth)
+	writeTestFile(t, certFile, certPEM, time.Now())
+	writeTestFile(t, keyFile, keyPEM, time.Now())
+	writeTestFile(t, caFile, ca.pem, time.Now())
+
+	reloader, err := NewCertReloader(&CertReloaderConfig{CertFile: certFile, KeyFile: keyFile}, nil)
+	if err != nil {
+		t.Fatalf("NewCertReloader failed: %v", err)
+	}
+	tlsConfig, err := NewValidatorTLSConfig(reloader, caFile)
+	if err != nil {
+		t.Fatalf("NewValidatorTLSConfig failed: %v", err)
+	}
+
+	var principal *Principal
+	authn := NewAuthMiddleware(nil, nil, nil)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	srv := &http.Server{Handler: authn.Wrap(authn.Require(PermissionSubmitProofs, principalHandler(&principal)))}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+	}
+	url := "https://" + ln.Addr().String() + "/api/v1/proofs/ingest"
+
+	clientPEM, clientKeyPEM := ca.issue(t, "validator-1", 20, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair failed: %v", err)
+	}
+	resp, err := client(clientCert).Post(url, "application/json", nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || principal == nil {
+		t.Fatalf("Expected the certificate to be accepted, got status %d", resp.StatusCode)
+	}
+	if principal.Type != PrincipalTypeCertificate || principal.KeyID() != nil || *principal.APIKey.ValidatorID != "validator-1" || !principal.APIKey.CanSubmitProofs {
+		t.Errorf("Unexpected principal: %+v", principal.APIKey)
+	}
+
+	// Without a client certificate the handshake fails
+	if resp, err := client().Post(url, "application/json", nil); err == nil {
+		resp.Body.Close()
+		t.Error("Expected a request without a client certificate to fail")
+	}
+}
@@ -9,6 +9,10 @@
 // - GET /api/v1/intents/{intentId}/attestations - Validator signatures
 // - GET /api/v1/user/{userId}/intents           - User's intent list
 // - GET /api/v1/audit/intents                   - Audit search
+//
+// A user's intent list is only returned to that user's token, to organisation
+// admins (limited to their organisation's intents) and to auditor keys. The
+// audit search is limited to auditor keys.
 
 package server
 
@@ -211,6 +215,11 @@ func (h *TransactionCenterHandlers) HandleGetUserIntents(w http.ResponseWriter,
 		return
 	}
 
+	organizationADI, ok := h.authorizeUserRead(w, r, userID)
+	if !ok {
+		return
+	}
+
 	// Parse pagination params
 	limit := h.parseIntParam(r, "limit", 50)
 	offset := h.parseIntParam(r, "offset", 0)
@@ -219,7 +228,7 @@ func (h *TransactionCenterHandlers) HandleGetUserIntents(w http.ResponseWriter,
 	}
 
 	ctx := r.Context()
-	intents, err := h.repos.ProofArtifacts.GetIntentsByUserID(ctx, userID, limit, offset)
+	intents, err := h.repos.ProofArtifacts.GetIntentsByUserID(ctx, userID, organizationADI, limit, offset)
 	if err != nil {
 		h.logger.Printf("Error getting user intents: %v", err)
 		h.writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to retrieve intents")
@@ -246,6 +255,16 @@ func (h *TransactionCenterHandlers) HandleSearchAuditTrail(w http.ResponseWriter
 		return
 	}
 
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "An auditor API key is required")
+		return
+	}
+	if !principal.IsAuditor() {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "The audit search requires an auditor API key")
+		return
+	}
+
 	// Build filter from query params
 	filter := &database.IntentFilter{
 		Limit:  h.parseIntParam(r, "limit", 50),
@@ -334,6 +353,22 @@ func (h *TransactionCenterHandlers) HandleIntentRouting(w http.ResponseWriter, r
 // HELPER METHODS
 // ============================================================================
 
+// authorizeUserRead checks that the caller may read userID's intents and
+// returns the organisation the results are limited to ("" for none)
+func (h *TransactionCenterHandlers) authorizeUserRead(w http.ResponseWriter, r *http.Request, userID string) (string, bool) {
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Credentials are required to read user intents")
+		return "", false
+	}
+	organizationADI, ok := userIntentScope(principal, userID)
+	if !ok {
+		h.writeError(w, http.StatusForbidden, "FORBIDDEN", "Credentials may not read this user's intents")
+		return "", false
+	}
+	return organizationADI, true
+}
+
 func (h *TransactionCenterHandlers) writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
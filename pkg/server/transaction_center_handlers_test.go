@@ -0,0 +1,125 @@
+// Copyright 2025 Certen Protocol
+//
+// Unit tests for Transaction Center access control
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/certen/proofs-service/pkg/auth"
+	"github.com/certen/proofs-service/pkg/database"
+)
+
+func keyPrincipal(clientType string) *Principal {
+	return &Principal{Type: PrincipalTypeAPIKey, APIKey: &database.APIKey{KeyID: uuid.New(), ClientType: clientType, CanReadProofs: true}}
+}
+
+func tokenPrincipal(subject, orgADI string, scopes ...string) *Principal {
+	claims := &auth.Claims{Subject: subject, OrgADI: orgADI, Scopes: append([]string{auth.ScopeProofsRead}, scopes...)}
+	return &Principal{Type: PrincipalTypeToken, APIKey: tokenAPIKey(claims), Claims: claims}
+}
+
+// ============================================================================
+// Access Control Tests
+// ============================================================================
+
+func TestUserIntentScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		wantOrg   string
+		wantOK    bool
+	}{
+		{"anonymous", nil, "", false},
+		{"own user", tokenPrincipal("user-1", ""), "", true},
+		{"other user", tokenPrincipal("user-2", "acc://acme.acme"), "", false},
+		{"organisation admin", tokenPrincipal("user-2", "acc://acme.acme", auth.ScopeOrgAdmin), "acc://acme.acme", true},
+		{"admin scope without organisation", tokenPrincipal("user-2", "", auth.ScopeOrgAdmin), "", false},
+		{"auditor key", keyPrincipal("auditor"), "", true},
+		{"internal key", keyPrincipal("internal"), "", true},
+		{"developer key", keyPrincipal("developer"), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, ok := userIntentScope(tt.principal, "user-1")
+			if org != tt.wantOrg || ok != tt.wantOK {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.wantOrg, tt.wantOK, org, ok)
+			}
+		})
+	}
+}
+
+func TestIntentListScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		want      *database.IntentScope
+		wantOK    bool
+	}{
+		{"anonymous", nil, nil, false},
+		{"auditor key", keyPrincipal("auditor"), nil, true},
+		{"internal key", keyPrincipal("internal"), nil, true},
+		{"developer key", keyPrincipal("developer"), nil, false},
+		{"user token", tokenPrincipal("user-1", "acc://acme.acme"), &database.IntentScope{UserID: "user-1"}, true},
+		{"org admin token", tokenPrincipal("user-1", "acc://acme.acme", auth.ScopeOrgAdmin), &database.IntentScope{UserID: "user-1", OrganizationADI: "acc://acme.acme"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, ok := intentListScope(tt.principal)
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok %v, got %v", tt.wantOK, ok)
+			}
+			if (scope == nil) != (tt.want == nil) || (scope != nil && *scope != *tt.want) {
+				t.Errorf("Expected scope %+v, got %+v", tt.want, scope)
+			}
+		})
+	}
+}
+
+func TestUserIntents_RequireAccess(t *testing.T) {
+	txCenter := NewTransactionCenterHandlers(nil, "validator-1", nil)
+	lifecycle := NewIntentLifecycleHandlers(nil, nil)
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		path      string
+		principal *Principal
+		want      int
+	}{
+		{"user intents anonymous", txCenter.HandleGetUserIntents, "/api/v1/user/user-1/intents", nil, http.StatusUnauthorized},
+		{"user intents other user", txCenter.HandleGetUserIntents, "/api/v1/user/user-1/intents", tokenPrincipal("user-2", ""), http.StatusForbidden},
+		{"user intents developer key", txCenter.HandleGetUserIntents, "/api/v1/user/user-1/intents", keyPrincipal("developer"), http.StatusForbidden},
+		{"lifecycle anonymous", lifecycle.HandleListByUser, "/api/v1/intent/user/user-1", nil, http.StatusUnauthorized},
+		{"lifecycle other user", lifecycle.HandleListByUser, "/api/v1/intent/user/user-1", tokenPrincipal("user-2", ""), http.StatusForbidden},
+		{"recent anonymous", lifecycle.HandleListRecent, "/api/v1/intent/recent", nil, http.StatusUnauthorized},
+		{"recent developer key", lifecycle.HandleListRecent, "/api/v1/intent/recent", keyPrincipal("developer"), http.StatusForbidden},
+		{"status anonymous", lifecycle.HandleListByStatus, "/api/v1/intent/status/anchored", nil, http.StatusUnauthorized},
+		{"status developer key", lifecycle.HandleListByStatus, "/api/v1/intent/status/anchored", keyPrincipal("developer"), http.StatusForbidden},
+		{"audit anonymous", txCenter.HandleSearchAuditTrail, "/api/v1/audit/intents", nil, http.StatusUnauthorized},
+		{"audit token", txCenter.HandleSearchAuditTrail, "/api/v1/audit/intents", tokenPrincipal("user-1", "acc://acme.acme", auth.ScopeOrgAdmin), http.StatusForbidden},
+		{"audit developer key", txCenter.HandleSearchAuditTrail, "/api/v1/audit/intents", keyPrincipal("developer"), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+			tt.handler(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}